@@ -0,0 +1,74 @@
+// The reference DNS provider plugin, serving the in-memory DNS provider over
+// the gRPC contract of pkg/dns/plugin. It is meant as an example for the teams
+// writing plugins for their own DNS, and to test the grpc DNS provider locally.
+package main
+
+import (
+	"flag"
+	"os"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	genericapiserver "k8s.io/apiserver/pkg/server"
+
+	"github.com/kuadrant/kcp-glbc/pkg/_internal/env"
+	"github.com/kuadrant/kcp-glbc/pkg/_internal/log"
+	dnsMemory "github.com/kuadrant/kcp-glbc/pkg/dns/memory"
+	dnsPlugin "github.com/kuadrant/kcp-glbc/pkg/dns/plugin"
+)
+
+var options struct {
+	// The address the plugin listens on
+	Address string
+	// The port of the embedded DNS server
+	DNSPort int
+}
+
+func init() {
+	flag.StringVar(&options.Address, "address", env.GetEnvString(dnsPlugin.AddressEnvVar, "unix:///tmp/glbc-dns-plugin.sock"), "The address the plugin listens on, either a Unix socket (unix://<path>) or a TCP address (<host>:<port>)")
+	flag.IntVar(&options.DNSPort, "dns-port", env.GetEnvInt(dnsMemory.PortEnvVar, dnsMemory.DefaultPort), "The UDP and TCP port of the embedded DNS server, serving the published records")
+
+	opts := log.Options{
+		EncoderConfigOptions: []log.EncoderConfigOption{
+			func(c *zapcore.EncoderConfig) {
+				c.ConsoleSeparator = " "
+			},
+		},
+		ZapOpts: []zap.Option{
+			zap.AddCaller(),
+		},
+	}
+	opts.BindFlags(flag.CommandLine)
+	flag.Parse()
+
+	log.Logger = log.New(log.UseFlagOptions(&opts))
+}
+
+func main() {
+	ctx := genericapiserver.SetupSignalContext()
+
+	provider, err := dnsMemory.NewProvider(dnsMemory.Config{Port: options.DNSPort})
+	exitOnError(err, "Failed to create memory DNS provider")
+	defer func() { _ = provider.Shutdown() }()
+
+	listener, err := dnsPlugin.Listen(options.Address)
+	exitOnError(err, "Failed to listen on "+options.Address)
+
+	server := dnsPlugin.NewServer(provider)
+	go func() {
+		<-ctx.Done()
+		server.GracefulStop()
+	}()
+
+	log.Logger.Info("Serving DNS provider plugin", "address", options.Address)
+	err = server.Serve(listener)
+	exitOnError(err, "Failed to serve DNS provider plugin")
+}
+
+func exitOnError(err error, msg string) {
+	if err != nil {
+		log.Logger.Error(err, msg)
+		os.Exit(1)
+	}
+}
@@ -37,6 +37,7 @@ import (
 	"github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1/helper"
 	conditionsutil "github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/util/conditions"
 	kcp "github.com/kcp-dev/kcp/pkg/client/clientset/versioned"
+	kcpinformers "github.com/kcp-dev/kcp/pkg/client/informers/externalversions"
 	"github.com/kcp-dev/logicalcluster/v2"
 
 	"github.com/kuadrant/kcp-glbc/pkg/_internal/log"
@@ -71,6 +72,32 @@ var options struct {
 	Domain string
 	// The DNS provider
 	DNSProvider string
+	// The owner ID recorded in the ownership records of the managed names
+	DNSOwnerID string
+	// The DNS zones the records are published to, with their domain
+	DNSZones string
+	// The interval between the audits of the DNS zones
+	DNSAuditInterval time.Duration
+	// Whether the records that no DNSRecord owns are deleted from the DNS zones
+	DNSDeleteOrphanedRecords bool
+	// The interval between the garbage collections of the orphaned health checks
+	DNSHealthCheckCollectionInterval time.Duration
+	// The time an orphaned health check is kept for before it is deleted
+	DNSHealthCheckGracePeriod time.Duration
+	// The name servers managed hosts are looked up against
+	Nameservers string
+	// Whether generated hosts are routed based on the continent of the clients
+	GeoRouting bool
+	// How the targets given by a host name are published
+	HostTargetMode string
+	// The CIDRs of the target addresses published to the public zones, despite being denied
+	DNSTargetAllowCIDRs string
+	// The CIDRs of the target addresses that are not published to the public zones
+	DNSTargetDenyCIDRs string
+	// Where the CAA records authorizing the certificate authorities are published
+	DNSCAAMode string
+	// The domains of the certificate authorities authorized by the CAA records
+	DNSCAAIssuers string
 	// The AWS Route53 region
 	Region string
 	// The port number of the metrics endpoint
@@ -95,7 +122,21 @@ func init() {
 	flagSet.StringVar(&options.TLSProvider, "glbc-tls-provider", env.GetEnvString("GLBC_TLS_PROVIDER", "glbc-ca"), "The TLS certificate issuer, one of [glbc-ca, le-staging, le-production]")
 	// DNS management options
 	flagSet.StringVar(&options.Domain, "domain", env.GetEnvString("GLBC_DOMAIN", "dev.hcpapps.net"), "The domain to use to expose ingresses")
-	flag.StringVar(&options.DNSProvider, "dns-provider", env.GetEnvString("GLBC_DNS_PROVIDER", "fake"), "The DNS provider being used [aws, fake]")
+	flag.StringVar(&options.DNSProvider, "dns-provider", env.GetEnvString("GLBC_DNS_PROVIDER", "fake"), "The DNS provider being used [aws, azure, gcp, rfc2136, memory, grpc, externaldns, fake]")
+	flag.StringVar(&options.DNSOwnerID, "dns-owner-id", env.GetEnvString("GLBC_DNS_OWNER_ID", dns.DefaultOwnerID), "The owner ID recorded in the TXT ownership records of the managed names, must be unique across the GLBC deployments sharing a DNS zone")
+	flag.StringVar(&options.DNSZones, "dns-zones", env.GetEnvString("GLBC_DNS_ZONES", ""), "Comma separated list of DNS zones (<domain>=<zone id>, or <domain>=private:<zone id> for the private zones), the records being published to the zone whose domain is the longest suffix of their names. Defaults to the zone ID set for the DNS provider")
+	flag.DurationVar(&options.DNSAuditInterval, "dns-audit-interval", env.GetEnvDuration("GLBC_DNS_AUDIT_INTERVAL", dns.DefaultAuditInterval), "The interval between the audits of the DNS zones, that publish again the records modified or deleted by hand (can be set to \"0\" to disable the audits)")
+	flag.BoolVar(&options.DNSDeleteOrphanedRecords, "dns-delete-orphaned-records", env.GetEnvBool("GLBC_DNS_DELETE_ORPHANED_RECORDS", false), "Delete the records owned by the GLBC that no DNSRecord owns anymore, found by the audits of the DNS zones")
+	flag.DurationVar(&options.DNSHealthCheckCollectionInterval, "dns-health-check-collection-interval", env.GetEnvDuration("GLBC_DNS_HEALTH_CHECK_COLLECTION_INTERVAL", dns.DefaultHealthCheckCollectionInterval), "The interval between the garbage collections of the health checks owned by the GLBC that no DNSRecord endpoint matches anymore (can be set to \"0\" to disable the collections)")
+	flag.DurationVar(&options.DNSHealthCheckGracePeriod, "dns-health-check-grace-period", env.GetEnvDuration("GLBC_DNS_HEALTH_CHECK_GRACE_PERIOD", dns.DefaultHealthCheckGracePeriod), "The time a health check must remain orphaned for before it is deleted")
+	flag.StringVar(&options.Nameservers, "nameservers", env.GetEnvString("GLBC_NAMESERVERS", ""), "Comma separated list of name servers (host:port) managed hosts are looked up against, instead of the system and domain name servers")
+	flag.BoolVar(&options.GeoRouting, "geo-routing", env.GetEnvBool("GLBC_GEO_ROUTING", false), "Route the traffic of generated hosts to the closest continent, based on the kuadrant.dev/continent label of the SyncTargets (requires the aws DNS provider)")
+	flag.StringVar(&options.HostTargetMode, "host-target-mode", env.GetEnvString("GLBC_HOST_TARGET_MODE", string(dns.HostTargetModeResolve)), "How the load balancer hosts of the traffic objects are published, one of [resolve, cname, alias]: resolved to their IPs, as weighted CNAME records, or as Route53 alias records for the AWS load balancers (requires the aws or externaldns DNS provider)")
+
+	flag.StringVar(&options.DNSTargetAllowCIDRs, "dns-target-allow-cidrs", env.GetEnvString("GLBC_DNS_TARGET_ALLOW_CIDRS", ""), "Comma separated list of CIDRs of the target addresses published to the public DNS zones, even if they belong to the denied CIDRs")
+	flag.StringVar(&options.DNSTargetDenyCIDRs, "dns-target-deny-cidrs", env.GetEnvString("GLBC_DNS_TARGET_DENY_CIDRS", dns.DefaultDeniedTargetCIDRs), "Comma separated list of CIDRs of the target addresses that are not published to the public DNS zones (can be set to \"\" to publish all the addresses)")
+	flag.StringVar(&options.DNSCAAMode, "dns-caa-mode", env.GetEnvString("GLBC_DNS_CAA_MODE", string(dns.CAAModeNone)), "Where the CAA records authorizing only the certificate authority of the TLS provider are published, one of [none, host, apex]: not published, with each generated host, or at the managed domain")
+	flag.StringVar(&options.DNSCAAIssuers, "dns-caa-issuers", env.GetEnvString("GLBC_DNS_CAA_ISSUERS", ""), "Comma separated list of the domains of the certificate authorities authorized by the CAA records. Defaults to the one of the TLS provider, e.g. letsencrypt.org")
 
 	// // AWS Route53 options
 	flag.StringVar(&options.Region, "region", env.GetEnvString("AWS_REGION", "eu-central-1"), "the region we should target with AWS clients")
@@ -188,11 +229,67 @@ func main() {
 
 	exitOnError(err, "Failed to create TLS certificate controller")
 
+	dnsZones, err := dns.ParseZones(options.DNSZones)
+	exitOnError(err, "Failed to parse DNS zones")
+	if len(dnsZones) == 0 {
+		dnsZones, err = dns.DefaultZones(options.DNSProvider)
+		exitOnError(err, "Failed to get the DNS zone of the provider")
+	}
+
+	// Geolocation routing is only supported by Route53, the fake provider is
+	// allowed so that it can be exercised without a DNS provider
+	var syncTargetInformerFactory kcpinformers.SharedInformerFactory
+	var getContinent func(ctx context.Context, target dns.Target) (string, error)
+	if options.GeoRouting {
+		if options.DNSProvider != "aws" && options.DNSProvider != "fake" {
+			exitOnError(fmt.Errorf("geo routing is not supported by the %s DNS provider", options.DNSProvider), "Failed to enable geo routing")
+		}
+		syncTargetInformerFactory = kcpinformers.NewSharedInformerFactory(kcpClient.Cluster(logicalcluster.New(options.LogicalClusterTarget)), resyncPeriod)
+		getContinent = traffic.ContinentFromSyncTargets(syncTargetInformerFactory.Workload().V1alpha1().SyncTargets().Lister())
+	}
+
+	// The alias records are only supported by Route53, either directly or
+	// through external-dns
+	hostTargetMode, err := dns.ParseHostTargetMode(options.HostTargetMode)
+	exitOnError(err, "Failed to parse host target mode")
+	if hostTargetMode == dns.HostTargetModeAlias && options.DNSProvider != "aws" && options.DNSProvider != "externaldns" && options.DNSProvider != "fake" {
+		exitOnError(fmt.Errorf("alias records are not supported by the %s DNS provider", options.DNSProvider), "Failed to enable alias records")
+	}
+
+	targetPolicy, err := dns.ParseTargetPolicy(options.DNSTargetAllowCIDRs, options.DNSTargetDenyCIDRs)
+	exitOnError(err, "Failed to parse DNS target policy")
+
+	caaMode, err := dns.ParseCAAMode(options.DNSCAAMode)
+	exitOnError(err, "Failed to parse CAA mode")
+	caaIssuers := dns.DefaultCAAIssuers(options.TLSProvider)
+	if options.DNSCAAIssuers != "" {
+		caaIssuers = strings.Split(options.DNSCAAIssuers, ",")
+	}
+	if caaMode != dns.CAAModeNone && len(caaIssuers) == 0 {
+		exitOnError(fmt.Errorf("no certificate authority is known for the %s TLS provider, set the CAA issuers", options.TLSProvider), "Failed to enable CAA records")
+	}
+	// The CAA record of the managed domain is reconciled in every mode, so that
+	// the issuers added in the apex mode are removed once it is disabled
+	var hostCAAIssuers, apexCAAIssuers []string
+	switch caaMode {
+	case dns.CAAModeHost:
+		hostCAAIssuers = caaIssuers
+	case dns.CAAModeApex:
+		apexCAAIssuers = caaIssuers
+	}
+
+	// A single DNS provider is shared by the controllers of all the APIExports,
+	// as they publish to the same zones
+	dnsProvider, err := dns.DNSProvider(options.DNSProvider, options.DNSOwnerID)
+	exitOnError(err, "Failed to create DNS provider")
+	dnsRegistry := dns.NewRegistry(options.DNSOwnerID, dnsProvider)
+
 	apiExportNames := strings.Split(options.ExportName, ",")
 	log.Logger.Info(fmt.Sprintf("Instantiating controllers for APIExports: %v", apiExportNames))
 
 	var apiExportClusterInformers []APIExportClusterInformers
 	var controllers []Controller
+	var dnsRecordControllers []*dns.Controller
 	for _, name := range apiExportNames {
 		glbcAPIExport, err := kcpClient.Cluster(logicalcluster.New(options.GLBCWorkspace)).ApisV1alpha1().APIExports().Get(ctx, name, metav1.GetOptions{})
 		exitOnError(err, "Failed to get GLBC APIExport "+name)
@@ -222,7 +319,11 @@ func main() {
 
 		isControllerLeader := len(controllers) == 0
 
-		dnsClient, domainVerifier := getDNSUtilities(os.Getenv("GLBC_HOST_RESOLVER"))
+		var nameservers []string
+		if options.Nameservers != "" {
+			nameservers = strings.Split(options.Nameservers, ",")
+		}
+		dnsClient, domainVerifier := getDNSUtilities(os.Getenv("GLBC_HOST_RESOLVER"), nameservers)
 
 		routeController := route.NewController(&route.ControllerConfig{
 			ControllerConfig: &reconciler.ControllerConfig{
@@ -240,6 +341,12 @@ func main() {
 			Domain:                          options.Domain,
 			CertProvider:                    certProvider,
 			HostResolver:                    dnsClient,
+			Nameservers:                     nameservers,
+			GetContinent:                    getContinent,
+			HostTargetMode:                  hostTargetMode,
+			TargetPolicy:                    targetPolicy,
+			PrivateZones:                    dns.HasPrivateZone(dnsZones),
+			CAAIssuers:                      hostCAAIssuers,
 			GLBCWorkspace:                   logicalcluster.New(options.GLBCWorkspace),
 		})
 
@@ -259,10 +366,22 @@ func main() {
 			Domain:                   options.Domain,
 			CertProvider:             certProvider,
 			HostResolver:             dnsClient,
+			Nameservers:              nameservers,
+			GetContinent:             getContinent,
+			HostTargetMode:           hostTargetMode,
+			TargetPolicy:             targetPolicy,
+			PrivateZones:             dns.HasPrivateZone(dnsZones),
+			CAAIssuers:               hostCAAIssuers,
 			GLBCWorkspace:            logicalcluster.New(options.GLBCWorkspace),
 		})
 		controllers = append(controllers, ingressController)
 
+		// The CAA record of the managed domain is only published by the first
+		// DNSRecord controller
+		dnsCAADomain := ""
+		if isControllerLeader {
+			dnsCAADomain = options.Domain
+		}
 		dnsRecordController, err := dns.NewController(&dns.ControllerConfig{
 			ControllerConfig: &reconciler.ControllerConfig{
 				NameSuffix: name,
@@ -270,9 +389,25 @@ func main() {
 			DnsRecordClient:       kcpKuadrantClient,
 			SharedInformerFactory: kcpKuadrantInformerFactory,
 			DNSProvider:           options.DNSProvider,
+			Registry:              dnsRegistry,
+			Zones:                 dnsZones,
+			CAADomain:             dnsCAADomain,
+			CAAIssuers:            apexCAAIssuers,
 		})
 		exitOnError(err, "Failed to create DNSRecord controller")
 		controllers = append(controllers, dnsRecordController)
+		dnsRecordControllers = append(dnsRecordControllers, dnsRecordController)
+
+		healthCheckController, err := dns.NewHealthCheckController(&dns.HealthCheckControllerConfig{
+			ControllerConfig: &reconciler.ControllerConfig{
+				NameSuffix: name,
+			},
+			KuadrantClient:        kcpKuadrantClient,
+			SharedInformerFactory: kcpKuadrantInformerFactory,
+			DNSProvider:           dnsProvider,
+		})
+		exitOnError(err, "Failed to create HealthCheck controller")
+		controllers = append(controllers, healthCheckController)
 
 		domainVerificationController, err := domainverification.NewController(&domainverification.ControllerConfig{
 			ControllerConfig: &reconciler.ControllerConfig{
@@ -326,6 +461,35 @@ func main() {
 		apiExportClusterInformers = append(apiExportClusterInformers, *clusterInformers)
 	}
 
+	// A single auditor covers the DNSRecords of all the APIExports, as they
+	// share the DNS zones. The zones of the providers that record the
+	// ownership of the names themselves cannot be listed, and are not audited.
+	if options.DNSAuditInterval > 0 && dns.RegistersOwnership(dnsProvider) {
+		log.Logger.Info(fmt.Sprintf("The DNS zones are not audited with the %s DNS provider", options.DNSProvider))
+	} else if options.DNSAuditInterval > 0 {
+		dnsAuditor, err := dns.NewAuditor(&dns.AuditorConfig{
+			Controllers:           dnsRecordControllers,
+			Registry:              dnsRegistry,
+			Zones:                 dnsZones,
+			Interval:              options.DNSAuditInterval,
+			DeleteOrphanedRecords: options.DNSDeleteOrphanedRecords,
+		})
+		exitOnError(err, "Failed to create DNS auditor")
+		controllers = append(controllers, dnsAuditor)
+	}
+
+	// A single collector covers the health checks of all the APIExports, as
+	// they share the DNS provider
+	if options.DNSHealthCheckCollectionInterval > 0 {
+		healthCheckCollector, err := dns.NewHealthCheckCollector(&dns.HealthCheckCollectorConfig{
+			Controllers: dnsRecordControllers,
+			Interval:    options.DNSHealthCheckCollectionInterval,
+			GracePeriod: options.DNSHealthCheckGracePeriod,
+		})
+		exitOnError(err, "Failed to create health check collector")
+		controllers = append(controllers, healthCheckCollector)
+	}
+
 	for _, clusterInformers := range apiExportClusterInformers {
 		clusterInformers.SharedInformerFactory.Start(ctx.Done())
 		clusterInformers.SharedInformerFactory.WaitForCacheSync(ctx.Done())
@@ -337,6 +501,11 @@ func main() {
 		clusterInformers.KCPDynamicInformerFactory.WaitForCacheSync(ctx.Done())
 	}
 
+	if syncTargetInformerFactory != nil {
+		syncTargetInformerFactory.Start(ctx.Done())
+		syncTargetInformerFactory.WaitForCacheSync(ctx.Done())
+	}
+
 	certificateInformerFactory.Start(ctx.Done())
 	certificateInformerFactory.WaitForCacheSync(ctx.Done())
 	glbcKubeInformerFactory.Start(ctx.Done())
@@ -395,11 +564,11 @@ func printOptions() {
 	}
 }
 
-func getDNSUtilities(hostResolverType string) (dns.HostResolver, domainverification.DNSVerifier) {
+func getDNSUtilities(hostResolverType string, nameservers []string) (dns.HostResolver, domainverification.DNSVerifier) {
 	switch hostResolverType {
 	case "default":
 		log.Logger.Info("using default host resolver")
-		return dns.NewDefaultHostResolver(), dns.NewVerifier(gonet.DefaultResolver)
+		return dns.NewDefaultHostResolver(nameservers...), dns.NewVerifier(gonet.DefaultResolver)
 	case "e2e-mock":
 		log.Logger.Info("using e2e-mock host resolver")
 		resolver := &dns.ConfigMapHostResolver{
@@ -410,6 +579,6 @@ func getDNSUtilities(hostResolverType string) (dns.HostResolver, domainverificat
 		return resolver, resolver
 	default:
 		log.Logger.Info("using default host resolver")
-		return dns.NewDefaultHostResolver(), dns.NewVerifier(gonet.DefaultResolver)
+		return dns.NewDefaultHostResolver(nameservers...), dns.NewVerifier(gonet.DefaultResolver)
 	}
 }
@@ -0,0 +1,48 @@
+package v1
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// CAATagIssue is the property tag of the CAA records that authorize a
+	// certificate authority to issue certificates for the name.
+	CAATagIssue = "issue"
+	// CAATagIssueWild is the property tag of the CAA records that authorize a
+	// certificate authority to issue wildcard certificates for the name.
+	CAATagIssueWild = "issuewild"
+)
+
+// NewCAATarget returns the target of a CAA endpoint, in the presentation
+// format of RFC 8659, e.g. `0 issue "letsencrypt.org"`.
+func NewCAATarget(flags uint8, tag, value string) string {
+	return fmt.Sprintf("%d %s %s", flags, tag, strconv.Quote(value))
+}
+
+// ParseCAATarget parses the target of a CAA endpoint, given in the
+// presentation format of RFC 8659, returning its flags, tag and value.
+func ParseCAATarget(target string) (uint8, string, string, error) {
+	parts := strings.SplitN(strings.TrimSpace(target), " ", 3)
+	if len(parts) != 3 {
+		return 0, "", "", fmt.Errorf("invalid CAA target %q, expected <flags> <tag> <value>", target)
+	}
+	flags, err := strconv.ParseUint(parts[0], 10, 8)
+	if err != nil {
+		return 0, "", "", fmt.Errorf("invalid CAA target %q flags: %v", target, err)
+	}
+	tag := parts[1]
+	if tag == "" || strings.IndexFunc(tag, func(r rune) bool {
+		return !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9')
+	}) >= 0 {
+		return 0, "", "", fmt.Errorf("invalid CAA target %q tag %q", target, tag)
+	}
+	value := strings.TrimSpace(parts[2])
+	if strings.HasPrefix(value, `"`) {
+		if value, err = strconv.Unquote(value); err != nil {
+			return 0, "", "", fmt.Errorf("invalid CAA target %q value: %v", target, err)
+		}
+	}
+	return uint8(flags), tag, value, nil
+}
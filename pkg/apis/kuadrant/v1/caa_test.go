@@ -0,0 +1,45 @@
+package v1
+
+import (
+	"testing"
+)
+
+func TestCAATarget(t *testing.T) {
+	target := NewCAATarget(0, CAATagIssue, "letsencrypt.org")
+	if target != `0 issue "letsencrypt.org"` {
+		t.Errorf("unexpected CAA target %s", target)
+	}
+
+	tests := []struct {
+		target string
+		flags  uint8
+		tag    string
+		value  string
+		err    bool
+	}{
+		{target: `0 issue "letsencrypt.org"`, tag: "issue", value: "letsencrypt.org"},
+		{target: `128 issuewild ";"`, flags: 128, tag: "issuewild", value: ";"},
+		{target: `0 iodef "mailto:security@example.com"`, tag: "iodef", value: "mailto:security@example.com"},
+		{target: `0 issue letsencrypt.org`, tag: "issue", value: "letsencrypt.org"},
+		{target: `0 issue`, err: true},
+		{target: `256 issue "letsencrypt.org"`, err: true},
+		{target: `0 is-sue "letsencrypt.org"`, err: true},
+		{target: `0 issue "letsencrypt.org`, err: true},
+	}
+	for _, tt := range tests {
+		flags, tag, value, err := ParseCAATarget(tt.target)
+		if tt.err {
+			if err == nil {
+				t.Errorf("expected error parsing %s", tt.target)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("unexpected error parsing %s: %v", tt.target, err)
+			continue
+		}
+		if flags != tt.flags || tag != tt.tag || value != tt.value {
+			t.Errorf("ParseCAATarget(%s) = %d %s %s, want %d %s %s", tt.target, flags, tag, value, tt.flags, tt.tag, tt.value)
+		}
+	}
+}
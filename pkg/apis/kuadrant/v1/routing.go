@@ -0,0 +1,158 @@
+package v1
+
+import (
+	"strconv"
+)
+
+// The provider specific properties the routing policies used to be encoded
+// with, before the RoutingPolicy field was introduced. They are still honoured
+// when an endpoint has no RoutingPolicy, as they may be stored in the existing
+// DNSRecords.
+const (
+	LegacyProviderSpecificWeight                   = "aws/weight"
+	LegacyProviderSpecificRegion                   = "aws/region"
+	LegacyProviderSpecificFailover                 = "aws/failover"
+	LegacyProviderSpecificGeolocationContinentCode = "aws/geolocation-continent-code"
+	LegacyProviderSpecificGeolocationCountryCode   = "aws/geolocation-country-code"
+
+	legacyGeolocationDefault = "*"
+)
+
+// RoutingPolicy is the policy the DNS provider applies to answer the queries
+// of the endpoints sharing a name and type, that are distinguished by their set
+// identifier. Exactly one of the policies is expected to be set.
+type RoutingPolicy struct {
+	// weighted routes the queries to the endpoints in proportion to their weight.
+	// +optional
+	Weighted *WeightedRoutingPolicy `json:"weighted,omitempty"`
+	// failover routes the queries to the primary endpoint, unless it is unhealthy.
+	// +optional
+	Failover *FailoverRoutingPolicy `json:"failover,omitempty"`
+	// geo routes the queries according to the location they originate from.
+	// +optional
+	Geo *GeoRoutingPolicy `json:"geo,omitempty"`
+	// latency routes the queries to the region with the lowest latency.
+	// +optional
+	Latency *LatencyRoutingPolicy `json:"latency,omitempty"`
+}
+
+// WeightedRoutingPolicy is the weight of an endpoint.
+type WeightedRoutingPolicy struct {
+	// weight is the relative weight of the endpoint. An endpoint with a weight
+	// of 0 is not returned, unless all the endpoints have a weight of 0.
+	// +kubebuilder:validation:Minimum=0
+	Weight int64 `json:"weight"`
+}
+
+// FailoverRole is the role of an endpoint within a failover routing policy.
+// +kubebuilder:validation:Enum=PRIMARY;SECONDARY
+type FailoverRole string
+
+const (
+	FailoverRolePrimary   FailoverRole = "PRIMARY"
+	FailoverRoleSecondary FailoverRole = "SECONDARY"
+)
+
+// FailoverRoutingPolicy is the role of an endpoint in an active-passive setup.
+type FailoverRoutingPolicy struct {
+	// role is either PRIMARY or SECONDARY.
+	Role FailoverRole `json:"role"`
+}
+
+// GeoRoutingPolicy is the location an endpoint answers the queries of.
+// Exactly one of continent, country or default is expected to be set.
+type GeoRoutingPolicy struct {
+	// continent is the two-letter code of a continent, e.g. EU.
+	// +optional
+	Continent string `json:"continent,omitempty"`
+	// country is the ISO 3166-1 alpha-2 code of a country, e.g. FR.
+	// +optional
+	Country string `json:"country,omitempty"`
+	// default marks the endpoint answering the queries of the locations no
+	// other endpoint answers.
+	// +optional
+	Default bool `json:"default,omitempty"`
+}
+
+// LatencyRoutingPolicy is the region an endpoint is served from.
+type LatencyRoutingPolicy struct {
+	// region is the provider region of the endpoint, e.g. us-east-1.
+	Region string `json:"region"`
+}
+
+// GetRoutingPolicy returns the routing policy of the endpoint, or the one
+// encoded by its legacy provider specific properties if it has none. It
+// returns nil if the endpoint has no routing policy.
+func (e *Endpoint) GetRoutingPolicy() *RoutingPolicy {
+	if e.RoutingPolicy != nil {
+		return e.RoutingPolicy
+	}
+	return RoutingPolicyFromProviderSpecific(e.ProviderSpecific)
+}
+
+// SetRoutingPolicy sets the routing policy of the endpoint, and deletes its
+// legacy routing provider specific properties, so that they do not linger
+// once the endpoint is updated.
+func (e *Endpoint) SetRoutingPolicy(policy *RoutingPolicy) {
+	e.RoutingPolicy = policy
+	for _, name := range legacyRoutingProperties {
+		e.DeleteProviderSpecific(name)
+	}
+	if len(e.ProviderSpecific) == 0 {
+		e.ProviderSpecific = nil
+	}
+}
+
+var legacyRoutingProperties = []string{
+	LegacyProviderSpecificWeight,
+	LegacyProviderSpecificRegion,
+	LegacyProviderSpecificFailover,
+	LegacyProviderSpecificGeolocationContinentCode,
+	LegacyProviderSpecificGeolocationCountryCode,
+}
+
+// IsLegacyRoutingProperty returns whether the provider specific property
+// encodes a routing policy.
+func IsLegacyRoutingProperty(name string) bool {
+	for _, property := range legacyRoutingProperties {
+		if property == name {
+			return true
+		}
+	}
+	return false
+}
+
+// RoutingPolicyFromProviderSpecific returns the routing policy encoded by the
+// legacy provider specific properties, or nil if there is none. An invalid
+// weight is decoded as a weight of 0, as it used to be by the providers.
+func RoutingPolicyFromProviderSpecific(properties ProviderSpecific) *RoutingPolicy {
+	get := func(name string) (string, bool) {
+		for _, property := range properties {
+			if property.Name == name {
+				return property.Value, true
+			}
+		}
+		return "", false
+	}
+
+	if value, ok := get(LegacyProviderSpecificWeight); ok {
+		weight, _ := strconv.ParseInt(value, 10, 64)
+		return &RoutingPolicy{Weighted: &WeightedRoutingPolicy{Weight: weight}}
+	}
+	if value, ok := get(LegacyProviderSpecificFailover); ok {
+		return &RoutingPolicy{Failover: &FailoverRoutingPolicy{Role: FailoverRole(value)}}
+	}
+	if value, ok := get(LegacyProviderSpecificGeolocationContinentCode); ok {
+		return &RoutingPolicy{Geo: &GeoRoutingPolicy{Continent: value}}
+	}
+	if value, ok := get(LegacyProviderSpecificGeolocationCountryCode); ok {
+		if value == legacyGeolocationDefault {
+			return &RoutingPolicy{Geo: &GeoRoutingPolicy{Default: true}}
+		}
+		return &RoutingPolicy{Geo: &GeoRoutingPolicy{Country: value}}
+	}
+	if value, ok := get(LegacyProviderSpecificRegion); ok {
+		return &RoutingPolicy{Latency: &LatencyRoutingPolicy{Region: value}}
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package v1
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGetRoutingPolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint *Endpoint
+		want     *RoutingPolicy
+	}{
+		{
+			name:     "no routing policy",
+			endpoint: &Endpoint{},
+		},
+		{
+			name: "routing policy",
+			endpoint: &Endpoint{
+				RoutingPolicy:    &RoutingPolicy{Latency: &LatencyRoutingPolicy{Region: "eu-west-1"}},
+				ProviderSpecific: ProviderSpecific{{Name: LegacyProviderSpecificWeight, Value: "120"}},
+			},
+			want: &RoutingPolicy{Latency: &LatencyRoutingPolicy{Region: "eu-west-1"}},
+		},
+		{
+			name:     "legacy weight",
+			endpoint: &Endpoint{ProviderSpecific: ProviderSpecific{{Name: LegacyProviderSpecificWeight, Value: "60"}}},
+			want:     &RoutingPolicy{Weighted: &WeightedRoutingPolicy{Weight: 60}},
+		},
+		{
+			name:     "invalid legacy weight",
+			endpoint: &Endpoint{ProviderSpecific: ProviderSpecific{{Name: LegacyProviderSpecificWeight, Value: "x"}}},
+			want:     &RoutingPolicy{Weighted: &WeightedRoutingPolicy{Weight: 0}},
+		},
+		{
+			name:     "legacy failover",
+			endpoint: &Endpoint{ProviderSpecific: ProviderSpecific{{Name: LegacyProviderSpecificFailover, Value: "SECONDARY"}}},
+			want:     &RoutingPolicy{Failover: &FailoverRoutingPolicy{Role: FailoverRoleSecondary}},
+		},
+		{
+			name:     "legacy continent",
+			endpoint: &Endpoint{ProviderSpecific: ProviderSpecific{{Name: LegacyProviderSpecificGeolocationContinentCode, Value: "EU"}}},
+			want:     &RoutingPolicy{Geo: &GeoRoutingPolicy{Continent: "EU"}},
+		},
+		{
+			name:     "legacy default location",
+			endpoint: &Endpoint{ProviderSpecific: ProviderSpecific{{Name: LegacyProviderSpecificGeolocationCountryCode, Value: "*"}}},
+			want:     &RoutingPolicy{Geo: &GeoRoutingPolicy{Default: true}},
+		},
+		{
+			name:     "legacy region",
+			endpoint: &Endpoint{ProviderSpecific: ProviderSpecific{{Name: LegacyProviderSpecificRegion, Value: "us-east-1"}}},
+			want:     &RoutingPolicy{Latency: &LatencyRoutingPolicy{Region: "us-east-1"}},
+		},
+		{
+			name:     "other provider specific property",
+			endpoint: &Endpoint{ProviderSpecific: ProviderSpecific{{Name: "aws/health-check-id", Value: "abc"}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.endpoint.GetRoutingPolicy(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetRoutingPolicy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetRoutingPolicy(t *testing.T) {
+	endpoint := &Endpoint{ProviderSpecific: ProviderSpecific{
+		{Name: LegacyProviderSpecificWeight, Value: "60"},
+		{Name: "aws/health-check-id", Value: "abc"},
+	}}
+	policy := &RoutingPolicy{Weighted: &WeightedRoutingPolicy{Weight: 120}}
+	endpoint.SetRoutingPolicy(policy)
+
+	if !reflect.DeepEqual(endpoint.RoutingPolicy, policy) {
+		t.Errorf("RoutingPolicy = %v, want %v", endpoint.RoutingPolicy, policy)
+	}
+	want := ProviderSpecific{{Name: "aws/health-check-id", Value: "abc"}}
+	if !reflect.DeepEqual(endpoint.ProviderSpecific, want) {
+		t.Errorf("ProviderSpecific = %v, want %v", endpoint.ProviderSpecific, want)
+	}
+}
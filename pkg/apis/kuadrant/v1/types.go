@@ -2,6 +2,7 @@ package v1
 
 import (
 	"fmt"
+	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
@@ -118,6 +119,9 @@ type Endpoint struct {
 	// Labels stores labels defined for the Endpoint
 	// +optional
 	Labels Labels `json:"labels,omitempty"`
+	// RoutingPolicy is the policy the DNS provider routes the queries of the endpoint with
+	// +optional
+	RoutingPolicy *RoutingPolicy `json:"routingPolicy,omitempty"`
 	// ProviderSpecific stores provider specific config
 	// +optional
 	ProviderSpecific ProviderSpecific `json:"providerSpecific,omitempty"`
@@ -143,6 +147,50 @@ type DNSRecordStatus struct {
 	// needs to retry the update for that specific zone.
 	// +optional
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// health is the health of the endpoints checked by a HealthCheck, as last
+	// reported in the status of the HealthCheck.
+	// +optional
+	Health []EndpointHealth `json:"health,omitempty"`
+}
+
+// EndpointHealthCheckRef references the health checks of an endpoint, by the
+// provider specific properties the endpoint is published with.
+type EndpointHealthCheckRef struct {
+	// dnsName is the host name of the endpoint.
+	DNSName string `json:"dnsName"`
+	// setIdentifier is the identifier of the endpoint among the endpoints of
+	// the host name.
+	// +optional
+	SetIdentifier string `json:"setIdentifier,omitempty"`
+	// cluster is the cluster of the endpoint, whose health check aggregates
+	// the health checks of its endpoints, if any.
+	// +optional
+	Cluster string `json:"cluster,omitempty"`
+	// providerSpecific are the provider specific properties of the health
+	// checks, e.g. their IDs.
+	ProviderSpecific ProviderSpecific `json:"providerSpecific"`
+	// stale is set once the endpoint is no longer checked, until its health
+	// checks are deleted, when the endpoint is no longer published with them.
+	// +optional
+	Stale bool `json:"stale,omitempty"`
+}
+
+// EndpointHealth is the health of an endpoint, as last observed by the DNS
+// provider.
+type EndpointHealth struct {
+	// dnsName is the host name of the endpoint.
+	DNSName string `json:"dnsName"`
+	// setIdentifier is the identifier of the endpoint among the endpoints of
+	// the host name.
+	// +optional
+	SetIdentifier string `json:"setIdentifier,omitempty"`
+	// health is the health of the endpoint.
+	Health Health `json:"health"`
+	// lastObservedTime is the time the health of the endpoint was last
+	// observed at.
+	// +optional
+	LastObservedTime metav1.Time `json:"lastObservedTime,omitempty"`
 }
 
 // DNSZone is used to define a DNS hosted zone.
@@ -191,6 +239,9 @@ type DNSZoneStatus struct {
 var (
 	// Succeeded means the record is available within a zone if the status condition is true.
 	DNSRecordSucceededConditionType = "Succeeded"
+	// Owned means the names of the record are owned by the GLBC within a zone if the status condition is true.
+	// The record is not published to the zone if any of its names is owned by someone else.
+	DNSRecordOwnedConditionType = "Owned"
 )
 
 // DNSZoneCondition is just the standard condition fields.
@@ -209,7 +260,7 @@ type DNSZoneCondition struct {
 }
 
 // DNSRecordType is a DNS resource record type.
-// +kubebuilder:validation:Enum=CNAME;A
+// +kubebuilder:validation:Enum=CNAME;A;AAAA;TXT;CAA
 type DNSRecordType string
 
 const (
@@ -218,8 +269,28 @@ const (
 
 	// ARecordType is an RFC 1035 A record.
 	ARecordType DNSRecordType = "A"
+
+	// AAAARecordType is an RFC 3596 AAAA record.
+	AAAARecordType DNSRecordType = "AAAA"
+
+	// TXTRecordType is an RFC 1035 TXT record.
+	TXTRecordType DNSRecordType = "TXT"
+
+	// CAARecordType is an RFC 8659 CAA record.
+	CAARecordType DNSRecordType = "CAA"
 )
 
+// ProviderSpecificAlias marks a CNAME endpoint to be published as an alias
+// record by the providers supporting them, e.g. a Route53 alias record to an
+// AWS load balancer. It is the property external-dns uses.
+const ProviderSpecificAlias = "alias"
+
+// IsAlias returns whether the endpoint is published as an alias record.
+func (endpoint *Endpoint) IsAlias() bool {
+	property, ok := endpoint.GetProviderSpecificProperty(ProviderSpecificAlias)
+	return ok && property.Value == "true"
+}
+
 // +kubebuilder:object:root=true
 
 // DNSRecordList contains a list of dnsrecords.
@@ -290,17 +361,186 @@ func (endpoint *Endpoint) GetAddress() (string, bool) {
 	return string(endpoint.Targets[0]), true
 }
 
-// not currently a generated API used internally only
+// +genclient
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// HealthCheck is a health check of the endpoints of a traffic object, e.g. an
+// Ingress, performed by the DNS provider, or by the GLBC for the providers that
+// have no health checks, so that the DNS queries are not answered with the
+// unhealthy endpoints.
 type HealthCheck struct {
-	Id               string
-	Name             string
-	Port             *int64
-	FailureThreshold *int64
-	Path             string
-	Protocol         *HealthCheckProtocol
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// spec is the specification of the desired behavior of the health check.
+	Spec HealthCheckSpec `json:"spec"`
+	// status is the most recently observed status of the health check.
+	// +optional
+	Status HealthCheckStatus `json:"status,omitempty"`
 }
 
+// HealthCheckSpec is the specification of a health check.
+type HealthCheckSpec struct {
+	// targetRef is the traffic object, in the namespace of the health check,
+	// whose endpoints are checked.
+	TargetRef HealthCheckTargetReference `json:"targetRef"`
+	// path is the path of the requests sent to the endpoints. It is ignored by
+	// the TCP health checks, that only open a connection.
+	// +kubebuilder:validation:Pattern=`^/`
+	Path string `json:"path"`
+	// port is the port the requests are sent to.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +kubebuilder:default=80
+	// +optional
+	Port *int64 `json:"port,omitempty"`
+	// protocol is the protocol of the requests.
+	// +kubebuilder:default=HTTP
+	// +optional
+	Protocol *HealthCheckProtocol `json:"protocol,omitempty"`
+	// interval is the interval between the requests sent to an endpoint.
+	// Defaults to the interval of the DNS provider.
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+	// failureThreshold is the number of consecutive failed requests after
+	// which an endpoint is unhealthy.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=10
+	// +optional
+	FailureThreshold *int64 `json:"failureThreshold,omitempty"`
+	// expectedStatusCodes are the status codes of the responses of the healthy
+	// endpoints. Defaults to the 2xx and 3xx status codes.
+	// +optional
+	ExpectedStatusCodes []HTTPStatusCode `json:"expectedStatusCodes,omitempty"`
+	// hostHeader is the Host header of the requests. Defaults to the host name
+	// of the endpoint.
+	// +optional
+	HostHeader string `json:"hostHeader,omitempty"`
+	// searchString is a string the body of the responses of the healthy
+	// endpoints must contain, within its first 5120 bytes. It is ignored by
+	// the TCP health checks.
+	// +kubebuilder:validation:MaxLength=255
+	// +optional
+	SearchString string `json:"searchString,omitempty"`
+	// regions are the regions of the DNS provider the endpoints are checked
+	// from, for the providers that check them from several regions. Defaults
+	// to the regions of the DNS provider.
+	// +kubebuilder:validation:MinItems=3
+	// +optional
+	Regions []string `json:"regions,omitempty"`
+	// inverted inverts the health of the endpoints, that are healthy when the
+	// requests fail, e.g. to fail over while a maintenance page is served.
+	// +optional
+	Inverted bool `json:"inverted,omitempty"`
+	// clusterHealthThreshold is the number of endpoints of a cluster that must
+	// be healthy for its endpoints to be published. When set, the DNS
+	// providers that support it aggregate the health checks of the endpoints
+	// of each cluster into a single health check.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ClusterHealthThreshold *int64 `json:"clusterHealthThreshold,omitempty"`
+}
+
+// HealthCheckTargetReference references the traffic object of a health check.
+type HealthCheckTargetReference struct {
+	// kind is the kind of the traffic object.
+	// +kubebuilder:validation:Enum=Ingress;Route
+	Kind string `json:"kind"`
+	// name is the name of the traffic object.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// HealthCheckProtocol is the protocol of the requests of a health check.
+// +kubebuilder:validation:Enum=HTTP;HTTPS;TCP
 type HealthCheckProtocol string
 
 const HealthCheckProtocolHTTP HealthCheckProtocol = "HTTP"
 const HealthCheckProtocolHTTPS HealthCheckProtocol = "HTTPS"
+const HealthCheckProtocolTCP HealthCheckProtocol = "TCP"
+
+// HTTPStatusCode is the status code of an HTTP response.
+// +kubebuilder:validation:Minimum=100
+// +kubebuilder:validation:Maximum=599
+type HTTPStatusCode int
+
+// HealthCheckStatus is the most recently observed status of a health check.
+type HealthCheckStatus struct {
+	// observedGeneration is the most recently observed generation of the
+	// health check.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// endpoints are the status of the health check of each endpoint of the
+	// traffic object.
+	// +optional
+	Endpoints []HealthCheckEndpointStatus `json:"endpoints,omitempty"`
+	// message explains why the endpoints are not checked, if so.
+	// +optional
+	Message string `json:"message,omitempty"`
+	// healthChecks are the health checks of the endpoints, that the endpoints
+	// are published with.
+	// +optional
+	HealthChecks []EndpointHealthCheckRef `json:"healthChecks,omitempty"`
+}
+
+// HealthCheckEndpointStatus is the status of the health check of an endpoint.
+type HealthCheckEndpointStatus struct {
+	// dnsName is the host name of the endpoint.
+	DNSName string `json:"dnsName"`
+	// setIdentifier is the identifier of the endpoint among the endpoints of
+	// the host name.
+	// +optional
+	SetIdentifier string `json:"setIdentifier,omitempty"`
+	// providerID is the identifier of the health check in the DNS provider.
+	// +optional
+	ProviderID string `json:"providerID,omitempty"`
+	// health is the health of the endpoint, as last observed by the DNS
+	// provider.
+	Health Health `json:"health"`
+	// lastObservedTime is the time the health of the endpoint was last
+	// observed at.
+	// +optional
+	LastObservedTime metav1.Time `json:"lastObservedTime,omitempty"`
+}
+
+// Health is the health of an endpoint.
+// +kubebuilder:validation:Enum=Healthy;Unhealthy;Unknown
+type Health string
+
+const (
+	Healthy   Health = "Healthy"
+	Unhealthy Health = "Unhealthy"
+	// HealthUnknown is the health of the endpoints whose DNS provider does not
+	// report the health of its health checks.
+	HealthUnknown Health = "Unknown"
+)
+
+// +kubebuilder:object:root=true
+
+// HealthCheckList contains a list of health checks.
+type HealthCheckList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []HealthCheck `json:"items"`
+}
+
+// EndpointHealthCheck is the health check of an endpoint, as reconciled by the
+// DNS providers. It is not a generated API, and is used internally only.
+type EndpointHealthCheck struct {
+	Id                  string
+	Name                string
+	Port                *int64
+	FailureThreshold    *int64
+	Path                string
+	Protocol            *HealthCheckProtocol
+	Interval            time.Duration
+	ExpectedStatusCodes []HTTPStatusCode
+	HostHeader          string
+	SearchString        string
+	Regions             []string
+	Inverted            bool
+	// ClusterHealthThreshold is only set on the health checks of the clusters,
+	// that aggregate the health checks of their endpoints.
+	ClusterHealthThreshold *int64
+}
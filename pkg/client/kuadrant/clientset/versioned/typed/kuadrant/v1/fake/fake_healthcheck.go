@@ -0,0 +1,126 @@
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	"context"
+
+	kuadrantv1 "github.com/kuadrant/kcp-glbc/pkg/apis/kuadrant/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeHealthChecks implements HealthCheckInterface
+type FakeHealthChecks struct {
+	Fake *FakeKuadrantV1
+	ns   string
+}
+
+var healthchecksResource = schema.GroupVersionResource{Group: "kuadrant.dev", Version: "v1", Resource: "healthchecks"}
+
+var healthchecksKind = schema.GroupVersionKind{Group: "kuadrant.dev", Version: "v1", Kind: "HealthCheck"}
+
+// Get takes name of the healthCheck, and returns the corresponding healthCheck object, and an error if there is any.
+func (c *FakeHealthChecks) Get(ctx context.Context, name string, options v1.GetOptions) (result *kuadrantv1.HealthCheck, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(healthchecksResource, c.ns, name), &kuadrantv1.HealthCheck{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*kuadrantv1.HealthCheck), err
+}
+
+// List takes label and field selectors, and returns the list of HealthChecks that match those selectors.
+func (c *FakeHealthChecks) List(ctx context.Context, opts v1.ListOptions) (result *kuadrantv1.HealthCheckList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(healthchecksResource, healthchecksKind, c.ns, opts), &kuadrantv1.HealthCheckList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &kuadrantv1.HealthCheckList{ListMeta: obj.(*kuadrantv1.HealthCheckList).ListMeta}
+	for _, item := range obj.(*kuadrantv1.HealthCheckList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested healthChecks.
+func (c *FakeHealthChecks) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(healthchecksResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a healthCheck and creates it.  Returns the server's representation of the healthCheck, and an error, if there is any.
+func (c *FakeHealthChecks) Create(ctx context.Context, healthCheck *kuadrantv1.HealthCheck, opts v1.CreateOptions) (result *kuadrantv1.HealthCheck, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(healthchecksResource, c.ns, healthCheck), &kuadrantv1.HealthCheck{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*kuadrantv1.HealthCheck), err
+}
+
+// Update takes the representation of a healthCheck and updates it. Returns the server's representation of the healthCheck, and an error, if there is any.
+func (c *FakeHealthChecks) Update(ctx context.Context, healthCheck *kuadrantv1.HealthCheck, opts v1.UpdateOptions) (result *kuadrantv1.HealthCheck, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(healthchecksResource, c.ns, healthCheck), &kuadrantv1.HealthCheck{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*kuadrantv1.HealthCheck), err
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeHealthChecks) UpdateStatus(ctx context.Context, healthCheck *kuadrantv1.HealthCheck, opts v1.UpdateOptions) (*kuadrantv1.HealthCheck, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateSubresourceAction(healthchecksResource, "status", c.ns, healthCheck), &kuadrantv1.HealthCheck{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*kuadrantv1.HealthCheck), err
+}
+
+// Delete takes name of the healthCheck and deletes it. Returns an error if one occurs.
+func (c *FakeHealthChecks) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteActionWithOptions(healthchecksResource, c.ns, name, opts), &kuadrantv1.HealthCheck{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeHealthChecks) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(healthchecksResource, c.ns, listOpts)
+
+	_, err := c.Fake.Invokes(action, &kuadrantv1.HealthCheckList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched healthCheck.
+func (c *FakeHealthChecks) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *kuadrantv1.HealthCheck, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(healthchecksResource, c.ns, name, pt, data, subresources...), &kuadrantv1.HealthCheck{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*kuadrantv1.HealthCheck), err
+}
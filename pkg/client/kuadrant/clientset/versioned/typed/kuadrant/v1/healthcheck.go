@@ -0,0 +1,191 @@
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1
+
+import (
+	"context"
+	"time"
+
+	v2 "github.com/kcp-dev/logicalcluster/v2"
+	v1 "github.com/kuadrant/kcp-glbc/pkg/apis/kuadrant/v1"
+	scheme "github.com/kuadrant/kcp-glbc/pkg/client/kuadrant/clientset/versioned/scheme"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// HealthChecksGetter has a method to return a HealthCheckInterface.
+// A group's client should implement this interface.
+type HealthChecksGetter interface {
+	HealthChecks(namespace string) HealthCheckInterface
+}
+
+// HealthCheckInterface has methods to work with HealthCheck resources.
+type HealthCheckInterface interface {
+	Create(ctx context.Context, healthCheck *v1.HealthCheck, opts metav1.CreateOptions) (*v1.HealthCheck, error)
+	Update(ctx context.Context, healthCheck *v1.HealthCheck, opts metav1.UpdateOptions) (*v1.HealthCheck, error)
+	UpdateStatus(ctx context.Context, healthCheck *v1.HealthCheck, opts metav1.UpdateOptions) (*v1.HealthCheck, error)
+	Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts metav1.DeleteOptions, listOpts metav1.ListOptions) error
+	Get(ctx context.Context, name string, opts metav1.GetOptions) (*v1.HealthCheck, error)
+	List(ctx context.Context, opts metav1.ListOptions) (*v1.HealthCheckList, error)
+	Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (result *v1.HealthCheck, err error)
+	HealthCheckExpansion
+}
+
+// healthChecks implements HealthCheckInterface
+type healthChecks struct {
+	client  rest.Interface
+	cluster v2.Name
+	ns      string
+}
+
+// newHealthChecks returns a HealthChecks
+func newHealthChecks(c *KuadrantV1Client, namespace string) *healthChecks {
+	return &healthChecks{
+		client:  c.RESTClient(),
+		cluster: c.cluster,
+		ns:      namespace,
+	}
+}
+
+// Get takes name of the healthCheck, and returns the corresponding healthCheck object, and an error if there is any.
+func (c *healthChecks) Get(ctx context.Context, name string, options metav1.GetOptions) (result *v1.HealthCheck, err error) {
+	result = &v1.HealthCheck{}
+	err = c.client.Get().
+		Cluster(c.cluster).
+		Namespace(c.ns).
+		Resource("healthchecks").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of HealthChecks that match those selectors.
+func (c *healthChecks) List(ctx context.Context, opts metav1.ListOptions) (result *v1.HealthCheckList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1.HealthCheckList{}
+	err = c.client.Get().
+		Cluster(c.cluster).
+		Namespace(c.ns).
+		Resource("healthchecks").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested healthChecks.
+func (c *healthChecks) Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Cluster(c.cluster).
+		Namespace(c.ns).
+		Resource("healthchecks").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch(ctx)
+}
+
+// Create takes the representation of a healthCheck and creates it.  Returns the server's representation of the healthCheck, and an error, if there is any.
+func (c *healthChecks) Create(ctx context.Context, healthCheck *v1.HealthCheck, opts metav1.CreateOptions) (result *v1.HealthCheck, err error) {
+	result = &v1.HealthCheck{}
+	err = c.client.Post().
+		Cluster(c.cluster).
+		Namespace(c.ns).
+		Resource("healthchecks").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(healthCheck).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Update takes the representation of a healthCheck and updates it. Returns the server's representation of the healthCheck, and an error, if there is any.
+func (c *healthChecks) Update(ctx context.Context, healthCheck *v1.HealthCheck, opts metav1.UpdateOptions) (result *v1.HealthCheck, err error) {
+	result = &v1.HealthCheck{}
+	err = c.client.Put().
+		Cluster(c.cluster).
+		Namespace(c.ns).
+		Resource("healthchecks").
+		Name(healthCheck.Name).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(healthCheck).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *healthChecks) UpdateStatus(ctx context.Context, healthCheck *v1.HealthCheck, opts metav1.UpdateOptions) (result *v1.HealthCheck, err error) {
+	result = &v1.HealthCheck{}
+	err = c.client.Put().
+		Cluster(c.cluster).
+		Namespace(c.ns).
+		Resource("healthchecks").
+		Name(healthCheck.Name).
+		SubResource("status").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(healthCheck).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Delete takes name of the healthCheck and deletes it. Returns an error if one occurs.
+func (c *healthChecks) Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error {
+	return c.client.Delete().
+		Cluster(c.cluster).
+		Namespace(c.ns).
+		Resource("healthchecks").
+		Name(name).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *healthChecks) DeleteCollection(ctx context.Context, opts metav1.DeleteOptions, listOpts metav1.ListOptions) error {
+	var timeout time.Duration
+	if listOpts.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOpts.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Cluster(c.cluster).
+		Namespace(c.ns).
+		Resource("healthchecks").
+		VersionedParams(&listOpts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// Patch applies the patch and returns the patched healthCheck.
+func (c *healthChecks) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (result *v1.HealthCheck, err error) {
+	result = &v1.HealthCheck{}
+	err = c.client.Patch(pt).
+		Cluster(c.cluster).
+		Namespace(c.ns).
+		Resource("healthchecks").
+		Name(name).
+		SubResource(subresources...).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
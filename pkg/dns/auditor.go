@@ -0,0 +1,278 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/kuadrant/kcp-glbc/pkg/_internal/log"
+	v1 "github.com/kuadrant/kcp-glbc/pkg/apis/kuadrant/v1"
+)
+
+// DefaultAuditInterval is the interval between the audits of the zones.
+const DefaultAuditInterval = 10 * time.Minute
+
+// AuditorConfig is the configuration of the Auditor.
+type AuditorConfig struct {
+	// Controllers are the DNSRecord controllers, whose DNSRecords own the
+	// records of the zones.
+	Controllers []*Controller
+	// Registry is the registry shared by the controllers, whose DNS provider
+	// the zones are audited with.
+	Registry *Registry
+	// Zones are the zones the controllers publish the records to.
+	Zones []Zone
+	// Interval is the interval between the audits. Defaults to DefaultAuditInterval.
+	Interval time.Duration
+	// DeleteOrphanedRecords enables the deletion of the records owned by this
+	// GLBC that no DNSRecord owns anymore. They are only reported otherwise.
+	DeleteOrphanedRecords bool
+}
+
+// Auditor periodically compares the records of the zones with the DNSRecords.
+// The DNSRecords whose records have been modified or deleted from the zones
+// are published again, and the names owned by this GLBC that no DNSRecord
+// owns anymore are reported, and deleted when enabled.
+type Auditor struct {
+	controllers           []*Controller
+	registry              *Registry
+	zones                 []Zone
+	interval              time.Duration
+	deleteOrphanedRecords bool
+	logger                logr.Logger
+}
+
+// NewAuditor returns a new Auditor of the zones of the controllers.
+func NewAuditor(config *AuditorConfig) (*Auditor, error) {
+	if len(config.Controllers) == 0 {
+		return nil, fmt.Errorf("no DNSRecord controller to audit")
+	}
+	if config.Registry == nil {
+		return nil, fmt.Errorf("no DNS registry set")
+	}
+	if RegistersOwnership(config.Registry.provider) {
+		return nil, fmt.Errorf("the zones of a DNS provider recording the ownership of the names cannot be audited")
+	}
+	interval := config.Interval
+	if interval <= 0 {
+		interval = DefaultAuditInterval
+	}
+	return &Auditor{
+		controllers:           config.Controllers,
+		registry:              config.Registry,
+		zones:                 config.Zones,
+		interval:              interval,
+		deleteOrphanedRecords: config.DeleteOrphanedRecords,
+		logger:                log.Logger.WithName("dns-auditor"),
+	}, nil
+}
+
+// Start runs the audits until the context is done.
+func (a *Auditor) Start(ctx context.Context, _ int) {
+	a.logger.Info("Starting auditor", "interval", a.interval, "deleteOrphanedRecords", a.deleteOrphanedRecords)
+	defer a.logger.Info("Stopping auditor")
+	wait.UntilWithContext(ctx, a.audit, a.interval)
+}
+
+// ownedRecord is a DNSRecord, along with the controller reconciling it.
+type ownedRecord struct {
+	controller *Controller
+	record     *v1.DNSRecord
+}
+
+// zoneAudit is the outcome of the audit of a zone.
+type zoneAudit struct {
+	// drifted are the DNSRecords whose records have drifted in the zone.
+	drifted []ownedRecord
+	// orphaned are the names owned by this GLBC that no DNSRecord owns.
+	orphaned []string
+	// orphanedEndpoints are the records of the orphaned names, including
+	// their ownership records.
+	orphanedEndpoints []*v1.Endpoint
+}
+
+func (a *Auditor) audit(ctx context.Context) {
+	zones, err := resolveZones(ctx, a.registry.provider, a.zones)
+	if err != nil {
+		a.logger.Error(err, "Failed to resolve DNS zones, skipping audit")
+		return
+	}
+
+	var records []ownedRecord
+	for _, controller := range a.controllers {
+		list, err := controller.lister.List(labels.Everything())
+		if err != nil {
+			a.logger.Error(err, "Failed to list DNSRecords, skipping audit")
+			return
+		}
+		for _, record := range list {
+			records = append(records, ownedRecord{controller: controller, record: record})
+		}
+	}
+
+	for _, zone := range zones {
+		result, err := a.auditZone(ctx, zone.DNSZone, records)
+		if err != nil {
+			a.logger.Error(err, "Failed to audit DNS zone", "zone", zone.ID)
+			observeAudit(zone.ID, err)
+			continue
+		}
+		observeAudit(zone.ID, nil)
+		zoneDriftedRecords.WithLabelValues(zone.ID).Set(float64(len(result.drifted)))
+		zoneOrphanedNames.WithLabelValues(zone.ID).Set(float64(len(result.orphaned)))
+
+		for _, drifted := range result.drifted {
+			a.logger.Info("DNS records have drifted, publishing them again", "zone", zone.ID, "record", drifted.record.Name, "namespace", drifted.record.Namespace)
+			drifted.controller.markDrifted(drifted.record)
+		}
+		if len(result.orphaned) == 0 {
+			continue
+		}
+		if !a.deleteOrphanedRecords {
+			a.logger.Info("Found names not owned by any DNSRecord, enable the deletion of orphaned records to delete them", "zone", zone.ID, "names", result.orphaned)
+			continue
+		}
+		if err := a.deleteOrphans(ctx, zone.DNSZone, result); err != nil {
+			a.logger.Error(err, "Failed to delete orphaned DNS records", "zone", zone.ID, "names", result.orphaned)
+			continue
+		}
+		zoneOrphanedNamesDeleted.WithLabelValues(zone.ID).Add(float64(len(result.orphaned)))
+		a.logger.Info("Deleted orphaned DNS records", "zone", zone.ID, "names", result.orphaned)
+	}
+}
+
+// auditZone compares the records of the zone with the endpoints the DNSRecords
+// have published to it, as recorded in their status.
+func (a *Auditor) auditZone(ctx context.Context, zone v1.DNSZone, records []ownedRecord) (*zoneAudit, error) {
+	live, err := a.registry.provider.ListRecords(ctx, zone)
+	if err != nil {
+		return nil, err
+	}
+	liveByName := map[string][]*v1.Endpoint{}
+	for _, endpoint := range live {
+		name := normalizeDNSName(endpoint.DNSName)
+		liveByName[name] = append(liveByName[name], endpoint)
+	}
+
+	result := &zoneAudit{}
+	published := map[string]struct{}{}
+	resources := map[string]struct{}{}
+	for _, owned := range records {
+		record := owned.record
+		resources[ownershipResource(record)] = struct{}{}
+		endpoints := publishedEndpoints(record, zone)
+		for _, endpoint := range endpoints {
+			published[normalizeDNSName(endpoint.DNSName)] = struct{}{}
+		}
+		// The DNSRecords that are about to be reconciled are skipped, as well
+		// as the ones that failed to be published
+		if record.DeletionTimestamp != nil || record.Generation != record.Status.ObservedGeneration || !RecordIsAlreadyPublishedToZone(record, &zone) {
+			continue
+		}
+		if hasDrifted(endpoints, liveByName) {
+			result.drifted = append(result.drifted, owned)
+		}
+	}
+
+	for name, resource := range a.registry.ownedNames(live) {
+		if _, ok := published[name]; ok {
+			continue
+		}
+		// The DNSRecord may have been published without its status being
+		// updated yet
+		if _, ok := resources[resource]; ok {
+			continue
+		}
+		result.orphaned = append(result.orphaned, name)
+	}
+	sort.Strings(result.orphaned)
+	for _, name := range result.orphaned {
+		for _, endpoint := range append(liveByName[name], liveByName[ownershipRecordName(name)]...) {
+			if isManagedRecordType(endpoint.RecordType) {
+				result.orphanedEndpoints = append(result.orphanedEndpoints, endpoint)
+			}
+		}
+	}
+	return result, nil
+}
+
+func (a *Auditor) deleteOrphans(ctx context.Context, zone v1.DNSZone, result *zoneAudit) error {
+	orphaned := &v1.DNSRecord{Spec: v1.DNSRecordSpec{Endpoints: result.orphanedEndpoints}}
+	a.registry.forget(zone, result.orphaned)
+	return a.registry.provider.Delete(ctx, orphaned, zone)
+}
+
+// hasDrifted returns whether the published endpoints differ from the live
+// records of their names. The records are compared by name and type, as the
+// set identifiers and provider specific properties do not round trip all the
+// providers, and some providers only publish a subset of the targets, e.g. the
+// RFC 2136 one skips the targets without weight. The records have drifted if
+// one of them is missing, or holds a target or a TTL that was not published.
+func hasDrifted(published []*v1.Endpoint, liveByName map[string][]*v1.Endpoint) bool {
+	var live []*v1.Endpoint
+	for _, name := range managedNames(published) {
+		live = append(live, liveByName[name]...)
+	}
+	for _, endpoint := range published {
+		if isOwnershipRecord(endpoint) {
+			live = append(live, liveByName[normalizeDNSName(endpoint.DNSName)]...)
+		}
+	}
+
+	actual := recordSets(live)
+	for key, expected := range recordSets(published) {
+		values, ok := actual[key]
+		if !ok {
+			return true
+		}
+		for value := range values {
+			if _, ok := expected[value]; !ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// recordSets returns the TTLs and targets of the endpoints, by name and type.
+func recordSets(endpoints []*v1.Endpoint) map[string]map[string]struct{} {
+	sets := map[string]map[string]struct{}{}
+	for _, endpoint := range endpoints {
+		key := recordSetKey(endpoint)
+		set, ok := sets[key]
+		if !ok {
+			set = map[string]struct{}{}
+			sets[key] = set
+		}
+		// The alias records have the TTL of their target
+		if !endpoint.IsAlias() {
+			set[fmt.Sprintf("ttl:%d", endpoint.RecordTTL)] = struct{}{}
+		}
+		for _, target := range endpoint.Targets {
+			if endpoint.RecordType == string(v1.CNAMERecordType) {
+				target = normalizeDNSName(target)
+			}
+			set["target:"+target] = struct{}{}
+		}
+	}
+	return sets
+}
+
+func recordSetKey(endpoint *v1.Endpoint) string {
+	return normalizeDNSName(endpoint.DNSName) + "/" + endpoint.RecordType
+}
+
+// isManagedRecordType returns whether the GLBC publishes records of the type.
+func isManagedRecordType(recordType string) bool {
+	switch v1.DNSRecordType(recordType) {
+	case v1.ARecordType, v1.AAAARecordType, v1.CNAMERecordType, v1.TXTRecordType, v1.CAARecordType:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+package dns
+
+import (
+	"context"
+	"testing"
+
+	"github.com/onsi/gomega"
+
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/kuadrant/kcp-glbc/pkg/_internal/log"
+	v1 "github.com/kuadrant/kcp-glbc/pkg/apis/kuadrant/v1"
+	kuadrantv1lister "github.com/kuadrant/kcp-glbc/pkg/client/kuadrant/listers/kuadrant/v1"
+	"github.com/kuadrant/kcp-glbc/pkg/reconciler"
+)
+
+func TestAuditorRepublishesDriftedRecords(t *testing.T) {
+	g := gomega.NewWithT(t)
+	provider := newTestMemoryProvider(t)
+	zone := v1.DNSZone{ID: "example.com"}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	c := &Controller{
+		Controller:  &reconciler.Controller{Logger: log.Logger, Queue: workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())},
+		lister:      kuadrantv1lister.NewDNSRecordLister(indexer),
+		dnsProvider: provider,
+		registry:    NewRegistry("glbc-1", provider),
+		dnsZones:    []Zone{{DNSZone: zone}},
+	}
+	t.Cleanup(c.Queue.ShutDown)
+	auditor, err := NewAuditor(&AuditorConfig{Controllers: []*Controller{c}, Registry: c.registry, Zones: c.dnsZones})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	record := newTestRecord("app", aEndpoint("app.example.com", "10.0.0.1"))
+	g.Expect(c.reconcile(context.TODO(), record)).To(gomega.Succeed())
+	g.Expect(indexer.Add(record)).To(gomega.Succeed())
+
+	result, err := auditor.auditZone(context.TODO(), zone, []ownedRecord{{controller: c, record: record}})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(result.drifted).To(gomega.BeEmpty())
+	g.Expect(result.orphaned).To(gomega.BeEmpty())
+
+	// The record is modified by hand
+	g.Expect(provider.Ensure(context.TODO(), newTestRecord("manual", aEndpoint("app.example.com", "10.0.0.9")), zone)).To(gomega.Succeed())
+	auditor.audit(context.TODO())
+	g.Expect(c.Queue.Len()).To(gomega.Equal(1))
+
+	// The drifted record is published again, although it is unchanged
+	g.Expect(c.reconcile(context.TODO(), record)).To(gomega.Succeed())
+	endpoints, err := provider.GetRecords(context.TODO(), zone, "app.example.com", string(v1.ARecordType))
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(endpoints).To(gomega.HaveLen(1))
+	g.Expect(endpoints[0].Targets).To(gomega.Equal(v1.Targets{"10.0.0.1"}))
+
+	// The record is deleted by hand
+	g.Expect(provider.Delete(context.TODO(), newTestRecord("manual", aEndpoint("app.example.com", "10.0.0.1")), zone)).To(gomega.Succeed())
+	result, err = auditor.auditZone(context.TODO(), zone, []ownedRecord{{controller: c, record: record}})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(result.drifted).To(gomega.HaveLen(1))
+}
+
+func TestAuditorDeletesOrphanedRecords(t *testing.T) {
+	g := gomega.NewWithT(t)
+	provider := newTestMemoryProvider(t)
+	zone := v1.DNSZone{ID: "example.com"}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	c := &Controller{
+		Controller:  &reconciler.Controller{Logger: log.Logger},
+		lister:      kuadrantv1lister.NewDNSRecordLister(indexer),
+		dnsProvider: provider,
+		registry:    NewRegistry("glbc-1", provider),
+		dnsZones:    []Zone{{DNSZone: zone}},
+	}
+
+	record := newTestRecord("app", aEndpoint("app.example.com", "10.0.0.1"))
+	g.Expect(c.reconcile(context.TODO(), record)).To(gomega.Succeed())
+	g.Expect(indexer.Add(record)).To(gomega.Succeed())
+
+	// The records of a lost DNSRecord, and of another owner
+	lost := newTestRecord("lost", aEndpoint("lost.example.com", "10.0.0.2"))
+	g.Expect(c.reconcile(context.TODO(), lost)).To(gomega.Succeed())
+	other := newTestRecord("other", aEndpoint("other.example.com", "10.0.0.3"))
+	_, err := NewRegistry("glbc-2", provider).Ensure(context.TODO(), other, zone)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	// The orphaned records are only reported by default
+	auditor, err := NewAuditor(&AuditorConfig{Controllers: []*Controller{c}, Registry: c.registry, Zones: c.dnsZones})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	result, err := auditor.auditZone(context.TODO(), zone, []ownedRecord{{controller: c, record: record}})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(result.orphaned).To(gomega.Equal([]string{"lost.example.com"}))
+	g.Expect(result.orphanedEndpoints).To(gomega.HaveLen(2))
+
+	auditor.audit(context.TODO())
+	g.Expect(provider.GetRecords(context.TODO(), zone, "lost.example.com", string(v1.ARecordType))).To(gomega.HaveLen(1))
+
+	auditor, err = NewAuditor(&AuditorConfig{Controllers: []*Controller{c}, Registry: c.registry, Zones: c.dnsZones, DeleteOrphanedRecords: true})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	auditor.audit(context.TODO())
+	g.Expect(provider.GetRecords(context.TODO(), zone, "lost.example.com", string(v1.ARecordType))).To(gomega.BeEmpty())
+	g.Expect(provider.GetRecords(context.TODO(), zone, "_glbc-owner.lost.example.com", string(v1.TXTRecordType))).To(gomega.BeEmpty())
+	g.Expect(provider.GetRecords(context.TODO(), zone, "app.example.com", string(v1.ARecordType))).To(gomega.HaveLen(1))
+	g.Expect(provider.GetRecords(context.TODO(), zone, "other.example.com", string(v1.ARecordType))).To(gomega.HaveLen(1))
+}
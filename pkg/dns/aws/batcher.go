@@ -0,0 +1,193 @@
+package aws
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/route53"
+)
+
+const (
+	// DefaultBatchInterval is the window during which the changes submitted
+	// for a hosted zone are coalesced into a single change batch.
+	DefaultBatchInterval = 200 * time.Millisecond
+
+	// maxChangesPerBatch bounds the size of the change batches, below the
+	// limit of 1000 resource records per ChangeResourceRecordSets request.
+	maxChangesPerBatch = 500
+)
+
+// changeRequest holds the changes of a single record, the context of the
+// caller, and the channel its outcome is sent to once the batch it is part of
+// has been submitted.
+type changeRequest struct {
+	ctx     context.Context
+	changes []*route53.Change
+	done    chan error
+}
+
+// changeBatcher coalesces the changes submitted for a hosted zone within the
+// batch interval into a single ChangeResourceRecordSets request, so that the
+// reconciliation of many records does not exceed the Route53 request rate.
+type changeBatcher struct {
+	client   *InstrumentedRoute53
+	interval time.Duration
+	logger   logr.Logger
+
+	mu      sync.Mutex
+	pending map[string][]*changeRequest
+}
+
+func newChangeBatcher(client *InstrumentedRoute53, interval time.Duration, logger logr.Logger) *changeBatcher {
+	if interval <= 0 {
+		interval = DefaultBatchInterval
+	}
+	return &changeBatcher{
+		client:   client,
+		interval: interval,
+		logger:   logger.WithName("batcher"),
+		pending:  map[string][]*changeRequest{},
+	}
+}
+
+// submit queues the changes for the hosted zone, and blocks until the batch
+// they are part of has been submitted.
+func (b *changeBatcher) submit(ctx context.Context, zoneID string, changes []*route53.Change) error {
+	request := &changeRequest{
+		ctx:     ctx,
+		changes: changes,
+		done:    make(chan error, 1),
+	}
+
+	b.mu.Lock()
+	first := len(b.pending[zoneID]) == 0
+	b.pending[zoneID] = append(b.pending[zoneID], request)
+	b.mu.Unlock()
+
+	if first {
+		time.AfterFunc(b.interval, func() { b.flush(zoneID) })
+	}
+
+	select {
+	case err := <-request.done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// flush submits the pending changes of the hosted zone. The changes of the
+// callers that have given up in the meantime are not submitted.
+func (b *changeBatcher) flush(zoneID string) {
+	b.mu.Lock()
+	pending := b.pending[zoneID]
+	delete(b.pending, zoneID)
+	b.mu.Unlock()
+
+	var requests []*changeRequest
+	for _, request := range pending {
+		if err := request.ctx.Err(); err != nil {
+			request.done <- err
+			continue
+		}
+		requests = append(requests, request)
+	}
+
+	for _, batch := range splitRequests(requests) {
+		err := b.submitBatch(zoneID, batch)
+		if err != nil && len(batch) > 1 && isInvalidChangeBatchError(err) {
+			// A single invalid change fails the whole batch, so the changes
+			// of each record are submitted on their own, to isolate it.
+			b.logger.V(3).Info("Change batch rejected, submitting the changes separately", "zone", zoneID, "error", err)
+			for _, request := range batch {
+				request.done <- b.submitBatch(zoneID, []*changeRequest{request})
+			}
+			continue
+		}
+		for _, request := range batch {
+			request.done <- err
+		}
+	}
+}
+
+func (b *changeBatcher) submitBatch(zoneID string, requests []*changeRequest) error {
+	ctx, cancel := batchContext(requests)
+	defer cancel()
+	changes := orderChanges(requests)
+	input := &route53.ChangeResourceRecordSetsInput{
+		HostedZoneId: aws.String(zoneID),
+		ChangeBatch:  &route53.ChangeBatch{Changes: changes},
+	}
+	_, err := b.client.ChangeResourceRecordSets(ctx, input)
+	observeBatch(len(requests), len(changes), err)
+	if err != nil {
+		return err
+	}
+	b.logger.V(3).Info("Submitted change batch", "zone", zoneID, "records", len(requests), "changes", len(changes))
+	return nil
+}
+
+// batchContext returns the context of a batch of requests, that is done once
+// the contexts of all the requests are done, so that the batch is abandoned
+// when none of its callers wait for it anymore.
+func batchContext(requests []*changeRequest) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		for _, request := range requests {
+			select {
+			case <-request.ctx.Done():
+			case <-ctx.Done():
+				return
+			}
+		}
+		cancel()
+	}()
+	return ctx, cancel
+}
+
+// splitRequests groups the requests into batches of at most
+// maxChangesPerBatch changes, without splitting the changes of a request.
+func splitRequests(requests []*changeRequest) [][]*changeRequest {
+	var batches [][]*changeRequest
+	var batch []*changeRequest
+	size := 0
+	for _, request := range requests {
+		if len(batch) > 0 && size+len(request.changes) > maxChangesPerBatch {
+			batches = append(batches, batch)
+			batch, size = nil, 0
+		}
+		batch = append(batch, request)
+		size += len(request.changes)
+	}
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+	return batches
+}
+
+// orderChanges returns the changes of the requests with the deletions first,
+// so that a record set can be replaced by one of another type with the same
+// name, e.g. an A record by a CNAME record.
+func orderChanges(requests []*changeRequest) []*route53.Change {
+	var deletions, others []*route53.Change
+	for _, request := range requests {
+		for _, change := range request.changes {
+			if aws.StringValue(change.Action) == string(deleteAction) {
+				deletions = append(deletions, change)
+			} else {
+				others = append(others, change)
+			}
+		}
+	}
+	return append(deletions, others...)
+}
+
+func isInvalidChangeBatchError(err error) bool {
+	awsErr, ok := err.(awserr.Error)
+	return ok && awsErr.Code() == route53.ErrCodeInvalidChangeBatch
+}
@@ -15,17 +15,134 @@ limitations under the License.
 package aws
 
 import (
+	"context"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/route53"
+	"golang.org/x/time/rate"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	dnserrors "github.com/kuadrant/kcp-glbc/pkg/dns/errors"
 )
 
+const (
+	// DefaultRequestsPerSecond is the rate of the requests to Route53, which
+	// allows 5 requests per second per account.
+	DefaultRequestsPerSecond = 5
+
+	// throttlingErrorCode is returned by Route53 when the request rate is
+	// exceeded, and priorRequestNotCompleteErrorCode when a change batch is
+	// submitted while the previous one is still being processed.
+	throttlingErrorCode              = "Throttling"
+	priorRequestNotCompleteErrorCode = "PriorRequestNotComplete"
+	accessDeniedErrorCode            = "AccessDenied"
+	invalidClientTokenIdErrorCode    = "InvalidClientTokenId"
+	signatureDoesNotMatchErrorCode   = "SignatureDoesNotMatch"
+)
+
+// throttlingBackoff is the jittered backoff applied to the throttled requests.
+var throttlingBackoff = wait.Backoff{
+	Duration: 500 * time.Millisecond,
+	Factor:   2,
+	Jitter:   1,
+	Steps:    5,
+	Cap:      10 * time.Second,
+}
+
 type InstrumentedRoute53 struct {
 	route53 *route53.Route53
+	// limiter is the token bucket shared by all the requests to Route53,
+	// including the health check requests.
+	limiter *rate.Limiter
+	backoff wait.Backoff
+}
+
+func newInstrumentedRoute53(client *route53.Route53, requestsPerSecond float64) *InstrumentedRoute53 {
+	if requestsPerSecond <= 0 {
+		requestsPerSecond = DefaultRequestsPerSecond
+	}
+	return &InstrumentedRoute53{
+		route53: client,
+		limiter: rate.NewLimiter(rate.Limit(requestsPerSecond), 1),
+		backoff: throttlingBackoff,
+	}
+}
+
+// do waits for the limiter before performing the request, and retries it
+// with a jittered backoff as long as it is throttled.
+func (c *InstrumentedRoute53) do(ctx context.Context, operation string, f func() error) error {
+	backoff := c.backoff
+	for {
+		if err := c.limiter.Wait(ctx); err != nil {
+			return err
+		}
+		var err error
+		observe(operation, func() error {
+			err = f()
+			return err
+		})
+		if err == nil || !isThrottlingError(err) || backoff.Steps < 1 {
+			return err
+		}
+		select {
+		case <-time.After(backoff.Step()):
+		case <-ctx.Done():
+			return err
+		}
+	}
+}
+
+func isThrottlingError(err error) bool {
+	if awsErr, ok := err.(awserr.Error); ok {
+		switch awsErr.Code() {
+		case throttlingErrorCode, priorRequestNotCompleteErrorCode:
+			return true
+		}
+	}
+	return false
+}
+
+// recordSetNotFoundMessage is the end of the message of the InvalidChangeBatch
+// errors Route53 rejects the deletion of a record set that does not exist with.
+const recordSetNotFoundMessage = "but it was not found"
+
+// providerError returns the error with the reason of the Route53 error code.
+func providerError(err error) error {
+	awsErr, ok := err.(awserr.Error)
+	if !ok {
+		return err
+	}
+	reason := dnserrors.ReasonUnknown
+	switch awsErr.Code() {
+	case route53.ErrCodeNoSuchHostedZone:
+		reason = dnserrors.ReasonZoneNotFound
+	case throttlingErrorCode, priorRequestNotCompleteErrorCode, route53.ErrCodeThrottlingException:
+		reason = dnserrors.ReasonThrottled
+	case accessDeniedErrorCode, invalidClientTokenIdErrorCode, signatureDoesNotMatchErrorCode:
+		reason = dnserrors.ReasonPermissionDenied
+	case route53.ErrCodeInvalidChangeBatch:
+		reason = dnserrors.ReasonInvalidRecord
+		if isRecordSetNotFound(awsErr) {
+			reason = dnserrors.ReasonNotFound
+		}
+	case route53.ErrCodeInvalidInput:
+		reason = dnserrors.ReasonInvalidRecord
+	}
+	return dnserrors.New(reason, err)
+}
+
+// isRecordSetNotFound returns whether the error is the rejection of the
+// deletion of a record set that does not exist. Route53 has no error code for
+// it, and reports it with the InvalidChangeBatch code it also rejects invalid
+// records with, so it can only be told apart by its message.
+func isRecordSetNotFound(awsErr awserr.Error) bool {
+	return awsErr.Code() == route53.ErrCodeInvalidChangeBatch && strings.Contains(awsErr.Message(), recordSetNotFoundMessage)
 }
 
 func observe(operation string, f func() error) {
@@ -51,40 +168,64 @@ func observe(operation string, f func() error) {
 	route53RequestTotal.WithLabelValues(operation, code).Inc()
 }
 
-func (c *InstrumentedRoute53) ListHostedZones(input *route53.ListHostedZonesInput) (output *route53.ListHostedZonesOutput, err error) {
-	observe("ListHostedZones", func() error {
-		output, err = c.route53.ListHostedZones(input)
+func (c *InstrumentedRoute53) ListHostedZones(ctx aws.Context, input *route53.ListHostedZonesInput) (output *route53.ListHostedZonesOutput, err error) {
+	err = c.do(ctx, "ListHostedZones", func() error {
+		output, err = c.route53.ListHostedZonesWithContext(ctx, input)
 		return err
 	})
 	return
 }
 
-func (c *InstrumentedRoute53) ChangeResourceRecordSets(input *route53.ChangeResourceRecordSetsInput) (output *route53.ChangeResourceRecordSetsOutput, err error) {
-	observe("ChangeResourceRecordSets", func() error {
-		output, err = c.route53.ChangeResourceRecordSets(input)
+func (c *InstrumentedRoute53) ChangeResourceRecordSets(ctx aws.Context, input *route53.ChangeResourceRecordSetsInput) (output *route53.ChangeResourceRecordSetsOutput, err error) {
+	err = c.do(ctx, "ChangeResourceRecordSets", func() error {
+		output, err = c.route53.ChangeResourceRecordSetsWithContext(ctx, input)
 		return err
 	})
 	return
 }
 
-func (c *InstrumentedRoute53) CreateHealthCheck(input *route53.CreateHealthCheckInput) (output *route53.CreateHealthCheckOutput, err error) {
-	observe("CreateHealthCheck", func() error {
-		output, err = c.route53.CreateHealthCheck(input)
+func (c *InstrumentedRoute53) ListResourceRecordSets(ctx aws.Context, input *route53.ListResourceRecordSetsInput) (output *route53.ListResourceRecordSetsOutput, err error) {
+	err = c.do(ctx, "ListResourceRecordSets", func() error {
+		output, err = c.route53.ListResourceRecordSetsWithContext(ctx, input)
+		return err
+	})
+	return
+}
+
+func (c *InstrumentedRoute53) CreateHealthCheck(ctx aws.Context, input *route53.CreateHealthCheckInput) (output *route53.CreateHealthCheckOutput, err error) {
+	err = c.do(ctx, "CreateHealthCheck", func() error {
+		output, err = c.route53.CreateHealthCheckWithContext(ctx, input)
 		return err
 	})
 	return
 }
 
 func (c *InstrumentedRoute53) GetHealthCheckWithContext(ctx aws.Context, input *route53.GetHealthCheckInput, opts ...request.Option) (output *route53.GetHealthCheckOutput, err error) {
-	observe("GetHealthCheckWithContext", func() error {
+	err = c.do(ctx, "GetHealthCheckWithContext", func() error {
 		output, err = c.route53.GetHealthCheckWithContext(ctx, input, opts...)
 		return err
 	})
 	return
 }
 
+func (c *InstrumentedRoute53) GetHealthCheckStatusWithContext(ctx aws.Context, input *route53.GetHealthCheckStatusInput, opts ...request.Option) (output *route53.GetHealthCheckStatusOutput, err error) {
+	err = c.do(ctx, "GetHealthCheckStatusWithContext", func() error {
+		output, err = c.route53.GetHealthCheckStatusWithContext(ctx, input, opts...)
+		return err
+	})
+	return
+}
+
+func (c *InstrumentedRoute53) ListHealthChecksWithContext(ctx aws.Context, input *route53.ListHealthChecksInput, opts ...request.Option) (output *route53.ListHealthChecksOutput, err error) {
+	err = c.do(ctx, "ListHealthChecksWithContext", func() error {
+		output, err = c.route53.ListHealthChecksWithContext(ctx, input, opts...)
+		return err
+	})
+	return
+}
+
 func (c *InstrumentedRoute53) UpdateHealthCheckWithContext(ctx aws.Context, input *route53.UpdateHealthCheckInput, opts ...request.Option) (output *route53.UpdateHealthCheckOutput, err error) {
-	observe("UpdateHealthCheckWithContext", func() error {
+	err = c.do(ctx, "UpdateHealthCheckWithContext", func() error {
 		output, err = c.route53.UpdateHealthCheckWithContext(ctx, input, opts...)
 		return err
 	})
@@ -92,7 +233,7 @@ func (c *InstrumentedRoute53) UpdateHealthCheckWithContext(ctx aws.Context, inpu
 }
 
 func (c *InstrumentedRoute53) DeleteHealthCheckWithContext(ctx aws.Context, input *route53.DeleteHealthCheckInput, opts ...request.Option) (output *route53.DeleteHealthCheckOutput, err error) {
-	observe("DeleteHealthCheckWithContext", func() error {
+	err = c.do(ctx, "DeleteHealthCheckWithContext", func() error {
 		output, err = c.route53.DeleteHealthCheckWithContext(ctx, input, opts...)
 		return err
 	})
@@ -100,9 +241,17 @@ func (c *InstrumentedRoute53) DeleteHealthCheckWithContext(ctx aws.Context, inpu
 }
 
 func (c *InstrumentedRoute53) ChangeTagsForResourceWithContext(ctx aws.Context, input *route53.ChangeTagsForResourceInput, opts ...request.Option) (output *route53.ChangeTagsForResourceOutput, err error) {
-	observe("ChangeTagsForResourceWithContext", func() error {
+	err = c.do(ctx, "ChangeTagsForResourceWithContext", func() error {
 		output, err = c.route53.ChangeTagsForResourceWithContext(ctx, input, opts...)
 		return err
 	})
 	return
 }
+
+func (c *InstrumentedRoute53) ListTagsForResourcesWithContext(ctx aws.Context, input *route53.ListTagsForResourcesInput, opts ...request.Option) (output *route53.ListTagsForResourcesOutput, err error) {
+	err = c.do(ctx, "ListTagsForResourcesWithContext", func() error {
+		output, err = c.route53.ListTagsForResourcesWithContext(ctx, input, opts...)
+		return err
+	})
+	return
+}
@@ -3,19 +3,23 @@ package aws
 import (
 	"context"
 	"fmt"
-	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/go-logr/logr"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/endpoints"
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi"
 	"github.com/aws/aws-sdk-go/service/route53"
 
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
 
 	"github.com/kuadrant/kcp-glbc/pkg/_internal/log"
 	v1 "github.com/kuadrant/kcp-glbc/pkg/apis/kuadrant/v1"
+	dnserrors "github.com/kuadrant/kcp-glbc/pkg/dns/errors"
 )
 
 const (
@@ -23,17 +27,38 @@ const (
 	chinaRoute53Endpoint = "https://route53.amazonaws.com.cn"
 
 	ProviderSpecificEvaluateTargetHealth = "aws/evaluate-target-health"
-	ProviderSpecificWeight               = "aws/weight"
-	ProviderSpecificRegion               = "aws/region"
-	ProviderSpecificFailover             = "aws/failover"
-	ProviderSpecificMultiValueAnswer     = "aws/multi-value-answer"
-	ProviderSpecificHealthCheckID        = "aws/health-check-id"
-	ZoneIDEnvVar                         = "AWS_DNS_PUBLIC_ZONE_ID"
+	// ProviderSpecificAlias publishes a CNAME endpoint targeting an AWS load
+	// balancer as an alias A record.
+	ProviderSpecificAlias            = v1.ProviderSpecificAlias
+	ProviderSpecificMultiValueAnswer = "aws/multi-value-answer"
+	ProviderSpecificHealthCheckID    = "aws/health-check-id"
+	// ProviderSpecificClusterHealthCheckID is the ID of the calculated health
+	// check of the cluster of the endpoint, that the record is associated with
+	// instead of the health check of the endpoint.
+	ProviderSpecificClusterHealthCheckID = "aws/cluster-health-check-id"
+	// ProviderSpecificWeight, ProviderSpecificRegion, ProviderSpecificFailover, ProviderSpecificGeolocationContinentCode
+	// and ProviderSpecificGeolocationCountryCode configure a routing policy.
+	//
+	// Deprecated: set the RoutingPolicy of the endpoints instead. They are only honoured when an endpoint has none.
+	ProviderSpecificWeight                   = v1.LegacyProviderSpecificWeight
+	ProviderSpecificRegion                   = v1.LegacyProviderSpecificRegion
+	ProviderSpecificFailover                 = v1.LegacyProviderSpecificFailover
+	ProviderSpecificGeolocationContinentCode = v1.LegacyProviderSpecificGeolocationContinentCode
+	ProviderSpecificGeolocationCountryCode   = v1.LegacyProviderSpecificGeolocationCountryCode
+	// GeolocationDefault is the Route53 country code matching the locations not covered by other records.
+	GeolocationDefault        = "*"
+	ZoneIDEnvVar              = "AWS_DNS_PUBLIC_ZONE_ID"
+	ZoneTagsEnvVar            = "AWS_DNS_PUBLIC_ZONE_TAGS"
+	ZoneRefreshIntervalEnvVar = "AWS_DNS_ZONE_REFRESH_INTERVAL"
+	RequestsPerSecondEnvVar   = "AWS_ROUTE53_REQUESTS_PER_SECOND"
+	BatchIntervalEnvVar       = "AWS_ROUTE53_BATCH_INTERVAL"
 )
 
 // Inspired by https://github.com/openshift/cluster-ingress-operator/blob/master/pkg/dns/aws/dns.go
 type Provider struct {
 	route53               *InstrumentedRoute53
+	batcher               *changeBatcher
+	zoneResolver          *zoneResolver
 	healthCheckReconciler *Route53HealthCheckReconciler
 	config                Config
 	logger                logr.Logger
@@ -43,6 +68,18 @@ type Provider struct {
 type Config struct {
 	// Region is the AWS region ELBs are created in.
 	Region string
+	// RequestsPerSecond is the rate of the requests to Route53, shared by the
+	// record and health check requests. Defaults to DefaultRequestsPerSecond.
+	RequestsPerSecond float64
+	// BatchInterval is the window during which the record changes of a hosted
+	// zone are coalesced into a single change batch. Defaults to DefaultBatchInterval.
+	BatchInterval time.Duration
+	// ZoneRefreshInterval is the interval after which the hosted zones found
+	// by tags are resolved again. Defaults to DefaultZoneRefreshInterval.
+	ZoneRefreshInterval time.Duration
+	// OwnerID identifies the GLBC in the tags of the health checks it creates,
+	// so that it only garbage collects its own health checks.
+	OwnerID string
 }
 
 func NewProvider(config Config) (*Provider, error) {
@@ -78,27 +115,62 @@ func NewProvider(config Config) (*Provider, error) {
 		r53Config = r53Config.WithRegion(endpoints.UsEast1RegionID)
 	}
 
+	logger := log.Logger.WithName("aws-route53").WithValues("region", r53Config.Region)
+	client := sharedRoute53Client(aws.StringValue(r53Config.Region), func() *route53.Route53 { return route53.New(sess, r53Config) }, config, logger)
 	p := &Provider{
-		route53: &InstrumentedRoute53{route53.New(sess, r53Config)},
+		route53: client.route53,
+		batcher: client.batcher,
 		config:  config,
-		logger:  log.Logger.WithName("aws-route53").WithValues("region", r53Config.Region),
+		logger:  logger,
 	}
-	if err := validateServiceEndpoints(p); err != nil {
+	// The hosted zones are tagged in the Route53 region
+	taggingConfig := aws.NewConfig().WithRegion(aws.StringValue(r53Config.Region))
+	p.zoneResolver = newZoneResolver(resourcegroupstaggingapi.New(sess, taggingConfig), config.ZoneRefreshInterval, p.logger)
+	if err := validateServiceEndpoints(context.Background(), p); err != nil {
 		return nil, fmt.Errorf("failed to validate AWS provider service endpoints: %v", err)
 	}
 	if p.healthCheckReconciler == nil {
-		p.healthCheckReconciler = newRoute53HealthCheckReconciler(p.route53, p.logger)
+		p.healthCheckReconciler = newRoute53HealthCheckReconciler(p.route53, config.OwnerID, p.logger)
 	}
 
 	return p, nil
 }
 
+// route53Clients are the Route53 clients shared by the providers, by Route53
+// region. Route53 limits the request rate per account, so the providers share
+// the limiter of the client of their region, and its change batcher.
+var (
+	route53ClientsMu sync.Mutex
+	route53Clients   = map[string]*route53Client{}
+)
+
+// route53Client is a rate limited Route53 client, along with the batcher of
+// the record changes submitted with it.
+type route53Client struct {
+	route53 *InstrumentedRoute53
+	batcher *changeBatcher
+}
+
+// sharedRoute53Client returns the client of the Route53 region. It is created
+// on first use, with the request rate and batch interval of the config.
+func sharedRoute53Client(region string, newClient func() *route53.Route53, config Config, logger logr.Logger) *route53Client {
+	route53ClientsMu.Lock()
+	defer route53ClientsMu.Unlock()
+	if client, ok := route53Clients[region]; ok {
+		return client
+	}
+	client := &route53Client{route53: newInstrumentedRoute53(newClient(), config.RequestsPerSecond)}
+	client.batcher = newChangeBatcher(client.route53, config.BatchInterval, logger)
+	route53Clients[region] = client
+	return client
+}
+
 // validateServiceEndpoints validates that provider clients can communicate with
 // associated API endpoints by having each client make a list/describe/get call.
-func validateServiceEndpoints(provider *Provider) error {
+func validateServiceEndpoints(ctx context.Context, provider *Provider) error {
 	var errs []error
 	zoneInput := route53.ListHostedZonesInput{MaxItems: aws.String("1")}
-	if _, err := provider.route53.ListHostedZones(&zoneInput); err != nil {
+	if _, err := provider.route53.ListHostedZones(ctx, &zoneInput); err != nil {
 		errs = append(errs, fmt.Errorf("failed to list route53 hosted zones: %v", err))
 	}
 	return kerrors.NewAggregate(errs)
@@ -111,15 +183,76 @@ const (
 	deleteAction action = "DELETE"
 )
 
-func (p *Provider) Ensure(record *v1.DNSRecord, zone v1.DNSZone) error {
-	return p.change(record, zone, upsertAction)
+func (p *Provider) Ensure(ctx context.Context, record *v1.DNSRecord, zone v1.DNSZone) error {
+	return p.change(ctx, record, zone, upsertAction)
 }
 
-func (p *Provider) Delete(record *v1.DNSRecord, zone v1.DNSZone) error {
-	return p.change(record, zone, deleteAction)
+func (p *Provider) Delete(ctx context.Context, record *v1.DNSRecord, zone v1.DNSZone) error {
+	return p.change(ctx, record, zone, deleteAction)
 }
 
-func (p *Provider) ReconcileHealthCheck(ctx context.Context, hc v1.HealthCheck, endpoint *v1.Endpoint) error {
+// GetRecords returns the record sets of the zone with the given name and type.
+func (p *Provider) GetRecords(ctx context.Context, zone v1.DNSZone, dnsName, recordType string) ([]*v1.Endpoint, error) {
+	name := normalizeName(dnsName)
+	input := &route53.ListResourceRecordSetsInput{
+		HostedZoneId:    aws.String(zone.ID),
+		StartRecordName: aws.String(name),
+		StartRecordType: aws.String(recordType),
+	}
+
+	var endpoints []*v1.Endpoint
+	for {
+		output, err := p.route53.ListResourceRecordSets(ctx, input)
+		if isNoSuchHostedZoneError(err) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list record sets %s in zone %s: %w", dnsName, zone.ID, providerError(err))
+		}
+		for _, recordSet := range output.ResourceRecordSets {
+			// The record sets are sorted by name and type, from the start name and type
+			if normalizeName(aws.StringValue(recordSet.Name)) != name || aws.StringValue(recordSet.Type) != recordType {
+				return endpoints, nil
+			}
+			endpoints = append(endpoints, endpointForRecordSet(recordSet))
+		}
+		if !aws.BoolValue(output.IsTruncated) {
+			return endpoints, nil
+		}
+		input.StartRecordName = output.NextRecordName
+		input.StartRecordType = output.NextRecordType
+		input.StartRecordIdentifier = output.NextRecordIdentifier
+	}
+}
+
+// ListRecords returns all the record sets of the zone.
+func (p *Provider) ListRecords(ctx context.Context, zone v1.DNSZone) ([]*v1.Endpoint, error) {
+	input := &route53.ListResourceRecordSetsInput{
+		HostedZoneId: aws.String(zone.ID),
+	}
+
+	var endpoints []*v1.Endpoint
+	for {
+		output, err := p.route53.ListResourceRecordSets(ctx, input)
+		if isNoSuchHostedZoneError(err) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list record sets in zone %s: %w", zone.ID, providerError(err))
+		}
+		for _, recordSet := range output.ResourceRecordSets {
+			endpoints = append(endpoints, endpointForRecordSet(recordSet))
+		}
+		if !aws.BoolValue(output.IsTruncated) {
+			return endpoints, nil
+		}
+		input.StartRecordName = output.NextRecordName
+		input.StartRecordType = output.NextRecordType
+		input.StartRecordIdentifier = output.NextRecordIdentifier
+	}
+}
+
+func (p *Provider) ReconcileHealthCheck(ctx context.Context, hc v1.EndpointHealthCheck, endpoint *v1.Endpoint) error {
 
 	return p.healthCheckReconciler.reconcile(ctx, hc, endpoint)
 }
@@ -128,12 +261,32 @@ func (p *Provider) DeleteHealthCheck(ctx context.Context, endpoint *v1.Endpoint)
 	return p.healthCheckReconciler.deleteHealthCheck(ctx, endpoint)
 }
 
+func (p *Provider) HealthCheckStatus(ctx context.Context, endpoint *v1.Endpoint) (string, v1.EndpointHealth, error) {
+	return p.healthCheckReconciler.healthCheckStatus(ctx, endpoint)
+}
+
+func (p *Provider) ListHealthChecks(ctx context.Context) (map[string]string, error) {
+	return p.healthCheckReconciler.listHealthChecks(ctx)
+}
+
+func (p *Provider) DeleteHealthCheckByID(ctx context.Context, id string) error {
+	return p.healthCheckReconciler.deleteHealthCheckByID(ctx, id)
+}
+
+func (p *Provider) ReconcileClusterHealthCheck(ctx context.Context, hc v1.EndpointHealthCheck, endpoints []*v1.Endpoint) error {
+	return p.healthCheckReconciler.reconcileClusterHealthCheck(ctx, hc, endpoints)
+}
+
+func (p *Provider) DeleteClusterHealthCheck(ctx context.Context, endpoints []*v1.Endpoint) error {
+	return p.healthCheckReconciler.deleteClusterHealthCheck(ctx, endpoints)
+}
+
 // change will perform an action on a record.
-func (p *Provider) change(record *v1.DNSRecord, zone v1.DNSZone, action action) error {
+func (p *Provider) change(ctx context.Context, record *v1.DNSRecord, zone v1.DNSZone, action action) error {
 	// Configure records.
-	err := p.updateRecord(record, zone.ID, string(action))
+	err := p.updateRecord(ctx, record, zone.ID, string(action))
 	if err != nil {
-		return fmt.Errorf("failed to update record in zone %s: %v", zone.ID, err)
+		return fmt.Errorf("failed to update record in zone %s: %w", zone.ID, err)
 	}
 	switch action {
 	case upsertAction:
@@ -144,13 +297,11 @@ func (p *Provider) change(record *v1.DNSRecord, zone v1.DNSZone, action action)
 	return nil
 }
 
-func (p *Provider) updateRecord(record *v1.DNSRecord, zoneID, action string) error {
-	input := route53.ChangeResourceRecordSetsInput{HostedZoneId: aws.String(zoneID)}
-
+func (p *Provider) updateRecord(ctx context.Context, record *v1.DNSRecord, zoneID, action string) error {
 	expectedEndpointsMap := make(map[string]struct{})
 	var changes []*route53.Change
 	for _, endpoint := range record.Spec.Endpoints {
-		expectedEndpointsMap[endpoint.SetID()] = struct{}{}
+		expectedEndpointsMap[recordSetKey(endpoint)] = struct{}{}
 		change, err := p.changeForEndpoint(endpoint, action)
 		if err != nil {
 			return err
@@ -158,47 +309,47 @@ func (p *Provider) updateRecord(record *v1.DNSRecord, zoneID, action string) err
 		changes = append(changes, change)
 	}
 
-	// Delete any previously published records that are no longer present in record.Spec.Endpoints
+	// Delete any previously published records that are no longer present in record.Spec.Endpoints.
+	// The batcher submits the deletions first, so that a record set can be replaced by one of another type with
+	// the same name, e.g. an A record by a CNAME record.
 	if action != string(deleteAction) {
 		lastPublishedEndpoints, err := p.endpointsFromZoneStatus(record, zoneID)
 		if err != nil {
 			return err
 		}
+		var deletions []*route53.Change
 		for _, endpoint := range lastPublishedEndpoints {
-			if _, found := expectedEndpointsMap[endpoint.SetID()]; !found {
+			if _, found := expectedEndpointsMap[recordSetKey(endpoint)]; !found {
 				change, err := p.changeForEndpoint(endpoint, string(deleteAction))
 				if err != nil {
 					return err
 				}
-				changes = append(changes, change)
+				deletions = append(deletions, change)
 			}
 		}
+		changes = append(deletions, changes...)
 	}
 
 	if len(changes) == 0 {
 		return nil
 	}
-	input.ChangeBatch = &route53.ChangeBatch{
-		Changes: changes,
+	err := p.batcher.submit(ctx, zoneID, changes)
+	if err != nil && action == string(deleteAction) && isNoSuchHostedZoneError(err) {
+		// The records went away with the hosted zone, e.g. one that was
+		// recreated and is now found by its tags under another ID.
+		p.logger.Info("Hosted zone no longer exists, skipping deletion of DNS record", "record", record.Name, "zone", zoneID)
+		return nil
 	}
-	resp, err := p.route53.ChangeResourceRecordSets(&input)
 	if err != nil {
-		return fmt.Errorf("couldn't update DNS record %s in zone %s: %v", record.Name, zoneID, err)
+		return fmt.Errorf("couldn't update DNS record %s in zone %s: %w", record.Name, zoneID, providerError(err))
 	}
-	p.logger.Info("Updated DNS record", "record", record, "zone", zoneID, "response", resp)
+	p.logger.Info("Updated DNS record", "record", record, "zone", zoneID)
 	return nil
 }
 
 func (p *Provider) changeForEndpoint(endpoint *v1.Endpoint, action string) (*route53.Change, error) {
-	if endpoint.RecordType != string(v1.ARecordType) {
-		return nil, fmt.Errorf("unsupported record type %s", endpoint.RecordType)
-	}
-	domain, targets := endpoint.DNSName, endpoint.Targets
-	if len(domain) == 0 {
-		return nil, fmt.Errorf("domain is required")
-	}
-	if len(targets) == 0 {
-		return nil, fmt.Errorf("targets is required")
+	if err := validateEndpoint(endpoint); err != nil {
+		return nil, err
 	}
 
 	var resourceRecords []*route53.ResourceRecord
@@ -208,32 +359,34 @@ func (p *Provider) changeForEndpoint(endpoint *v1.Endpoint, action string) (*rou
 
 	resourceRecordSet := &route53.ResourceRecordSet{
 		Name:            aws.String(endpoint.DNSName),
-		Type:            aws.String(route53.RRTypeA),
+		Type:            aws.String(endpoint.RecordType),
 		TTL:             aws.Int64(int64(endpoint.RecordTTL)),
 		ResourceRecords: resourceRecords,
 	}
+	if endpoint.IsAlias() {
+		// The alias records have the TTL of their target, the load balancer
+		setAliasTarget(resourceRecordSet, endpoint)
+	}
 
-	if endpoint.SetIdentifier != "" {
+	// Routing policies only apply to records with a set identifier, Route53 rejects them otherwise
+	policy := endpoint.GetRoutingPolicy()
+	_, multiValueAnswer := endpoint.GetProviderSpecificProperty(ProviderSpecificMultiValueAnswer)
+	if endpoint.SetIdentifier == "" {
+		if policy != nil || multiValueAnswer {
+			p.logger.V(3).Info("Ignoring routing policy of endpoint without set identifier", "endpoint", endpoint.DNSName)
+		}
+	} else {
 		resourceRecordSet.SetIdentifier = aws.String(endpoint.SetIdentifier)
-	}
-	if prop, ok := endpoint.GetProviderSpecificProperty(ProviderSpecificWeight); ok {
-		weight, err := strconv.ParseInt(prop.Value, 10, 64)
-		if err != nil {
-			p.logger.Error(err, "Failed parsing value, using weight of 0", "weight", ProviderSpecificWeight, "value", prop.Value)
-			weight = 0
+		if policy != nil {
+			setRoutingPolicy(resourceRecordSet, policy)
+		}
+		if multiValueAnswer {
+			resourceRecordSet.MultiValueAnswer = aws.Bool(true)
 		}
-		resourceRecordSet.Weight = aws.Int64(weight)
-	}
-	if prop, ok := endpoint.GetProviderSpecificProperty(ProviderSpecificRegion); ok {
-		resourceRecordSet.Region = aws.String(prop.Value)
-	}
-	if prop, ok := endpoint.GetProviderSpecificProperty(ProviderSpecificFailover); ok {
-		resourceRecordSet.Failover = aws.String(prop.Value)
-	}
-	if _, ok := endpoint.GetProviderSpecificProperty(ProviderSpecificMultiValueAnswer); ok {
-		resourceRecordSet.MultiValueAnswer = aws.Bool(true)
 	}
-	if prop, ok := endpoint.GetProviderSpecificProperty(ProviderSpecificHealthCheckID); ok {
+	if prop, ok := endpoint.GetProviderSpecificProperty(ProviderSpecificClusterHealthCheckID); ok {
+		resourceRecordSet.HealthCheckId = aws.String(prop.Value)
+	} else if prop, ok := endpoint.GetProviderSpecificProperty(ProviderSpecificHealthCheckID); ok {
 		resourceRecordSet.HealthCheckId = aws.String(prop.Value)
 	}
 
@@ -244,6 +397,141 @@ func (p *Provider) changeForEndpoint(endpoint *v1.Endpoint, action string) (*rou
 	return change, nil
 }
 
+// setAliasTarget turns the record set of the CNAME endpoint into an alias A
+// record to the load balancer it targets. The health of the load balancer is
+// evaluated, unless disabled with ProviderSpecificEvaluateTargetHealth.
+func setAliasTarget(resourceRecordSet *route53.ResourceRecordSet, endpoint *v1.Endpoint) {
+	target := endpoint.Targets[0]
+	zoneID, _ := CanonicalHostedZone(target)
+	evaluateTargetHealth := true
+	if prop, ok := endpoint.GetProviderSpecificProperty(ProviderSpecificEvaluateTargetHealth); ok {
+		evaluateTargetHealth = prop.Value != "false"
+	}
+	resourceRecordSet.Type = aws.String(string(v1.ARecordType))
+	resourceRecordSet.TTL = nil
+	resourceRecordSet.ResourceRecords = nil
+	resourceRecordSet.AliasTarget = &route53.AliasTarget{
+		DNSName:              aws.String(target),
+		HostedZoneId:         aws.String(zoneID),
+		EvaluateTargetHealth: aws.Bool(evaluateTargetHealth),
+	}
+}
+
+// setRoutingPolicy translates the routing policy into the Route53 one of the record set.
+func setRoutingPolicy(resourceRecordSet *route53.ResourceRecordSet, policy *v1.RoutingPolicy) {
+	switch {
+	case policy.Weighted != nil:
+		resourceRecordSet.Weight = aws.Int64(policy.Weighted.Weight)
+	case policy.Failover != nil:
+		resourceRecordSet.Failover = aws.String(string(policy.Failover.Role))
+	case policy.Geo != nil:
+		switch {
+		case policy.Geo.Default:
+			resourceRecordSet.GeoLocation = &route53.GeoLocation{CountryCode: aws.String(GeolocationDefault)}
+		case policy.Geo.Country != "":
+			resourceRecordSet.GeoLocation = &route53.GeoLocation{CountryCode: aws.String(policy.Geo.Country)}
+		default:
+			resourceRecordSet.GeoLocation = &route53.GeoLocation{ContinentCode: aws.String(policy.Geo.Continent)}
+		}
+	case policy.Latency != nil:
+		resourceRecordSet.Region = aws.String(policy.Latency.Region)
+	}
+}
+
+// routingPolicyForRecordSet converts the Route53 routing policy of the record set.
+func routingPolicyForRecordSet(recordSet *route53.ResourceRecordSet) *v1.RoutingPolicy {
+	switch {
+	case recordSet.Weight != nil:
+		return &v1.RoutingPolicy{Weighted: &v1.WeightedRoutingPolicy{Weight: *recordSet.Weight}}
+	case recordSet.Failover != nil:
+		return &v1.RoutingPolicy{Failover: &v1.FailoverRoutingPolicy{Role: v1.FailoverRole(*recordSet.Failover)}}
+	case recordSet.GeoLocation != nil:
+		geo := &v1.GeoRoutingPolicy{
+			Continent: aws.StringValue(recordSet.GeoLocation.ContinentCode),
+			Country:   aws.StringValue(recordSet.GeoLocation.CountryCode),
+		}
+		if geo.Country == GeolocationDefault {
+			geo.Country = ""
+			geo.Default = true
+		}
+		return &v1.RoutingPolicy{Geo: geo}
+	case recordSet.Region != nil:
+		return &v1.RoutingPolicy{Latency: &v1.LatencyRoutingPolicy{Region: *recordSet.Region}}
+	}
+	return nil
+}
+
+func validateEndpoint(endpoint *v1.Endpoint) error {
+	switch endpoint.RecordType {
+	case string(v1.ARecordType), string(v1.AAAARecordType), string(v1.CNAMERecordType), string(v1.TXTRecordType), string(v1.CAARecordType):
+	default:
+		return dnserrors.Errorf(dnserrors.ReasonInvalidRecord, "unsupported record type %s", endpoint.RecordType)
+	}
+	if len(endpoint.DNSName) == 0 {
+		return dnserrors.Errorf(dnserrors.ReasonInvalidRecord, "domain is required")
+	}
+	if len(endpoint.Targets) == 0 {
+		return dnserrors.Errorf(dnserrors.ReasonInvalidRecord, "targets is required")
+	}
+	if endpoint.RecordType == string(v1.CNAMERecordType) && len(endpoint.Targets) > 1 {
+		return dnserrors.Errorf(dnserrors.ReasonInvalidRecord, "CNAME record %s must have a single target", endpoint.DNSName)
+	}
+	if endpoint.RecordType == string(v1.CAARecordType) {
+		for _, target := range endpoint.Targets {
+			if _, _, _, err := v1.ParseCAATarget(target); err != nil {
+				return dnserrors.Errorf(dnserrors.ReasonInvalidRecord, "%v", err)
+			}
+		}
+	}
+	if endpoint.IsAlias() {
+		if endpoint.RecordType != string(v1.CNAMERecordType) {
+			return dnserrors.Errorf(dnserrors.ReasonInvalidRecord, "alias record %s must be a CNAME record", endpoint.DNSName)
+		}
+		if !IsLoadBalancerHostname(endpoint.Targets[0]) {
+			return dnserrors.Errorf(dnserrors.ReasonInvalidRecord, "alias record %s target %s is not an AWS load balancer", endpoint.DNSName, endpoint.Targets[0])
+		}
+	}
+	return nil
+}
+
+// recordSetKey identifies a Route53 record set, by its name, type and set identifier.
+func recordSetKey(endpoint *v1.Endpoint) string {
+	return normalizeName(endpoint.DNSName) + "/" + endpoint.RecordType + "/" + endpoint.SetIdentifier
+}
+
+// endpointForRecordSet converts a Route53 record set into an endpoint.
+func endpointForRecordSet(recordSet *route53.ResourceRecordSet) *v1.Endpoint {
+	endpoint := &v1.Endpoint{
+		DNSName:       normalizeName(aws.StringValue(recordSet.Name)),
+		RecordType:    aws.StringValue(recordSet.Type),
+		RecordTTL:     v1.TTL(aws.Int64Value(recordSet.TTL)),
+		SetIdentifier: aws.StringValue(recordSet.SetIdentifier),
+	}
+	for _, resourceRecord := range recordSet.ResourceRecords {
+		endpoint.Targets = append(endpoint.Targets, aws.StringValue(resourceRecord.Value))
+	}
+	// The alias records are converted back into the CNAME endpoints they are
+	// published from
+	if recordSet.AliasTarget != nil {
+		endpoint.RecordType = string(v1.CNAMERecordType)
+		endpoint.Targets = v1.Targets{normalizeName(aws.StringValue(recordSet.AliasTarget.DNSName))}
+		endpoint.SetProviderSpecific(ProviderSpecificAlias, "true")
+		if !aws.BoolValue(recordSet.AliasTarget.EvaluateTargetHealth) {
+			endpoint.SetProviderSpecific(ProviderSpecificEvaluateTargetHealth, "false")
+		}
+	}
+	endpoint.RoutingPolicy = routingPolicyForRecordSet(recordSet)
+	if recordSet.HealthCheckId != nil {
+		endpoint.SetProviderSpecific(ProviderSpecificHealthCheckID, *recordSet.HealthCheckId)
+	}
+	return endpoint
+}
+
+// normalizeName returns the lower case name, without the trailing dot.
+func normalizeName(name string) string {
+	return strings.ToLower(strings.TrimSuffix(name, "."))
+}
+
 func (p *Provider) endpointsFromZoneStatus(record *v1.DNSRecord, zoneID string) ([]*v1.Endpoint, error) {
 	for _, zoneStatus := range record.Status.Zones {
 		if zoneStatus.DNSZone.ID == zoneID {
@@ -0,0 +1,733 @@
+package aws
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/onsi/gomega"
+
+	"github.com/kuadrant/kcp-glbc/pkg/_internal/log"
+	v1 "github.com/kuadrant/kcp-glbc/pkg/apis/kuadrant/v1"
+	dnserrors "github.com/kuadrant/kcp-glbc/pkg/dns/errors"
+)
+
+// changeBatch is the subset of the ChangeResourceRecordSets request payload
+// the tests check.
+type changeBatch struct {
+	Changes []struct {
+		Action            string
+		ResourceRecordSet struct {
+			Name          string
+			Type          string
+			SetIdentifier string
+		}
+	} `xml:"ChangeBatch>Changes>Change"`
+}
+
+// fakeRoute53 is a fake Route53 API, that records the change batches it
+// receives.
+type fakeRoute53 struct {
+	mu      sync.Mutex
+	batches []changeBatch
+	// errorCodes are returned, in order, in place of the next responses.
+	errorCodes []string
+	// recordSets is the XML of the record sets returned by ListResourceRecordSets.
+	recordSets string
+}
+
+func (f *fakeRoute53) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.Method == http.MethodGet {
+		_, _ = w.Write([]byte(`<ListResourceRecordSetsResponse><ResourceRecordSets>` + f.recordSets + `</ResourceRecordSets><IsTruncated>false</IsTruncated><MaxItems>100</MaxItems></ListResourceRecordSetsResponse>`))
+		return
+	}
+
+	batch := changeBatch{}
+	if err := xml.NewDecoder(r.Body).Decode(&batch); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if len(f.errorCodes) > 0 {
+		code := f.errorCodes[0]
+		f.errorCodes = f.errorCodes[1:]
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`<ErrorResponse><Error><Type>Sender</Type><Code>` + code + `</Code><Message>` + code + `</Message></Error><RequestId>request</RequestId></ErrorResponse>`))
+		return
+	}
+	f.batches = append(f.batches, batch)
+	_, _ = w.Write([]byte(`<ChangeResourceRecordSetsResponse><ChangeInfo><Id>change</Id><Status>PENDING</Status><SubmittedAt>2022-01-01T00:00:00Z</SubmittedAt></ChangeInfo></ChangeResourceRecordSetsResponse>`))
+}
+
+func (f *fakeRoute53) changeBatches() []changeBatch {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]changeBatch{}, f.batches...)
+}
+
+// newTestProvider returns a provider backed by a fake Route53 API.
+func newTestProvider(t *testing.T) (*Provider, *fakeRoute53) {
+	fake := &fakeRoute53{}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	sess, err := session.NewSession(&aws.Config{
+		Region:      aws.String("us-east-1"),
+		Endpoint:    aws.String(server.URL),
+		Credentials: credentials.NewStaticCredentials("id", "secret", ""),
+		MaxRetries:  aws.Int(0),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error creating session: %v", err)
+	}
+
+	client := newInstrumentedRoute53(route53.New(sess), 100)
+	client.backoff.Duration = time.Millisecond
+	return &Provider{
+		route53: client,
+		batcher: newChangeBatcher(client, 50*time.Millisecond, log.Logger),
+		logger:  log.Logger,
+	}, fake
+}
+
+func TestChangeForEndpoint(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Endpoint *v1.Endpoint
+		Expected *route53.ResourceRecordSet
+		Error    string
+	}{
+		{
+			Name: "weighted A record",
+			Endpoint: &v1.Endpoint{
+				DNSName:       "app.example.com",
+				RecordType:    string(v1.ARecordType),
+				RecordTTL:     60,
+				SetIdentifier: "10.0.0.1",
+				Targets:       v1.Targets{"10.0.0.1"},
+				RoutingPolicy: &v1.RoutingPolicy{Weighted: &v1.WeightedRoutingPolicy{Weight: 120}},
+			},
+			Expected: &route53.ResourceRecordSet{
+				Name:            aws.String("app.example.com"),
+				Type:            aws.String(route53.RRTypeA),
+				TTL:             aws.Int64(60),
+				SetIdentifier:   aws.String("10.0.0.1"),
+				Weight:          aws.Int64(120),
+				ResourceRecords: []*route53.ResourceRecord{{Value: aws.String("10.0.0.1")}},
+			},
+		},
+		{
+			Name: "weighted A record associated with the health check of its cluster",
+			Endpoint: func() *v1.Endpoint {
+				e := &v1.Endpoint{
+					DNSName:       "app.example.com",
+					RecordType:    string(v1.ARecordType),
+					RecordTTL:     60,
+					SetIdentifier: "10.0.0.1",
+					Targets:       v1.Targets{"10.0.0.1"},
+					RoutingPolicy: &v1.RoutingPolicy{Weighted: &v1.WeightedRoutingPolicy{Weight: 120}},
+				}
+				e.SetProviderSpecific(ProviderSpecificHealthCheckID, "endpoint")
+				e.SetProviderSpecific(ProviderSpecificClusterHealthCheckID, "cluster")
+				return e
+			}(),
+			Expected: &route53.ResourceRecordSet{
+				Name:            aws.String("app.example.com"),
+				Type:            aws.String(route53.RRTypeA),
+				TTL:             aws.Int64(60),
+				SetIdentifier:   aws.String("10.0.0.1"),
+				Weight:          aws.Int64(120),
+				HealthCheckId:   aws.String("cluster"),
+				ResourceRecords: []*route53.ResourceRecord{{Value: aws.String("10.0.0.1")}},
+			},
+		},
+		{
+			Name: "weighted AAAA record with legacy weight property",
+			Endpoint: func() *v1.Endpoint {
+				e := &v1.Endpoint{
+					DNSName:       "app.example.com",
+					RecordType:    string(v1.AAAARecordType),
+					RecordTTL:     60,
+					SetIdentifier: "2001:db8::1",
+					Targets:       v1.Targets{"2001:db8::1"},
+				}
+				e.SetProviderSpecific(ProviderSpecificWeight, "120")
+				return e
+			}(),
+			Expected: &route53.ResourceRecordSet{
+				Name:            aws.String("app.example.com"),
+				Type:            aws.String(route53.RRTypeAaaa),
+				TTL:             aws.Int64(60),
+				SetIdentifier:   aws.String("2001:db8::1"),
+				Weight:          aws.Int64(120),
+				ResourceRecords: []*route53.ResourceRecord{{Value: aws.String("2001:db8::1")}},
+			},
+		},
+		{
+			Name: "simple CNAME record",
+			Endpoint: &v1.Endpoint{
+				DNSName:    "www.example.com",
+				RecordType: string(v1.CNAMERecordType),
+				RecordTTL:  300,
+				Targets:    v1.Targets{"eu.app.example.com"},
+			},
+			Expected: &route53.ResourceRecordSet{
+				Name:            aws.String("www.example.com"),
+				Type:            aws.String(route53.RRTypeCname),
+				TTL:             aws.Int64(300),
+				ResourceRecords: []*route53.ResourceRecord{{Value: aws.String("eu.app.example.com")}},
+			},
+		},
+		{
+			Name: "continent geolocation CNAME record",
+			Endpoint: &v1.Endpoint{
+				DNSName:       "app.example.com",
+				RecordType:    string(v1.CNAMERecordType),
+				RecordTTL:     60,
+				SetIdentifier: "EU",
+				Targets:       v1.Targets{"app.eu.example.com"},
+				RoutingPolicy: &v1.RoutingPolicy{Geo: &v1.GeoRoutingPolicy{Continent: "EU"}},
+			},
+			Expected: &route53.ResourceRecordSet{
+				Name:            aws.String("app.example.com"),
+				Type:            aws.String(route53.RRTypeCname),
+				TTL:             aws.Int64(60),
+				SetIdentifier:   aws.String("EU"),
+				GeoLocation:     &route53.GeoLocation{ContinentCode: aws.String("EU")},
+				ResourceRecords: []*route53.ResourceRecord{{Value: aws.String("app.eu.example.com")}},
+			},
+		},
+		{
+			Name: "default geolocation CNAME record",
+			Endpoint: &v1.Endpoint{
+				DNSName:       "app.example.com",
+				RecordType:    string(v1.CNAMERecordType),
+				RecordTTL:     60,
+				SetIdentifier: "default",
+				Targets:       v1.Targets{"app.eu.example.com"},
+				RoutingPolicy: &v1.RoutingPolicy{Geo: &v1.GeoRoutingPolicy{Default: true}},
+			},
+			Expected: &route53.ResourceRecordSet{
+				Name:            aws.String("app.example.com"),
+				Type:            aws.String(route53.RRTypeCname),
+				TTL:             aws.Int64(60),
+				SetIdentifier:   aws.String("default"),
+				GeoLocation:     &route53.GeoLocation{CountryCode: aws.String("*")},
+				ResourceRecords: []*route53.ResourceRecord{{Value: aws.String("app.eu.example.com")}},
+			},
+		},
+		{
+			Name: "legacy default geolocation property",
+			Endpoint: func() *v1.Endpoint {
+				e := &v1.Endpoint{
+					DNSName:       "app.example.com",
+					RecordType:    string(v1.CNAMERecordType),
+					RecordTTL:     60,
+					SetIdentifier: "default",
+					Targets:       v1.Targets{"app.eu.example.com"},
+				}
+				e.SetProviderSpecific(ProviderSpecificGeolocationCountryCode, GeolocationDefault)
+				return e
+			}(),
+			Expected: &route53.ResourceRecordSet{
+				Name:            aws.String("app.example.com"),
+				Type:            aws.String(route53.RRTypeCname),
+				TTL:             aws.Int64(60),
+				SetIdentifier:   aws.String("default"),
+				GeoLocation:     &route53.GeoLocation{CountryCode: aws.String("*")},
+				ResourceRecords: []*route53.ResourceRecord{{Value: aws.String("app.eu.example.com")}},
+			},
+		},
+		{
+			Name: "failover A record",
+			Endpoint: &v1.Endpoint{
+				DNSName:       "app.example.com",
+				RecordType:    string(v1.ARecordType),
+				RecordTTL:     60,
+				SetIdentifier: "primary",
+				Targets:       v1.Targets{"10.0.0.1"},
+				RoutingPolicy: &v1.RoutingPolicy{Failover: &v1.FailoverRoutingPolicy{Role: v1.FailoverRolePrimary}},
+			},
+			Expected: &route53.ResourceRecordSet{
+				Name:            aws.String("app.example.com"),
+				Type:            aws.String(route53.RRTypeA),
+				TTL:             aws.Int64(60),
+				SetIdentifier:   aws.String("primary"),
+				Failover:        aws.String("PRIMARY"),
+				ResourceRecords: []*route53.ResourceRecord{{Value: aws.String("10.0.0.1")}},
+			},
+		},
+		{
+			Name: "latency A record",
+			Endpoint: &v1.Endpoint{
+				DNSName:       "app.example.com",
+				RecordType:    string(v1.ARecordType),
+				RecordTTL:     60,
+				SetIdentifier: "us-east-1",
+				Targets:       v1.Targets{"10.0.0.1"},
+				RoutingPolicy: &v1.RoutingPolicy{Latency: &v1.LatencyRoutingPolicy{Region: "us-east-1"}},
+			},
+			Expected: &route53.ResourceRecordSet{
+				Name:            aws.String("app.example.com"),
+				Type:            aws.String(route53.RRTypeA),
+				TTL:             aws.Int64(60),
+				SetIdentifier:   aws.String("us-east-1"),
+				Region:          aws.String("us-east-1"),
+				ResourceRecords: []*route53.ResourceRecord{{Value: aws.String("10.0.0.1")}},
+			},
+		},
+		{
+			Name: "weight is ignored without set identifier",
+			Endpoint: func() *v1.Endpoint {
+				e := &v1.Endpoint{
+					DNSName:    "www.example.com",
+					RecordType: string(v1.CNAMERecordType),
+					RecordTTL:  300,
+					Targets:    v1.Targets{"eu.app.example.com"},
+				}
+				e.SetProviderSpecific(ProviderSpecificWeight, "120")
+				return e
+			}(),
+			Expected: &route53.ResourceRecordSet{
+				Name:            aws.String("www.example.com"),
+				Type:            aws.String(route53.RRTypeCname),
+				TTL:             aws.Int64(300),
+				ResourceRecords: []*route53.ResourceRecord{{Value: aws.String("eu.app.example.com")}},
+			},
+		},
+		{
+			Name: "TXT record",
+			Endpoint: &v1.Endpoint{
+				DNSName:    "_glbc-owner.app.example.com",
+				RecordType: string(v1.TXTRecordType),
+				RecordTTL:  300,
+				Targets:    v1.Targets{`"heritage=kcp-glbc,kcp-glbc/owner=glbc-1"`},
+			},
+			Expected: &route53.ResourceRecordSet{
+				Name:            aws.String("_glbc-owner.app.example.com"),
+				Type:            aws.String(route53.RRTypeTxt),
+				TTL:             aws.Int64(300),
+				ResourceRecords: []*route53.ResourceRecord{{Value: aws.String(`"heritage=kcp-glbc,kcp-glbc/owner=glbc-1"`)}},
+			},
+		},
+		{
+			Name: "CNAME record with multiple targets",
+			Endpoint: &v1.Endpoint{
+				DNSName:    "www.example.com",
+				RecordType: string(v1.CNAMERecordType),
+				Targets:    v1.Targets{"eu.app.example.com", "us.app.example.com"},
+			},
+			Error: "CNAME record www.example.com must have a single target",
+		},
+		{
+			Name: "weighted alias record to a load balancer",
+			Endpoint: &v1.Endpoint{
+				DNSName:          "app.example.com",
+				RecordType:       string(v1.CNAMERecordType),
+				RecordTTL:        60,
+				SetIdentifier:    "lb-1.eu-west-1.elb.amazonaws.com",
+				Targets:          v1.Targets{"lb-1.eu-west-1.elb.amazonaws.com"},
+				RoutingPolicy:    &v1.RoutingPolicy{Weighted: &v1.WeightedRoutingPolicy{Weight: 120}},
+				ProviderSpecific: v1.ProviderSpecific{{Name: ProviderSpecificAlias, Value: "true"}},
+			},
+			Expected: &route53.ResourceRecordSet{
+				Name:          aws.String("app.example.com"),
+				Type:          aws.String(route53.RRTypeA),
+				SetIdentifier: aws.String("lb-1.eu-west-1.elb.amazonaws.com"),
+				Weight:        aws.Int64(120),
+				AliasTarget: &route53.AliasTarget{
+					DNSName:              aws.String("lb-1.eu-west-1.elb.amazonaws.com"),
+					HostedZoneId:         aws.String("Z32O12XQLNTSW2"),
+					EvaluateTargetHealth: aws.Bool(true),
+				},
+			},
+		},
+		{
+			Name: "alias record to another host",
+			Endpoint: &v1.Endpoint{
+				DNSName:          "app.example.com",
+				RecordType:       string(v1.CNAMERecordType),
+				Targets:          v1.Targets{"lb.example.org"},
+				ProviderSpecific: v1.ProviderSpecific{{Name: ProviderSpecificAlias, Value: "true"}},
+			},
+			Error: "alias record app.example.com target lb.example.org is not an AWS load balancer",
+		},
+		{
+			Name: "unsupported record type",
+			Endpoint: &v1.Endpoint{
+				DNSName:    "app.example.com",
+				RecordType: "SRV",
+				Targets:    v1.Targets{"0 5 5060 sip.example.com"},
+			},
+			Error: "unsupported record type SRV",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+			p := &Provider{logger: log.Logger}
+
+			change, err := p.changeForEndpoint(tc.Endpoint, string(upsertAction))
+			if tc.Error != "" {
+				g.Expect(err).To(gomega.MatchError(tc.Error))
+				return
+			}
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			g.Expect(change.Action).To(gomega.Equal(aws.String(string(upsertAction))))
+			g.Expect(change.ResourceRecordSet).To(gomega.Equal(tc.Expected))
+		})
+	}
+}
+
+func TestEnsureReplacesRecordType(t *testing.T) {
+	g := gomega.NewWithT(t)
+	provider, fake := newTestProvider(t)
+	zone := v1.DNSZone{ID: "Z1"}
+
+	published := &v1.Endpoint{
+		DNSName:    "www.example.com",
+		RecordType: string(v1.ARecordType),
+		RecordTTL:  60,
+		Targets:    v1.Targets{"10.0.0.1"},
+	}
+	record := &v1.DNSRecord{
+		Spec: v1.DNSRecordSpec{
+			Endpoints: []*v1.Endpoint{{
+				DNSName:    "www.example.com",
+				RecordType: string(v1.CNAMERecordType),
+				RecordTTL:  60,
+				Targets:    v1.Targets{"app.example.com"},
+			}},
+		},
+		Status: v1.DNSRecordStatus{
+			Zones: []v1.DNSZoneStatus{{DNSZone: zone, Endpoints: []*v1.Endpoint{published}}},
+		},
+	}
+
+	g.Expect(provider.Ensure(context.TODO(), record, zone)).To(gomega.Succeed())
+	g.Expect(fake.changeBatches()).To(gomega.HaveLen(1))
+
+	// The A record is deleted before the CNAME record with the same name is created
+	changes := fake.changeBatches()[0].Changes
+	g.Expect(changes).To(gomega.HaveLen(2))
+	g.Expect(changes[0].Action).To(gomega.Equal(string(deleteAction)))
+	g.Expect(changes[0].ResourceRecordSet.Type).To(gomega.Equal(route53.RRTypeA))
+	g.Expect(changes[1].Action).To(gomega.Equal(string(upsertAction)))
+	g.Expect(changes[1].ResourceRecordSet.Type).To(gomega.Equal(route53.RRTypeCname))
+}
+
+func TestEnsureCoalescesChanges(t *testing.T) {
+	g := gomega.NewWithT(t)
+	provider, fake := newTestProvider(t)
+	zone := v1.DNSZone{ID: "Z1"}
+
+	newRecord := func(name string) *v1.DNSRecord {
+		return &v1.DNSRecord{
+			Spec: v1.DNSRecordSpec{
+				Endpoints: []*v1.Endpoint{{
+					DNSName:    name,
+					RecordType: string(v1.ARecordType),
+					RecordTTL:  60,
+					Targets:    v1.Targets{"10.0.0.1"},
+				}},
+			},
+		}
+	}
+	deleted := newRecord("old.example.com")
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 3)
+	for _, f := range []func() error{
+		func() error { return provider.Ensure(context.TODO(), newRecord("app.example.com"), zone) },
+		func() error { return provider.Ensure(context.TODO(), newRecord("www.example.com"), zone) },
+		func() error { return provider.Delete(context.TODO(), deleted, zone) },
+	} {
+		wg.Add(1)
+		go func(f func() error) {
+			defer wg.Done()
+			errs <- f()
+		}(f)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+	}
+
+	// The changes are submitted in a single batch, with the deletions first
+	batches := fake.changeBatches()
+	g.Expect(batches).To(gomega.HaveLen(1))
+	changes := batches[0].Changes
+	g.Expect(changes).To(gomega.HaveLen(3))
+	g.Expect(changes[0].Action).To(gomega.Equal(string(deleteAction)))
+	g.Expect(changes[0].ResourceRecordSet.Name).To(gomega.Equal("old.example.com"))
+	g.Expect(changes[1].Action).To(gomega.Equal(string(upsertAction)))
+	g.Expect(changes[2].Action).To(gomega.Equal(string(upsertAction)))
+}
+
+func TestEnsureRetriesThrottledChanges(t *testing.T) {
+	g := gomega.NewWithT(t)
+	provider, fake := newTestProvider(t)
+	fake.errorCodes = []string{throttlingErrorCode, priorRequestNotCompleteErrorCode}
+
+	record := &v1.DNSRecord{
+		Spec: v1.DNSRecordSpec{
+			Endpoints: []*v1.Endpoint{{
+				DNSName:    "app.example.com",
+				RecordType: string(v1.ARecordType),
+				RecordTTL:  60,
+				Targets:    v1.Targets{"10.0.0.1"},
+			}},
+		},
+	}
+	g.Expect(provider.Ensure(context.TODO(), record, v1.DNSZone{ID: "Z1"})).To(gomega.Succeed())
+	g.Expect(fake.changeBatches()).To(gomega.HaveLen(1))
+}
+
+func TestEnsureIsolatesInvalidChanges(t *testing.T) {
+	g := gomega.NewWithT(t)
+	provider, fake := newTestProvider(t)
+	fake.errorCodes = []string{route53.ErrCodeInvalidChangeBatch, route53.ErrCodeInvalidChangeBatch}
+	zone := v1.DNSZone{ID: "Z1"}
+
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i, name := range []string{"app.example.com", "www.example.com"} {
+		record := &v1.DNSRecord{
+			Spec: v1.DNSRecordSpec{
+				Endpoints: []*v1.Endpoint{{
+					DNSName:    name,
+					RecordType: string(v1.ARecordType),
+					RecordTTL:  60,
+					Targets:    v1.Targets{"10.0.0.1"},
+				}},
+			},
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = provider.Ensure(context.TODO(), record, zone)
+		}(i)
+	}
+	wg.Wait()
+
+	// The rejected batch is split, and only one of the records fails
+	failed := 0
+	for _, err := range errs {
+		if err != nil {
+			failed++
+		}
+	}
+	g.Expect(failed).To(gomega.Equal(1))
+	g.Expect(fake.changeBatches()).To(gomega.HaveLen(1))
+	g.Expect(fake.changeBatches()[0].Changes).To(gomega.HaveLen(1))
+}
+
+func TestEnsureSkipsCancelledChanges(t *testing.T) {
+	g := gomega.NewWithT(t)
+	provider, fake := newTestProvider(t)
+	zone := v1.DNSZone{ID: "Z1"}
+
+	newRecord := func(name string) *v1.DNSRecord {
+		return &v1.DNSRecord{
+			Spec: v1.DNSRecordSpec{
+				Endpoints: []*v1.Endpoint{{
+					DNSName:    name,
+					RecordType: string(v1.ARecordType),
+					RecordTTL:  60,
+					Targets:    v1.Targets{"10.0.0.1"},
+				}},
+			},
+		}
+	}
+
+	// The caller of the first change gives up before the batch is flushed
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancelled := make(chan error, 1)
+	go func() { cancelled <- provider.Ensure(ctx, newRecord("app.example.com"), zone) }()
+	g.Eventually(func() int {
+		provider.batcher.mu.Lock()
+		defer provider.batcher.mu.Unlock()
+		return len(provider.batcher.pending["Z1"])
+	}).Should(gomega.Equal(1))
+	cancel()
+	g.Expect(<-cancelled).To(gomega.MatchError(context.Canceled))
+	g.Expect(provider.Ensure(context.TODO(), newRecord("www.example.com"), zone)).To(gomega.Succeed())
+
+	// Only the changes of the caller still waiting are submitted
+	batches := fake.changeBatches()
+	g.Expect(batches).To(gomega.HaveLen(1))
+	g.Expect(batches[0].Changes).To(gomega.HaveLen(1))
+	g.Expect(batches[0].Changes[0].ResourceRecordSet.Name).To(gomega.Equal("www.example.com"))
+}
+
+func TestSharedRoute53Client(t *testing.T) {
+	g := gomega.NewWithT(t)
+	t.Cleanup(func() {
+		route53ClientsMu.Lock()
+		defer route53ClientsMu.Unlock()
+		delete(route53Clients, "test-east-1")
+		delete(route53Clients, "test-west-1")
+	})
+
+	created := 0
+	newClient := func() *route53.Route53 {
+		created++
+		return &route53.Route53{}
+	}
+
+	// The providers of a region share its client, and thus its limiter and batcher
+	first := sharedRoute53Client("test-east-1", newClient, Config{}, log.Logger)
+	second := sharedRoute53Client("test-east-1", newClient, Config{RequestsPerSecond: 100}, log.Logger)
+	g.Expect(second).To(gomega.BeIdenticalTo(first))
+	g.Expect(second.batcher.client).To(gomega.BeIdenticalTo(first.route53))
+	g.Expect(created).To(gomega.Equal(1))
+
+	other := sharedRoute53Client("test-west-1", newClient, Config{}, log.Logger)
+	g.Expect(other).NotTo(gomega.BeIdenticalTo(first))
+	g.Expect(created).To(gomega.Equal(2))
+}
+
+func TestProviderError(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Err      error
+		Expected dnserrors.Reason
+	}{
+		{
+			Name:     "hosted zone not found",
+			Err:      awserr.New(route53.ErrCodeNoSuchHostedZone, "No hosted zone found with ID: Z1", nil),
+			Expected: dnserrors.ReasonZoneNotFound,
+		},
+		{
+			Name:     "throttled",
+			Err:      awserr.New(throttlingErrorCode, "Rate exceeded", nil),
+			Expected: dnserrors.ReasonThrottled,
+		},
+		{
+			Name:     "access denied",
+			Err:      awserr.New(accessDeniedErrorCode, "User is not authorized to perform: route53:ChangeResourceRecordSets", nil),
+			Expected: dnserrors.ReasonPermissionDenied,
+		},
+		{
+			Name:     "record set not found",
+			Err:      awserr.New(route53.ErrCodeInvalidChangeBatch, "[Tried to delete resource record set [name='app.example.com.', type='A'] but it was not found]", nil),
+			Expected: dnserrors.ReasonNotFound,
+		},
+		{
+			Name:     "invalid change batch",
+			Err:      awserr.New(route53.ErrCodeInvalidChangeBatch, "[RRSet of type CNAME with DNS name app.example.com. is not permitted at apex]", nil),
+			Expected: dnserrors.ReasonInvalidRecord,
+		},
+		{
+			Name:     "record set already exists",
+			Err:      awserr.New(route53.ErrCodeInvalidChangeBatch, "[Tried to create resource record set [name='app.example.com.', type='A'] but it already exists]", nil),
+			Expected: dnserrors.ReasonInvalidRecord,
+		},
+		{
+			Name:     "not found message of another code",
+			Err:      awserr.New(route53.ErrCodeInvalidInput, "Health check hc-1 referenced by the record set but it was not found", nil),
+			Expected: dnserrors.ReasonInvalidRecord,
+		},
+		{
+			Name:     "unknown",
+			Err:      awserr.New("ServiceUnavailable", "Service unavailable", nil),
+			Expected: dnserrors.ReasonUnknown,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+			g.Expect(dnserrors.ReasonForError(providerError(tc.Err))).To(gomega.Equal(tc.Expected))
+		})
+	}
+}
+
+func TestEnsureReturnsProviderError(t *testing.T) {
+	g := gomega.NewWithT(t)
+	provider, fake := newTestProvider(t)
+	fake.errorCodes = []string{accessDeniedErrorCode}
+
+	record := &v1.DNSRecord{
+		Spec: v1.DNSRecordSpec{
+			Endpoints: []*v1.Endpoint{{
+				DNSName:    "app.example.com",
+				RecordType: string(v1.ARecordType),
+				RecordTTL:  60,
+				Targets:    v1.Targets{"10.0.0.1"},
+			}},
+		},
+	}
+	err := provider.Ensure(context.TODO(), record, v1.DNSZone{ID: "Z1"})
+	g.Expect(err).To(gomega.HaveOccurred())
+	g.Expect(dnserrors.IsPermissionDenied(err)).To(gomega.BeTrue())
+}
+
+func TestListRecords(t *testing.T) {
+	g := gomega.NewWithT(t)
+	p, fake := newTestProvider(t)
+	fake.recordSets = `<ResourceRecordSet><Name>app.example.com.</Name><Type>A</Type><SetIdentifier>10.0.0.1</SetIdentifier><Weight>120</Weight><TTL>60</TTL><ResourceRecords><ResourceRecord><Value>10.0.0.1</Value></ResourceRecord></ResourceRecords></ResourceRecordSet>` +
+		`<ResourceRecordSet><Name>_glbc-owner.app.example.com.</Name><Type>TXT</Type><TTL>300</TTL><ResourceRecords><ResourceRecord><Value>"heritage=kcp-glbc"</Value></ResourceRecord></ResourceRecords></ResourceRecordSet>`
+
+	endpoints, err := p.ListRecords(context.TODO(), v1.DNSZone{ID: "Z1"})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(endpoints).To(gomega.HaveLen(2))
+	g.Expect(endpoints[0].DNSName).To(gomega.Equal("app.example.com"))
+	g.Expect(endpoints[0].SetIdentifier).To(gomega.Equal("10.0.0.1"))
+	g.Expect(endpoints[0].Targets).To(gomega.Equal(v1.Targets{"10.0.0.1"}))
+	g.Expect(endpoints[0].RoutingPolicy).To(gomega.Equal(&v1.RoutingPolicy{Weighted: &v1.WeightedRoutingPolicy{Weight: 120}}))
+	g.Expect(endpoints[1]).To(gomega.Equal(&v1.Endpoint{
+		DNSName:    "_glbc-owner.app.example.com",
+		RecordType: string(v1.TXTRecordType),
+		RecordTTL:  300,
+		Targets:    v1.Targets{`"heritage=kcp-glbc"`},
+	}))
+}
+
+func TestListRecordsConvertsAliasRecords(t *testing.T) {
+	g := gomega.NewWithT(t)
+	p, fake := newTestProvider(t)
+	fake.recordSets = `<ResourceRecordSet><Name>app.example.com.</Name><Type>A</Type><SetIdentifier>lb-1</SetIdentifier><Weight>120</Weight>` +
+		`<AliasTarget><HostedZoneId>ZLMOA37VPKANP</HostedZoneId><DNSName>lb-1.elb.us-east-2.amazonaws.com.</DNSName><EvaluateTargetHealth>false</EvaluateTargetHealth></AliasTarget></ResourceRecordSet>`
+
+	endpoints, err := p.ListRecords(context.TODO(), v1.DNSZone{ID: "Z1"})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(endpoints).To(gomega.Equal([]*v1.Endpoint{{
+		DNSName:       "app.example.com",
+		RecordType:    string(v1.CNAMERecordType),
+		SetIdentifier: "lb-1",
+		Targets:       v1.Targets{"lb-1.elb.us-east-2.amazonaws.com"},
+		RoutingPolicy: &v1.RoutingPolicy{Weighted: &v1.WeightedRoutingPolicy{Weight: 120}},
+		ProviderSpecific: v1.ProviderSpecific{
+			{Name: ProviderSpecificAlias, Value: "true"},
+			{Name: ProviderSpecificEvaluateTargetHealth, Value: "false"},
+		},
+	}}))
+}
+
+func TestCanonicalHostedZone(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	zoneID, ok := CanonicalHostedZone("a1b2c3-123456789.us-east-1.elb.amazonaws.com.")
+	g.Expect(ok).To(gomega.BeTrue())
+	g.Expect(zoneID).To(gomega.Equal("Z35SXDOTRQ7X7K"))
+
+	zoneID, ok = CanonicalHostedZone("nlb-1-0123456789abcdef.elb.eu-west-1.amazonaws.com")
+	g.Expect(ok).To(gomega.BeTrue())
+	g.Expect(zoneID).To(gomega.Equal("Z2IFOLAFXWLO4F"))
+
+	g.Expect(IsLoadBalancerHostname("lb.example.com")).To(gomega.BeFalse())
+}
@@ -3,18 +3,53 @@ package aws
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/rs/xid"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/route53"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kuadrant/kcp-glbc/pkg/_internal/slice"
 	v1 "github.com/kuadrant/kcp-glbc/pkg/apis/kuadrant/v1"
+	dnserrors "github.com/kuadrant/kcp-glbc/pkg/dns/errors"
 )
 
 const (
 	idTag = "kuadrant.dev/healthcheck"
+	// ownerTag holds the owner ID of the GLBC that created the health check.
+	// The health checks created before it was set have no owner tag.
+	ownerTag = "kuadrant.dev/owner"
+
+	// defaultOwnerID is the owner ID of the GLBCs with none configured, that
+	// the health checks with no owner tag are considered owned by.
+	defaultOwnerID = "kcp-glbc"
+
+	// maxTaggedResources is the maximum number of resources whose tags
+	// Route53 lists at once.
+	maxTaggedResources = 10
+
+	// standardRequestInterval and fastRequestInterval are the intervals, in
+	// seconds, Route53 can send the requests of a health check at.
+	standardRequestInterval = 30
+	fastRequestInterval     = 10
+
+	// healthyCheckersThreshold is the percentage of the Route53 health
+	// checkers above which an endpoint is healthy.
+	healthyCheckersThreshold = 18
+
+	// minRegions is the minimum number of regions Route53 checks the
+	// endpoints from, when they are set.
+	minRegions = 3
+
+	// maxSearchStringLength is the maximum length of the string Route53
+	// searches the responses for.
+	maxSearchStringLength = 255
 )
 
 var (
@@ -22,18 +57,27 @@ var (
 )
 
 type Route53HealthCheckReconciler struct {
-	client *InstrumentedRoute53
-	logger logr.Logger
+	client  *InstrumentedRoute53
+	ownerID string
+	logger  logr.Logger
 }
 
-func newRoute53HealthCheckReconciler(c *InstrumentedRoute53, l logr.Logger) *Route53HealthCheckReconciler {
+func newRoute53HealthCheckReconciler(c *InstrumentedRoute53, ownerID string, l logr.Logger) *Route53HealthCheckReconciler {
+	if ownerID == "" {
+		ownerID = defaultOwnerID
+	}
 	return &Route53HealthCheckReconciler{
-		client: c,
-		logger: l.WithName("health"),
+		client:  c,
+		ownerID: ownerID,
+		logger:  l.WithName("health"),
 	}
 }
 
-func (r *Route53HealthCheckReconciler) reconcile(ctx context.Context, spec v1.HealthCheck, endpoint *v1.Endpoint) error {
+func (r *Route53HealthCheckReconciler) reconcile(ctx context.Context, spec v1.EndpointHealthCheck, endpoint *v1.Endpoint) error {
+	if err := validateHealthCheck(spec); err != nil {
+		return err
+	}
+
 	healthCheck, exists, err := r.findHealthCheck(ctx, endpoint)
 	if err != nil {
 		return err
@@ -45,6 +89,20 @@ func (r *Route53HealthCheckReconciler) reconcile(ctx context.Context, spec v1.He
 		}
 	}()
 
+	// The type and the request interval of a health check cannot be updated,
+	// it is recreated instead
+	if exists && !isUpdatable(healthCheck, spec) {
+		r.logger.Info("Recreating health check to change its type or request interval", "id", *healthCheck.Id, "type", aws.StringValue(healthCheckType(spec)), "interval", requestInterval(spec))
+		if err := r.detachFromClusterHealthCheck(ctx, endpoint, *healthCheck.Id); err != nil {
+			return err
+		}
+		if _, err := r.client.DeleteHealthCheckWithContext(ctx, &route53.DeleteHealthCheckInput{HealthCheckId: healthCheck.Id}); err != nil && !isNoSuchHealthCheck(err) {
+			return providerError(err)
+		}
+		endpoint.DeleteProviderSpecific(ProviderSpecificHealthCheckID)
+		healthCheck, exists = nil, false
+	}
+
 	if exists {
 		return r.updateHealthCheck(ctx, spec, endpoint, healthCheck)
 	}
@@ -62,6 +120,12 @@ func (r *Route53HealthCheckReconciler) deleteHealthCheck(ctx context.Context, en
 		return nil
 	}
 
+	// The health checks cannot be deleted while a calculated health check
+	// aggregates them
+	if err := r.detachFromClusterHealthCheck(ctx, endpoint, *healthCheck.Id); err != nil {
+		return err
+	}
+
 	_, err = r.client.DeleteHealthCheckWithContext(ctx, &route53.DeleteHealthCheckInput{
 		HealthCheckId: healthCheck.Id,
 	})
@@ -83,6 +147,10 @@ func (r *Route53HealthCheckReconciler) findHealthCheck(ctx context.Context, endp
 	response, err := r.client.GetHealthCheckWithContext(ctx, &route53.GetHealthCheckInput{
 		HealthCheckId: &id,
 	})
+	// The health check was deleted, e.g. out of band
+	if isNoSuchHealthCheck(err) {
+		return nil, false, nil
+	}
 	if err != nil {
 		return nil, false, err
 	}
@@ -91,22 +159,45 @@ func (r *Route53HealthCheckReconciler) findHealthCheck(ctx context.Context, endp
 
 }
 
-func (r *Route53HealthCheckReconciler) createHealthCheck(ctx context.Context, spec v1.HealthCheck, endpoint *v1.Endpoint) (*route53.HealthCheck, error) {
-	address, _ := endpoint.GetAddress()
-	host := endpoint.DNSName
-
-	// Create the health check
-	output, err := r.client.CreateHealthCheck(&route53.CreateHealthCheckInput{
-		CallerReference: callerReference(spec.Id),
-		HealthCheckConfig: &route53.HealthCheckConfig{
-			IPAddress:                &address,
-			FullyQualifiedDomainName: &host,
-			Port:                     spec.Port,
-			ResourcePath:             &spec.Path,
-			Type:                     healthCheckType(spec.Protocol),
-			FailureThreshold:         spec.FailureThreshold,
-		},
+func (r *Route53HealthCheckReconciler) createHealthCheck(ctx context.Context, spec v1.EndpointHealthCheck, endpoint *v1.Endpoint) (*route53.HealthCheck, error) {
+	address, host := healthCheckTarget(spec, endpoint)
+
+	// Create the health check. The caller reference includes the request
+	// interval, as the health checks are recreated when it changes.
+	config := &route53.HealthCheckConfig{
+		IPAddress:                address,
+		FullyQualifiedDomainName: &host,
+		Port:                     spec.Port,
+		ResourcePath:             resourcePath(spec),
+		Type:                     healthCheckType(spec),
+		FailureThreshold:         spec.FailureThreshold,
+		RequestInterval:          aws.Int64(requestInterval(spec)),
+		SearchString:             searchString(spec),
+		Inverted:                 aws.Bool(spec.Inverted),
+		EnableSNI:                enableSNI(spec),
+	}
+	if len(spec.Regions) > 0 {
+		config.Regions = aws.StringSlice(spec.Regions)
+	}
+	return r.create(ctx, spec, fmt.Sprintf("%s-%d", spec.Id, requestInterval(spec)), config)
+}
+
+// create creates a health check with the given configuration, and tags it
+// with the ID of the health check spec.
+func (r *Route53HealthCheckReconciler) create(ctx context.Context, spec v1.EndpointHealthCheck, reference string, config *route53.HealthCheckConfig) (*route53.HealthCheck, error) {
+	output, err := r.client.CreateHealthCheck(ctx, &route53.CreateHealthCheckInput{
+		CallerReference:   callerReference(reference),
+		HealthCheckConfig: config,
 	})
+	// The caller reference of a deleted health check cannot be reused, e.g.
+	// when a health check is recreated to change its type, a new one is used
+	// instead
+	if isHealthCheckAlreadyExists(err) {
+		output, err = r.client.CreateHealthCheck(ctx, &route53.CreateHealthCheckInput{
+			CallerReference:   aws.String(fmt.Sprintf("%s.%s", spec.Id, xid.New())),
+			HealthCheckConfig: config,
+		})
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -123,6 +214,10 @@ func (r *Route53HealthCheckReconciler) createHealthCheck(ctx context.Context, sp
 				Key:   aws.String("Name"),
 				Value: &name,
 			},
+			{
+				Key:   aws.String(ownerTag),
+				Value: aws.String(r.ownerID),
+			},
 		},
 		ResourceId:   output.HealthCheck.Id,
 		ResourceType: aws.String(route53.TagResourceTypeHealthcheck),
@@ -134,7 +229,7 @@ func (r *Route53HealthCheckReconciler) createHealthCheck(ctx context.Context, sp
 	return output.HealthCheck, nil
 }
 
-func (r *Route53HealthCheckReconciler) updateHealthCheck(ctx context.Context, spec v1.HealthCheck, endpoint *v1.Endpoint, healthCheck *route53.HealthCheck) error {
+func (r *Route53HealthCheckReconciler) updateHealthCheck(ctx context.Context, spec v1.EndpointHealthCheck, endpoint *v1.Endpoint, healthCheck *route53.HealthCheck) error {
 	diff := healthCheckDiff(healthCheck, spec, endpoint)
 	if diff == nil {
 		return nil
@@ -153,7 +248,7 @@ func (r *Route53HealthCheckReconciler) updateHealthCheck(ctx context.Context, sp
 // healthCheckDiff creates a `UpdateHealthCheckInput` object with the fields to
 // update on healthCheck based on the given spec.
 // If the health check matches the spec, returns `nil`
-func healthCheckDiff(healthCheck *route53.HealthCheck, spec v1.HealthCheck, endpoint *v1.Endpoint) *route53.UpdateHealthCheckInput {
+func healthCheckDiff(healthCheck *route53.HealthCheck, spec v1.EndpointHealthCheck, endpoint *v1.Endpoint) *route53.UpdateHealthCheckInput {
 	var result *route53.UpdateHealthCheckInput
 
 	diff := func() *route53.UpdateHealthCheckInput {
@@ -166,16 +261,15 @@ func healthCheckDiff(healthCheck *route53.HealthCheck, spec v1.HealthCheck, endp
 		return result
 	}
 
-	if !strValuesEqual(&endpoint.DNSName, healthCheck.HealthCheckConfig.FullyQualifiedDomainName) {
-		diff().FullyQualifiedDomainName = &endpoint.DNSName
+	address, host := healthCheckTarget(spec, endpoint)
+	if !strValuesEqual(&host, healthCheck.HealthCheckConfig.FullyQualifiedDomainName) {
+		diff().FullyQualifiedDomainName = &host
 	}
-
-	address, _ := endpoint.GetAddress()
-	if !strValuesEqual(&address, healthCheck.HealthCheckConfig.IPAddress) {
-		diff().IPAddress = &address
+	if address != nil && !strValuesEqual(address, healthCheck.HealthCheckConfig.IPAddress) {
+		diff().IPAddress = address
 	}
-	if !strValuesEqual(&spec.Path, healthCheck.HealthCheckConfig.ResourcePath) {
-		diff().ResourcePath = &spec.Path
+	if path := resourcePath(spec); path != nil && !strValuesEqual(path, healthCheck.HealthCheckConfig.ResourcePath) {
+		diff().ResourcePath = path
 	}
 
 	if !intValuesEqual(spec.Port, healthCheck.HealthCheckConfig.Port) {
@@ -184,6 +278,22 @@ func healthCheckDiff(healthCheck *route53.HealthCheck, spec v1.HealthCheck, endp
 	if !intValuesEqual(spec.FailureThreshold, healthCheck.HealthCheckConfig.FailureThreshold) {
 		diff().FailureThreshold = spec.FailureThreshold
 	}
+	if search := searchString(spec); search != nil && !strValuesEqual(search, healthCheck.HealthCheckConfig.SearchString) {
+		diff().SearchString = search
+	}
+	if !stringSetsEqual(spec.Regions, aws.StringValueSlice(healthCheck.HealthCheckConfig.Regions)) {
+		if len(spec.Regions) == 0 {
+			diff().ResetElements = aws.StringSlice([]string{route53.ResettableElementNameRegions})
+		} else {
+			diff().Regions = aws.StringSlice(spec.Regions)
+		}
+	}
+	if spec.Inverted != aws.BoolValue(healthCheck.HealthCheckConfig.Inverted) {
+		diff().Inverted = aws.Bool(spec.Inverted)
+	}
+	if sni := enableSNI(spec); sni != nil && !aws.BoolValue(healthCheck.HealthCheckConfig.EnableSNI) {
+		diff().EnableSNI = sni
+	}
 
 	return result
 }
@@ -195,22 +305,383 @@ func init() {
 	}
 }
 
-func healthCheckType(protocol *v1.HealthCheckProtocol) *string {
-	if protocol == nil {
-		return nil
+// healthCheckType returns the type of the health check, the HTTP and HTTPS
+// health checks searching the responses for the search string, if any.
+func healthCheckType(spec v1.EndpointHealthCheck) *string {
+	protocol := v1.HealthCheckProtocolHTTP
+	if spec.Protocol != nil {
+		protocol = *spec.Protocol
 	}
 
-	switch *protocol {
+	switch protocol {
 	case v1.HealthCheckProtocolHTTP:
+		if spec.SearchString != "" {
+			return aws.String(route53.HealthCheckTypeHttpStrMatch)
+		}
 		return aws.String(route53.HealthCheckTypeHttp)
 
 	case v1.HealthCheckProtocolHTTPS:
+		if spec.SearchString != "" {
+			return aws.String(route53.HealthCheckTypeHttpsStrMatch)
+		}
 		return aws.String(route53.HealthCheckTypeHttps)
+
+	case v1.HealthCheckProtocolTCP:
+		return aws.String(route53.HealthCheckTypeTcp)
+	}
+
+	return nil
+}
+
+// healthCheckTarget returns the IP address and the host name the health check
+// of the endpoint probes. The endpoints targeting a host, e.g. a load balancer,
+// have no IP address, the target being probed by its host name. Otherwise, the
+// host name is the Host header of the requests.
+func healthCheckTarget(spec v1.EndpointHealthCheck, endpoint *v1.Endpoint) (*string, string) {
+	if endpoint.RecordType == string(v1.CNAMERecordType) && len(endpoint.Targets) > 0 {
+		return nil, endpoint.Targets[0]
+	}
+	address, _ := endpoint.GetAddress()
+	if spec.HostHeader != "" {
+		return &address, spec.HostHeader
+	}
+	return &address, endpoint.DNSName
+}
+
+// resourcePath returns the path of the requests of the health check, if any, as
+// the TCP health checks send no request.
+func resourcePath(spec v1.EndpointHealthCheck) *string {
+	if spec.Protocol != nil && *spec.Protocol == v1.HealthCheckProtocolTCP {
+		return nil
+	}
+	return &spec.Path
+}
+
+// searchString returns the string the responses of the health check are
+// searched for, if any, as the TCP health checks receive no response.
+func searchString(spec v1.EndpointHealthCheck) *string {
+	if spec.SearchString == "" || spec.Protocol != nil && *spec.Protocol == v1.HealthCheckProtocolTCP {
+		return nil
+	}
+	return &spec.SearchString
+}
+
+// enableSNI returns whether the HTTPS health checks send the host name in the
+// TLS handshake, which they always do, as the endpoints often serve several
+// host names.
+func enableSNI(spec v1.EndpointHealthCheck) *bool {
+	if spec.Protocol != nil && *spec.Protocol == v1.HealthCheckProtocolHTTPS {
+		return aws.Bool(true)
+	}
+	return nil
+}
+
+// isUpdatable returns whether the health check can be updated to match the
+// spec, as its type and request interval cannot be changed.
+func isUpdatable(healthCheck *route53.HealthCheck, spec v1.EndpointHealthCheck) bool {
+	return aws.StringValue(healthCheck.HealthCheckConfig.Type) == aws.StringValue(healthCheckType(spec)) &&
+		aws.Int64Value(healthCheck.HealthCheckConfig.RequestInterval) == requestInterval(spec)
+}
+
+// requestInterval returns the interval, in seconds, of the requests of the
+// health check, rounded up to one of the intervals Route53 supports.
+func requestInterval(spec v1.EndpointHealthCheck) int64 {
+	if spec.Interval > 0 && spec.Interval <= fastRequestInterval*time.Second {
+		return fastRequestInterval
+	}
+	return standardRequestInterval
+}
+
+// validateHealthCheck returns an error if the health check cannot be performed
+// by Route53, whose health checks expect any of the 2xx and 3xx status codes,
+// and are performed from at least 3 of the regions it supports.
+func validateHealthCheck(spec v1.EndpointHealthCheck) error {
+	for _, code := range spec.ExpectedStatusCodes {
+		if code < 200 || code > 399 {
+			return dnserrors.Errorf(dnserrors.ReasonInvalidRecord, "Route53 health checks only expect the 2xx and 3xx status codes, got %d", code)
+		}
+	}
+	if len(spec.Regions) > 0 && len(spec.Regions) < minRegions {
+		return dnserrors.Errorf(dnserrors.ReasonInvalidRecord, "Route53 health checks are performed from at least %d regions, got %d", minRegions, len(spec.Regions))
+	}
+	supported := route53.HealthCheckRegion_Values()
+	for _, region := range spec.Regions {
+		if !slice.ContainsString(supported, region) {
+			return dnserrors.Errorf(dnserrors.ReasonInvalidRecord, "Route53 health checks cannot be performed from region %s, supported regions are %s", region, strings.Join(supported, ", "))
+		}
+	}
+	if len(spec.SearchString) > maxSearchStringLength {
+		return dnserrors.Errorf(dnserrors.ReasonInvalidRecord, "Route53 health checks search for strings of at most %d characters, got %d", maxSearchStringLength, len(spec.SearchString))
+	}
+	return nil
+}
+
+// reconcileClusterHealthCheck reconciles the calculated health check of the
+// cluster of the endpoints, that aggregates the health checks of the endpoints,
+// and associates the endpoints with it. It is deleted when the spec sets no
+// cluster health threshold, or none of the endpoints is checked.
+func (r *Route53HealthCheckReconciler) reconcileClusterHealthCheck(ctx context.Context, spec v1.EndpointHealthCheck, endpoints []*v1.Endpoint) error {
+	var children []string
+	for _, endpoint := range endpoints {
+		if id, ok := getHealthCheckId(endpoint); ok {
+			children = append(children, id)
+		}
+	}
+	if spec.ClusterHealthThreshold == nil || len(children) == 0 {
+		return r.deleteClusterHealthCheck(ctx, endpoints)
+	}
+	threshold := clusterHealthThreshold(spec, len(children))
+
+	healthCheck, exists, err := r.findClusterHealthCheck(ctx, endpoints)
+	if err != nil {
+		return err
+	}
+	if exists {
+		if !stringSetsEqual(children, aws.StringValueSlice(healthCheck.HealthCheckConfig.ChildHealthChecks)) ||
+			aws.Int64Value(healthCheck.HealthCheckConfig.HealthThreshold) != threshold {
+			r.logger.Info("Updating cluster health check", "id", *healthCheck.Id, "children", children, "threshold", threshold)
+			if _, err := r.client.UpdateHealthCheckWithContext(ctx, &route53.UpdateHealthCheckInput{
+				HealthCheckId:     healthCheck.Id,
+				ChildHealthChecks: aws.StringSlice(children),
+				HealthThreshold:   aws.Int64(threshold),
+			}); err != nil {
+				return err
+			}
+		}
+	} else {
+		healthCheck, err = r.create(ctx, spec, spec.Id, &route53.HealthCheckConfig{
+			Type:              aws.String(route53.HealthCheckTypeCalculated),
+			ChildHealthChecks: aws.StringSlice(children),
+			HealthThreshold:   aws.Int64(threshold),
+		})
+		if err != nil {
+			return err
+		}
 	}
 
+	for _, endpoint := range endpoints {
+		endpoint.SetProviderSpecific(ProviderSpecificClusterHealthCheckID, *healthCheck.Id)
+	}
 	return nil
 }
 
+// deleteClusterHealthCheck deletes the calculated health checks the endpoints
+// are associated with, if any, and dissociates them from the endpoints.
+func (r *Route53HealthCheckReconciler) deleteClusterHealthCheck(ctx context.Context, endpoints []*v1.Endpoint) error {
+	deleted := map[string]struct{}{}
+	for _, endpoint := range endpoints {
+		id, ok := endpoint.GetProviderSpecific(ProviderSpecificClusterHealthCheckID)
+		if !ok {
+			continue
+		}
+		if _, ok := deleted[id]; !ok {
+			if err := r.deleteHealthCheckByID(ctx, id); err != nil {
+				return err
+			}
+			deleted[id] = struct{}{}
+		}
+		endpoint.DeleteProviderSpecific(ProviderSpecificClusterHealthCheckID)
+	}
+	return nil
+}
+
+// findClusterHealthCheck returns the calculated health check the endpoints are
+// associated with, if it still exists.
+func (r *Route53HealthCheckReconciler) findClusterHealthCheck(ctx context.Context, endpoints []*v1.Endpoint) (*route53.HealthCheck, bool, error) {
+	for _, endpoint := range endpoints {
+		id, ok := endpoint.GetProviderSpecific(ProviderSpecificClusterHealthCheckID)
+		if !ok {
+			continue
+		}
+		response, err := r.client.GetHealthCheckWithContext(ctx, &route53.GetHealthCheckInput{HealthCheckId: &id})
+		if isNoSuchHealthCheck(err) {
+			continue
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		return response.HealthCheck, true, nil
+	}
+	return nil, false, nil
+}
+
+// detachFromClusterHealthCheck removes the health check with the given ID
+// from the children of the calculated health check the endpoint is associated
+// with, if any, so that it can be deleted.
+func (r *Route53HealthCheckReconciler) detachFromClusterHealthCheck(ctx context.Context, endpoint *v1.Endpoint, id string) error {
+	healthCheck, exists, err := r.findClusterHealthCheck(ctx, []*v1.Endpoint{endpoint})
+	if err != nil || !exists {
+		return err
+	}
+	children := aws.StringValueSlice(healthCheck.HealthCheckConfig.ChildHealthChecks)
+	if !slice.ContainsString(children, id) {
+		return nil
+	}
+	children = slice.RemoveString(children, id)
+	// The health check of the last endpoint of the cluster is no longer
+	// aggregated
+	if len(children) == 0 {
+		return r.deleteHealthCheckByID(ctx, *healthCheck.Id)
+	}
+	threshold := aws.Int64Value(healthCheck.HealthCheckConfig.HealthThreshold)
+	if threshold > int64(len(children)) {
+		threshold = int64(len(children))
+	}
+	_, err = r.client.UpdateHealthCheckWithContext(ctx, &route53.UpdateHealthCheckInput{
+		HealthCheckId:     healthCheck.Id,
+		ChildHealthChecks: aws.StringSlice(children),
+		HealthThreshold:   aws.Int64(threshold),
+	})
+	if isNoSuchHealthCheck(err) {
+		return nil
+	}
+	return err
+}
+
+// clusterHealthThreshold returns the number of children of a calculated health
+// check that must be healthy, which is all of them when there are fewer than
+// the cluster health threshold, as the health check would never be healthy
+// otherwise.
+func clusterHealthThreshold(spec v1.EndpointHealthCheck, children int) int64 {
+	if threshold := *spec.ClusterHealthThreshold; threshold < int64(children) {
+		return threshold
+	}
+	return int64(children)
+}
+
+// healthCheckStatus returns the ID of the health check of the endpoint, and the
+// health of the endpoint, as last reported by the Route53 health checkers.
+func (r *Route53HealthCheckReconciler) healthCheckStatus(ctx context.Context, endpoint *v1.Endpoint) (string, v1.EndpointHealth, error) {
+	health := v1.EndpointHealth{
+		DNSName:       endpoint.DNSName,
+		SetIdentifier: endpoint.SetIdentifier,
+		Health:        v1.HealthUnknown,
+	}
+	id, hasId := getHealthCheckId(endpoint)
+	if !hasId {
+		return "", health, nil
+	}
+
+	output, err := r.client.GetHealthCheckStatusWithContext(ctx, &route53.GetHealthCheckStatusInput{
+		HealthCheckId: &id,
+	})
+	if isNoSuchHealthCheck(err) {
+		return "", health, nil
+	}
+	if err != nil {
+		return id, health, providerError(err)
+	}
+
+	health.Health, health.LastObservedTime = healthFromObservations(output.HealthCheckObservations)
+	return id, health, nil
+}
+
+// healthFromObservations returns the health of an endpoint, given the
+// observations of the Route53 health checkers, and the time of the last one.
+func healthFromObservations(observations []*route53.HealthCheckObservation) (v1.Health, metav1.Time) {
+	var lastObserved time.Time
+	healthy := 0
+	for _, observation := range observations {
+		if observation.StatusReport == nil {
+			continue
+		}
+		if strings.HasPrefix(aws.StringValue(observation.StatusReport.Status), "Success") {
+			healthy++
+		}
+		if checked := aws.TimeValue(observation.StatusReport.CheckedTime); checked.After(lastObserved) {
+			lastObserved = checked
+		}
+	}
+	switch {
+	case len(observations) == 0:
+		return v1.HealthUnknown, metav1.Time{}
+	case healthy*100 > healthyCheckersThreshold*len(observations):
+		return v1.Healthy, metav1.NewTime(lastObserved)
+	default:
+		return v1.Unhealthy, metav1.NewTime(lastObserved)
+	}
+}
+
+// listHealthChecks returns the IDs of the endpoints of the health checks owned
+// by the GLBC, as tagged when they were created, by health check ID.
+func (r *Route53HealthCheckReconciler) listHealthChecks(ctx context.Context) (map[string]string, error) {
+	var ids []*string
+	input := &route53.ListHealthChecksInput{}
+	for {
+		output, err := r.client.ListHealthChecksWithContext(ctx, input)
+		if err != nil {
+			return nil, providerError(err)
+		}
+		for _, healthCheck := range output.HealthChecks {
+			ids = append(ids, healthCheck.Id)
+		}
+		if !aws.BoolValue(output.IsTruncated) {
+			break
+		}
+		input.Marker = output.NextMarker
+	}
+
+	owned := map[string]string{}
+	for start := 0; start < len(ids); start += maxTaggedResources {
+		end := start + maxTaggedResources
+		if end > len(ids) {
+			end = len(ids)
+		}
+		output, err := r.client.ListTagsForResourcesWithContext(ctx, &route53.ListTagsForResourcesInput{
+			ResourceIds:  ids[start:end],
+			ResourceType: aws.String(route53.TagResourceTypeHealthcheck),
+		})
+		if err != nil {
+			return nil, providerError(err)
+		}
+		for _, tagSet := range output.ResourceTagSets {
+			if endpointID, ok := ownedHealthCheck(tagSet.Tags, r.ownerID); ok {
+				owned[aws.StringValue(tagSet.ResourceId)] = endpointID
+			}
+		}
+	}
+	return owned, nil
+}
+
+// ownedHealthCheck returns the ID of the endpoint of the health check with the
+// given tags, if it is owned by the GLBC with the given owner ID.
+func ownedHealthCheck(tags []*route53.Tag, ownerID string) (string, bool) {
+	endpointID, owner := "", defaultOwnerID
+	for _, tag := range tags {
+		switch aws.StringValue(tag.Key) {
+		case idTag:
+			endpointID = aws.StringValue(tag.Value)
+		case ownerTag:
+			owner = aws.StringValue(tag.Value)
+		}
+	}
+	return endpointID, endpointID != "" && owner == ownerID
+}
+
+// deleteHealthCheckByID deletes the health check with the given ID, if it still
+// exists.
+func (r *Route53HealthCheckReconciler) deleteHealthCheckByID(ctx context.Context, id string) error {
+	_, err := r.client.DeleteHealthCheckWithContext(ctx, &route53.DeleteHealthCheckInput{HealthCheckId: aws.String(id)})
+	if err != nil && !isNoSuchHealthCheck(err) {
+		return providerError(err)
+	}
+	return nil
+}
+
+func isNoSuchHealthCheck(err error) bool {
+	if awsErr, ok := err.(awserr.Error); ok {
+		return awsErr.Code() == route53.ErrCodeNoSuchHealthCheck
+	}
+	return false
+}
+
+func isHealthCheckAlreadyExists(err error) bool {
+	if awsErr, ok := err.(awserr.Error); ok {
+		return awsErr.Code() == route53.ErrCodeHealthCheckAlreadyExists
+	}
+	return false
+}
+
 func strValuesEqual(str1, str2 *string) bool {
 	if str1 == nil && str2 != nil {
 		return false
@@ -237,6 +708,20 @@ func intValuesEqual(int1, int2 *int64) bool {
 	return *int1 == *int2
 }
 
+// stringSetsEqual returns whether the slices hold the same strings, regardless
+// of their order.
+func stringSetsEqual(strs1, strs2 []string) bool {
+	if len(strs1) != len(strs2) {
+		return false
+	}
+	for _, str := range strs1 {
+		if !slice.ContainsString(strs2, str) {
+			return false
+		}
+	}
+	return true
+}
+
 func getHealthCheckId(endpoint *v1.Endpoint) (string, bool) {
 	return endpoint.GetProviderSpecific(ProviderSpecificHealthCheckID)
 }
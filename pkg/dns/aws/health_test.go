@@ -0,0 +1,173 @@
+package aws
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/onsi/gomega"
+
+	v1 "github.com/kuadrant/kcp-glbc/pkg/apis/kuadrant/v1"
+)
+
+func observation(status string, checked time.Time) *route53.HealthCheckObservation {
+	return &route53.HealthCheckObservation{
+		StatusReport: &route53.StatusReport{Status: aws.String(status), CheckedTime: aws.Time(checked)},
+	}
+}
+
+func TestHealthFromObservations(t *testing.T) {
+	g := gomega.NewWithT(t)
+	now := time.Now().Truncate(time.Second)
+	success := "Success: HTTP Status Code 200, OK"
+	failure := "Failure: Connection timed out"
+
+	health, observed := healthFromObservations(nil)
+	g.Expect(health).To(gomega.Equal(v1.HealthUnknown))
+	g.Expect(observed.IsZero()).To(gomega.BeTrue())
+
+	// The endpoints are healthy when more than 18% of the checkers report so
+	health, observed = healthFromObservations([]*route53.HealthCheckObservation{
+		observation(success, now.Add(-time.Minute)),
+		observation(failure, now),
+		observation(failure, now.Add(-time.Second)),
+		observation(failure, now.Add(-time.Second)),
+	})
+	g.Expect(health).To(gomega.Equal(v1.Healthy))
+	g.Expect(observed.Time).To(gomega.BeTemporally("==", now))
+
+	health, _ = healthFromObservations([]*route53.HealthCheckObservation{
+		observation(success, now),
+		observation(failure, now),
+		observation(failure, now),
+		observation(failure, now),
+		observation(failure, now),
+		observation(failure, now),
+	})
+	g.Expect(health).To(gomega.Equal(v1.Unhealthy))
+}
+
+func TestRequestInterval(t *testing.T) {
+	g := gomega.NewWithT(t)
+	g.Expect(requestInterval(v1.EndpointHealthCheck{})).To(gomega.Equal(int64(standardRequestInterval)))
+	g.Expect(requestInterval(v1.EndpointHealthCheck{Interval: 5 * time.Second})).To(gomega.Equal(int64(fastRequestInterval)))
+	g.Expect(requestInterval(v1.EndpointHealthCheck{Interval: 10 * time.Second})).To(gomega.Equal(int64(fastRequestInterval)))
+	g.Expect(requestInterval(v1.EndpointHealthCheck{Interval: 20 * time.Second})).To(gomega.Equal(int64(standardRequestInterval)))
+}
+
+func TestOwnedHealthCheck(t *testing.T) {
+	g := gomega.NewWithT(t)
+	tag := func(key, value string) *route53.Tag {
+		return &route53.Tag{Key: aws.String(key), Value: aws.String(value)}
+	}
+
+	id, owned := ownedHealthCheck([]*route53.Tag{tag(idTag, "abc"), tag(ownerTag, "glbc-1")}, "glbc-1")
+	g.Expect(owned).To(gomega.BeTrue())
+	g.Expect(id).To(gomega.Equal("abc"))
+
+	_, owned = ownedHealthCheck([]*route53.Tag{tag(idTag, "abc"), tag(ownerTag, "glbc-2")}, "glbc-1")
+	g.Expect(owned).To(gomega.BeFalse())
+
+	// The health checks with no owner tag are owned by the default owner
+	_, owned = ownedHealthCheck([]*route53.Tag{tag(idTag, "abc")}, defaultOwnerID)
+	g.Expect(owned).To(gomega.BeTrue())
+	_, owned = ownedHealthCheck([]*route53.Tag{tag(idTag, "abc")}, "glbc-1")
+	g.Expect(owned).To(gomega.BeFalse())
+
+	// The health checks not created by the GLBC have no ID tag
+	_, owned = ownedHealthCheck([]*route53.Tag{tag("Name", "manual")}, defaultOwnerID)
+	g.Expect(owned).To(gomega.BeFalse())
+}
+
+func TestHealthCheckType(t *testing.T) {
+	g := gomega.NewWithT(t)
+	http, https, tcp := v1.HealthCheckProtocolHTTP, v1.HealthCheckProtocolHTTPS, v1.HealthCheckProtocolTCP
+
+	g.Expect(*healthCheckType(v1.EndpointHealthCheck{})).To(gomega.Equal(route53.HealthCheckTypeHttp))
+	g.Expect(*healthCheckType(v1.EndpointHealthCheck{Protocol: &https})).To(gomega.Equal(route53.HealthCheckTypeHttps))
+	g.Expect(*healthCheckType(v1.EndpointHealthCheck{Protocol: &tcp})).To(gomega.Equal(route53.HealthCheckTypeTcp))
+
+	// The responses are searched for the search string, if any
+	g.Expect(*healthCheckType(v1.EndpointHealthCheck{Protocol: &http, SearchString: "ok"})).To(gomega.Equal(route53.HealthCheckTypeHttpStrMatch))
+	g.Expect(*healthCheckType(v1.EndpointHealthCheck{Protocol: &https, SearchString: "ok"})).To(gomega.Equal(route53.HealthCheckTypeHttpsStrMatch))
+	g.Expect(*healthCheckType(v1.EndpointHealthCheck{Protocol: &tcp, SearchString: "ok"})).To(gomega.Equal(route53.HealthCheckTypeTcp))
+	g.Expect(searchString(v1.EndpointHealthCheck{Protocol: &tcp, SearchString: "ok"})).To(gomega.BeNil())
+}
+
+func TestHealthCheckIsRecreated(t *testing.T) {
+	g := gomega.NewWithT(t)
+	https := v1.HealthCheckProtocolHTTPS
+	healthCheck := &route53.HealthCheck{HealthCheckConfig: &route53.HealthCheckConfig{
+		Type:            aws.String(route53.HealthCheckTypeHttp),
+		RequestInterval: aws.Int64(standardRequestInterval),
+	}}
+
+	g.Expect(isUpdatable(healthCheck, v1.EndpointHealthCheck{})).To(gomega.BeTrue())
+	g.Expect(isUpdatable(healthCheck, v1.EndpointHealthCheck{Interval: 10 * time.Second})).To(gomega.BeFalse())
+	g.Expect(isUpdatable(healthCheck, v1.EndpointHealthCheck{Protocol: &https})).To(gomega.BeFalse())
+	g.Expect(isUpdatable(healthCheck, v1.EndpointHealthCheck{SearchString: "ok"})).To(gomega.BeFalse())
+}
+
+func TestHealthCheckDiff(t *testing.T) {
+	g := gomega.NewWithT(t)
+	port := int64(443)
+	https := v1.HealthCheckProtocolHTTPS
+	endpoint := &v1.Endpoint{DNSName: "app.example.com", SetIdentifier: "10.0.0.1", Targets: v1.Targets{"10.0.0.1"}}
+	spec := v1.EndpointHealthCheck{Path: "/healthz", Port: &port, Protocol: &https, SearchString: "ok"}
+	healthCheck := &route53.HealthCheck{
+		Id: aws.String("id"),
+		HealthCheckConfig: &route53.HealthCheckConfig{
+			IPAddress:                aws.String("10.0.0.1"),
+			FullyQualifiedDomainName: aws.String("app.example.com"),
+			Port:                     aws.Int64(443),
+			ResourcePath:             aws.String("/healthz"),
+			Type:                     aws.String(route53.HealthCheckTypeHttpsStrMatch),
+			SearchString:             aws.String("ok"),
+			Inverted:                 aws.Bool(false),
+			EnableSNI:                aws.Bool(true),
+		},
+	}
+	g.Expect(healthCheckDiff(healthCheck, spec, endpoint)).To(gomega.BeNil())
+
+	spec.SearchString = "healthy"
+	spec.Regions = []string{"us-east-1", "eu-west-1", "ap-southeast-1"}
+	spec.Inverted = true
+	diff := healthCheckDiff(healthCheck, spec, endpoint)
+	g.Expect(diff).NotTo(gomega.BeNil())
+	g.Expect(aws.StringValue(diff.SearchString)).To(gomega.Equal("healthy"))
+	g.Expect(aws.StringValueSlice(diff.Regions)).To(gomega.Equal(spec.Regions))
+	g.Expect(aws.BoolValue(diff.Inverted)).To(gomega.BeTrue())
+
+	// The regions are compared regardless of their order, and reset to the
+	// default ones when unset
+	healthCheck.HealthCheckConfig.Regions = aws.StringSlice([]string{"ap-southeast-1", "us-east-1", "eu-west-1"})
+	g.Expect(healthCheckDiff(healthCheck, spec, endpoint).Regions).To(gomega.BeNil())
+	spec.Regions = nil
+	g.Expect(aws.StringValueSlice(healthCheckDiff(healthCheck, spec, endpoint).ResetElements)).To(gomega.ConsistOf(route53.ResettableElementNameRegions))
+
+	// The HTTPS health checks send the host name in the TLS handshake
+	healthCheck.HealthCheckConfig.EnableSNI = aws.Bool(false)
+	g.Expect(aws.BoolValue(healthCheckDiff(healthCheck, spec, endpoint).EnableSNI)).To(gomega.BeTrue())
+}
+
+func TestValidateHealthCheck(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	g.Expect(validateHealthCheck(v1.EndpointHealthCheck{Regions: []string{"us-east-1", "eu-west-1", "ap-southeast-1"}})).To(gomega.Succeed())
+	g.Expect(validateHealthCheck(v1.EndpointHealthCheck{ExpectedStatusCodes: []v1.HTTPStatusCode{500}})).To(gomega.HaveOccurred())
+	g.Expect(validateHealthCheck(v1.EndpointHealthCheck{Regions: []string{"us-east-1", "eu-west-1"}})).To(gomega.HaveOccurred())
+	g.Expect(validateHealthCheck(v1.EndpointHealthCheck{Regions: []string{"us-east-1", "eu-west-1", "eu-central-1"}})).To(gomega.HaveOccurred())
+	g.Expect(validateHealthCheck(v1.EndpointHealthCheck{SearchString: strings.Repeat("a", 256)})).To(gomega.HaveOccurred())
+}
+
+func TestClusterHealthThreshold(t *testing.T) {
+	g := gomega.NewWithT(t)
+	threshold := int64(2)
+	spec := v1.EndpointHealthCheck{ClusterHealthThreshold: &threshold}
+
+	g.Expect(clusterHealthThreshold(spec, 3)).To(gomega.Equal(int64(2)))
+	// All the endpoints of the clusters with fewer endpoints must be healthy
+	g.Expect(clusterHealthThreshold(spec, 1)).To(gomega.Equal(int64(1)))
+}
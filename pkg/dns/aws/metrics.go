@@ -26,6 +26,13 @@ const (
 	returnCodeLabel = "code"
 	// The default return code
 	returnCodeLabelDefault = ""
+
+	outcomeLabel = "outcome"
+	// The outcomes of the change batches
+	outcomeSuccess   = "success"
+	outcomeInvalid   = "invalid"
+	outcomeThrottled = "throttled"
+	outcomeError     = "error"
 )
 
 var (
@@ -75,6 +82,36 @@ var (
 		},
 		[]string{operationLabel, returnCodeLabel},
 	)
+
+	// route53ChangeBatchTotal is a prometheus counter metrics which holds the
+	// total number of change batches submitted to Route53, by outcome.
+	route53ChangeBatchTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "glbc_aws_route53_change_batch_total",
+			Help: "GLBC AWS Route53 total number of change batches",
+		},
+		[]string{outcomeLabel},
+	)
+
+	// route53ChangeBatchRecords is a prometheus metric which records the
+	// number of DNS records coalesced into the change batches.
+	route53ChangeBatchRecords = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "glbc_aws_route53_change_batch_records",
+			Help:    "GLBC AWS Route53 number of DNS records per change batch",
+			Buckets: []float64{1, 2, 5, 10, 20, 50, 100, 200, 500},
+		},
+	)
+
+	// route53ChangeBatchChanges is a prometheus metric which records the
+	// number of changes of the change batches.
+	route53ChangeBatchChanges = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "glbc_aws_route53_change_batch_changes",
+			Help:    "GLBC AWS Route53 number of changes per change batch",
+			Buckets: []float64{1, 2, 5, 10, 20, 50, 100, 200, 500},
+		},
+	)
 )
 
 var operationLabelValues []string
@@ -86,6 +123,9 @@ func init() {
 		route53RequestTotal,
 		route53RequestErrors,
 		route53RequestDuration,
+		route53ChangeBatchTotal,
+		route53ChangeBatchRecords,
+		route53ChangeBatchChanges,
 	)
 
 	monitoredRoute53 := reflect.PtrTo(reflect.TypeOf(InstrumentedRoute53{}))
@@ -99,4 +139,24 @@ func init() {
 		route53RequestTotal.WithLabelValues(operation, returnCodeLabelDefault).Add(0)
 		route53RequestErrors.WithLabelValues(operation, returnCodeLabelDefault).Add(0)
 	}
+	for _, outcome := range []string{outcomeSuccess, outcomeInvalid, outcomeThrottled, outcomeError} {
+		route53ChangeBatchTotal.WithLabelValues(outcome).Add(0)
+	}
+}
+
+// observeBatch records the outcome of a change batch.
+func observeBatch(records, changes int, err error) {
+	outcome := outcomeSuccess
+	switch {
+	case err == nil:
+	case isInvalidChangeBatchError(err):
+		outcome = outcomeInvalid
+	case isThrottlingError(err):
+		outcome = outcomeThrottled
+	default:
+		outcome = outcomeError
+	}
+	route53ChangeBatchTotal.WithLabelValues(outcome).Inc()
+	route53ChangeBatchRecords.Observe(float64(records))
+	route53ChangeBatchChanges.Observe(float64(changes))
 }
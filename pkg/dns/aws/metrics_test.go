@@ -25,10 +25,14 @@ func TestRegister(t *testing.T) {
 	g.Expect(operationLabelValues).To(gomega.ConsistOf(
 		"ListHostedZones",
 		"ChangeResourceRecordSets",
+		"ListResourceRecordSets",
 		"CreateHealthCheck",
 		"GetHealthCheckWithContext",
+		"GetHealthCheckStatusWithContext",
+		"ListHealthChecksWithContext",
 		"UpdateHealthCheckWithContext",
 		"DeleteHealthCheckWithContext",
 		"ChangeTagsForResourceWithContext",
+		"ListTagsForResourcesWithContext",
 	))
 }
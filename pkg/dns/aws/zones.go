@@ -0,0 +1,152 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi"
+	"github.com/aws/aws-sdk-go/service/route53"
+
+	v1 "github.com/kuadrant/kcp-glbc/pkg/apis/kuadrant/v1"
+)
+
+const (
+	// DefaultZoneRefreshInterval is the interval after which the IDs of the
+	// hosted zones found by tags are resolved again.
+	DefaultZoneRefreshInterval = 5 * time.Minute
+
+	hostedZoneResourceType = "route53:hostedzone"
+	hostedZoneARNPrefix    = "hostedzone/"
+)
+
+type resolvedZone struct {
+	id         string
+	resolvedAt time.Time
+}
+
+// zoneResolver finds the hosted zones by tags, using the resource groups
+// tagging API, and caches their IDs for the refresh interval.
+type zoneResolver struct {
+	client          *resourcegroupstaggingapi.ResourceGroupsTaggingAPI
+	refreshInterval time.Duration
+	logger          logr.Logger
+
+	mu    sync.Mutex
+	cache map[string]resolvedZone
+}
+
+func newZoneResolver(client *resourcegroupstaggingapi.ResourceGroupsTaggingAPI, refreshInterval time.Duration, logger logr.Logger) *zoneResolver {
+	if refreshInterval <= 0 {
+		refreshInterval = DefaultZoneRefreshInterval
+	}
+	return &zoneResolver{
+		client:          client,
+		refreshInterval: refreshInterval,
+		logger:          logger.WithName("zones"),
+		cache:           map[string]resolvedZone{},
+	}
+}
+
+// resolve returns the ID of the hosted zone with the given tags. The cached ID
+// is returned if it was resolved within the refresh interval, or if it cannot
+// be refreshed, so that a transient failure does not stop the records from
+// being published.
+func (r *zoneResolver) resolve(ctx context.Context, tags map[string]string) (string, error) {
+	key := tagsKey(tags)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cached, ok := r.cache[key]
+	if ok && time.Since(cached.resolvedAt) < r.refreshInterval {
+		return cached.id, nil
+	}
+
+	id, err := r.findHostedZone(ctx, tags)
+	if err != nil {
+		if ok {
+			r.logger.Error(err, "Failed to refresh hosted zone, using the cached one", "tags", key, "id", cached.id)
+			return cached.id, nil
+		}
+		return "", err
+	}
+	if ok && cached.id != id {
+		r.logger.Info("Hosted zone changed", "tags", key, "previous", cached.id, "id", id)
+	}
+	r.cache[key] = resolvedZone{id: id, resolvedAt: time.Now()}
+	return id, nil
+}
+
+func (r *zoneResolver) findHostedZone(ctx context.Context, tags map[string]string) (string, error) {
+	input := &resourcegroupstaggingapi.GetResourcesInput{
+		ResourceTypeFilters: []*string{aws.String(hostedZoneResourceType)},
+	}
+	for key, value := range tags {
+		input.TagFilters = append(input.TagFilters, &resourcegroupstaggingapi.TagFilter{
+			Key:    aws.String(key),
+			Values: []*string{aws.String(value)},
+		})
+	}
+
+	var ids []string
+	err := r.client.GetResourcesPagesWithContext(ctx, input, func(output *resourcegroupstaggingapi.GetResourcesOutput, _ bool) bool {
+		for _, mapping := range output.ResourceTagMappingList {
+			arn := aws.StringValue(mapping.ResourceARN)
+			if i := strings.LastIndex(arn, hostedZoneARNPrefix); i >= 0 {
+				ids = append(ids, arn[i+len(hostedZoneARNPrefix):])
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get hosted zones with tags %s: %v", tagsKey(tags), err)
+	}
+
+	switch len(ids) {
+	case 0:
+		return "", fmt.Errorf("no hosted zone found with tags %s", tagsKey(tags))
+	case 1:
+		return ids[0], nil
+	default:
+		sort.Strings(ids)
+		return "", fmt.Errorf("multiple hosted zones found with tags %s: %s", tagsKey(tags), strings.Join(ids, ", "))
+	}
+}
+
+// tagsKey returns the sorted key=value pairs of the tags.
+func tagsKey(tags map[string]string) string {
+	pairs := make([]string, 0, len(tags))
+	for key, value := range tags {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// ResolveZone returns the zone with the ID of the hosted zone found by its
+// tags. Zones that are given an ID are returned as is.
+func (p *Provider) ResolveZone(ctx context.Context, zone v1.DNSZone) (v1.DNSZone, error) {
+	if len(zone.Tags) == 0 {
+		return zone, nil
+	}
+	id, err := p.zoneResolver.resolve(ctx, zone.Tags)
+	if err != nil {
+		return zone, err
+	}
+	resolved := *zone.DeepCopy()
+	resolved.ID = id
+	return resolved, nil
+}
+
+func isNoSuchHostedZoneError(err error) bool {
+	awsErr, ok := err.(awserr.Error)
+	return ok && awsErr.Code() == route53.ErrCodeNoSuchHostedZone
+}
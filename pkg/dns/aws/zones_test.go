@@ -0,0 +1,132 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi"
+	"github.com/onsi/gomega"
+
+	"github.com/kuadrant/kcp-glbc/pkg/_internal/log"
+	v1 "github.com/kuadrant/kcp-glbc/pkg/apis/kuadrant/v1"
+)
+
+// fakeTaggingAPI serves GetResources requests of the resource groups tagging
+// API, returning the configured ARNs, or an error when failing.
+type fakeTaggingAPI struct {
+	mu       sync.Mutex
+	arns     []string
+	failing  bool
+	requests int
+}
+
+func (f *fakeTaggingAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests++
+
+	w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+	if f.failing {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"__type":"ThrottledException","message":"Rate exceeded"}`))
+		return
+	}
+	var mappings []map[string]string
+	for _, arn := range f.arns {
+		mappings = append(mappings, map[string]string{"ResourceARN": arn})
+	}
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"ResourceTagMappingList": mappings})
+}
+
+func (f *fakeTaggingAPI) setARNs(arns ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.arns = arns
+	f.failing = false
+}
+
+func (f *fakeTaggingAPI) fail() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failing = true
+}
+
+func newTestZoneResolver(t *testing.T, refreshInterval time.Duration) (*zoneResolver, *fakeTaggingAPI) {
+	fake := &fakeTaggingAPI{}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	sess, err := session.NewSession(&aws.Config{
+		Region:      aws.String("us-east-1"),
+		Endpoint:    aws.String(server.URL),
+		Credentials: credentials.NewStaticCredentials("id", "secret", ""),
+		MaxRetries:  aws.Int(0),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error creating session: %v", err)
+	}
+	return newZoneResolver(resourcegroupstaggingapi.New(sess), refreshInterval, log.Logger), fake
+}
+
+func TestResolveZone(t *testing.T) {
+	g := gomega.NewWithT(t)
+	resolver, fake := newTestZoneResolver(t, time.Hour)
+	p := &Provider{zoneResolver: resolver, logger: log.Logger}
+	tags := map[string]string{"kuadrant.dev/zone": "dev"}
+
+	// Zones given by ID are not resolved
+	zone, err := p.ResolveZone(context.TODO(), v1.DNSZone{ID: "Z1"})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(zone).To(gomega.Equal(v1.DNSZone{ID: "Z1"}))
+	g.Expect(fake.requests).To(gomega.BeZero())
+
+	// No zone found, and nothing cached yet
+	fake.setARNs()
+	_, err = p.ResolveZone(context.TODO(), v1.DNSZone{Tags: tags})
+	g.Expect(err).To(gomega.MatchError("no hosted zone found with tags kuadrant.dev/zone=dev"))
+
+	// Multiple zones found
+	fake.setARNs("arn:aws:route53:::hostedzone/Z2", "arn:aws:route53:::hostedzone/Z1")
+	_, err = p.ResolveZone(context.TODO(), v1.DNSZone{Tags: tags})
+	g.Expect(err).To(gomega.MatchError("multiple hosted zones found with tags kuadrant.dev/zone=dev: Z1, Z2"))
+
+	fake.setARNs("arn:aws:route53:::hostedzone/Z1")
+	zone, err = p.ResolveZone(context.TODO(), v1.DNSZone{Tags: tags})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(zone).To(gomega.Equal(v1.DNSZone{ID: "Z1", Tags: tags}))
+
+	// The zone is cached for the refresh interval
+	fake.setARNs("arn:aws:route53:::hostedzone/Z2")
+	zone, err = p.ResolveZone(context.TODO(), v1.DNSZone{Tags: tags})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(zone.ID).To(gomega.Equal("Z1"))
+	g.Expect(fake.requests).To(gomega.Equal(3))
+}
+
+func TestResolveZoneRefresh(t *testing.T) {
+	g := gomega.NewWithT(t)
+	resolver, fake := newTestZoneResolver(t, time.Nanosecond)
+	tags := map[string]string{"kuadrant.dev/zone": "dev"}
+
+	fake.setARNs("arn:aws:route53:::hostedzone/Z1")
+	g.Expect(resolver.resolve(context.TODO(), tags)).To(gomega.Equal("Z1"))
+
+	// The recreated zone is found once the cached one has expired
+	fake.setARNs("arn:aws:route53:::hostedzone/Z2")
+	g.Expect(resolver.resolve(context.TODO(), tags)).To(gomega.Equal("Z2"))
+
+	// The cached zone is kept when it cannot be refreshed
+	fake.setARNs()
+	g.Expect(resolver.resolve(context.TODO(), tags)).To(gomega.Equal("Z2"))
+	fake.fail()
+	g.Expect(resolver.resolve(context.TODO(), tags)).To(gomega.Equal("Z2"))
+	g.Expect(fake.requests).To(gomega.Equal(4))
+}
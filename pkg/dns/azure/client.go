@@ -0,0 +1,182 @@
+package azure
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	dnserrors "github.com/kuadrant/kcp-glbc/pkg/dns/errors"
+)
+
+const (
+	// defaultEndpoint is the Azure Resource Manager endpoint of the public cloud.
+	defaultEndpoint = "https://management.azure.com"
+	// dnsAPIVersion is the version of the Microsoft.Network DNS API.
+	dnsAPIVersion = "2018-05-01"
+)
+
+// recordSet mirrors the Azure DNS RecordSet resource.
+// See https://learn.microsoft.com/en-us/rest/api/dns/record-sets
+type recordSet struct {
+	// Name and Type are only set on the record sets returned by the API, Type
+	// being e.g. "Microsoft.Network/dnszones/A".
+	Name       string              `json:"name,omitempty"`
+	Type       string              `json:"type,omitempty"`
+	Properties recordSetProperties `json:"properties"`
+}
+
+type recordSetList struct {
+	Value    []*recordSet `json:"value"`
+	NextLink string       `json:"nextLink"`
+}
+
+type recordSetProperties struct {
+	TTL         int64        `json:"TTL"`
+	ARecords    []aRecord    `json:"ARecords,omitempty"`
+	AAAARecords []aaaaRecord `json:"AAAARecords,omitempty"`
+	CNAMERecord *cnameRecord `json:"CNAMERecord,omitempty"`
+	TXTRecords  []txtRecord  `json:"TXTRecords,omitempty"`
+	CAARecords  []caaRecord  `json:"caaRecords,omitempty"`
+}
+
+type aRecord struct {
+	IPv4Address string `json:"ipv4Address"`
+}
+
+type aaaaRecord struct {
+	IPv6Address string `json:"ipv6Address"`
+}
+
+type cnameRecord struct {
+	CNAME string `json:"cname"`
+}
+
+type txtRecord struct {
+	Value []string `json:"value"`
+}
+
+type caaRecord struct {
+	Flags int    `json:"flags"`
+	Tag   string `json:"tag"`
+	Value string `json:"value"`
+}
+
+// apiError is the error payload returned by Azure Resource Manager.
+type apiError struct {
+	StatusCode int    `json:"-"`
+	Code       string `json:"code"`
+	Message    string `json:"message"`
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("azure dns: %d %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+// reason returns the reason of the error. Azure Resource Manager returns a
+// ParentResourceNotFound error when the zone of a record set does not exist.
+func (e *apiError) reason() dnserrors.Reason {
+	if e.Code == "ParentResourceNotFound" {
+		return dnserrors.ReasonZoneNotFound
+	}
+	return dnserrors.ReasonForHTTPStatus(e.StatusCode)
+}
+
+func isNotFound(err error) bool {
+	var apiErr *apiError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// client is a minimal Azure DNS REST client covering the record set
+// operations required by the provider.
+type client struct {
+	httpClient *http.Client
+	endpoint   string
+}
+
+func (c *client) getRecordSet(ctx context.Context, zoneID, recordType, name string) (*recordSet, error) {
+	out := &recordSet{}
+	if err := c.do(ctx, http.MethodGet, c.recordSetURL(zoneID, recordType, name), nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *client) listRecordSets(ctx context.Context, zoneID string) ([]*recordSet, error) {
+	query := url.Values{"api-version": []string{dnsAPIVersion}}
+	next := fmt.Sprintf("%s/%s/recordsets?%s", strings.TrimSuffix(c.endpoint, "/"), strings.Trim(zoneID, "/"), query.Encode())
+	var recordSets []*recordSet
+	for next != "" {
+		out := &recordSetList{}
+		if err := c.do(ctx, http.MethodGet, next, nil, out); err != nil {
+			return nil, err
+		}
+		recordSets = append(recordSets, out.Value...)
+		next = out.NextLink
+	}
+	return recordSets, nil
+}
+
+func (c *client) createOrUpdateRecordSet(ctx context.Context, zoneID, recordType, name string, rs *recordSet) error {
+	return c.do(ctx, http.MethodPut, c.recordSetURL(zoneID, recordType, name), rs, nil)
+}
+
+func (c *client) deleteRecordSet(ctx context.Context, zoneID, recordType, name string) error {
+	return c.do(ctx, http.MethodDelete, c.recordSetURL(zoneID, recordType, name), nil, nil)
+}
+
+func (c *client) recordSetURL(zoneID, recordType, name string) string {
+	query := url.Values{"api-version": []string{dnsAPIVersion}}
+	return fmt.Sprintf("%s/%s/%s/%s?%s", strings.TrimSuffix(c.endpoint, "/"), strings.Trim(zoneID, "/"), recordType, url.PathEscape(name), query.Encode())
+}
+
+func (c *client) do(ctx context.Context, method, url string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		payload := struct {
+			Error *apiError `json:"error"`
+		}{}
+		if err := json.Unmarshal(data, &payload); err != nil || payload.Error == nil {
+			payload.Error = &apiError{Message: strings.TrimSpace(string(data))}
+		}
+		payload.Error.StatusCode = resp.StatusCode
+		return dnserrors.New(payload.Error.reason(), payload.Error)
+	}
+
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
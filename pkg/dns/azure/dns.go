@@ -0,0 +1,413 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"golang.org/x/oauth2/clientcredentials"
+
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"github.com/kuadrant/kcp-glbc/pkg/_internal/log"
+	v1 "github.com/kuadrant/kcp-glbc/pkg/apis/kuadrant/v1"
+	dnserrors "github.com/kuadrant/kcp-glbc/pkg/dns/errors"
+)
+
+const (
+	// ProviderSpecificWeight overrides the weight of the weighted routing policy.
+	ProviderSpecificWeight = "azure/weight"
+
+	TenantIDEnvVar     = "AZURE_TENANT_ID"
+	ClientIDEnvVar     = "AZURE_CLIENT_ID"
+	ClientSecretEnvVar = "AZURE_CLIENT_SECRET"
+	ZoneIDEnvVar       = "AZURE_DNS_PUBLIC_ZONE_ID"
+
+	loginEndpoint = "https://login.microsoftonline.com"
+)
+
+// Provider publishes DNSRecords to Azure DNS zones. The zone ID is expected to
+// be the full resource ID of the zone, e.g.
+// /subscriptions/{subscription}/resourceGroups/{group}/providers/Microsoft.Network/dnszones/{zone}
+//
+// Azure DNS has no weighted routing policy for record sets: endpoints sharing
+// the same name and type are published as a single record set, holding all the
+// targets with a non-zero weight, which resolvers then balance evenly.
+type Provider struct {
+	client *client
+	config Config
+	logger logr.Logger
+}
+
+// Config is the necessary input to configure the provider.
+type Config struct {
+	// TenantID, ClientID and ClientSecret are the credentials of the service
+	// principal used to authenticate against Azure Resource Manager.
+	TenantID     string
+	ClientID     string
+	ClientSecret string
+	// Endpoint is the Azure Resource Manager endpoint. Defaults to the public cloud endpoint.
+	Endpoint string
+	// HTTPClient is the client used to call the Azure Resource Manager API.
+	// Defaults to a client authenticated with the service principal credentials.
+	HTTPClient *http.Client
+}
+
+func NewProvider(config Config) (*Provider, error) {
+	if config.Endpoint == "" {
+		config.Endpoint = defaultEndpoint
+	}
+
+	httpClient := config.HTTPClient
+	if httpClient == nil {
+		if config.TenantID == "" || config.ClientID == "" || config.ClientSecret == "" {
+			return nil, fmt.Errorf("azure credentials are required, set %s, %s and %s", TenantIDEnvVar, ClientIDEnvVar, ClientSecretEnvVar)
+		}
+		credentials := clientcredentials.Config{
+			ClientID:     config.ClientID,
+			ClientSecret: config.ClientSecret,
+			TokenURL:     fmt.Sprintf("%s/%s/oauth2/v2.0/token", loginEndpoint, config.TenantID),
+			Scopes:       []string{strings.TrimSuffix(config.Endpoint, "/") + "/.default"},
+		}
+		if err := validateCredentials(&credentials); err != nil {
+			return nil, fmt.Errorf("failed to validate Azure provider credentials: %v", err)
+		}
+		httpClient = credentials.Client(context.Background())
+	}
+
+	p := &Provider{
+		client: &client{
+			httpClient: httpClient,
+			endpoint:   config.Endpoint,
+		},
+		config: config,
+		logger: log.Logger.WithName("azure-dns"),
+	}
+
+	return p, nil
+}
+
+// validateCredentials validates that the service principal credentials can be
+// exchanged for an access token.
+func validateCredentials(credentials *clientcredentials.Config) error {
+	var errs []error
+	if _, err := credentials.Token(context.Background()); err != nil {
+		errs = append(errs, fmt.Errorf("failed to acquire azure access token: %v", err))
+	}
+	return kerrors.NewAggregate(errs)
+}
+
+func (p *Provider) Ensure(ctx context.Context, record *v1.DNSRecord, zone v1.DNSZone) error {
+	desired, err := p.recordSetsForEndpoints(record.Spec.Endpoints, zone)
+	if err != nil {
+		return err
+	}
+
+	for key, rs := range desired {
+		if err := p.client.createOrUpdateRecordSet(ctx, zone.ID, key.recordType, key.name, rs); err != nil {
+			return fmt.Errorf("failed to update record in zone %s: %w", zone.ID, err)
+		}
+	}
+
+	// Delete any previously published record sets that are no longer present in record.Spec.Endpoints
+	published, err := p.recordSetsForEndpoints(endpointsFromZoneStatus(record, zone.ID), zone)
+	if err != nil {
+		return err
+	}
+	for key := range published {
+		if _, found := desired[key]; found {
+			continue
+		}
+		if err := p.client.deleteRecordSet(ctx, zone.ID, key.recordType, key.name); err != nil && !isNotFound(err) {
+			return fmt.Errorf("failed to delete record in zone %s: %w", zone.ID, err)
+		}
+	}
+
+	p.logger.Info("Upserted DNS record", "record", record.Spec, "zone", zone)
+	return nil
+}
+
+func (p *Provider) Delete(ctx context.Context, record *v1.DNSRecord, zone v1.DNSZone) error {
+	recordSets, err := p.recordSetsForEndpoints(record.Spec.Endpoints, zone)
+	if err != nil {
+		return err
+	}
+
+	for key := range recordSets {
+		if err := p.client.deleteRecordSet(ctx, zone.ID, key.recordType, key.name); err != nil && !isNotFound(err) {
+			return fmt.Errorf("failed to delete record in zone %s: %w", zone.ID, err)
+		}
+	}
+
+	p.logger.Info("Deleted DNS record", "record", record.Spec, "zone", zone)
+	return nil
+}
+
+// GetRecords returns the endpoint of the record set of the zone with the given
+// name and type.
+func (p *Provider) GetRecords(ctx context.Context, zone v1.DNSZone, dnsName, recordType string) ([]*v1.Endpoint, error) {
+	name, err := relativeName(dnsName, zoneNameFromID(zone.ID))
+	if err != nil {
+		return nil, err
+	}
+	rs, err := p.client.getRecordSet(ctx, zone.ID, recordType, name)
+	if isNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get record set %s in zone %s: %w", dnsName, zone.ID, err)
+	}
+
+	if endpoint := endpointForRecordSet(dnsName, recordType, rs); endpoint != nil {
+		return []*v1.Endpoint{endpoint}, nil
+	}
+	return nil, nil
+}
+
+// ListRecords returns all the record sets of the zone.
+func (p *Provider) ListRecords(ctx context.Context, zone v1.DNSZone) ([]*v1.Endpoint, error) {
+	recordSets, err := p.client.listRecordSets(ctx, zone.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list record sets in zone %s: %w", zone.ID, err)
+	}
+
+	zoneName := zoneNameFromID(zone.ID)
+	var endpoints []*v1.Endpoint
+	for _, rs := range recordSets {
+		dnsName := zoneName
+		if rs.Name != "@" {
+			dnsName = rs.Name + "." + zoneName
+		}
+		recordType := rs.Type[strings.LastIndex(rs.Type, "/")+1:]
+		if endpoint := endpointForRecordSet(dnsName, recordType, rs); endpoint != nil {
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	return endpoints, nil
+}
+
+// endpointForRecordSet returns the endpoint of the record set, or nil if it
+// holds none of the record types supported by the provider.
+func endpointForRecordSet(dnsName, recordType string, rs *recordSet) *v1.Endpoint {
+	endpoint := &v1.Endpoint{
+		DNSName:    strings.ToLower(strings.TrimSuffix(dnsName, ".")),
+		RecordType: recordType,
+		RecordTTL:  v1.TTL(rs.Properties.TTL),
+	}
+	for _, a := range rs.Properties.ARecords {
+		endpoint.Targets = append(endpoint.Targets, a.IPv4Address)
+	}
+	for _, aaaa := range rs.Properties.AAAARecords {
+		endpoint.Targets = append(endpoint.Targets, aaaa.IPv6Address)
+	}
+	if rs.Properties.CNAMERecord != nil {
+		endpoint.Targets = append(endpoint.Targets, rs.Properties.CNAMERecord.CNAME)
+	}
+	for _, txt := range rs.Properties.TXTRecords {
+		endpoint.Targets = append(endpoint.Targets, strconv.Quote(strings.Join(txt.Value, "")))
+	}
+	for _, caa := range rs.Properties.CAARecords {
+		endpoint.Targets = append(endpoint.Targets, v1.NewCAATarget(uint8(caa.Flags), caa.Tag, caa.Value))
+	}
+	if len(endpoint.Targets) == 0 {
+		return nil
+	}
+	return endpoint
+}
+
+// RequiresHealthProbes returns true, as the Azure provider has no health checks
+// of its own, the endpoints are probed by the GLBC instead.
+func (p *Provider) RequiresHealthProbes() bool {
+	return true
+}
+
+// ReconcileHealthCheck is a no-op, Azure DNS record sets cannot be
+// associated with health checks.
+func (p *Provider) ReconcileHealthCheck(ctx context.Context, _ v1.EndpointHealthCheck, endpoint *v1.Endpoint) error {
+	p.logger.V(3).Info("Health checks are not supported by the Azure provider, skipping", "endpoint", endpoint.SetID())
+	return nil
+}
+
+func (p *Provider) DeleteHealthCheck(ctx context.Context, _ *v1.Endpoint) error {
+	return nil
+}
+
+// recordSetKey identifies a record set within a zone.
+type recordSetKey struct {
+	recordType string
+	name       string
+}
+
+// recordSetsForEndpoints groups the endpoints by name and type, and returns
+// the corresponding Azure DNS record sets.
+func (p *Provider) recordSetsForEndpoints(endpoints []*v1.Endpoint, zone v1.DNSZone) (map[recordSetKey]*recordSet, error) {
+	zoneName := zoneNameFromID(zone.ID)
+
+	groups := map[recordSetKey][]*v1.Endpoint{}
+	for _, endpoint := range endpoints {
+		if err := validateEndpoint(endpoint); err != nil {
+			return nil, err
+		}
+		name, err := relativeName(endpoint.DNSName, zoneName)
+		if err != nil {
+			return nil, err
+		}
+		key := recordSetKey{recordType: endpoint.RecordType, name: name}
+		groups[key] = append(groups[key], endpoint)
+	}
+
+	recordSets := make(map[recordSetKey]*recordSet, len(groups))
+	for key, group := range groups {
+		rs, err := p.recordSetForEndpoints(group)
+		if err != nil {
+			return nil, err
+		}
+		recordSets[key] = rs
+	}
+	return recordSets, nil
+}
+
+func (p *Provider) recordSetForEndpoints(endpoints []*v1.Endpoint) (*recordSet, error) {
+	first := endpoints[0]
+	rs := &recordSet{
+		Properties: recordSetProperties{
+			TTL: int64(first.RecordTTL),
+		},
+	}
+
+	switch first.RecordType {
+	case string(v1.CNAMERecordType):
+		if len(endpoints) > 1 || len(first.Targets) > 1 {
+			return nil, dnserrors.Errorf(dnserrors.ReasonInvalidRecord, "CNAME record %s must have a single target", first.DNSName)
+		}
+		rs.Properties.CNAMERecord = &cnameRecord{CNAME: first.Targets[0]}
+	case string(v1.TXTRecordType):
+		// Azure DNS expects the TXT values unquoted
+		for _, endpoint := range endpoints {
+			for _, target := range endpoint.Targets {
+				rs.Properties.TXTRecords = append(rs.Properties.TXTRecords, txtRecord{Value: []string{unquote(target)}})
+			}
+		}
+	case string(v1.CAARecordType):
+		for _, endpoint := range endpoints {
+			for _, target := range endpoint.Targets {
+				flags, tag, value, err := v1.ParseCAATarget(target)
+				if err != nil {
+					return nil, dnserrors.Errorf(dnserrors.ReasonInvalidRecord, "%v", err)
+				}
+				rs.Properties.CAARecords = append(rs.Properties.CAARecords, caaRecord{Flags: int(flags), Tag: tag, Value: value})
+			}
+		}
+	case string(v1.ARecordType):
+		for _, target := range p.weightedTargets(endpoints) {
+			rs.Properties.ARecords = append(rs.Properties.ARecords, aRecord{IPv4Address: target})
+		}
+	case string(v1.AAAARecordType):
+		for _, target := range p.weightedTargets(endpoints) {
+			rs.Properties.AAAARecords = append(rs.Properties.AAAARecords, aaaaRecord{IPv6Address: target})
+		}
+	}
+
+	return rs, nil
+}
+
+// weightedTargets returns the distinct targets of the endpoints that are
+// expected to receive traffic, as Azure DNS has no weighted record sets.
+func (p *Provider) weightedTargets(endpoints []*v1.Endpoint) []string {
+	sort.SliceStable(endpoints, func(i, j int) bool {
+		return endpoints[i].SetID() < endpoints[j].SetID()
+	})
+	var targets []string
+	seen := map[string]struct{}{}
+	for _, endpoint := range endpoints {
+		if !p.hasWeight(endpoint) {
+			continue
+		}
+		for _, target := range endpoint.Targets {
+			if _, ok := seen[target]; ok {
+				continue
+			}
+			seen[target] = struct{}{}
+			targets = append(targets, target)
+		}
+	}
+	// All the endpoints have a weight of 0, fallback to publish all of them
+	if len(targets) == 0 {
+		for _, endpoint := range endpoints {
+			targets = append(targets, endpoint.Targets...)
+		}
+	}
+	return targets
+}
+
+// hasWeight returns false if the endpoint has been given a weight of 0, and
+// is therefore not expected to receive traffic.
+func (p *Provider) hasWeight(endpoint *v1.Endpoint) bool {
+	if value, ok := endpoint.GetProviderSpecific(ProviderSpecificWeight); ok {
+		weight, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			p.logger.Error(err, "Failed parsing value, using weight of 0", "weight", ProviderSpecificWeight, "value", value)
+			return false
+		}
+		return weight > 0
+	}
+	if policy := endpoint.GetRoutingPolicy(); policy != nil && policy.Weighted != nil {
+		return policy.Weighted.Weight > 0
+	}
+	return true
+}
+
+func validateEndpoint(endpoint *v1.Endpoint) error {
+	switch endpoint.RecordType {
+	case string(v1.ARecordType), string(v1.AAAARecordType), string(v1.CNAMERecordType), string(v1.TXTRecordType), string(v1.CAARecordType):
+	default:
+		return dnserrors.Errorf(dnserrors.ReasonInvalidRecord, "unsupported record type %s", endpoint.RecordType)
+	}
+	if len(endpoint.DNSName) == 0 {
+		return dnserrors.Errorf(dnserrors.ReasonInvalidRecord, "domain is required")
+	}
+	if len(endpoint.Targets) == 0 {
+		return dnserrors.Errorf(dnserrors.ReasonInvalidRecord, "targets is required")
+	}
+	return nil
+}
+
+// unquote returns the TXT value without its surrounding quotes, if any.
+func unquote(value string) string {
+	if unquoted, err := strconv.Unquote(value); err == nil {
+		return unquoted
+	}
+	return value
+}
+
+// zoneNameFromID returns the DNS name of the zone from its resource ID.
+func zoneNameFromID(zoneID string) string {
+	parts := strings.Split(strings.Trim(zoneID, "/"), "/")
+	return strings.ToLower(parts[len(parts)-1])
+}
+
+// relativeName returns the name of the record set relative to the zone, as
+// expected by Azure DNS, where the zone apex is represented by "@".
+func relativeName(dnsName, zoneName string) (string, error) {
+	name := strings.ToLower(strings.TrimSuffix(dnsName, "."))
+	if name == zoneName {
+		return "@", nil
+	}
+	if !strings.HasSuffix(name, "."+zoneName) {
+		return "", dnserrors.Errorf(dnserrors.ReasonInvalidRecord, "domain %s is not part of zone %s", dnsName, zoneName)
+	}
+	return strings.TrimSuffix(name, "."+zoneName), nil
+}
+
+func endpointsFromZoneStatus(record *v1.DNSRecord, zoneID string) []*v1.Endpoint {
+	for _, zoneStatus := range record.Status.Zones {
+		if zoneStatus.DNSZone.ID == zoneID {
+			return zoneStatus.Endpoints
+		}
+	}
+	return []*v1.Endpoint{}
+}
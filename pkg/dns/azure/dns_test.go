@@ -0,0 +1,225 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/onsi/gomega"
+
+	v1 "github.com/kuadrant/kcp-glbc/pkg/apis/kuadrant/v1"
+)
+
+const testZoneID = "/subscriptions/sub/resourceGroups/group/providers/Microsoft.Network/dnszones/example.com"
+
+// fakeAzureDNS is a minimal in-memory stand-in for the Azure DNS REST API.
+type fakeAzureDNS struct {
+	mu         sync.Mutex
+	recordSets map[string]recordSet
+}
+
+func (f *fakeAzureDNS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.URL.Query().Get("api-version") != dnsAPIVersion {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	key := strings.TrimPrefix(r.URL.Path, testZoneID+"/")
+
+	switch r.Method {
+	case http.MethodPut:
+		rs := recordSet{}
+		if err := json.NewDecoder(r.Body).Decode(&rs); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.recordSets[key] = rs
+	case http.MethodDelete:
+		if _, ok := f.recordSets[key]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":{"code":"NotFound","message":"not found"}}`))
+			return
+		}
+		delete(f.recordSets, key)
+	}
+}
+
+func newTestProvider(t *testing.T) (*Provider, *fakeAzureDNS) {
+	fake := &fakeAzureDNS{recordSets: map[string]recordSet{}}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	provider, err := NewProvider(Config{
+		Endpoint:   server.URL,
+		HTTPClient: server.Client(),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error creating provider: %v", err)
+	}
+	return provider, fake
+}
+
+func weightedEndpoint(ip string, weight int64) *v1.Endpoint {
+	return &v1.Endpoint{
+		DNSName:       "app.example.com",
+		RecordType:    string(v1.ARecordType),
+		RecordTTL:     60,
+		SetIdentifier: ip,
+		Targets:       v1.Targets{ip},
+		RoutingPolicy: &v1.RoutingPolicy{Weighted: &v1.WeightedRoutingPolicy{Weight: weight}},
+	}
+}
+
+func TestEnsureWeightedEndpoints(t *testing.T) {
+	g := gomega.NewWithT(t)
+	provider, fake := newTestProvider(t)
+	zone := v1.DNSZone{ID: testZoneID}
+
+	record := &v1.DNSRecord{
+		Spec: v1.DNSRecordSpec{
+			Endpoints: []*v1.Endpoint{
+				weightedEndpoint("10.0.0.3", 0),
+				weightedEndpoint("10.0.0.2", 60),
+				weightedEndpoint("10.0.0.1", 120),
+			},
+		},
+	}
+
+	g.Expect(provider.Ensure(context.TODO(), record, zone)).To(gomega.Succeed())
+	g.Expect(fake.recordSets).To(gomega.HaveLen(1))
+	g.Expect(fake.recordSets["A/app"].Properties).To(gomega.Equal(recordSetProperties{
+		TTL:      60,
+		ARecords: []aRecord{{IPv4Address: "10.0.0.1"}, {IPv4Address: "10.0.0.2"}},
+	}))
+}
+
+func TestEnsureDualStackEndpoints(t *testing.T) {
+	g := gomega.NewWithT(t)
+	provider, fake := newTestProvider(t)
+	zone := v1.DNSZone{ID: testZoneID}
+
+	ipv6 := weightedEndpoint("2001:db8::1", 120)
+	ipv6.RecordType = string(v1.AAAARecordType)
+	record := &v1.DNSRecord{
+		Spec: v1.DNSRecordSpec{
+			Endpoints: []*v1.Endpoint{weightedEndpoint("10.0.0.1", 120), ipv6},
+		},
+	}
+
+	g.Expect(provider.Ensure(context.TODO(), record, zone)).To(gomega.Succeed())
+	g.Expect(fake.recordSets).To(gomega.HaveLen(2))
+	g.Expect(fake.recordSets["A/app"].Properties.ARecords).To(gomega.Equal([]aRecord{{IPv4Address: "10.0.0.1"}}))
+	g.Expect(fake.recordSets["AAAA/app"].Properties).To(gomega.Equal(recordSetProperties{
+		TTL:         60,
+		AAAARecords: []aaaaRecord{{IPv6Address: "2001:db8::1"}},
+	}))
+}
+
+func TestEnsureCAAEndpoints(t *testing.T) {
+	g := gomega.NewWithT(t)
+	provider, fake := newTestProvider(t)
+	zone := v1.DNSZone{ID: testZoneID}
+
+	record := &v1.DNSRecord{
+		Spec: v1.DNSRecordSpec{
+			Endpoints: []*v1.Endpoint{{
+				DNSName:    "app.example.com",
+				RecordType: string(v1.CAARecordType),
+				RecordTTL:  300,
+				Targets:    v1.Targets{`0 issue "letsencrypt.org"`, `0 issuewild ";"`},
+			}},
+		},
+	}
+
+	g.Expect(provider.Ensure(context.TODO(), record, zone)).To(gomega.Succeed())
+	g.Expect(fake.recordSets["CAA/app"].Properties).To(gomega.Equal(recordSetProperties{
+		TTL:        300,
+		CAARecords: []caaRecord{{Tag: "issue", Value: "letsencrypt.org"}, {Tag: "issuewild", Value: ";"}},
+	}))
+
+	endpoint := endpointForRecordSet("app.example.com", "CAA", &recordSet{Properties: fake.recordSets["CAA/app"].Properties})
+	g.Expect(endpoint.Targets).To(gomega.Equal(record.Spec.Endpoints[0].Targets))
+}
+
+func TestEnsureRemovesStaleRecordSets(t *testing.T) {
+	g := gomega.NewWithT(t)
+	provider, fake := newTestProvider(t)
+	zone := v1.DNSZone{ID: testZoneID}
+
+	stale := &v1.Endpoint{
+		DNSName:    "example.com",
+		RecordType: string(v1.CNAMERecordType),
+		RecordTTL:  60,
+		Targets:    v1.Targets{"lb.example.net"},
+	}
+	record := &v1.DNSRecord{
+		Spec: v1.DNSRecordSpec{Endpoints: []*v1.Endpoint{stale}},
+	}
+	g.Expect(provider.Ensure(context.TODO(), record, zone)).To(gomega.Succeed())
+	g.Expect(fake.recordSets["CNAME/@"].Properties.CNAMERecord).To(gomega.Equal(&cnameRecord{CNAME: "lb.example.net"}))
+
+	record.Status.Zones = []v1.DNSZoneStatus{{DNSZone: zone, Endpoints: []*v1.Endpoint{stale}}}
+	record.Spec.Endpoints = []*v1.Endpoint{weightedEndpoint("10.0.0.1", 120)}
+	g.Expect(provider.Ensure(context.TODO(), record, zone)).To(gomega.Succeed())
+	g.Expect(fake.recordSets).To(gomega.HaveLen(1))
+	g.Expect(fake.recordSets).To(gomega.HaveKey("A/app"))
+}
+
+func TestDelete(t *testing.T) {
+	g := gomega.NewWithT(t)
+	provider, fake := newTestProvider(t)
+	zone := v1.DNSZone{ID: testZoneID}
+
+	record := &v1.DNSRecord{
+		Spec: v1.DNSRecordSpec{
+			Endpoints: []*v1.Endpoint{weightedEndpoint("10.0.0.1", 120)},
+		},
+	}
+	g.Expect(provider.Ensure(context.TODO(), record, zone)).To(gomega.Succeed())
+	g.Expect(provider.Delete(context.TODO(), record, zone)).To(gomega.Succeed())
+	g.Expect(fake.recordSets).To(gomega.BeEmpty())
+
+	// Deleting a record set that no longer exists is not an error
+	g.Expect(provider.Delete(context.TODO(), record, zone)).To(gomega.Succeed())
+}
+
+func TestEnsureInvalidEndpoints(t *testing.T) {
+	provider, _ := newTestProvider(t)
+	zone := v1.DNSZone{ID: testZoneID}
+
+	cases := []struct {
+		Name     string
+		Endpoint *v1.Endpoint
+		Error    string
+	}{
+		{
+			Name:     "unsupported record type",
+			Endpoint: &v1.Endpoint{DNSName: "app.example.com", RecordType: "SRV", Targets: v1.Targets{"x"}},
+			Error:    "unsupported record type SRV",
+		},
+		{
+			Name:     "domain outside of zone",
+			Endpoint: &v1.Endpoint{DNSName: "app.example.org", RecordType: "A", Targets: v1.Targets{"10.0.0.1"}},
+			Error:    "domain app.example.org is not part of zone example.com",
+		},
+		{
+			Name:     "CNAME with multiple targets",
+			Endpoint: &v1.Endpoint{DNSName: "app.example.com", RecordType: "CNAME", Targets: v1.Targets{"a.example.net", "b.example.net"}},
+			Error:    "CNAME record app.example.com must have a single target",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+			record := &v1.DNSRecord{Spec: v1.DNSRecordSpec{Endpoints: []*v1.Endpoint{tc.Endpoint}}}
+			g.Expect(provider.Ensure(context.TODO(), record, zone)).To(gomega.MatchError(tc.Error))
+		})
+	}
+}
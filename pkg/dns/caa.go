@@ -0,0 +1,264 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/kuadrant/kcp-glbc/pkg/apis/kuadrant/v1"
+)
+
+// CAAMode is where the CAA records, that only authorize the configured
+// certificate authorities to issue certificates, are published.
+type CAAMode string
+
+const (
+	// CAAModeNone publishes no CAA records.
+	CAAModeNone CAAMode = "none"
+	// CAAModeHost publishes a CAA record per generated host.
+	CAAModeHost CAAMode = "host"
+	// CAAModeApex publishes a single CAA record at the managed domain, that
+	// applies to all the hosts of the domain.
+	CAAModeApex CAAMode = "apex"
+
+	caaRecordTTL = 300
+
+	// caaIssuersRecordPrefix is prepended to the managed domain, to get the
+	// name of the TXT record holding the issuers the GLBC added to the CAA
+	// record of the domain.
+	caaIssuersRecordPrefix = "_glbc-caa."
+
+	// caaRefreshInterval is the interval at which the CAA record of the
+	// managed domain is reconciled, e.g. after a change by hand.
+	caaRefreshInterval = 10 * time.Minute
+)
+
+// ParseCAAMode returns the CAA mode of the given name, the empty name being
+// the none mode.
+func ParseCAAMode(name string) (CAAMode, error) {
+	switch mode := CAAMode(name); mode {
+	case "":
+		return CAAModeNone, nil
+	case CAAModeNone, CAAModeHost, CAAModeApex:
+		return mode, nil
+	}
+	return "", fmt.Errorf("invalid CAA mode %q, must be one of [%s, %s, %s]", name, CAAModeNone, CAAModeHost, CAAModeApex)
+}
+
+// DefaultCAAIssuers returns the domains of the certificate authorities of the
+// TLS provider, that are authorized in the CAA records. The CA of glbc-ca is
+// self-signed, and has no domain.
+func DefaultCAAIssuers(tlsProvider string) []string {
+	switch tlsProvider {
+	case "le-staging", "le-production":
+		return []string{"letsencrypt.org"}
+	}
+	return nil
+}
+
+// CAAEndpoint returns the endpoint of the CAA record of the name, that only
+// authorizes the issuers to issue certificates for it.
+func CAAEndpoint(name string, issuers []string) *v1.Endpoint {
+	endpoint := &v1.Endpoint{
+		DNSName:    name,
+		RecordType: string(v1.CAARecordType),
+		RecordTTL:  caaRecordTTL,
+	}
+	for _, issuer := range issuers {
+		endpoint.Targets = append(endpoint.Targets, v1.NewCAATarget(0, v1.CAATagIssue, issuer))
+	}
+	return endpoint
+}
+
+// publishApexCAA reconciles the CAA record of the managed domain in the public
+// zone the domain belongs to. The CAA record set of the domain may already hold
+// the issuers of others, e.g. amazon.com for ACM, so the issuers are merged
+// into it rather than replacing it, and the issuers added by the GLBC are
+// recorded in a companion TXT record, so that only they are removed once the
+// apex mode is disabled, i.e. when no issuers are set. Neither record has an
+// ownership record, as they are not owned by any DNSRecord, so that the audits
+// do not report them as orphaned.
+func (c *Controller) publishApexCAA(ctx context.Context) {
+	zones, err := resolveZones(ctx, c.dnsProvider, c.dnsZones)
+	if err != nil {
+		c.Logger.Error(err, "Failed to resolve DNS zones of the CAA record")
+		return
+	}
+	i := zoneForName(zones, c.caaDomain, false)
+	if i < 0 {
+		if len(c.caaIssuers) > 0 {
+			c.Logger.Info("No DNS zone for the CAA record of the managed domain, skipping", "domain", c.caaDomain)
+		}
+		return
+	}
+	zone := zones[i].DNSZone
+
+	markers, err := c.dnsProvider.GetRecords(ctx, zone, caaIssuersRecordName(c.caaDomain), string(v1.TXTRecordType))
+	if err != nil {
+		c.Logger.Error(err, "Failed to get the CAA issuers record of the managed domain", "domain", c.caaDomain, "zone", zone.ID)
+		return
+	}
+	if len(markers) == 0 && len(c.caaIssuers) == 0 {
+		return
+	}
+	existing, err := c.dnsProvider.GetRecords(ctx, zone, c.caaDomain, string(v1.CAARecordType))
+	if err != nil {
+		c.Logger.Error(err, "Failed to get the CAA record of the managed domain", "domain", c.caaDomain, "zone", zone.ID)
+		return
+	}
+
+	var current []string
+	for _, endpoint := range existing {
+		current = append(current, endpoint.Targets...)
+	}
+	targets, added := mergeCAATargets(current, parseCAAIssuers(markers), c.caaIssuers)
+
+	var ensured, deleted []*v1.Endpoint
+	switch {
+	case len(targets) == 0:
+		deleted = append(deleted, existing...)
+	case !sameCAATargets(current, targets):
+		endpoint := CAAEndpoint(c.caaDomain, nil)
+		endpoint.Targets = targets
+		if len(existing) > 0 {
+			endpoint.RecordTTL = existing[0].RecordTTL
+		}
+		ensured = append(ensured, endpoint)
+	}
+	switch {
+	case len(added) > 0:
+		marker := caaIssuersEndpoint(c.caaDomain, added)
+		if len(markers) != 1 || !equality.Semantic.DeepEqual(markers[0].Targets, marker.Targets) {
+			deleted = append(deleted, markers...)
+			ensured = append(ensured, marker)
+		}
+	case len(markers) > 0:
+		deleted = append(deleted, markers...)
+	}
+
+	if len(deleted) > 0 {
+		if err := c.dnsProvider.Delete(ctx, caaRecord(deleted), zone); err != nil {
+			c.Logger.Error(err, "Failed to delete the CAA record of the managed domain", "domain", c.caaDomain, "zone", zone.ID)
+			return
+		}
+	}
+	if len(ensured) > 0 {
+		if err := c.dnsProvider.Ensure(ctx, caaRecord(ensured), zone); err != nil {
+			c.Logger.Error(err, "Failed to publish the CAA record of the managed domain", "domain", c.caaDomain, "zone", zone.ID)
+			return
+		}
+	}
+	c.Logger.V(3).Info("Reconciled the CAA record of the managed domain", "domain", c.caaDomain, "zone", zone.ID, "targets", targets, "added", added)
+}
+
+// mergeCAATargets returns the targets of the CAA record set holding the
+// current targets, without the ones of the issuers previously added, along
+// with the given issuers, and the issuers that are added to it, i.e. that it
+// did not already hold.
+func mergeCAATargets(current, previouslyAdded, issuers []string) ([]string, []string) {
+	removed := map[string]struct{}{}
+	for _, issuer := range previouslyAdded {
+		removed[caaIssueTarget(issuer)] = struct{}{}
+	}
+	var targets []string
+	seen := map[string]struct{}{}
+	for _, target := range current {
+		key := canonicalCAATarget(target)
+		if _, ok := removed[key]; ok {
+			continue
+		}
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		targets = append(targets, target)
+	}
+	var added []string
+	for _, issuer := range issuers {
+		target := caaIssueTarget(issuer)
+		if _, ok := seen[target]; ok {
+			continue
+		}
+		seen[target] = struct{}{}
+		targets = append(targets, target)
+		added = append(added, issuer)
+	}
+	return targets, added
+}
+
+// sameCAATargets returns whether the CAA targets are the same, regardless of
+// their order and presentation.
+func sameCAATargets(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	set := map[string]struct{}{}
+	for _, target := range a {
+		set[canonicalCAATarget(target)] = struct{}{}
+	}
+	for _, target := range b {
+		if _, ok := set[canonicalCAATarget(target)]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func caaIssueTarget(issuer string) string {
+	return v1.NewCAATarget(0, v1.CAATagIssue, issuer)
+}
+
+// canonicalCAATarget returns the target in the presentation format of
+// NewCAATarget, as the providers may return it differently, e.g. unquoted.
+func canonicalCAATarget(target string) string {
+	flags, tag, value, err := v1.ParseCAATarget(target)
+	if err != nil {
+		return target
+	}
+	return v1.NewCAATarget(flags, strings.ToLower(tag), value)
+}
+
+// caaIssuersEndpoint returns the TXT record holding the issuers the GLBC added
+// to the CAA record of the name.
+func caaIssuersEndpoint(name string, issuers []string) *v1.Endpoint {
+	return &v1.Endpoint{
+		DNSName:    caaIssuersRecordName(name),
+		RecordType: string(v1.TXTRecordType),
+		RecordTTL:  caaRecordTTL,
+		Targets:    v1.Targets{strconv.Quote(strings.Join(issuers, ","))},
+	}
+}
+
+// parseCAAIssuers returns the issuers held by the CAA issuers records.
+func parseCAAIssuers(endpoints []*v1.Endpoint) []string {
+	var issuers []string
+	for _, endpoint := range endpoints {
+		for _, target := range endpoint.Targets {
+			if unquoted, err := strconv.Unquote(target); err == nil {
+				target = unquoted
+			}
+			for _, issuer := range strings.Split(target, ",") {
+				if issuer != "" {
+					issuers = append(issuers, issuer)
+				}
+			}
+		}
+	}
+	return issuers
+}
+
+func caaIssuersRecordName(name string) string {
+	return caaIssuersRecordPrefix + name
+}
+
+func caaRecord(endpoints []*v1.Endpoint) *v1.DNSRecord {
+	return &v1.DNSRecord{
+		ObjectMeta: metav1.ObjectMeta{Name: "caa"},
+		Spec:       v1.DNSRecordSpec{Endpoints: endpoints},
+	}
+}
@@ -0,0 +1,125 @@
+package dns
+
+import (
+	"context"
+	"testing"
+
+	"github.com/onsi/gomega"
+
+	"github.com/kuadrant/kcp-glbc/pkg/_internal/log"
+	v1 "github.com/kuadrant/kcp-glbc/pkg/apis/kuadrant/v1"
+	"github.com/kuadrant/kcp-glbc/pkg/reconciler"
+)
+
+func TestParseCAAMode(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	mode, err := ParseCAAMode("")
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(mode).To(gomega.Equal(CAAModeNone))
+
+	mode, err = ParseCAAMode("apex")
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(mode).To(gomega.Equal(CAAModeApex))
+
+	_, err = ParseCAAMode("zone")
+	g.Expect(err).To(gomega.MatchError(`invalid CAA mode "zone", must be one of [none, host, apex]`))
+
+	g.Expect(DefaultCAAIssuers("le-production")).To(gomega.Equal([]string{"letsencrypt.org"}))
+	g.Expect(DefaultCAAIssuers("glbc-ca")).To(gomega.BeEmpty())
+}
+
+func TestMergeCAATargets(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	// The issuers are added to the ones of others
+	targets, added := mergeCAATargets([]string{`0 issue "amazon.com"`}, nil, []string{"letsencrypt.org"})
+	g.Expect(targets).To(gomega.Equal([]string{`0 issue "amazon.com"`, `0 issue "letsencrypt.org"`}))
+	g.Expect(added).To(gomega.Equal([]string{"letsencrypt.org"}))
+
+	// The issuers already authorized are not recorded as added, regardless
+	// of the presentation of their targets
+	targets, added = mergeCAATargets([]string{`0 issue amazon.com`, `0 issue "letsencrypt.org"`}, nil, []string{"letsencrypt.org", "pki.goog"})
+	g.Expect(targets).To(gomega.Equal([]string{`0 issue amazon.com`, `0 issue "letsencrypt.org"`, `0 issue "pki.goog"`}))
+	g.Expect(added).To(gomega.Equal([]string{"pki.goog"}))
+
+	// The issuers previously added are removed once no longer set, the ones
+	// of others are left untouched
+	targets, added = mergeCAATargets([]string{`0 issue "amazon.com"`, `0 issue "letsencrypt.org"`, `0 issue "pki.goog"`}, []string{"letsencrypt.org", "pki.goog"}, []string{"pki.goog"})
+	g.Expect(targets).To(gomega.Equal([]string{`0 issue "amazon.com"`, `0 issue "pki.goog"`}))
+	g.Expect(added).To(gomega.Equal([]string{"pki.goog"}))
+
+	targets, added = mergeCAATargets([]string{`0 issue "letsencrypt.org"`}, []string{"letsencrypt.org"}, nil)
+	g.Expect(targets).To(gomega.BeEmpty())
+	g.Expect(added).To(gomega.BeEmpty())
+}
+
+func TestPublishApexCAA(t *testing.T) {
+	g := gomega.NewWithT(t)
+	provider := newTestMemoryProvider(t)
+	zone := v1.DNSZone{ID: "hcpapps.net"}
+	c := &Controller{
+		Controller:  &reconciler.Controller{Logger: log.Logger},
+		dnsProvider: provider,
+		dnsZones: []Zone{
+			{DNSZone: zone, Domain: "hcpapps.net"},
+			{DNSZone: v1.DNSZone{ID: "private"}, Domain: "hcpapps.net", Private: true},
+		},
+		caaDomain:  "dev.hcpapps.net",
+		caaIssuers: []string{"letsencrypt.org", "pki.goog"},
+	}
+	caaTargets := func() []string {
+		endpoints, err := provider.GetRecords(context.TODO(), zone, "dev.hcpapps.net", string(v1.CAARecordType))
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		if len(endpoints) == 0 {
+			return nil
+		}
+		g.Expect(endpoints).To(gomega.HaveLen(1))
+		return endpoints[0].Targets
+	}
+	issuersRecord := func() []*v1.Endpoint {
+		endpoints, err := provider.GetRecords(context.TODO(), zone, caaIssuersRecordName("dev.hcpapps.net"), string(v1.TXTRecordType))
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		return endpoints
+	}
+
+	// An issuer authorized by someone else, e.g. for ACM
+	existing := CAAEndpoint("dev.hcpapps.net", []string{"amazon.com"})
+	g.Expect(provider.Ensure(context.TODO(), caaRecord([]*v1.Endpoint{existing}), zone)).To(gomega.Succeed())
+
+	// The CAA record is reconciled again, e.g. after a change by hand
+	for i := 0; i < 2; i++ {
+		if i > 0 {
+			g.Expect(provider.Ensure(context.TODO(), caaRecord([]*v1.Endpoint{existing}), zone)).To(gomega.Succeed())
+		}
+		c.publishApexCAA(context.TODO())
+
+		g.Expect(caaTargets()).To(gomega.Equal([]string{`0 issue "amazon.com"`, `0 issue "letsencrypt.org"`, `0 issue "pki.goog"`}))
+		g.Expect(issuersRecord()).To(gomega.HaveLen(1))
+		g.Expect(issuersRecord()[0].Targets).To(gomega.Equal(v1.Targets{`"letsencrypt.org,pki.goog"`}))
+
+		// No ownership record is published, the CAA record is not owned by
+		// any DNSRecord
+		txt, err := provider.GetRecords(context.TODO(), zone, ownershipRecordName("dev.hcpapps.net"), string(v1.TXTRecordType))
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		g.Expect(txt).To(gomega.BeEmpty())
+	}
+
+	// Only the issuers added by the GLBC are removed once the apex mode is
+	// disabled
+	c.caaIssuers = nil
+	c.publishApexCAA(context.TODO())
+	g.Expect(caaTargets()).To(gomega.Equal([]string{`0 issue "amazon.com"`}))
+	g.Expect(issuersRecord()).To(gomega.BeEmpty())
+
+	// The CAA record is deleted when it only holds the issuers added by the
+	// GLBC
+	g.Expect(provider.Delete(context.TODO(), caaRecord([]*v1.Endpoint{existing}), zone)).To(gomega.Succeed())
+	c.caaIssuers = []string{"letsencrypt.org"}
+	c.publishApexCAA(context.TODO())
+	g.Expect(caaTargets()).To(gomega.Equal([]string{`0 issue "letsencrypt.org"`}))
+	c.caaIssuers = nil
+	c.publishApexCAA(context.TODO())
+	g.Expect(caaTargets()).To(gomega.BeEmpty())
+	g.Expect(issuersRecord()).To(gomega.BeEmpty())
+}
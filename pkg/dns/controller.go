@@ -2,10 +2,15 @@ package dns
 
 import (
 	"context"
+	"fmt"
 	"os"
+	"reflect"
+	"sync"
+	"time"
 
 	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
 
@@ -18,7 +23,14 @@ import (
 	"github.com/kuadrant/kcp-glbc/pkg/reconciler"
 )
 
-const defaultControllerName = "kcp-glbc-dns"
+const (
+	defaultControllerName = "kcp-glbc-dns"
+
+	// zoneRefreshInterval is the interval at which the zones given by their
+	// tags are checked for changes. The providers cache the resolved zones
+	// according to their own refresh interval.
+	zoneRefreshInterval = time.Minute
+)
 
 // NewController returns a new Controller which reconciles DNSRecord.
 func NewController(config *ControllerConfig) (*Controller, error) {
@@ -31,24 +43,44 @@ func NewController(config *ControllerConfig) (*Controller, error) {
 	}
 	c.Process = c.process
 
-	dnsProvider, err := DNSProvider(config.DNSProvider)
-	if err != nil {
-		return nil, err
+	if config.Registry == nil {
+		return nil, fmt.Errorf("no DNS registry set")
 	}
+	dnsProvider := config.Registry.provider
 	c.dnsProvider = dnsProvider
+	c.registry = config.Registry
 
-	var dnsZones []v1.DNSZone
-	zoneID, zoneIDSet := os.LookupEnv("AWS_DNS_PUBLIC_ZONE_ID")
-	if zoneIDSet {
-		dnsZone := &v1.DNSZone{
-			ID: zoneID,
+	dnsZones := config.Zones
+	if len(dnsZones) == 0 {
+		// Fallback to the single zone of the provider, that all the names are published to
+		zones, err := DefaultZones(config.DNSProvider)
+		if err != nil {
+			return nil, err
 		}
-		dnsZones = append(dnsZones, *dnsZone)
-		c.Logger.Info("Using AWS DNS zone", "id", zoneID)
-	} else {
-		c.Logger.Info("No AWS DNS zone id set (AWS_DNS_PUBLIC_ZONE_ID), no DNS records will be created!")
+		if len(zones) == 0 {
+			c.Logger.Info(fmt.Sprintf("No DNS zone id set (%s), no DNS records will be created!", ZoneIDEnvVar(config.DNSProvider)))
+		}
+		dnsZones = zones
+	}
+	for _, zone := range dnsZones {
+		c.Logger.Info("Using DNS zone", "provider", config.DNSProvider, "id", zone.ID, "tags", zone.Tags, "domain", zone.Domain)
 	}
 	c.dnsZones = dnsZones
+	c.caaDomain = config.CAADomain
+	c.caaIssuers = config.CAAIssuers
+
+	// Resolve the zones given by their tags upfront, so that a misconfiguration
+	// is reported at startup. They are resolved again on reconciliation.
+	if resolved, err := resolveZones(context.TODO(), dnsProvider, dnsZones); err != nil {
+		c.Logger.Error(err, "Failed to resolve DNS zones")
+	} else {
+		for i, zone := range resolved {
+			if len(dnsZones[i].Tags) > 0 {
+				c.Logger.Info("Resolved DNS zone", "tags", zone.Tags, "id", zone.ID, "domain", zone.Domain)
+			}
+		}
+		c.resolvedZones = resolved
+	}
 
 	//Logging state of AWS credentials
 	awsIdKey := os.Getenv("AWS_ACCESS_KEY_ID")
@@ -75,8 +107,26 @@ func NewController(config *ControllerConfig) (*Controller, error) {
 		DeleteFunc: func(obj interface{}) { c.Enqueue(obj) },
 	})
 
+	// The DNSRecords are published again when the health checks, or the
+	// health, of their endpoints change, as reported by the HealthChecks
+	healthCheckInformer := c.sharedInformerFactory.Kuadrant().V1().HealthChecks().Informer()
+	if err := addHealthCheckTargetIndex(healthCheckInformer); err != nil {
+		return nil, err
+	}
+	healthCheckInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) { c.enqueueTargetRecord(obj) },
+		UpdateFunc: func(old, obj interface{}) {
+			if !equality.Semantic.DeepEqual(old.(*v1.HealthCheck).Status.HealthChecks, obj.(*v1.HealthCheck).Status.HealthChecks) ||
+				!equality.Semantic.DeepEqual(old.(*v1.HealthCheck).Status.Endpoints, obj.(*v1.HealthCheck).Status.Endpoints) {
+				c.enqueueTargetRecord(obj)
+			}
+		},
+		DeleteFunc: func(obj interface{}) { c.enqueueTargetRecord(obj) },
+	})
+
 	c.indexer = c.sharedInformerFactory.Kuadrant().V1().DNSRecords().Informer().GetIndexer()
 	c.lister = c.sharedInformerFactory.Kuadrant().V1().DNSRecords().Lister()
+	c.healthCheckIndexer = healthCheckInformer.GetIndexer()
 
 	return c, nil
 }
@@ -85,7 +135,23 @@ type ControllerConfig struct {
 	*reconciler.ControllerConfig
 	DnsRecordClient       kuadrantv1.ClusterInterface
 	SharedInformerFactory externalversions.SharedInformerFactory
-	DNSProvider           string
+	// DNSProvider is the name of the DNS provider, that the zone environment
+	// variables are looked up for.
+	DNSProvider string
+	// Registry publishes the records with the DNS provider. It is shared by the
+	// controllers of all the APIExports, so that they share a single provider
+	// instance and the zone state it holds.
+	Registry *Registry
+	// Zones are the DNS zones the records are published to, the names of the
+	// records being published to the zone whose domain is their longest suffix.
+	// Defaults to the zone set in the zone ID environment variable of the
+	// provider, that all the names are published to.
+	Zones []Zone
+	// CAADomain is the domain the CAA record authorizing the CAAIssuers is
+	// published at, if any. The issuers previously added to its CAA record are
+	// removed when no CAAIssuers are set.
+	CAADomain  string
+	CAAIssuers []string
 }
 
 type Controller struct {
@@ -94,8 +160,110 @@ type Controller struct {
 	dnsRecordClient       kuadrantv1.ClusterInterface
 	indexer               cache.Indexer
 	lister                kuadrantv1lister.DNSRecordLister
-	dnsProvider           Provider
-	dnsZones              []v1.DNSZone
+	// healthCheckIndexer holds the HealthChecks, indexed by the DNSRecord
+	// they target.
+	healthCheckIndexer cache.Indexer
+	dnsProvider        Provider
+	registry           *Registry
+	dnsZones           []Zone
+	// resolvedZones are the zones last resolved by refreshZones.
+	resolvedZones []Zone
+	caaDomain     string
+	caaIssuers    []string
+	// drifted holds the keys of the DNSRecords whose records have drifted in
+	// the zones, as found by the Auditor, to be published again.
+	driftMu sync.Mutex
+	drifted map[string]struct{}
+}
+
+// Provider returns the DNS provider the records are published with.
+func (c *Controller) Provider() Provider {
+	return c.dnsProvider
+}
+
+// enqueueTargetRecord enqueues the DNSRecord the HealthCheck targets.
+func (c *Controller) enqueueTargetRecord(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	healthCheck, ok := obj.(*v1.HealthCheck)
+	if !ok {
+		return
+	}
+	key, err := healthCheckTargetKey(healthCheck)
+	if err != nil {
+		c.Logger.Error(err, "Failed to get key of DNSRecord targeted by HealthCheck", "healthCheck", healthCheck.Name)
+		return
+	}
+	c.Queue.Add(key)
+}
+
+// markDrifted requeues the DNSRecord, for its records to be published again
+// regardless of its status.
+func (c *Controller) markDrifted(record *v1.DNSRecord) {
+	key, err := cache.MetaNamespaceKeyFunc(record)
+	if err != nil {
+		c.Logger.Error(err, "Failed to get key of drifted DNSRecord", "record", record.Name)
+		return
+	}
+	c.driftMu.Lock()
+	if c.drifted == nil {
+		c.drifted = map[string]struct{}{}
+	}
+	c.drifted[key] = struct{}{}
+	c.driftMu.Unlock()
+	c.Queue.Add(key)
+}
+
+// takeDrifted returns whether the DNSRecord was marked as drifted, and clears
+// the mark.
+func (c *Controller) takeDrifted(record *v1.DNSRecord) bool {
+	key, err := cache.MetaNamespaceKeyFunc(record)
+	if err != nil {
+		return false
+	}
+	c.driftMu.Lock()
+	defer c.driftMu.Unlock()
+	_, ok := c.drifted[key]
+	delete(c.drifted, key)
+	return ok
+}
+
+// Start runs the workers, along with the periodic refresh of the zones given
+// by their tags, if any, and the periodic publication of the CAA record of the
+// managed domain, if any.
+func (c *Controller) Start(ctx context.Context, numThreads int) {
+	if c.caaDomain != "" {
+		go wait.UntilWithContext(ctx, c.publishApexCAA, caaRefreshInterval)
+	}
+	for _, zone := range c.dnsZones {
+		if len(zone.Tags) > 0 {
+			go wait.UntilWithContext(ctx, c.refreshZones, zoneRefreshInterval)
+			break
+		}
+	}
+	c.Controller.Start(ctx, numThreads)
+}
+
+// refreshZones resolves the zones given by their tags, and requeues all the
+// DNSRecords when one of them has changed, e.g. because it was recreated, so
+// that they are published to the new zone.
+func (c *Controller) refreshZones(ctx context.Context) {
+	resolved, err := resolveZones(ctx, c.dnsProvider, c.dnsZones)
+	if err != nil {
+		c.Logger.Error(err, "Failed to refresh DNS zones")
+		return
+	}
+	if reflect.DeepEqual(resolved, c.resolvedZones) {
+		return
+	}
+	for _, zone := range resolved {
+		c.Logger.Info("Using DNS zone", "id", zone.ID, "tags", zone.Tags, "domain", zone.Domain)
+	}
+	c.resolvedZones = resolved
+	for _, obj := range c.indexer.List() {
+		c.Enqueue(obj)
+	}
 }
 
 func (c *Controller) process(ctx context.Context, key string) error {
@@ -0,0 +1,62 @@
+package dns
+
+import (
+	"context"
+	"testing"
+
+	"github.com/onsi/gomega"
+
+	v1 "github.com/kuadrant/kcp-glbc/pkg/apis/kuadrant/v1"
+	"github.com/kuadrant/kcp-glbc/pkg/client/kuadrant/clientset/versioned/fake"
+	"github.com/kuadrant/kcp-glbc/pkg/client/kuadrant/informers/externalversions"
+	"github.com/kuadrant/kcp-glbc/pkg/reconciler"
+)
+
+func newTestController(t *testing.T, name string, registry *Registry, zones []Zone) *Controller {
+	client := fake.NewSimpleClientset()
+	c, err := NewController(&ControllerConfig{
+		ControllerConfig:      &reconciler.ControllerConfig{NameSuffix: name},
+		DnsRecordClient:       &fakeClusterClient{client},
+		SharedInformerFactory: externalversions.NewSharedInformerFactory(client, 0),
+		DNSProvider:           "memory",
+		Registry:              registry,
+		Zones:                 zones,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error creating controller: %v", err)
+	}
+	t.Cleanup(c.Queue.ShutDown)
+	return c
+}
+
+func TestControllersShareProvider(t *testing.T) {
+	g := gomega.NewWithT(t)
+	provider := newTestMemoryProvider(t)
+	registry := NewRegistry("glbc-1", provider)
+	zone := v1.DNSZone{ID: "example.com"}
+	zones := []Zone{{DNSZone: zone, Domain: "example.com"}}
+
+	// The controllers of two APIExports publish with the same provider
+	first := newTestController(t, "first", registry, zones)
+	second := newTestController(t, "second", registry, zones)
+	g.Expect(first.Provider()).To(gomega.BeIdenticalTo(provider))
+	g.Expect(second.Provider()).To(gomega.BeIdenticalTo(provider))
+
+	app := newTestRecord("app", aEndpoint("app.example.com", "10.0.0.1"))
+	g.Expect(first.reconcile(context.TODO(), app)).To(gomega.Succeed())
+	other := newTestRecord("other", aEndpoint("other.example.com", "10.0.0.2"))
+	g.Expect(second.reconcile(context.TODO(), other)).To(gomega.Succeed())
+
+	// Both records are published to the same zone state
+	g.Expect(provider.GetRecords(context.TODO(), zone, "app.example.com", string(v1.ARecordType))).To(gomega.HaveLen(1))
+	g.Expect(provider.GetRecords(context.TODO(), zone, "other.example.com", string(v1.ARecordType))).To(gomega.HaveLen(1))
+
+	// The names published by one controller are seen as owned by the other
+	conflicting := newTestRecord("conflicting", aEndpoint("app.example.com", "10.0.0.3"))
+	g.Expect(second.reconcile(context.TODO(), conflicting)).To(gomega.Succeed())
+	g.Expect(RecordIsPublished(conflicting)).To(gomega.BeFalse())
+	endpoints, err := provider.GetRecords(context.TODO(), zone, "app.example.com", string(v1.ARecordType))
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(endpoints).To(gomega.HaveLen(1))
+	g.Expect(endpoints[0].Targets).To(gomega.Equal(v1.Targets{"10.0.0.1"}))
+}
@@ -1,25 +1,77 @@
 package dns
 
 import (
+	"context"
+
 	v1 "github.com/kuadrant/kcp-glbc/pkg/apis/kuadrant/v1"
 )
 
 // Provider knows how to manage DNS zones only as pertains to routing.
 type Provider interface {
 	// Ensure will create or update record.
-	Ensure(record *v1.DNSRecord, zone v1.DNSZone) error
+	Ensure(ctx context.Context, record *v1.DNSRecord, zone v1.DNSZone) error
 
 	// Delete will delete record.
-	Delete(record *v1.DNSRecord, zone v1.DNSZone) error
+	Delete(ctx context.Context, record *v1.DNSRecord, zone v1.DNSZone) error
+
+	// GetRecords returns the records of the zone with the given name and type.
+	GetRecords(ctx context.Context, zone v1.DNSZone, dnsName, recordType string) ([]*v1.Endpoint, error)
+
+	// ListRecords returns all the records of the zone.
+	ListRecords(ctx context.Context, zone v1.DNSZone) ([]*v1.Endpoint, error)
 	// Get a health check reconciler for this provider
 	HealthCheckReconciler
 }
 
+// ZoneResolver is implemented by the providers that can find zones by their
+// tags.
+type ZoneResolver interface {
+	// ResolveZone returns the zone with the ID of the zone found by its tags.
+	ResolveZone(ctx context.Context, zone v1.DNSZone) (v1.DNSZone, error)
+}
+
+// PublicationChecker is implemented by the providers that publish the records
+// asynchronously, e.g. through external-dns, so that the records are only
+// reported as published once they are.
+type PublicationChecker interface {
+	// IsPublished returns whether the record ensured in the zone is published.
+	IsPublished(ctx context.Context, record *v1.DNSRecord, zone v1.DNSZone) (bool, error)
+}
+
+// OwnershipRegistrar is implemented by the providers whose records are
+// published by another controller that records the ownership of the names
+// itself, e.g. external-dns and its TXT registry. Their GetRecords and
+// ListRecords methods only return the records requested by the GLBC, rather
+// than the records of the zones, so the GLBC neither verifies nor records the
+// ownership of the names published with them, and does not audit their zones.
+type OwnershipRegistrar interface {
+	// RegistersOwnership returns whether the provider records the ownership
+	// of the names itself.
+	RegistersOwnership() bool
+}
+
+// RegistersOwnership returns whether the provider records the ownership of the
+// names itself.
+func RegistersOwnership(provider Provider) bool {
+	registrar, ok := provider.(OwnershipRegistrar)
+	return ok && registrar.RegistersOwnership()
+}
+
 var _ Provider = &FakeProvider{fakeHealthCheckReconciler: &fakeHealthCheckReconciler{}}
 
 type FakeProvider struct {
 	*fakeHealthCheckReconciler
 }
 
-func (_ *FakeProvider) Ensure(record *v1.DNSRecord, zone v1.DNSZone) error { return nil }
-func (_ *FakeProvider) Delete(record *v1.DNSRecord, zone v1.DNSZone) error { return nil }
+func (_ *FakeProvider) Ensure(ctx context.Context, record *v1.DNSRecord, zone v1.DNSZone) error {
+	return nil
+}
+func (_ *FakeProvider) Delete(ctx context.Context, record *v1.DNSRecord, zone v1.DNSZone) error {
+	return nil
+}
+func (_ *FakeProvider) GetRecords(ctx context.Context, zone v1.DNSZone, dnsName, recordType string) ([]*v1.Endpoint, error) {
+	return nil, nil
+}
+func (_ *FakeProvider) ListRecords(ctx context.Context, zone v1.DNSZone) ([]*v1.Endpoint, error) {
+	return nil, nil
+}
@@ -2,25 +2,129 @@ package dns
 
 import (
 	"fmt"
+	"os"
+	"strconv"
+	"time"
 
+	"github.com/kcp-dev/logicalcluster/v2"
+
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/kuadrant/kcp-glbc/pkg/_internal/env"
+	v1 "github.com/kuadrant/kcp-glbc/pkg/apis/kuadrant/v1"
 	dnsAWS "github.com/kuadrant/kcp-glbc/pkg/dns/aws"
+	dnsAzure "github.com/kuadrant/kcp-glbc/pkg/dns/azure"
+	dnsExternalDNS "github.com/kuadrant/kcp-glbc/pkg/dns/externaldns"
+	dnsGCP "github.com/kuadrant/kcp-glbc/pkg/dns/gcp"
+	dnsMemory "github.com/kuadrant/kcp-glbc/pkg/dns/memory"
+	dnsPlugin "github.com/kuadrant/kcp-glbc/pkg/dns/plugin"
+	dnsRFC2136 "github.com/kuadrant/kcp-glbc/pkg/dns/rfc2136"
 )
 
-func DNSProvider(dnsProviderName string) (Provider, error) {
+// DNSProvider returns the DNS provider with the given name. The owner ID
+// identifies the GLBC in the resources the provider creates, if any.
+func DNSProvider(dnsProviderName, ownerID string) (Provider, error) {
 	var dnsProvider Provider
 	var dnsError error
 	switch dnsProviderName {
 	case "aws":
-		dnsProvider, dnsError = newAWSDNSProvider()
+		dnsProvider, dnsError = newAWSDNSProvider(ownerID)
+	case "gcp":
+		dnsProvider, dnsError = newGCPDNSProvider()
+	case "azure":
+		dnsProvider, dnsError = newAzureDNSProvider()
+	case "rfc2136":
+		dnsProvider, dnsError = newRFC2136DNSProvider()
+	case "memory":
+		dnsProvider, dnsError = newMemoryDNSProvider()
+	case "grpc":
+		dnsProvider, dnsError = newPluginDNSProvider()
+	case "externaldns":
+		dnsProvider, dnsError = newExternalDNSProvider()
 	default:
 		dnsProvider = &FakeProvider{}
 	}
 	return dnsProvider, dnsError
 }
 
-func newAWSDNSProvider() (Provider, error) {
+// ZoneIDEnvVar returns the name of the environment variable holding the ID of
+// the managed zone for the given DNS provider.
+func ZoneIDEnvVar(dnsProviderName string) string {
+	switch dnsProviderName {
+	case "gcp":
+		return dnsGCP.ZoneIDEnvVar
+	case "azure":
+		return dnsAzure.ZoneIDEnvVar
+	case "rfc2136":
+		return dnsRFC2136.ZoneIDEnvVar
+	case "memory":
+		return dnsMemory.ZoneIDEnvVar
+	case "grpc":
+		return dnsPlugin.ZoneIDEnvVar
+	case "externaldns":
+		return dnsExternalDNS.ZoneIDEnvVar
+	default:
+		return dnsAWS.ZoneIDEnvVar
+	}
+}
+
+// ZoneTagsEnvVar returns the name of the environment variable holding the tags
+// of the managed zone for the given DNS provider, or an empty string if the
+// provider cannot find zones by tags.
+func ZoneTagsEnvVar(dnsProviderName string) string {
+	switch dnsProviderName {
+	case "gcp", "azure", "rfc2136", "memory", "grpc", "externaldns":
+		return ""
+	default:
+		return dnsAWS.ZoneTagsEnvVar
+	}
+}
+
+// DefaultZones returns the single zone of the given DNS provider set in its
+// environment variables, that all the names are published to when no zones are
+// configured, if any.
+func DefaultZones(dnsProviderName string) ([]Zone, error) {
+	zoneIDEnvVar := ZoneIDEnvVar(dnsProviderName)
+	zoneTagsEnvVar := ZoneTagsEnvVar(dnsProviderName)
+	if zoneID, zoneIDSet := os.LookupEnv(zoneIDEnvVar); zoneIDSet {
+		return []Zone{{DNSZone: v1.DNSZone{ID: zoneID}}}, nil
+	}
+	if zoneTags, zoneTagsSet := os.LookupEnv(zoneTagsEnvVar); zoneTagsEnvVar != "" && zoneTagsSet {
+		tags, err := ParseZoneTags(zoneTags)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value %q: %v", zoneTagsEnvVar, zoneTags, err)
+		}
+		return []Zone{{DNSZone: v1.DNSZone{Tags: tags}}}, nil
+	}
+	return nil, nil
+}
+
+func newAWSDNSProvider(ownerID string) (Provider, error) {
 	var dnsProvider Provider
-	provider, err := dnsAWS.NewProvider(dnsAWS.Config{})
+	config := dnsAWS.Config{OwnerID: ownerID}
+	if value, ok := os.LookupEnv(dnsAWS.RequestsPerSecondEnvVar); ok {
+		requestsPerSecond, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value %q: %v", dnsAWS.RequestsPerSecondEnvVar, value, err)
+		}
+		config.RequestsPerSecond = requestsPerSecond
+	}
+	if value, ok := os.LookupEnv(dnsAWS.BatchIntervalEnvVar); ok {
+		batchInterval, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value %q: %v", dnsAWS.BatchIntervalEnvVar, value, err)
+		}
+		config.BatchInterval = batchInterval
+	}
+	if value, ok := os.LookupEnv(dnsAWS.ZoneRefreshIntervalEnvVar); ok {
+		zoneRefreshInterval, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value %q: %v", dnsAWS.ZoneRefreshIntervalEnvVar, value, err)
+		}
+		config.ZoneRefreshInterval = zoneRefreshInterval
+	}
+	provider, err := dnsAWS.NewProvider(config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create AWS DNS manager: %v", err)
 	}
@@ -28,3 +132,120 @@ func newAWSDNSProvider() (Provider, error) {
 
 	return dnsProvider, nil
 }
+
+func newGCPDNSProvider() (Provider, error) {
+	var dnsProvider Provider
+	provider, err := dnsGCP.NewProvider(dnsGCP.Config{
+		Project: os.Getenv(dnsGCP.ProjectIDEnvVar),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCP DNS manager: %v", err)
+	}
+	dnsProvider = provider
+
+	return dnsProvider, nil
+}
+
+func newAzureDNSProvider() (Provider, error) {
+	var dnsProvider Provider
+	provider, err := dnsAzure.NewProvider(dnsAzure.Config{
+		TenantID:     os.Getenv(dnsAzure.TenantIDEnvVar),
+		ClientID:     os.Getenv(dnsAzure.ClientIDEnvVar),
+		ClientSecret: os.Getenv(dnsAzure.ClientSecretEnvVar),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Azure DNS manager: %v", err)
+	}
+	dnsProvider = provider
+
+	return dnsProvider, nil
+}
+
+func newRFC2136DNSProvider() (Provider, error) {
+	var dnsProvider Provider
+	provider, err := dnsRFC2136.NewProvider(dnsRFC2136.Config{
+		Nameserver:    os.Getenv(dnsRFC2136.NameserverEnvVar),
+		TSIGKeyName:   os.Getenv(dnsRFC2136.TSIGKeyNameEnvVar),
+		TSIGSecret:    os.Getenv(dnsRFC2136.TSIGSecretEnvVar),
+		TSIGAlgorithm: os.Getenv(dnsRFC2136.TSIGAlgorithmEnvVar),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create RFC 2136 DNS manager: %v", err)
+	}
+	dnsProvider = provider
+
+	return dnsProvider, nil
+}
+
+func newMemoryDNSProvider() (Provider, error) {
+	var dnsProvider Provider
+	config := dnsMemory.Config{}
+	if value, ok := os.LookupEnv(dnsMemory.PortEnvVar); ok {
+		port, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value %q: %v", dnsMemory.PortEnvVar, value, err)
+		}
+		config.Port = port
+	}
+	provider, err := dnsMemory.NewProvider(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create memory DNS manager: %v", err)
+	}
+	dnsProvider = provider
+
+	return dnsProvider, nil
+}
+
+func newPluginDNSProvider() (Provider, error) {
+	var dnsProvider Provider
+	config := dnsPlugin.Config{
+		Address: os.Getenv(dnsPlugin.AddressEnvVar),
+	}
+	if value, ok := os.LookupEnv(dnsPlugin.TimeoutEnvVar); ok {
+		timeout, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value %q: %v", dnsPlugin.TimeoutEnvVar, value, err)
+		}
+		config.Timeout = timeout
+	}
+	provider, err := dnsPlugin.NewProvider(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gRPC DNS manager: %v", err)
+	}
+	dnsProvider = provider
+
+	return dnsProvider, nil
+}
+
+func newExternalDNSProvider() (Provider, error) {
+	var dnsProvider Provider
+	// The DNSEndpoints are created in the GLBC workspace, unless configured
+	// otherwise, with the same client configuration as the GLBC.
+	workspace := env.GetEnvString(dnsExternalDNS.WorkspaceEnvVar, env.GetEnvString("GLBC_WORKSPACE", "root:kuadrant"))
+	namespace := env.GetEnvString(dnsExternalDNS.NamespaceEnvVar, env.GetNamespace())
+	if namespace == "" {
+		namespace = "kcp-glbc"
+	}
+
+	clientConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		clientcmd.NewDefaultClientConfigLoadingRules(),
+		&clientcmd.ConfigOverrides{}).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create external-dns DNS manager: %v", err)
+	}
+	client, err := dynamic.NewClusterForConfig(clientConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create external-dns DNS manager: %v", err)
+	}
+
+	provider, err := dnsExternalDNS.NewProvider(dnsExternalDNS.Config{
+		Client:    client.Cluster(logicalcluster.New(workspace)),
+		Namespace: namespace,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create external-dns DNS manager: %v", err)
+	}
+	dnsProvider = provider
+
+	return dnsProvider, nil
+}
@@ -2,14 +2,16 @@ package dns
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"reflect"
-	"strings"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/kcp-dev/logicalcluster/v2"
 
+	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	utilclock "k8s.io/utils/clock"
@@ -17,6 +19,7 @@ import (
 	"github.com/kuadrant/kcp-glbc/pkg/_internal/metadata"
 	"github.com/kuadrant/kcp-glbc/pkg/_internal/slice"
 	v1 "github.com/kuadrant/kcp-glbc/pkg/apis/kuadrant/v1"
+	dnserrors "github.com/kuadrant/kcp-glbc/pkg/dns/errors"
 )
 
 type ConditionStatus string
@@ -33,12 +36,8 @@ func (c *Controller) reconcile(ctx context.Context, dnsRecord *v1.DNSRecord) err
 	c.Logger.V(3).Info("starting reconcile of dnsRecord ", "name", dnsRecord.Name, "namespace", dnsRecord.Namespace, "cluster", logicalcluster.From(dnsRecord))
 	// If the DNS record was deleted, clean up and return.
 	if dnsRecord.DeletionTimestamp != nil && !dnsRecord.DeletionTimestamp.IsZero() {
-		if err := c.reconcileHealthCheckDeletion(ctx, dnsRecord); err != nil {
-			return err
-		}
-
 		c.Logger.Info("Deleting DNSRecord", "dnsRecord", dnsRecord)
-		if err := c.deleteRecord(dnsRecord); err != nil && !strings.Contains(err.Error(), "was not found") {
+		if err := c.deleteRecord(ctx, dnsRecord); err != nil {
 			c.Logger.Error(err, "Failed to delete DNSRecord", "record", dnsRecord)
 			return err
 		}
@@ -52,80 +51,201 @@ func (c *Controller) reconcile(ctx context.Context, dnsRecord *v1.DNSRecord) err
 		dnsRecord.Finalizers = append(dnsRecord.Finalizers, DNSRecordFinalizer)
 	}
 
-	statuses := c.publishRecordToZones(c.dnsZones, dnsRecord)
-	if !dnsZoneStatusSlicesEqual(statuses, dnsRecord.Status.Zones) || dnsRecord.Status.ObservedGeneration != dnsRecord.Generation {
-		dnsRecord.Status.Zones = statuses
-		dnsRecord.Status.ObservedGeneration = dnsRecord.Generation
+	// The zones given by their tags are resolved on each reconciliation, so
+	// that the records follow the zones that are recreated under another ID.
+	dnsZones, err := resolveZones(ctx, c.dnsProvider, c.dnsZones)
+	if err != nil {
+		return fmt.Errorf("failed to resolve DNS zones: %v", err)
 	}
 
-	if err := c.ReconcileHealthChecks(ctx, dnsRecord); err != nil {
-		c.Logger.Error(err, "Failed to reconcile health check for DNSRecord", "record", dnsRecord)
+	// The endpoints are published with the health checks reconciled by the
+	// HealthChecks that target the DNSRecord, if any, whose health is reported
+	// in the status of the DNSRecord. The unhealthy endpoints are withheld from
+	// the zones when they are probed by the GLBC, as the DNS provider has no
+	// health checks to fail over with
+	healthChecks, err := c.healthChecksOf(dnsRecord)
+	if err != nil {
 		return err
 	}
+	dnsRecord.Status.Health = endpointHealthOf(healthChecks)
+	published := withHealthChecks(dnsRecord, healthCheckRefsOf(healthChecks))
+	if probesHealth(c.dnsProvider) {
+		published = withholdUnhealthyEndpoints(published)
+	}
+	zones, zoneRecords, unmatched := zoneRecords(dnsZones, published)
+	if len(unmatched) > 0 {
+		c.Logger.Info("Skipping names that do not belong to any DNS zone", "record", dnsRecord.Name, "names", unmatched)
+	}
+	statuses, errs := c.publishRecordToZones(ctx, zones, zoneRecords, dnsRecord, c.takeDrifted(dnsRecord))
+	statuses = c.unpublishRecordFromStaleZones(ctx, zones, dnsRecord, statuses)
+	if !dnsZoneStatusSlicesEqual(statuses, dnsRecord.Status.Zones) || dnsRecord.Status.ObservedGeneration != dnsRecord.Generation {
+		dnsRecord.Status.Zones = statuses
+		dnsRecord.Status.ObservedGeneration = dnsRecord.Generation
+	}
+	c.requeueAfterProviderErrors(dnsRecord, errs)
 
 	return nil
 }
 
-func (c *Controller) publishRecordToZones(zones []v1.DNSZone, record *v1.DNSRecord) []v1.DNSZoneStatus {
+// publishRecordToZones publishes the records holding the endpoints of each of
+// the zones, as returned by zoneRecords, and returns the updated statuses,
+// along with the errors of the DNS provider. The records are published again
+// when they have drifted, or when their endpoints differ from the ones last
+// published, even if the status indicates that they are already published.
+func (c *Controller) publishRecordToZones(ctx context.Context, zones []Zone, zoneRecords []*v1.DNSRecord, record *v1.DNSRecord, drifted bool) ([]v1.DNSZoneStatus, []error) {
 	var statuses []v1.DNSZoneStatus
+	var errs []error
+	pending := false
 	for i := range zones {
-		zone := zones[i]
+		zone := zones[i].DNSZone
+		zoneRecord := zoneRecords[i]
 
 		// Only publish the record if the DNSRecord has been modified
 		// (which would mean the target could have changed) or its
 		// status does not indicate that it has already been published.
-		if record.Generation == record.Status.ObservedGeneration && RecordIsAlreadyPublishedToZone(record, &zone) {
+		// The endpoints withheld, or restored, as their health changed
+		// are published as well.
+		if !drifted && record.Generation == record.Status.ObservedGeneration && RecordIsAlreadyPublishedToZone(record, &zone) && !publishedEndpointsChanged(record, zoneRecord, zone) {
 			c.Logger.Info("Skipping zone to which the DNS record is already published", "record", record, "zone", zone)
 			continue
 		}
+		// Invalid records are not published again until they are modified
+		if !drifted && record.Generation == record.Status.ObservedGeneration && RecordIsRejectedByZone(record, &zone) {
+			c.Logger.Info("Skipping zone that rejected the DNS record as invalid", "record", record, "zone", zone)
+			continue
+		}
 
 		condition := v1.DNSZoneCondition{
 			Status:             string(ConditionUnknown),
 			Type:               v1.DNSRecordSucceededConditionType,
 			LastTransitionTime: metav1.Now(),
 		}
+		owned := v1.DNSZoneCondition{
+			Status:             string(ConditionTrue),
+			Type:               v1.DNSRecordOwnedConditionType,
+			Reason:             "OwnershipVerified",
+			Message:            "The names of the record are owned by this GLBC",
+			LastTransitionTime: metav1.Now(),
+		}
 
+		action, actioning := "ensure", "ensuring"
 		if RecordIsAlreadyPublishedToZone(record, &zone) {
+			action, actioning = "replace", "replacing"
 			c.Logger.Info("replacing DNS record", "record", record, "zone", zone)
+		}
 
-			if err := c.dnsProvider.Ensure(record, zone); err != nil {
-				c.Logger.Error(err, "Failed to replace DNS record in zone", "record", record.Spec, "zone", zone)
-				condition.Status = string(ConditionFalse)
-				condition.Type = v1.DNSRecordSucceededConditionType
-				condition.Reason = "ProviderError"
-				condition.Message = fmt.Sprintf("The DNS provider failed to replace the record: %v", err)
-			} else {
-				c.Logger.Info("Replaced DNS record in zone", "record", record.Spec, "zone", zone)
-				condition.Status = string(ConditionTrue)
-				condition.Type = v1.DNSRecordSucceededConditionType
-				condition.Reason = "ProviderSuccess"
-				condition.Message = "The DNS provider succeeded in replacing the record"
-			}
-		} else {
-			if err := c.dnsProvider.Ensure(record, zone); err != nil {
-				c.Logger.Error(err, "Failed to publish DNS record to zone", "record", record.Spec, "zone", zone)
-				condition.Status = string(ConditionFalse)
-				condition.Type = v1.DNSRecordSucceededConditionType
-				condition.Reason = "ProviderError"
-				condition.Message = fmt.Sprintf("The DNS provider failed to ensure the record: %v", err)
-			} else {
+		// The endpoints recorded in the zone status are the ones published to
+		// the zone, including the ownership records, that the providers rely on
+		// to clean up stale records.
+		endpoints, err := c.registry.Ensure(ctx, zoneRecord, zone)
+		var conflict *OwnershipConflictError
+		switch {
+		case errors.As(err, &conflict):
+			c.Logger.Info("Refusing to publish DNS record owned by someone else", "record", record.Spec, "zone", zone, "reason", err.Error())
+			condition.Status = string(ConditionFalse)
+			condition.Reason = ConditionReasonOwnershipConflict
+			condition.Message = fmt.Sprintf("The record was not published to avoid overwriting records owned by someone else: %v", err)
+			owned.Status = string(ConditionFalse)
+			owned.Reason = ConditionReasonOwnershipConflict
+			owned.Message = err.Error()
+			endpoints = publishedEndpoints(record, zone)
+			// The names may be released by their owner, so the record is
+			// published again with a growing delay
+			errs = append(errs, &requeueError{error: err, delay: ownershipConflictRequeueDelay(record, zone)})
+		case err != nil:
+			c.Logger.Error(err, fmt.Sprintf("Failed to %s DNS record in zone", action), "record", record.Spec, "zone", zone)
+			condition.Status = string(ConditionFalse)
+			condition.Reason = conditionReasonForError(err)
+			condition.Message = fmt.Sprintf("The DNS provider failed to %s the record: %v", action, err)
+			owned.Status = string(ConditionUnknown)
+			owned.Reason = condition.Reason
+			owned.Message = "The ownership of the names of the record could not be verified"
+			endpoints = publishedEndpoints(record, zone)
+			errs = append(errs, err)
+		default:
+			published, err := c.isPublished(ctx, zoneRecord, zone)
+			switch {
+			case err != nil:
+				c.Logger.Error(err, "Failed to check publication of DNS record in zone", "record", record.Spec, "zone", zone)
+				condition.Reason = conditionReasonForError(err)
+				condition.Message = fmt.Sprintf("The publication of the record could not be checked: %v", err)
+				errs = append(errs, err)
+			case !published:
+				c.Logger.Info("Waiting for DNS record to be published to zone", "record", record.Spec, "zone", zone)
+				condition.Reason = ConditionReasonPublicationPending
+				condition.Message = "The DNS provider accepted the record, that is not yet published"
+				pending = true
+			default:
 				c.Logger.Info("Published DNS record to zone", "record", record.Spec, "zone", zone)
 				condition.Status = string(ConditionTrue)
-				condition.Type = v1.DNSRecordSucceededConditionType
 				condition.Reason = "ProviderSuccess"
-				condition.Message = "The DNS provider succeeded in ensuring the record"
+				condition.Message = fmt.Sprintf("The DNS provider succeeded in %s the record", actioning)
 			}
 		}
 		statuses = append(statuses, v1.DNSZoneStatus{
 			DNSZone:    zone,
-			Conditions: []v1.DNSZoneCondition{condition},
-			Endpoints:  record.Spec.Endpoints,
+			Conditions: []v1.DNSZoneCondition{condition, owned},
+			Endpoints:  endpoints,
 		})
 	}
-	return mergeStatuses(zones, record.Status.DeepCopy().Zones, statuses)
+	if pending {
+		c.Logger.V(3).Info("Requeuing DNS record pending publication", "record", record.Name, "after", publicationPendingRequeueDelay)
+		c.EnqueueAfter(record, publicationPendingRequeueDelay)
+	}
+	return mergeStatuses(record.Status.DeepCopy().Zones, statuses), errs
+}
+
+// isPublished returns whether the record ensured in the zone is published, for
+// the providers that publish the records asynchronously. The records are
+// published once ensured otherwise.
+func (c *Controller) isPublished(ctx context.Context, record *v1.DNSRecord, zone v1.DNSZone) (bool, error) {
+	checker, ok := c.dnsProvider.(PublicationChecker)
+	if !ok {
+		return true, nil
+	}
+	return checker.IsPublished(ctx, record, zone)
+}
+
+// requeueAfterProviderErrors requeues the record after the shortest of the
+// delays of the errors it failed to be published with, if any. The invalid
+// records are not requeued, as they are published again once modified.
+func (c *Controller) requeueAfterProviderErrors(record *v1.DNSRecord, errs []error) {
+	var delay time.Duration
+	requeue := false
+	for _, err := range errs {
+		if d, ok := requeueDelayForError(err); ok && (!requeue || d < delay) {
+			delay, requeue = d, true
+		}
+	}
+	if requeue {
+		c.Logger.V(3).Info("Requeuing DNS record that failed to be published", "record", record.Name, "after", delay)
+		c.EnqueueAfter(record, delay)
+	}
 }
 
-func (c *Controller) deleteRecord(record *v1.DNSRecord) error {
+// unpublishRecordFromStaleZones deletes the record from the zones of its status
+// that none of its names belong to anymore, and returns the statuses without
+// these zones. The status of a zone is kept when the deletion fails, so that
+// it is retried.
+func (c *Controller) unpublishRecordFromStaleZones(ctx context.Context, zones []Zone, record *v1.DNSRecord, statuses []v1.DNSZoneStatus) []v1.DNSZoneStatus {
+	var kept []v1.DNSZoneStatus
+	for _, status := range statuses {
+		if containsZone(zones, status.DNSZone) {
+			kept = append(kept, status)
+			continue
+		}
+		if RecordIsAlreadyPublishedToZone(record, &status.DNSZone) {
+			if err := c.deleteRecordFromZone(ctx, record, status.DNSZone); err != nil {
+				c.Logger.Error(err, "Failed to delete DNS record from zone it no longer belongs to", "record", record.Spec, "zone", status.DNSZone)
+				kept = append(kept, status)
+				continue
+			}
+		}
+	}
+	return kept
+}
+
+func (c *Controller) deleteRecord(ctx context.Context, record *v1.DNSRecord) error {
 	var errs []error
 	for i := range record.Status.Zones {
 		zone := record.Status.Zones[i].DNSZone
@@ -134,11 +254,8 @@ func (c *Controller) deleteRecord(record *v1.DNSRecord) error {
 		if !RecordIsAlreadyPublishedToZone(record, &zone) {
 			continue
 		}
-		err := c.dnsProvider.Delete(record, zone)
-		if err != nil {
+		if err := c.deleteRecordFromZone(ctx, record, zone); err != nil {
 			errs = append(errs, err)
-		} else {
-			c.Logger.Info("Deleted DNSRecord from DNS provider", "record", record.Spec, "zone", zone)
 		}
 	}
 	if len(errs) == 0 {
@@ -149,6 +266,45 @@ func (c *Controller) deleteRecord(record *v1.DNSRecord) error {
 	return utilerrors.NewAggregate(errs)
 }
 
+// deleteRecordFromZone deletes the endpoints last published to the zone, as
+// recorded in the DNSRecord's status, along with their ownership records.
+func (c *Controller) deleteRecordFromZone(ctx context.Context, record *v1.DNSRecord, zone v1.DNSZone) error {
+	published := record.DeepCopy()
+	published.Spec.Endpoints = nil
+	for _, endpoint := range publishedEndpoints(record, zone) {
+		if !isOwnershipRecord(endpoint) {
+			published.Spec.Endpoints = append(published.Spec.Endpoints, endpoint)
+		}
+	}
+
+	err := c.registry.Delete(ctx, published, zone)
+	var conflict *OwnershipConflictError
+	if errors.As(err, &conflict) {
+		// The names are now owned by someone else, leave their records untouched
+		c.Logger.Info("Skipping deletion of DNS record owned by someone else", "record", record.Spec, "zone", zone, "reason", err.Error())
+		return nil
+	}
+	if dnserrors.IsNotFound(err) || dnserrors.IsZoneNotFound(err) {
+		// The records are already gone, e.g. deleted out of band
+		c.Logger.Info("Skipping deletion of DNS record that no longer exists", "record", record.Spec, "zone", zone, "reason", err.Error())
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	c.Logger.Info("Deleted DNSRecord from DNS provider", "record", record.Spec, "zone", zone)
+	return nil
+}
+
+func containsZone(zones []Zone, zone v1.DNSZone) bool {
+	for i := range zones {
+		if reflect.DeepEqual(zones[i].DNSZone, zone) {
+			return true
+		}
+	}
+	return false
+}
+
 // RecordIsAlreadyPublishedToZone returns a Boolean value indicating whether the
 // given DNSRecord is already published to the given zone, as determined from
 // the DNSRecord's status conditions.
@@ -168,9 +324,176 @@ func RecordIsAlreadyPublishedToZone(record *v1.DNSRecord, zoneToPublish *v1.DNSZ
 	return false
 }
 
+// RecordIsRejectedByZone returns whether the DNS provider rejected the given
+// DNSRecord as invalid when it was last published to the given zone, as
+// determined from the DNSRecord's status conditions.
+func RecordIsRejectedByZone(record *v1.DNSRecord, zone *v1.DNSZone) bool {
+	for _, zoneInStatus := range record.Status.Zones {
+		if !reflect.DeepEqual(&zoneInStatus.DNSZone, zone) {
+			continue
+		}
+
+		for _, condition := range zoneInStatus.Conditions {
+			if condition.Type == v1.DNSRecordSucceededConditionType {
+				return condition.Status == string(ConditionFalse) && condition.Reason == ConditionReasonInvalidRecord
+			}
+		}
+	}
+
+	return false
+}
+
+// publishedEndpoints returns the endpoints of the given DNSRecord that were
+// last published to the given zone, as recorded in the DNSRecord's status.
+func publishedEndpoints(record *v1.DNSRecord, zone v1.DNSZone) []*v1.Endpoint {
+	for _, zoneInStatus := range record.Status.Zones {
+		if reflect.DeepEqual(zoneInStatus.DNSZone, zone) {
+			return zoneInStatus.Endpoints
+		}
+	}
+	return nil
+}
+
+// publishedEndpointsChanged returns whether the endpoints of the record of the
+// zone differ from the endpoints of the DNSRecord last published to the zone,
+// as recorded in its status, which happens when unhealthy endpoints are
+// withheld from the zone, or restored to it, or when the health checks of the
+// endpoints change.
+func publishedEndpointsChanged(record, zoneRecord *v1.DNSRecord, zone v1.DNSZone) bool {
+	endpoints := map[string]struct{}{}
+	for _, endpoint := range record.Spec.Endpoints {
+		endpoints[publishedEndpointKey(endpoint)] = struct{}{}
+	}
+	// The ownership records that are also published are ignored
+	published := map[string]*v1.Endpoint{}
+	for _, endpoint := range publishedEndpoints(record, zone) {
+		if _, ok := endpoints[publishedEndpointKey(endpoint)]; ok {
+			published[publishedEndpointKey(endpoint)] = endpoint
+		}
+	}
+	if len(published) != len(zoneRecord.Spec.Endpoints) {
+		return true
+	}
+	for _, endpoint := range zoneRecord.Spec.Endpoints {
+		previous, ok := published[publishedEndpointKey(endpoint)]
+		if !ok || !equality.Semantic.DeepEqual(previous.ProviderSpecific, endpoint.ProviderSpecific) {
+			return true
+		}
+	}
+	return false
+}
+
+func publishedEndpointKey(endpoint *v1.Endpoint) string {
+	return endpoint.RecordType + "/" + endpointKey(endpoint)
+}
+
+// healthChecksOf returns the HealthChecks that target the DNSRecord.
+func (c *Controller) healthChecksOf(record *v1.DNSRecord) ([]*v1.HealthCheck, error) {
+	if c.healthCheckIndexer == nil {
+		return nil, nil
+	}
+	return healthChecksTargeting(c.healthCheckIndexer, record)
+}
+
+// healthCheckRefsOf returns the references of the health checks of the
+// HealthChecks.
+func healthCheckRefsOf(healthChecks []*v1.HealthCheck) []v1.EndpointHealthCheckRef {
+	var refs []v1.EndpointHealthCheckRef
+	for _, healthCheck := range healthChecks {
+		refs = append(refs, healthCheck.Status.HealthChecks...)
+	}
+	return refs
+}
+
+// endpointHealthOf returns the health of the endpoints checked by the
+// HealthChecks, as reported in their status.
+func endpointHealthOf(healthChecks []*v1.HealthCheck) []v1.EndpointHealth {
+	var health []v1.EndpointHealth
+	for _, healthCheck := range healthChecks {
+		for _, endpoint := range healthCheck.Status.Endpoints {
+			health = append(health, v1.EndpointHealth{
+				DNSName:          endpoint.DNSName,
+				SetIdentifier:    endpoint.SetIdentifier,
+				Health:           endpoint.Health,
+				LastObservedTime: endpoint.LastObservedTime,
+			})
+		}
+	}
+	return health
+}
+
+// withHealthChecks returns a copy of the DNSRecord whose endpoints have the
+// provider specific properties of their health checks, as referenced by the
+// HealthChecks, unless the health checks are stale.
+func withHealthChecks(record *v1.DNSRecord, refs []v1.EndpointHealthCheckRef) *v1.DNSRecord {
+	properties := map[string]v1.ProviderSpecific{}
+	for _, ref := range refs {
+		if !ref.Stale {
+			properties[ref.DNSName+"/"+ref.SetIdentifier] = ref.ProviderSpecific
+		}
+	}
+	if len(properties) == 0 {
+		return record
+	}
+
+	checked := record.DeepCopy()
+	for _, endpoint := range checked.Spec.Endpoints {
+		for _, property := range properties[endpointKey(endpoint)] {
+			endpoint.SetProviderSpecific(property.Name, property.Value)
+		}
+	}
+	return checked
+}
+
+// withholdUnhealthyEndpoints returns a copy of the DNSRecord without the
+// endpoints reported as unhealthy in its status. The endpoints of a name are
+// all kept when they are all unhealthy, so that the name still resolves.
+func withholdUnhealthyEndpoints(record *v1.DNSRecord) *v1.DNSRecord {
+	unhealthy := map[string]struct{}{}
+	for _, health := range record.Status.Health {
+		if health.Health == v1.Unhealthy {
+			unhealthy[health.DNSName+"/"+health.SetIdentifier] = struct{}{}
+		}
+	}
+	if len(unhealthy) == 0 {
+		return record
+	}
+
+	healthy := map[string]int{}
+	for _, endpoint := range record.Spec.Endpoints {
+		if _, ok := unhealthy[endpointKey(endpoint)]; !ok {
+			healthy[endpoint.DNSName+"/"+endpoint.RecordType]++
+		}
+	}
+	withheld := record.DeepCopy()
+	withheld.Spec.Endpoints = nil
+	for _, endpoint := range record.Spec.Endpoints {
+		_, isUnhealthy := unhealthy[endpointKey(endpoint)]
+		if isUnhealthy && healthy[endpoint.DNSName+"/"+endpoint.RecordType] > 0 {
+			continue
+		}
+		withheld.Spec.Endpoints = append(withheld.Spec.Endpoints, endpoint.DeepCopy())
+	}
+	return withheld
+}
+
+// RecordIsPublished returns a Boolean value indicating whether the given
+// DNSRecord is published to every zone listed in its status.
+func RecordIsPublished(record *v1.DNSRecord) bool {
+	if len(record.Status.Zones) == 0 {
+		return false
+	}
+	for i := range record.Status.Zones {
+		if !RecordIsAlreadyPublishedToZone(record, &record.Status.Zones[i].DNSZone) {
+			return false
+		}
+	}
+	return true
+}
+
 // mergeStatuses updates or extends the provided slice of statuses with the
 // provided updates and returns the resulting slice.
-func mergeStatuses(zones []v1.DNSZone, statuses, updates []v1.DNSZoneStatus) []v1.DNSZoneStatus {
+func mergeStatuses(statuses, updates []v1.DNSZoneStatus) []v1.DNSZoneStatus {
 	var additions []v1.DNSZoneStatus
 	for i, update := range updates {
 		add := true
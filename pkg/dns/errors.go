@@ -0,0 +1,121 @@
+package dns
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	v1 "github.com/kuadrant/kcp-glbc/pkg/apis/kuadrant/v1"
+	dnserrors "github.com/kuadrant/kcp-glbc/pkg/dns/errors"
+)
+
+// The reasons of the Succeeded condition of a zone the record could not be
+// published to.
+const (
+	ConditionReasonProviderError            = "ProviderError"
+	ConditionReasonProviderThrottled        = "ProviderThrottled"
+	ConditionReasonInvalidRecord            = "InvalidRecord"
+	ConditionReasonProviderPermissionDenied = "ProviderPermissionDenied"
+	ConditionReasonZoneNotFound             = "ZoneNotFound"
+	ConditionReasonRecordNotFound           = "RecordNotFound"
+	ConditionReasonOwnershipConflict        = "OwnershipConflict"
+)
+
+// ConditionReasonPublicationPending is the reason of the Succeeded condition of
+// a zone the record is ensured in, but not yet published to, by the providers
+// that publish the records asynchronously.
+const ConditionReasonPublicationPending = "PublicationPending"
+
+const (
+	// throttledRequeueDelay is the delay after which a record whose
+	// publication was throttled is published again. It is jittered, so that
+	// the throttled records are not all retried at once.
+	throttledRequeueDelay = 10 * time.Second
+	// providerErrorRequeueDelay is the delay after which a record whose
+	// publication failed for another reason is published again, as these
+	// errors are not expected to go away quickly, e.g. missing permissions.
+	providerErrorRequeueDelay = 5 * time.Minute
+	// publicationPendingRequeueDelay is the delay after which the publication
+	// of a record that is not yet published is checked again.
+	publicationPendingRequeueDelay = 15 * time.Second
+	// minOwnershipConflictRequeueDelay and maxOwnershipConflictRequeueDelay
+	// bound the delay after which a record whose names are owned by someone
+	// else is published again.
+	minOwnershipConflictRequeueDelay = 30 * time.Second
+	maxOwnershipConflictRequeueDelay = 30 * time.Minute
+)
+
+// requeueError is an error after which the record is published again after
+// the given delay.
+type requeueError struct {
+	error
+	delay time.Duration
+}
+
+func (e *requeueError) Unwrap() error {
+	return e.error
+}
+
+// conditionReasonForError returns the reason of the Succeeded condition of a
+// zone the record could not be published to.
+func conditionReasonForError(err error) string {
+	switch dnserrors.ReasonForError(err) {
+	case dnserrors.ReasonThrottled:
+		return ConditionReasonProviderThrottled
+	case dnserrors.ReasonInvalidRecord:
+		return ConditionReasonInvalidRecord
+	case dnserrors.ReasonPermissionDenied:
+		return ConditionReasonProviderPermissionDenied
+	case dnserrors.ReasonZoneNotFound:
+		return ConditionReasonZoneNotFound
+	case dnserrors.ReasonNotFound:
+		return ConditionReasonRecordNotFound
+	}
+	return ConditionReasonProviderError
+}
+
+// requeueDelayForError returns the delay after which a record whose
+// publication failed with the error is published again, or false if it is
+// not retried until it is modified, as it is invalid.
+func requeueDelayForError(err error) (time.Duration, bool) {
+	var requeue *requeueError
+	if errors.As(err, &requeue) {
+		return requeue.delay, true
+	}
+	switch dnserrors.ReasonForError(err) {
+	case dnserrors.ReasonInvalidRecord:
+		return 0, false
+	case dnserrors.ReasonThrottled:
+		return wait.Jitter(throttledRequeueDelay, 1.0), true
+	}
+	return providerErrorRequeueDelay, true
+}
+
+// ownershipConflictRequeueDelay returns the delay after which a record whose
+// names are owned by someone else in the zone is published again. It is the
+// time the conflict has lasted, as recorded in the Owned condition of the zone,
+// so that the conflicts that are not resolved quickly are retried less and
+// less often.
+func ownershipConflictRequeueDelay(record *v1.DNSRecord, zone v1.DNSZone) time.Duration {
+	delay := minOwnershipConflictRequeueDelay
+	for _, status := range record.Status.Zones {
+		if !cmp.Equal(status.DNSZone, zone) {
+			continue
+		}
+		for _, condition := range status.Conditions {
+			if condition.Type != v1.DNSRecordOwnedConditionType || condition.Reason != ConditionReasonOwnershipConflict {
+				continue
+			}
+			if lasted := clock.Since(condition.LastTransitionTime.Time); lasted > delay {
+				delay = lasted
+			}
+		}
+	}
+	if delay > maxOwnershipConflictRequeueDelay {
+		delay = maxOwnershipConflictRequeueDelay
+	}
+	return delay
+}
@@ -0,0 +1,126 @@
+// Package errors defines the errors the DNS providers return, so that their
+// causes can be told apart regardless of the provider that returned them.
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// Reason is the cause of a DNS provider error.
+type Reason string
+
+const (
+	// ReasonNotFound means the record does not exist.
+	ReasonNotFound Reason = "NotFound"
+	// ReasonThrottled means the request was rejected by the rate limit of the
+	// DNS provider, and can be retried later.
+	ReasonThrottled Reason = "Throttled"
+	// ReasonInvalidRecord means the record is rejected by the DNS provider,
+	// and cannot be published until it is modified.
+	ReasonInvalidRecord Reason = "InvalidRecord"
+	// ReasonPermissionDenied means the credentials of the DNS provider are
+	// invalid, or not allowed to manage the zone.
+	ReasonPermissionDenied Reason = "PermissionDenied"
+	// ReasonZoneNotFound means the zone does not exist.
+	ReasonZoneNotFound Reason = "ZoneNotFound"
+	// ReasonUnknown is the reason of the errors that are not typed.
+	ReasonUnknown Reason = "Unknown"
+)
+
+// Error is an error returned by a DNS provider, along with its reason.
+type Error struct {
+	Reason Reason
+	Err    error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// New returns the error with the given reason. It returns nil if err is nil.
+func New(reason Reason, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Reason: reason, Err: err}
+}
+
+// Errorf formats an error with the given reason.
+func Errorf(reason Reason, format string, args ...interface{}) error {
+	return &Error{Reason: reason, Err: fmt.Errorf(format, args...)}
+}
+
+// ReasonForError returns the reason of the error, or ReasonUnknown if it is
+// not typed. The reason of an aggregate is the one shared by all its errors,
+// or ReasonUnknown if they differ.
+func ReasonForError(err error) Reason {
+	if err == nil {
+		return ReasonUnknown
+	}
+	var aggregate utilerrors.Aggregate
+	if errors.As(err, &aggregate) && len(aggregate.Errors()) > 0 {
+		errs := aggregate.Errors()
+		reason := ReasonForError(errs[0])
+		for _, err := range errs[1:] {
+			if ReasonForError(err) != reason {
+				return ReasonUnknown
+			}
+		}
+		return reason
+	}
+	var typed *Error
+	if errors.As(err, &typed) {
+		return typed.Reason
+	}
+	return ReasonUnknown
+}
+
+// IsNotFound returns whether the record does not exist.
+func IsNotFound(err error) bool {
+	return ReasonForError(err) == ReasonNotFound
+}
+
+// IsThrottled returns whether the request was rejected by the rate limit of
+// the DNS provider.
+func IsThrottled(err error) bool {
+	return ReasonForError(err) == ReasonThrottled
+}
+
+// IsInvalidRecord returns whether the record is rejected by the DNS provider.
+func IsInvalidRecord(err error) bool {
+	return ReasonForError(err) == ReasonInvalidRecord
+}
+
+// IsPermissionDenied returns whether the DNS provider denied the request.
+func IsPermissionDenied(err error) bool {
+	return ReasonForError(err) == ReasonPermissionDenied
+}
+
+// IsZoneNotFound returns whether the zone does not exist.
+func IsZoneNotFound(err error) bool {
+	return ReasonForError(err) == ReasonZoneNotFound
+}
+
+// ReasonForHTTPStatus returns the reason of an error response of a DNS
+// provider REST API.
+func ReasonForHTTPStatus(status int) Reason {
+	switch status {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return ReasonInvalidRecord
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ReasonPermissionDenied
+	case http.StatusNotFound:
+		return ReasonNotFound
+	case http.StatusTooManyRequests:
+		return ReasonThrottled
+	}
+	return ReasonUnknown
+}
@@ -0,0 +1,79 @@
+package errors
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/onsi/gomega"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+func TestReasonForError(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Err      error
+		Expected Reason
+	}{
+		{
+			Name:     "untyped",
+			Err:      fmt.Errorf("connection refused"),
+			Expected: ReasonUnknown,
+		},
+		{
+			Name:     "typed",
+			Err:      Errorf(ReasonThrottled, "rate exceeded"),
+			Expected: ReasonThrottled,
+		},
+		{
+			Name:     "wrapped",
+			Err:      fmt.Errorf("couldn't update DNS record in zone Z1: %w", New(ReasonZoneNotFound, fmt.Errorf("no such zone"))),
+			Expected: ReasonZoneNotFound,
+		},
+		{
+			Name: "aggregate of the same reason",
+			Err: utilerrors.NewAggregate([]error{
+				Errorf(ReasonNotFound, "app.example.com not found"),
+				Errorf(ReasonNotFound, "www.example.com not found"),
+			}),
+			Expected: ReasonNotFound,
+		},
+		{
+			Name: "aggregate of different reasons",
+			Err: utilerrors.NewAggregate([]error{
+				Errorf(ReasonNotFound, "app.example.com not found"),
+				Errorf(ReasonThrottled, "rate exceeded"),
+			}),
+			Expected: ReasonUnknown,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+			g.Expect(ReasonForError(tc.Err)).To(gomega.Equal(tc.Expected))
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	g := gomega.NewWithT(t)
+	g.Expect(New(ReasonThrottled, nil)).To(gomega.BeNil())
+
+	cause := fmt.Errorf("rate exceeded")
+	err := New(ReasonThrottled, cause)
+	g.Expect(err).To(gomega.MatchError("rate exceeded"))
+	g.Expect(err).To(gomega.MatchError(cause))
+	g.Expect(IsThrottled(err)).To(gomega.BeTrue())
+	g.Expect(IsNotFound(err)).To(gomega.BeFalse())
+}
+
+func TestReasonForHTTPStatus(t *testing.T) {
+	g := gomega.NewWithT(t)
+	g.Expect(ReasonForHTTPStatus(http.StatusBadRequest)).To(gomega.Equal(ReasonInvalidRecord))
+	g.Expect(ReasonForHTTPStatus(http.StatusForbidden)).To(gomega.Equal(ReasonPermissionDenied))
+	g.Expect(ReasonForHTTPStatus(http.StatusNotFound)).To(gomega.Equal(ReasonNotFound))
+	g.Expect(ReasonForHTTPStatus(http.StatusTooManyRequests)).To(gomega.Equal(ReasonThrottled))
+	g.Expect(ReasonForHTTPStatus(http.StatusInternalServerError)).To(gomega.Equal(ReasonUnknown))
+}
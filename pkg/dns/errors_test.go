@@ -0,0 +1,238 @@
+package dns
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+
+	"k8s.io/client-go/util/workqueue"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"github.com/kuadrant/kcp-glbc/pkg/_internal/log"
+	v1 "github.com/kuadrant/kcp-glbc/pkg/apis/kuadrant/v1"
+	dnserrors "github.com/kuadrant/kcp-glbc/pkg/dns/errors"
+	"github.com/kuadrant/kcp-glbc/pkg/reconciler"
+)
+
+// failingProvider fails to ensure and delete the records with its errors, if
+// any.
+type failingProvider struct {
+	Provider
+	ensureErr error
+	deleteErr error
+}
+
+func (p *failingProvider) Ensure(ctx context.Context, record *v1.DNSRecord, zone v1.DNSZone) error {
+	if p.ensureErr != nil {
+		return p.ensureErr
+	}
+	return p.Provider.Ensure(ctx, record, zone)
+}
+
+func (p *failingProvider) Delete(ctx context.Context, record *v1.DNSRecord, zone v1.DNSZone) error {
+	if p.deleteErr != nil {
+		return p.deleteErr
+	}
+	return p.Provider.Delete(ctx, record, zone)
+}
+
+// delayingQueue records the delays the keys are added to the queue after.
+type delayingQueue struct {
+	workqueue.RateLimitingInterface
+	delays []time.Duration
+}
+
+func (q *delayingQueue) AddAfter(item interface{}, duration time.Duration) {
+	q.delays = append(q.delays, duration)
+}
+
+func newFailingController(t *testing.T, provider Provider) (*Controller, *delayingQueue) {
+	queue := &delayingQueue{RateLimitingInterface: workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())}
+	t.Cleanup(queue.ShutDown)
+	return &Controller{
+		Controller:  &reconciler.Controller{Logger: log.Logger, Queue: queue},
+		dnsProvider: provider,
+		registry:    NewRegistry("glbc-1", provider),
+		dnsZones:    []Zone{{DNSZone: v1.DNSZone{ID: "example.com"}}},
+	}, queue
+}
+
+func succeededCondition(record *v1.DNSRecord) v1.DNSZoneCondition {
+	for _, condition := range record.Status.Zones[0].Conditions {
+		if condition.Type == v1.DNSRecordSucceededConditionType {
+			return condition
+		}
+	}
+	return v1.DNSZoneCondition{}
+}
+
+func TestReconcileRequeuesByProviderError(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Err      error
+		Reason   string
+		MinDelay time.Duration
+		MaxDelay time.Duration
+		Requeued bool
+	}{
+		{
+			Name:     "throttled",
+			Err:      dnserrors.Errorf(dnserrors.ReasonThrottled, "rate exceeded"),
+			Reason:   ConditionReasonProviderThrottled,
+			MinDelay: throttledRequeueDelay,
+			MaxDelay: 2 * throttledRequeueDelay,
+			Requeued: true,
+		},
+		{
+			Name:     "permission denied",
+			Err:      dnserrors.Errorf(dnserrors.ReasonPermissionDenied, "access denied"),
+			Reason:   ConditionReasonProviderPermissionDenied,
+			MinDelay: providerErrorRequeueDelay,
+			MaxDelay: providerErrorRequeueDelay,
+			Requeued: true,
+		},
+		{
+			Name:     "zone not found",
+			Err:      dnserrors.Errorf(dnserrors.ReasonZoneNotFound, "no such zone"),
+			Reason:   ConditionReasonZoneNotFound,
+			MinDelay: providerErrorRequeueDelay,
+			MaxDelay: providerErrorRequeueDelay,
+			Requeued: true,
+		},
+		{
+			Name:     "invalid record",
+			Err:      dnserrors.Errorf(dnserrors.ReasonInvalidRecord, "invalid A record target"),
+			Reason:   ConditionReasonInvalidRecord,
+			Requeued: false,
+		},
+		{
+			Name:     "unknown",
+			Err:      context.DeadlineExceeded,
+			Reason:   ConditionReasonProviderError,
+			MinDelay: providerErrorRequeueDelay,
+			MaxDelay: providerErrorRequeueDelay,
+			Requeued: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+			c, queue := newFailingController(t, &failingProvider{Provider: newTestMemoryProvider(t), ensureErr: tc.Err})
+
+			record := newTestRecord("app", aEndpoint("app.example.com", "10.0.0.1"))
+			g.Expect(c.reconcile(context.TODO(), record)).To(gomega.Succeed())
+
+			condition := succeededCondition(record)
+			g.Expect(condition.Status).To(gomega.Equal(string(ConditionFalse)))
+			g.Expect(condition.Reason).To(gomega.Equal(tc.Reason))
+			if !tc.Requeued {
+				g.Expect(queue.delays).To(gomega.BeEmpty())
+				return
+			}
+			g.Expect(queue.delays).To(gomega.HaveLen(1))
+			g.Expect(queue.delays[0]).To(gomega.BeNumerically(">=", tc.MinDelay))
+			g.Expect(queue.delays[0]).To(gomega.BeNumerically("<=", tc.MaxDelay))
+		})
+	}
+}
+
+func TestReconcileRequeuesOwnershipConflicts(t *testing.T) {
+	g := gomega.NewWithT(t)
+	fakeClock := clocktesting.NewFakeClock(time.Now())
+	previous := clock
+	clock = fakeClock
+	t.Cleanup(func() { clock = previous })
+
+	provider := newTestMemoryProvider(t)
+	c, queue := newFailingController(t, provider)
+	_, err := NewRegistry("glbc-2", provider).Ensure(context.TODO(), newTestRecord("other", aEndpoint("app.example.com", "10.0.0.2")), v1.DNSZone{ID: "example.com"})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	record := newTestRecord("app", aEndpoint("app.example.com", "10.0.0.1"))
+	g.Expect(c.reconcile(context.TODO(), record)).To(gomega.Succeed())
+	g.Expect(succeededCondition(record).Reason).To(gomega.Equal(ConditionReasonOwnershipConflict))
+	g.Expect(queue.delays).To(gomega.Equal([]time.Duration{minOwnershipConflictRequeueDelay}))
+
+	// The delay grows with the time the conflict has lasted
+	fakeClock.Step(5 * time.Minute)
+	g.Expect(c.reconcile(context.TODO(), record)).To(gomega.Succeed())
+	g.Expect(queue.delays[1]).To(gomega.BeNumerically("~", 5*time.Minute, time.Second))
+
+	fakeClock.Step(time.Hour)
+	g.Expect(c.reconcile(context.TODO(), record)).To(gomega.Succeed())
+	g.Expect(queue.delays[2]).To(gomega.Equal(maxOwnershipConflictRequeueDelay))
+}
+
+func TestReconcileSkipsInvalidRecordUntilModified(t *testing.T) {
+	g := gomega.NewWithT(t)
+	provider := &failingProvider{
+		Provider:  newTestMemoryProvider(t),
+		ensureErr: dnserrors.Errorf(dnserrors.ReasonInvalidRecord, "invalid A record target"),
+	}
+	c, _ := newFailingController(t, provider)
+
+	record := newTestRecord("app", aEndpoint("app.example.com", "10.0.0.1"))
+	g.Expect(c.reconcile(context.TODO(), record)).To(gomega.Succeed())
+	g.Expect(succeededCondition(record).Reason).To(gomega.Equal(ConditionReasonInvalidRecord))
+
+	// The record is not published again while it is unchanged
+	provider.ensureErr = nil
+	g.Expect(c.reconcile(context.TODO(), record)).To(gomega.Succeed())
+	g.Expect(succeededCondition(record).Reason).To(gomega.Equal(ConditionReasonInvalidRecord))
+
+	// The record is published again once modified
+	record.Generation++
+	g.Expect(c.reconcile(context.TODO(), record)).To(gomega.Succeed())
+	g.Expect(RecordIsPublished(record)).To(gomega.BeTrue())
+}
+
+func TestDeleteRecordIgnoresNotFound(t *testing.T) {
+	g := gomega.NewWithT(t)
+	provider := &failingProvider{Provider: newTestMemoryProvider(t)}
+	c, _ := newFailingController(t, provider)
+
+	record := newTestRecord("app", aEndpoint("app.example.com", "10.0.0.1"))
+	g.Expect(c.reconcile(context.TODO(), record)).To(gomega.Succeed())
+	g.Expect(RecordIsPublished(record)).To(gomega.BeTrue())
+
+	provider.deleteErr = dnserrors.Errorf(dnserrors.ReasonNotFound, "record app.example.com was not found")
+	g.Expect(c.deleteRecord(context.TODO(), record)).To(gomega.Succeed())
+
+	provider.deleteErr = dnserrors.Errorf(dnserrors.ReasonZoneNotFound, "zone example.com was not found")
+	g.Expect(c.deleteRecord(context.TODO(), record)).To(gomega.Succeed())
+
+	provider.deleteErr = dnserrors.Errorf(dnserrors.ReasonThrottled, "rate exceeded")
+	g.Expect(c.deleteRecord(context.TODO(), record)).NotTo(gomega.Succeed())
+}
+
+// pendingProvider publishes the records asynchronously, once published is set.
+type pendingProvider struct {
+	Provider
+	published bool
+}
+
+func (p *pendingProvider) IsPublished(_ context.Context, _ *v1.DNSRecord, _ v1.DNSZone) (bool, error) {
+	return p.published, nil
+}
+
+func TestReconcileRequeuesPendingPublication(t *testing.T) {
+	g := gomega.NewWithT(t)
+	provider := &pendingProvider{Provider: newTestMemoryProvider(t)}
+	c, queue := newFailingController(t, provider)
+
+	record := newTestRecord("app", aEndpoint("app.example.com", "10.0.0.1"))
+	g.Expect(c.reconcile(context.TODO(), record)).To(gomega.Succeed())
+	condition := succeededCondition(record)
+	g.Expect(condition.Status).To(gomega.Equal(string(ConditionUnknown)))
+	g.Expect(condition.Reason).To(gomega.Equal(ConditionReasonPublicationPending))
+	g.Expect(queue.delays).To(gomega.Equal([]time.Duration{publicationPendingRequeueDelay}))
+
+	// The publication is checked again, although the record is unchanged
+	provider.published = true
+	g.Expect(c.reconcile(context.TODO(), record)).To(gomega.Succeed())
+	g.Expect(RecordIsPublished(record)).To(gomega.BeTrue())
+	g.Expect(queue.delays).To(gomega.HaveLen(1))
+}
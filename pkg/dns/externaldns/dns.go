@@ -0,0 +1,352 @@
+package externaldns
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/kcp-dev/logicalcluster/v2"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/kuadrant/kcp-glbc/pkg/_internal/log"
+	v1 "github.com/kuadrant/kcp-glbc/pkg/apis/kuadrant/v1"
+)
+
+const (
+	WorkspaceEnvVar = "EXTERNAL_DNS_WORKSPACE"
+	NamespaceEnvVar = "EXTERNAL_DNS_NAMESPACE"
+	ZoneIDEnvVar    = "EXTERNAL_DNS_ZONE_ID"
+
+	// The labels identifying the DNSRecord and zone a DNSEndpoint is
+	// translated from. The values that are not valid label values, e.g. the
+	// zone IDs, are hashed.
+	LabelRecordName      = "kuadrant.dev/dns-record-name"
+	LabelRecordNamespace = "kuadrant.dev/dns-record-namespace"
+	LabelZone            = "kuadrant.dev/dns-zone"
+
+	// AnnotationRecordCluster and AnnotationZoneID hold the values of the
+	// logical cluster of the DNSRecord and of the zone ID as is.
+	AnnotationRecordCluster = "kuadrant.dev/dns-record-cluster"
+	AnnotationZoneID        = "kuadrant.dev/dns-zone-id"
+)
+
+// DNSEndpointResource is the resource of the external-dns DNSEndpoints.
+var DNSEndpointResource = schema.GroupVersionResource{Group: "externaldns.k8s.io", Version: "v1alpha1", Resource: "dnsendpoints"}
+
+// Provider does not call any DNS API, but translates the records into
+// external-dns DNSEndpoints, one per DNSRecord and zone, that the external-dns
+// instances watching them publish.
+type Provider struct {
+	client    dynamic.NamespaceableResourceInterface
+	namespace string
+	logger    logr.Logger
+}
+
+// Config is the necessary input to configure the provider.
+type Config struct {
+	// Client is the dynamic client of the workspace the DNSEndpoints are
+	// created in.
+	Client dynamic.Interface
+	// Namespace is the namespace the DNSEndpoints are created in.
+	Namespace string
+}
+
+func NewProvider(config Config) (*Provider, error) {
+	if config.Client == nil {
+		return nil, fmt.Errorf("a client is required")
+	}
+	if config.Namespace == "" {
+		return nil, fmt.Errorf("the namespace of the DNSEndpoints is required, set %s", NamespaceEnvVar)
+	}
+	return &Provider{
+		client:    config.Client.Resource(DNSEndpointResource),
+		namespace: config.Namespace,
+		logger:    log.Logger.WithName("external-dns").WithValues("namespace", config.Namespace),
+	}, nil
+}
+
+// endpoint is an external-dns endpoint, that has the same fields as a v1
+// endpoint, but for its routing policy that is encoded with provider specific
+// properties.
+type endpoint struct {
+	DNSName          string                        `json:"dnsName,omitempty"`
+	Targets          []string                      `json:"targets,omitempty"`
+	RecordType       string                        `json:"recordType,omitempty"`
+	SetIdentifier    string                        `json:"setIdentifier,omitempty"`
+	RecordTTL        int64                         `json:"recordTTL,omitempty"`
+	Labels           map[string]string             `json:"labels,omitempty"`
+	ProviderSpecific []v1.ProviderSpecificProperty `json:"providerSpecific,omitempty"`
+}
+
+type dnsEndpointSpec struct {
+	Endpoints []*endpoint `json:"endpoints,omitempty"`
+}
+
+type dnsEndpointStatus struct {
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+func (p *Provider) Ensure(ctx context.Context, record *v1.DNSRecord, zone v1.DNSZone) error {
+	name := dnsEndpointName(record, zone)
+
+	spec, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&dnsEndpointSpec{Endpoints: endpointsForRecord(record)})
+	if err != nil {
+		return err
+	}
+
+	existing, err := p.client.Namespace(p.namespace).Get(ctx, name, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		dnsEndpoint := &unstructured.Unstructured{}
+		dnsEndpoint.SetAPIVersion(DNSEndpointResource.GroupVersion().String())
+		dnsEndpoint.SetKind("DNSEndpoint")
+		dnsEndpoint.SetName(name)
+		dnsEndpoint.SetNamespace(p.namespace)
+		dnsEndpoint.SetLabels(dnsEndpointLabels(record, zone))
+		dnsEndpoint.SetAnnotations(map[string]string{
+			AnnotationRecordCluster: logicalcluster.From(record).String(),
+			AnnotationZoneID:        zone.ID,
+		})
+		dnsEndpoint.Object["spec"] = spec
+		if _, err := p.client.Namespace(p.namespace).Create(ctx, dnsEndpoint, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("failed to create DNSEndpoint %s for DNS record %s in zone %s: %w", name, record.Name, zone.ID, err)
+		}
+		p.logger.Info("Created DNSEndpoint", "name", name, "record", record.Name, "zone", zone.ID)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get DNSEndpoint %s for DNS record %s in zone %s: %w", name, record.Name, zone.ID, err)
+	}
+
+	// The DNSEndpoint is only updated when its endpoints have changed, so that
+	// its generation is left untouched otherwise
+	if reflect.DeepEqual(existing.Object["spec"], spec) {
+		return nil
+	}
+	existing.Object["spec"] = spec
+	if _, err := p.client.Namespace(p.namespace).Update(ctx, existing, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update DNSEndpoint %s for DNS record %s in zone %s: %w", name, record.Name, zone.ID, err)
+	}
+	p.logger.Info("Updated DNSEndpoint", "name", name, "record", record.Name, "zone", zone.ID)
+	return nil
+}
+
+func (p *Provider) Delete(ctx context.Context, record *v1.DNSRecord, zone v1.DNSZone) error {
+	name := dnsEndpointName(record, zone)
+	err := p.client.Namespace(p.namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete DNSEndpoint %s for DNS record %s in zone %s: %w", name, record.Name, zone.ID, err)
+	}
+	p.logger.Info("Deleted DNSEndpoint", "name", name, "record", record.Name, "zone", zone.ID)
+	return nil
+}
+
+// IsPublished returns whether external-dns has published the endpoints of the
+// DNSEndpoint of the record in the zone, as reported by its observed
+// generation.
+func (p *Provider) IsPublished(ctx context.Context, record *v1.DNSRecord, zone v1.DNSZone) (bool, error) {
+	name := dnsEndpointName(record, zone)
+	dnsEndpoint, err := p.client.Namespace(p.namespace).Get(ctx, name, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get DNSEndpoint %s for DNS record %s in zone %s: %w", name, record.Name, zone.ID, err)
+	}
+	status := &dnsEndpointStatus{}
+	if object, ok := dnsEndpoint.Object["status"].(map[string]interface{}); ok {
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object, status); err != nil {
+			return false, err
+		}
+	}
+	return status.ObservedGeneration >= dnsEndpoint.GetGeneration(), nil
+}
+
+// RegistersOwnership returns true, as external-dns records the ownership of the
+// names in its own registry, and only the DNSEndpoints of the GLBC can be
+// listed, rather than the records of the zones.
+func (p *Provider) RegistersOwnership() bool {
+	return true
+}
+
+// GetRecords returns the endpoints of the DNSEndpoints of the zone with the
+// given name and type. They are the endpoints requested to external-dns,
+// rather than the records of the zone, so they are not used to verify the
+// ownership of the names nor to audit the zone.
+func (p *Provider) GetRecords(ctx context.Context, zone v1.DNSZone, dnsName, recordType string) ([]*v1.Endpoint, error) {
+	endpoints, err := p.ListRecords(ctx, zone)
+	if err != nil {
+		return nil, err
+	}
+	var matching []*v1.Endpoint
+	for _, endpoint := range endpoints {
+		if normalizeName(endpoint.DNSName) == normalizeName(dnsName) && endpoint.RecordType == recordType {
+			matching = append(matching, endpoint)
+		}
+	}
+	return matching, nil
+}
+
+// ListRecords returns the endpoints of all the DNSEndpoints of the zone.
+func (p *Provider) ListRecords(ctx context.Context, zone v1.DNSZone) ([]*v1.Endpoint, error) {
+	list, err := p.client.Namespace(p.namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: LabelZone + "=" + hash(zone.ID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list DNSEndpoints of zone %s: %w", zone.ID, err)
+	}
+	var endpoints []*v1.Endpoint
+	for _, item := range list.Items {
+		object, ok := item.Object["spec"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		spec := &dnsEndpointSpec{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object, spec); err != nil {
+			return nil, fmt.Errorf("invalid DNSEndpoint %s: %v", item.GetName(), err)
+		}
+		for _, e := range spec.Endpoints {
+			endpoints = append(endpoints, endpointFromExternalDNS(e))
+		}
+	}
+	return endpoints, nil
+}
+
+// RequiresHealthProbes returns true, as the external-dns provider has no health checks
+// of its own, the endpoints are probed by the GLBC instead.
+func (p *Provider) RequiresHealthProbes() bool {
+	return true
+}
+
+// ReconcileHealthCheck is a no-op, external-dns does not manage health checks.
+func (p *Provider) ReconcileHealthCheck(_ context.Context, _ v1.EndpointHealthCheck, endpoint *v1.Endpoint) error {
+	p.logger.V(3).Info("Health checks are not supported by the external-dns provider, skipping", "endpoint", endpoint.SetID())
+	return nil
+}
+
+func (p *Provider) DeleteHealthCheck(_ context.Context, _ *v1.Endpoint) error {
+	return nil
+}
+
+// dnsEndpointName returns the name of the DNSEndpoint of the record in the
+// zone, that is unique across the logical clusters and namespaces of the
+// DNSRecords, as they are all translated into the same namespace.
+func dnsEndpointName(record *v1.DNSRecord, zone v1.DNSZone) string {
+	suffix := hash(strings.Join([]string{logicalcluster.From(record).String(), record.Namespace, record.Name, zone.ID}, "/"))
+	name := record.Name
+	if len(name) > 52 {
+		name = name[:52]
+	}
+	return strings.TrimSuffix(name, "-") + "-" + suffix
+}
+
+func dnsEndpointLabels(record *v1.DNSRecord, zone v1.DNSZone) map[string]string {
+	return map[string]string{
+		LabelRecordName:      truncate(record.Name),
+		LabelRecordNamespace: truncate(record.Namespace),
+		LabelZone:            hash(zone.ID),
+	}
+}
+
+func hash(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])[:10]
+}
+
+// truncate truncates the value to the maximum length of a label value.
+func truncate(value string) string {
+	if len(value) > 63 {
+		return strings.TrimRight(value[:63], "-_.")
+	}
+	return value
+}
+
+func normalizeName(name string) string {
+	return strings.ToLower(strings.TrimSuffix(name, "."))
+}
+
+func endpointsForRecord(record *v1.DNSRecord) []*endpoint {
+	var endpoints []*endpoint
+	for _, e := range record.Spec.Endpoints {
+		endpoints = append(endpoints, endpointForExternalDNS(e))
+	}
+	return endpoints
+}
+
+// endpointForExternalDNS converts the endpoint, with its routing policy
+// encoded with the provider specific properties of external-dns.
+func endpointForExternalDNS(e *v1.Endpoint) *endpoint {
+	out := &endpoint{
+		DNSName:       e.DNSName,
+		Targets:       e.Targets,
+		RecordType:    e.RecordType,
+		SetIdentifier: e.SetIdentifier,
+		RecordTTL:     int64(e.RecordTTL),
+		Labels:        e.Labels,
+	}
+	for _, property := range e.ProviderSpecific {
+		if !v1.IsLegacyRoutingProperty(property.Name) {
+			out.ProviderSpecific = append(out.ProviderSpecific, property)
+		}
+	}
+	out.ProviderSpecific = append(out.ProviderSpecific, routingPolicyProperties(e.GetRoutingPolicy())...)
+	return out
+}
+
+func endpointFromExternalDNS(e *endpoint) *v1.Endpoint {
+	out := &v1.Endpoint{
+		DNSName:       e.DNSName,
+		Targets:       e.Targets,
+		RecordType:    e.RecordType,
+		SetIdentifier: e.SetIdentifier,
+		RecordTTL:     v1.TTL(e.RecordTTL),
+		Labels:        e.Labels,
+		RoutingPolicy: v1.RoutingPolicyFromProviderSpecific(e.ProviderSpecific),
+	}
+	for _, property := range e.ProviderSpecific {
+		if !v1.IsLegacyRoutingProperty(property.Name) {
+			out.ProviderSpecific = append(out.ProviderSpecific, property)
+		}
+	}
+	return out
+}
+
+// routingPolicyProperties returns the provider specific properties of
+// external-dns encoding the routing policy. They are the legacy ones of the
+// DNSRecords, that were borrowed from external-dns.
+func routingPolicyProperties(policy *v1.RoutingPolicy) []v1.ProviderSpecificProperty {
+	if policy == nil {
+		return nil
+	}
+	var properties []v1.ProviderSpecificProperty
+	if policy.Weighted != nil {
+		properties = append(properties, v1.ProviderSpecificProperty{Name: v1.LegacyProviderSpecificWeight, Value: strconv.FormatInt(policy.Weighted.Weight, 10)})
+	}
+	if policy.Failover != nil {
+		properties = append(properties, v1.ProviderSpecificProperty{Name: v1.LegacyProviderSpecificFailover, Value: string(policy.Failover.Role)})
+	}
+	if policy.Geo != nil {
+		switch {
+		case policy.Geo.Default:
+			properties = append(properties, v1.ProviderSpecificProperty{Name: v1.LegacyProviderSpecificGeolocationCountryCode, Value: "*"})
+		case policy.Geo.Country != "":
+			properties = append(properties, v1.ProviderSpecificProperty{Name: v1.LegacyProviderSpecificGeolocationCountryCode, Value: policy.Geo.Country})
+		case policy.Geo.Continent != "":
+			properties = append(properties, v1.ProviderSpecificProperty{Name: v1.LegacyProviderSpecificGeolocationContinentCode, Value: policy.Geo.Continent})
+		}
+	}
+	if policy.Latency != nil {
+		properties = append(properties, v1.ProviderSpecificProperty{Name: v1.LegacyProviderSpecificRegion, Value: policy.Latency.Region})
+	}
+	return properties
+}
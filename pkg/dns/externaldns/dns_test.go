@@ -0,0 +1,151 @@
+package externaldns
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+	"github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+
+	v1 "github.com/kuadrant/kcp-glbc/pkg/apis/kuadrant/v1"
+)
+
+func newTestProvider(t *testing.T) (*Provider, *fake.FakeDynamicClient) {
+	client := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		DNSEndpointResource: "DNSEndpointList",
+	})
+	provider, err := NewProvider(Config{Client: client, Namespace: "kcp-glbc"})
+	if err != nil {
+		t.Fatalf("unexpected error creating provider: %v", err)
+	}
+	return provider, client
+}
+
+func newTestRecord(endpoints ...*v1.Endpoint) *v1.DNSRecord {
+	return &v1.DNSRecord{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "app",
+			Namespace:   "default",
+			Annotations: map[string]string{logicalcluster.AnnotationKey: "root:org:ws"},
+		},
+		Spec: v1.DNSRecordSpec{Endpoints: endpoints},
+	}
+}
+
+func weightedEndpoint(ip string, weight int64) *v1.Endpoint {
+	return &v1.Endpoint{
+		DNSName:       "app.example.com",
+		RecordType:    string(v1.ARecordType),
+		SetIdentifier: ip,
+		RecordTTL:     60,
+		Targets:       v1.Targets{ip},
+		RoutingPolicy: &v1.RoutingPolicy{Weighted: &v1.WeightedRoutingPolicy{Weight: weight}},
+	}
+}
+
+func TestEnsureAndDelete(t *testing.T) {
+	g := gomega.NewWithT(t)
+	provider, client := newTestProvider(t)
+	zone := v1.DNSZone{ID: "example.com"}
+
+	record := newTestRecord(weightedEndpoint("10.0.0.1", 120), weightedEndpoint("10.0.0.2", 0))
+	g.Expect(provider.Ensure(context.TODO(), record, zone)).To(gomega.Succeed())
+
+	list, err := client.Resource(DNSEndpointResource).Namespace("kcp-glbc").List(context.TODO(), metav1.ListOptions{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(list.Items).To(gomega.HaveLen(1))
+	dnsEndpoint := list.Items[0]
+	g.Expect(dnsEndpoint.GetLabels()).To(gomega.HaveKeyWithValue(LabelRecordName, "app"))
+	g.Expect(dnsEndpoint.GetLabels()).To(gomega.HaveKeyWithValue(LabelRecordNamespace, "default"))
+	g.Expect(dnsEndpoint.GetAnnotations()).To(gomega.HaveKeyWithValue(AnnotationRecordCluster, "root:org:ws"))
+
+	// The routing policy is encoded with the provider specific properties of
+	// external-dns
+	endpoints, _, err := unstructured.NestedSlice(dnsEndpoint.Object, "spec", "endpoints")
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(endpoints).To(gomega.HaveLen(2))
+	g.Expect(endpoints[0]).To(gomega.HaveKeyWithValue("setIdentifier", "10.0.0.1"))
+	g.Expect(endpoints[0]).To(gomega.HaveKeyWithValue("providerSpecific", []interface{}{
+		map[string]interface{}{"name": v1.LegacyProviderSpecificWeight, "value": "120"},
+	}))
+
+	records, err := provider.GetRecords(context.TODO(), zone, "app.example.com.", string(v1.ARecordType))
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(records).To(gomega.ConsistOf(record.Spec.Endpoints[0], record.Spec.Endpoints[1]))
+
+	record.Spec.Endpoints = record.Spec.Endpoints[:1]
+	g.Expect(provider.Ensure(context.TODO(), record, zone)).To(gomega.Succeed())
+	records, err = provider.ListRecords(context.TODO(), zone)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(records).To(gomega.Equal(record.Spec.Endpoints))
+
+	// The records of other zones are not listed
+	records, err = provider.ListRecords(context.TODO(), v1.DNSZone{ID: "example.org"})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(records).To(gomega.BeEmpty())
+
+	g.Expect(provider.Delete(context.TODO(), record, zone)).To(gomega.Succeed())
+	records, err = provider.ListRecords(context.TODO(), zone)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(records).To(gomega.BeEmpty())
+
+	// Deleting a record that does not exist succeeds
+	g.Expect(provider.Delete(context.TODO(), record, zone)).To(gomega.Succeed())
+}
+
+func TestDNSEndpointNameIsUniqueAcrossClusters(t *testing.T) {
+	g := gomega.NewWithT(t)
+	zone := v1.DNSZone{ID: "example.com"}
+
+	record := newTestRecord()
+	other := newTestRecord()
+	other.Annotations[logicalcluster.AnnotationKey] = "root:org:other"
+
+	g.Expect(dnsEndpointName(record, zone)).To(gomega.HavePrefix("app-"))
+	g.Expect(dnsEndpointName(record, zone)).NotTo(gomega.Equal(dnsEndpointName(other, zone)))
+	g.Expect(dnsEndpointName(record, zone)).NotTo(gomega.Equal(dnsEndpointName(record, v1.DNSZone{ID: "example.org"})))
+}
+
+func TestIsPublished(t *testing.T) {
+	g := gomega.NewWithT(t)
+	provider, client := newTestProvider(t)
+	zone := v1.DNSZone{ID: "example.com"}
+
+	record := newTestRecord(weightedEndpoint("10.0.0.1", 120))
+	published, err := provider.IsPublished(context.TODO(), record, zone)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(published).To(gomega.BeFalse())
+
+	g.Expect(provider.Ensure(context.TODO(), record, zone)).To(gomega.Succeed())
+
+	// The fake client does not maintain the generation
+	resource := client.Resource(DNSEndpointResource).Namespace("kcp-glbc")
+	dnsEndpoint, err := resource.Get(context.TODO(), dnsEndpointName(record, zone), metav1.GetOptions{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	dnsEndpoint.SetGeneration(2)
+	g.Expect(unstructured.SetNestedField(dnsEndpoint.Object, int64(1), "status", "observedGeneration")).To(gomega.Succeed())
+	_, err = resource.Update(context.TODO(), dnsEndpoint, metav1.UpdateOptions{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	published, err = provider.IsPublished(context.TODO(), record, zone)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(published).To(gomega.BeFalse())
+
+	// external-dns reports the DNSEndpoint as published once it has observed
+	// its last generation
+	dnsEndpoint, err = resource.Get(context.TODO(), dnsEndpointName(record, zone), metav1.GetOptions{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(unstructured.SetNestedField(dnsEndpoint.Object, int64(2), "status", "observedGeneration")).To(gomega.Succeed())
+	_, err = resource.Update(context.TODO(), dnsEndpoint, metav1.UpdateOptions{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	published, err = provider.IsPublished(context.TODO(), record, zone)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(published).To(gomega.BeTrue())
+}
@@ -0,0 +1,189 @@
+package gcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	dnserrors "github.com/kuadrant/kcp-glbc/pkg/dns/errors"
+)
+
+// defaultEndpoint is the base URL of the Cloud DNS v1 REST API.
+const defaultEndpoint = "https://dns.googleapis.com/dns/v1/"
+
+// resourceRecordSet mirrors the Cloud DNS ResourceRecordSet resource.
+// See https://cloud.google.com/dns/docs/reference/v1/resourceRecordSets
+type resourceRecordSet struct {
+	Name          string         `json:"name"`
+	Type          string         `json:"type"`
+	TTL           int64          `json:"ttl,omitempty"`
+	Rrdatas       []string       `json:"rrdatas,omitempty"`
+	RoutingPolicy *routingPolicy `json:"routingPolicy,omitempty"`
+}
+
+type routingPolicy struct {
+	Wrr *wrrPolicy `json:"wrr,omitempty"`
+}
+
+type wrrPolicy struct {
+	Items []wrrPolicyItem `json:"items"`
+}
+
+type wrrPolicyItem struct {
+	Weight  float64  `json:"weight"`
+	Rrdatas []string `json:"rrdatas"`
+}
+
+type resourceRecordSetsList struct {
+	Rrsets        []*resourceRecordSet `json:"rrsets"`
+	NextPageToken string               `json:"nextPageToken"`
+}
+
+type managedZonesList struct {
+	ManagedZones []struct {
+		Name    string `json:"name"`
+		DNSName string `json:"dnsName"`
+	} `json:"managedZones"`
+}
+
+// apiError is the error payload returned by the Cloud DNS API.
+type apiError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("cloud dns: %d %s", e.Code, e.Message)
+}
+
+// reason returns the reason of the error. The Cloud DNS API returns a not found
+// error that names the managed zone when the zone does not exist, and may
+// return a forbidden error when a rate limit is exceeded.
+func (e *apiError) reason() dnserrors.Reason {
+	switch {
+	case e.Code == http.StatusNotFound && strings.Contains(e.Message, "managedZone"):
+		return dnserrors.ReasonZoneNotFound
+	case e.Code == http.StatusForbidden && strings.Contains(e.Message, "rateLimitExceeded"):
+		return dnserrors.ReasonThrottled
+	}
+	return dnserrors.ReasonForHTTPStatus(e.Code)
+}
+
+func isNotFound(err error) bool {
+	var apiErr *apiError
+	return errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound
+}
+
+// client is a minimal Cloud DNS REST client covering the resource record set
+// operations required by the provider.
+type client struct {
+	httpClient *http.Client
+	endpoint   string
+	project    string
+}
+
+func (c *client) listManagedZones(ctx context.Context, maxResults int) (*managedZonesList, error) {
+	out := &managedZonesList{}
+	query := url.Values{"maxResults": []string{fmt.Sprint(maxResults)}}
+	if err := c.do(ctx, http.MethodGet, c.path("managedZones")+"?"+query.Encode(), nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *client) getRecordSet(ctx context.Context, zone, name, recordType string) (*resourceRecordSet, error) {
+	out := &resourceRecordSet{}
+	if err := c.do(ctx, http.MethodGet, c.recordSetPath(zone, name, recordType), nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *client) listRecordSets(ctx context.Context, zone, pageToken string) (*resourceRecordSetsList, error) {
+	out := &resourceRecordSetsList{}
+	path := c.path("managedZones", zone, "rrsets")
+	if pageToken != "" {
+		path += "?" + url.Values{"pageToken": []string{pageToken}}.Encode()
+	}
+	if err := c.do(ctx, http.MethodGet, path, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *client) createRecordSet(ctx context.Context, zone string, rrset *resourceRecordSet) error {
+	return c.do(ctx, http.MethodPost, c.path("managedZones", zone, "rrsets"), rrset, nil)
+}
+
+func (c *client) patchRecordSet(ctx context.Context, zone string, rrset *resourceRecordSet) error {
+	return c.do(ctx, http.MethodPatch, c.recordSetPath(zone, rrset.Name, rrset.Type), rrset, nil)
+}
+
+func (c *client) deleteRecordSet(ctx context.Context, zone, name, recordType string) error {
+	return c.do(ctx, http.MethodDelete, c.recordSetPath(zone, name, recordType), nil, nil)
+}
+
+func (c *client) recordSetPath(zone, name, recordType string) string {
+	return c.path("managedZones", zone, "rrsets", name, recordType)
+}
+
+func (c *client) path(elements ...string) string {
+	escaped := make([]string, 0, len(elements)+2)
+	escaped = append(escaped, "projects", url.PathEscape(c.project))
+	for _, e := range elements {
+		escaped = append(escaped, url.PathEscape(e))
+	}
+	return strings.TrimSuffix(c.endpoint, "/") + "/" + strings.Join(escaped, "/")
+}
+
+func (c *client) do(ctx context.Context, method, url string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		payload := struct {
+			Error *apiError `json:"error"`
+		}{}
+		if err := json.Unmarshal(data, &payload); err != nil || payload.Error == nil {
+			payload.Error = &apiError{Message: strings.TrimSpace(string(data))}
+		}
+		payload.Error.Code = resp.StatusCode
+		return dnserrors.New(payload.Error.reason(), payload.Error)
+	}
+
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
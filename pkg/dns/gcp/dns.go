@@ -0,0 +1,351 @@
+package gcp
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"golang.org/x/oauth2/google"
+
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"github.com/kuadrant/kcp-glbc/pkg/_internal/log"
+	v1 "github.com/kuadrant/kcp-glbc/pkg/apis/kuadrant/v1"
+	dnserrors "github.com/kuadrant/kcp-glbc/pkg/dns/errors"
+)
+
+const (
+	// ProviderSpecificWeight overrides the weight of the weighted routing policy.
+	ProviderSpecificWeight = "gcp/weight"
+
+	ProjectIDEnvVar = "GCP_PROJECT_ID"
+	ZoneIDEnvVar    = "GCP_DNS_PUBLIC_ZONE_ID"
+
+	cloudDNSScope = "https://www.googleapis.com/auth/ndev.clouddns.readwrite"
+)
+
+// Provider publishes DNSRecords to Google Cloud DNS managed zones.
+// Endpoints sharing the same name and type but with distinct set identifiers
+// are published as a single record set with a weighted round robin routing policy.
+type Provider struct {
+	client *client
+	config Config
+	logger logr.Logger
+}
+
+// Config is the necessary input to configure the provider.
+type Config struct {
+	// Project is the GCP project the managed zones belong to.
+	Project string
+	// Endpoint is the base URL of the Cloud DNS API. Defaults to the public API endpoint.
+	Endpoint string
+	// HTTPClient is the client used to call the Cloud DNS API. Defaults to a
+	// client authenticated using the Application Default Credentials.
+	HTTPClient *http.Client
+}
+
+func NewProvider(config Config) (*Provider, error) {
+	if config.Project == "" {
+		return nil, fmt.Errorf("GCP project is required, set %s", ProjectIDEnvVar)
+	}
+	if config.Endpoint == "" {
+		config.Endpoint = defaultEndpoint
+	}
+
+	httpClient := config.HTTPClient
+	if httpClient == nil {
+		var err error
+		httpClient, err = google.DefaultClient(context.Background(), cloudDNSScope)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't create GCP client: %v", err)
+		}
+	}
+
+	p := &Provider{
+		client: &client{
+			httpClient: httpClient,
+			endpoint:   config.Endpoint,
+			project:    config.Project,
+		},
+		config: config,
+		logger: log.Logger.WithName("gcp-clouddns").WithValues("project", config.Project),
+	}
+	if err := validateServiceEndpoints(p); err != nil {
+		return nil, fmt.Errorf("failed to validate GCP provider service endpoints: %v", err)
+	}
+
+	return p, nil
+}
+
+// validateServiceEndpoints validates that the provider can communicate with
+// the Cloud DNS API by listing the managed zones of the project.
+func validateServiceEndpoints(provider *Provider) error {
+	var errs []error
+	if _, err := provider.client.listManagedZones(context.Background(), 1); err != nil {
+		errs = append(errs, fmt.Errorf("failed to list cloud dns managed zones: %v", err))
+	}
+	return kerrors.NewAggregate(errs)
+}
+
+func (p *Provider) Ensure(ctx context.Context, record *v1.DNSRecord, zone v1.DNSZone) error {
+	desired, err := p.recordSetsForEndpoints(record.Spec.Endpoints)
+	if err != nil {
+		return err
+	}
+
+	for _, rrset := range desired {
+		if err := p.upsertRecordSet(ctx, zone.ID, rrset); err != nil {
+			return fmt.Errorf("failed to update record in zone %s: %w", zone.ID, err)
+		}
+	}
+
+	// Delete any previously published record sets that are no longer present in record.Spec.Endpoints
+	published, err := p.recordSetsForEndpoints(endpointsFromZoneStatus(record, zone.ID))
+	if err != nil {
+		return err
+	}
+	for key, rrset := range published {
+		if _, found := desired[key]; found {
+			continue
+		}
+		if err := p.client.deleteRecordSet(ctx, zone.ID, rrset.Name, rrset.Type); err != nil && !isNotFound(err) {
+			return fmt.Errorf("failed to delete record in zone %s: %w", zone.ID, err)
+		}
+	}
+
+	p.logger.Info("Upserted DNS record", "record", record.Spec, "zone", zone)
+	return nil
+}
+
+func (p *Provider) Delete(ctx context.Context, record *v1.DNSRecord, zone v1.DNSZone) error {
+	rrsets, err := p.recordSetsForEndpoints(record.Spec.Endpoints)
+	if err != nil {
+		return err
+	}
+
+	for _, rrset := range rrsets {
+		if err := p.client.deleteRecordSet(ctx, zone.ID, rrset.Name, rrset.Type); err != nil && !isNotFound(err) {
+			return fmt.Errorf("failed to delete record in zone %s: %w", zone.ID, err)
+		}
+	}
+
+	p.logger.Info("Deleted DNS record", "record", record.Spec, "zone", zone)
+	return nil
+}
+
+// GetRecords returns the endpoints of the record set of the zone with the
+// given name and type. Each item of a weighted round robin routing policy is
+// returned as a distinct endpoint.
+func (p *Provider) GetRecords(ctx context.Context, zone v1.DNSZone, dnsName, recordType string) ([]*v1.Endpoint, error) {
+	rrset, err := p.client.getRecordSet(ctx, zone.ID, fqdn(dnsName), recordType)
+	if isNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get record set %s in zone %s: %w", dnsName, zone.ID, err)
+	}
+
+	return endpointsForRecordSet(rrset), nil
+}
+
+// ListRecords returns all the record sets of the zone.
+func (p *Provider) ListRecords(ctx context.Context, zone v1.DNSZone) ([]*v1.Endpoint, error) {
+	var endpoints []*v1.Endpoint
+	pageToken := ""
+	for {
+		list, err := p.client.listRecordSets(ctx, zone.ID, pageToken)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list record sets in zone %s: %w", zone.ID, err)
+		}
+		for _, rrset := range list.Rrsets {
+			endpoints = append(endpoints, endpointsForRecordSet(rrset)...)
+		}
+		if list.NextPageToken == "" {
+			return endpoints, nil
+		}
+		pageToken = list.NextPageToken
+	}
+}
+
+// endpointsForRecordSet returns the endpoints of the record set, one per item
+// of its weighted round robin routing policy, if any.
+func endpointsForRecordSet(rrset *resourceRecordSet) []*v1.Endpoint {
+	endpointFor := func(rrdatas []string) *v1.Endpoint {
+		endpoint := &v1.Endpoint{
+			DNSName:    strings.TrimSuffix(rrset.Name, "."),
+			RecordType: rrset.Type,
+			RecordTTL:  v1.TTL(rrset.TTL),
+		}
+		for _, rrdata := range rrdatas {
+			if rrset.Type == string(v1.CNAMERecordType) {
+				rrdata = strings.TrimSuffix(rrdata, ".")
+			}
+			endpoint.Targets = append(endpoint.Targets, rrdata)
+		}
+		return endpoint
+	}
+
+	if rrset.RoutingPolicy == nil || rrset.RoutingPolicy.Wrr == nil {
+		return []*v1.Endpoint{endpointFor(rrset.Rrdatas)}
+	}
+	endpoints := make([]*v1.Endpoint, 0, len(rrset.RoutingPolicy.Wrr.Items))
+	for _, item := range rrset.RoutingPolicy.Wrr.Items {
+		endpoint := endpointFor(item.Rrdatas)
+		endpoint.SetProviderSpecific(ProviderSpecificWeight, strconv.FormatFloat(item.Weight, 'f', -1, 64))
+		endpoints = append(endpoints, endpoint)
+	}
+	return endpoints
+}
+
+// RequiresHealthProbes returns true, as the GCP provider has no health checks
+// of its own, the endpoints are probed by the GLBC instead.
+func (p *Provider) RequiresHealthProbes() bool {
+	return true
+}
+
+// ReconcileHealthCheck is a no-op, Cloud DNS health checked routing policies
+// are only available for private zones targeting internal load balancers.
+func (p *Provider) ReconcileHealthCheck(ctx context.Context, _ v1.EndpointHealthCheck, endpoint *v1.Endpoint) error {
+	p.logger.V(3).Info("Health checks are not supported by the GCP provider, skipping", "endpoint", endpoint.SetID())
+	return nil
+}
+
+func (p *Provider) DeleteHealthCheck(ctx context.Context, _ *v1.Endpoint) error {
+	return nil
+}
+
+func (p *Provider) upsertRecordSet(ctx context.Context, zoneID string, rrset *resourceRecordSet) error {
+	_, err := p.client.getRecordSet(ctx, zoneID, rrset.Name, rrset.Type)
+	if isNotFound(err) {
+		return p.client.createRecordSet(ctx, zoneID, rrset)
+	}
+	if err != nil {
+		return err
+	}
+	return p.client.patchRecordSet(ctx, zoneID, rrset)
+}
+
+// recordSetsForEndpoints groups the endpoints by name and type, and returns
+// the corresponding Cloud DNS record sets, keyed by name and type.
+func (p *Provider) recordSetsForEndpoints(endpoints []*v1.Endpoint) (map[string]*resourceRecordSet, error) {
+	groups := map[string][]*v1.Endpoint{}
+	for _, endpoint := range endpoints {
+		if err := validateEndpoint(endpoint); err != nil {
+			return nil, err
+		}
+		key := recordSetKey(endpoint.DNSName, endpoint.RecordType)
+		groups[key] = append(groups[key], endpoint)
+	}
+
+	rrsets := make(map[string]*resourceRecordSet, len(groups))
+	for key, group := range groups {
+		rrsets[key] = p.recordSetForEndpoints(group)
+	}
+	return rrsets, nil
+}
+
+// recordSetForEndpoints converts a group of endpoints sharing the same name
+// and type into a record set. A group with set identifiers is converted into a
+// weighted round robin routing policy, with an item per set identifier.
+func (p *Provider) recordSetForEndpoints(endpoints []*v1.Endpoint) *resourceRecordSet {
+	first := endpoints[0]
+	rrset := &resourceRecordSet{
+		Name: fqdn(first.DNSName),
+		Type: first.RecordType,
+		TTL:  int64(first.RecordTTL),
+	}
+
+	if len(endpoints) == 1 && first.SetIdentifier == "" {
+		rrset.Rrdatas = rrdatas(first)
+		return rrset
+	}
+
+	sort.SliceStable(endpoints, func(i, j int) bool {
+		return endpoints[i].SetID() < endpoints[j].SetID()
+	})
+
+	items := make([]wrrPolicyItem, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		items = append(items, wrrPolicyItem{
+			Weight:  p.weight(endpoint),
+			Rrdatas: rrdatas(endpoint),
+		})
+	}
+	rrset.RoutingPolicy = &routingPolicy{Wrr: &wrrPolicy{Items: items}}
+
+	return rrset
+}
+
+func (p *Provider) weight(endpoint *v1.Endpoint) float64 {
+	if value, ok := endpoint.GetProviderSpecific(ProviderSpecificWeight); ok {
+		weight, err := strconv.ParseFloat(value, 64)
+		if err != nil || weight < 0 {
+			p.logger.Error(err, "Failed parsing value, using weight of 0", "weight", ProviderSpecificWeight, "value", value)
+			return 0
+		}
+		return weight
+	}
+	if policy := endpoint.GetRoutingPolicy(); policy != nil && policy.Weighted != nil {
+		return math.Max(float64(policy.Weighted.Weight), 0)
+	}
+	return 1
+}
+
+func validateEndpoint(endpoint *v1.Endpoint) error {
+	switch endpoint.RecordType {
+	case string(v1.ARecordType), string(v1.AAAARecordType), string(v1.CNAMERecordType), string(v1.TXTRecordType), string(v1.CAARecordType):
+	default:
+		return dnserrors.Errorf(dnserrors.ReasonInvalidRecord, "unsupported record type %s", endpoint.RecordType)
+	}
+	if len(endpoint.DNSName) == 0 {
+		return dnserrors.Errorf(dnserrors.ReasonInvalidRecord, "domain is required")
+	}
+	if len(endpoint.Targets) == 0 {
+		return dnserrors.Errorf(dnserrors.ReasonInvalidRecord, "targets is required")
+	}
+	if endpoint.RecordType == string(v1.CAARecordType) {
+		for _, target := range endpoint.Targets {
+			if _, _, _, err := v1.ParseCAATarget(target); err != nil {
+				return dnserrors.Errorf(dnserrors.ReasonInvalidRecord, "%v", err)
+			}
+		}
+	}
+	return nil
+}
+
+func rrdatas(endpoint *v1.Endpoint) []string {
+	rrdatas := make([]string, 0, len(endpoint.Targets))
+	for _, target := range endpoint.Targets {
+		if endpoint.RecordType == string(v1.CNAMERecordType) {
+			target = fqdn(target)
+		}
+		rrdatas = append(rrdatas, target)
+	}
+	return rrdatas
+}
+
+func recordSetKey(name, recordType string) string {
+	return fqdn(name) + "/" + recordType
+}
+
+// fqdn returns the name terminated with a dot, as expected by Cloud DNS.
+func fqdn(name string) string {
+	if strings.HasSuffix(name, ".") {
+		return name
+	}
+	return name + "."
+}
+
+func endpointsFromZoneStatus(record *v1.DNSRecord, zoneID string) []*v1.Endpoint {
+	for _, zoneStatus := range record.Status.Zones {
+		if zoneStatus.DNSZone.ID == zoneID {
+			return zoneStatus.Endpoints
+		}
+	}
+	return []*v1.Endpoint{}
+}
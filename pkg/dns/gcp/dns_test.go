@@ -0,0 +1,229 @@
+package gcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/onsi/gomega"
+
+	v1 "github.com/kuadrant/kcp-glbc/pkg/apis/kuadrant/v1"
+	dnserrors "github.com/kuadrant/kcp-glbc/pkg/dns/errors"
+)
+
+// fakeCloudDNS is a minimal in-memory stand-in for the Cloud DNS REST API.
+type fakeCloudDNS struct {
+	mu     sync.Mutex
+	rrsets map[string]resourceRecordSet
+}
+
+func (f *fakeCloudDNS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// /projects/{project}/managedZones[/{zone}/rrsets[/{name}/{type}]]
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case len(parts) == 3 && r.Method == http.MethodGet:
+		_, _ = w.Write([]byte(`{"managedZones":[{"name":"test-zone","dnsName":"example.com."}]}`))
+	case len(parts) == 5 && r.Method == http.MethodGet:
+		list := resourceRecordSetsList{}
+		for key := range f.rrsets {
+			if strings.HasPrefix(key, parts[3]+"/") {
+				rrset := f.rrsets[key]
+				list.Rrsets = append(list.Rrsets, &rrset)
+			}
+		}
+		sort.Slice(list.Rrsets, func(i, j int) bool {
+			return recordSetKey(list.Rrsets[i].Name, list.Rrsets[i].Type) < recordSetKey(list.Rrsets[j].Name, list.Rrsets[j].Type)
+		})
+		_ = json.NewEncoder(w).Encode(list)
+	case len(parts) == 5 && r.Method == http.MethodPost:
+		rrset := resourceRecordSet{}
+		if err := json.NewDecoder(r.Body).Decode(&rrset); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		key := parts[3] + "/" + recordSetKey(rrset.Name, rrset.Type)
+		if _, exists := f.rrsets[key]; exists {
+			writeError(w, http.StatusConflict)
+			return
+		}
+		f.rrsets[key] = rrset
+	case len(parts) == 7:
+		key := parts[3] + "/" + recordSetKey(parts[5], parts[6])
+		rrset, exists := f.rrsets[key]
+		if !exists {
+			writeError(w, http.StatusNotFound)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			_ = json.NewEncoder(w).Encode(rrset)
+		case http.MethodPatch:
+			if err := json.NewDecoder(r.Body).Decode(&rrset); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			f.rrsets[key] = rrset
+		case http.MethodDelete:
+			delete(f.rrsets, key)
+		}
+	default:
+		writeError(w, http.StatusNotFound)
+	}
+}
+
+func writeError(w http.ResponseWriter, code int) {
+	w.WriteHeader(code)
+	_, _ = w.Write([]byte(fmt.Sprintf(`{"error":{"code":%d,"message":%q}}`, code, http.StatusText(code))))
+}
+
+func newTestProvider(t *testing.T) (*Provider, *fakeCloudDNS) {
+	fake := &fakeCloudDNS{rrsets: map[string]resourceRecordSet{}}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	provider, err := NewProvider(Config{
+		Project:    "test-project",
+		Endpoint:   server.URL,
+		HTTPClient: server.Client(),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error creating provider: %v", err)
+	}
+	return provider, fake
+}
+
+func weightedEndpoint(ip string, weight int64) *v1.Endpoint {
+	return &v1.Endpoint{
+		DNSName:       "app.example.com",
+		RecordType:    string(v1.ARecordType),
+		RecordTTL:     60,
+		SetIdentifier: ip,
+		Targets:       v1.Targets{ip},
+		RoutingPolicy: &v1.RoutingPolicy{Weighted: &v1.WeightedRoutingPolicy{Weight: weight}},
+	}
+}
+
+func TestEnsureWeightedEndpoints(t *testing.T) {
+	g := gomega.NewWithT(t)
+	provider, fake := newTestProvider(t)
+	zone := v1.DNSZone{ID: "test-zone"}
+
+	record := &v1.DNSRecord{
+		Spec: v1.DNSRecordSpec{
+			Endpoints: []*v1.Endpoint{
+				weightedEndpoint("10.0.0.2", 60),
+				weightedEndpoint("10.0.0.1", 120),
+			},
+		},
+	}
+
+	g.Expect(provider.Ensure(context.TODO(), record, zone)).To(gomega.Succeed())
+	g.Expect(fake.rrsets).To(gomega.HaveLen(1))
+
+	rrset := fake.rrsets["test-zone/app.example.com./A"]
+	g.Expect(rrset.TTL).To(gomega.Equal(int64(60)))
+	g.Expect(rrset.Rrdatas).To(gomega.BeEmpty())
+	g.Expect(rrset.RoutingPolicy).NotTo(gomega.BeNil())
+	g.Expect(rrset.RoutingPolicy.Wrr.Items).To(gomega.Equal([]wrrPolicyItem{
+		{Weight: 120, Rrdatas: []string{"10.0.0.1"}},
+		{Weight: 60, Rrdatas: []string{"10.0.0.2"}},
+	}))
+
+	// Updating the endpoints patches the existing record set
+	record.Spec.Endpoints = []*v1.Endpoint{weightedEndpoint("10.0.0.3", 120)}
+	g.Expect(provider.Ensure(context.TODO(), record, zone)).To(gomega.Succeed())
+	g.Expect(fake.rrsets["test-zone/app.example.com./A"].RoutingPolicy.Wrr.Items).To(gomega.Equal([]wrrPolicyItem{
+		{Weight: 120, Rrdatas: []string{"10.0.0.3"}},
+	}))
+}
+
+func TestEnsureRemovesStaleRecordSets(t *testing.T) {
+	g := gomega.NewWithT(t)
+	provider, fake := newTestProvider(t)
+	zone := v1.DNSZone{ID: "test-zone"}
+
+	stale := &v1.Endpoint{
+		DNSName:    "old.example.com",
+		RecordType: string(v1.CNAMERecordType),
+		RecordTTL:  60,
+		Targets:    v1.Targets{"lb.example.com"},
+	}
+	record := &v1.DNSRecord{
+		Spec: v1.DNSRecordSpec{Endpoints: []*v1.Endpoint{stale}},
+	}
+	g.Expect(provider.Ensure(context.TODO(), record, zone)).To(gomega.Succeed())
+	g.Expect(fake.rrsets["test-zone/old.example.com./CNAME"].Rrdatas).To(gomega.Equal([]string{"lb.example.com."}))
+
+	record.Status.Zones = []v1.DNSZoneStatus{{DNSZone: zone, Endpoints: []*v1.Endpoint{stale}}}
+	record.Spec.Endpoints = []*v1.Endpoint{weightedEndpoint("10.0.0.1", 120)}
+	g.Expect(provider.Ensure(context.TODO(), record, zone)).To(gomega.Succeed())
+	g.Expect(fake.rrsets).To(gomega.HaveLen(1))
+	g.Expect(fake.rrsets).To(gomega.HaveKey("test-zone/app.example.com./A"))
+}
+
+func TestDelete(t *testing.T) {
+	g := gomega.NewWithT(t)
+	provider, fake := newTestProvider(t)
+	zone := v1.DNSZone{ID: "test-zone"}
+
+	record := &v1.DNSRecord{
+		Spec: v1.DNSRecordSpec{
+			Endpoints: []*v1.Endpoint{weightedEndpoint("10.0.0.1", 120)},
+		},
+	}
+	g.Expect(provider.Ensure(context.TODO(), record, zone)).To(gomega.Succeed())
+	g.Expect(provider.Delete(context.TODO(), record, zone)).To(gomega.Succeed())
+	g.Expect(fake.rrsets).To(gomega.BeEmpty())
+
+	// Deleting a record set that no longer exists is not an error
+	g.Expect(provider.Delete(context.TODO(), record, zone)).To(gomega.Succeed())
+}
+
+func TestEnsureUnsupportedRecordType(t *testing.T) {
+	g := gomega.NewWithT(t)
+	provider, _ := newTestProvider(t)
+
+	record := &v1.DNSRecord{
+		Spec: v1.DNSRecordSpec{
+			Endpoints: []*v1.Endpoint{{DNSName: "app.example.com", RecordType: "SRV", Targets: v1.Targets{"x"}}},
+		},
+	}
+	err := provider.Ensure(context.TODO(), record, v1.DNSZone{ID: "test-zone"})
+	g.Expect(err).To(gomega.MatchError("unsupported record type SRV"))
+	g.Expect(dnserrors.IsInvalidRecord(err)).To(gomega.BeTrue())
+}
+
+func TestListRecords(t *testing.T) {
+	g := gomega.NewWithT(t)
+	provider, _ := newTestProvider(t)
+	zone := v1.DNSZone{ID: "test-zone"}
+
+	record := &v1.DNSRecord{Spec: v1.DNSRecordSpec{Endpoints: []*v1.Endpoint{
+		weightedEndpoint("10.0.0.1", 120),
+		weightedEndpoint("10.0.0.2", 120),
+		{DNSName: "www.example.com", RecordType: string(v1.CNAMERecordType), RecordTTL: 300, Targets: v1.Targets{"app.example.com"}},
+	}}}
+	g.Expect(provider.Ensure(context.TODO(), record, zone)).To(gomega.Succeed())
+
+	endpoints, err := provider.ListRecords(context.TODO(), zone)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(endpoints).To(gomega.HaveLen(3))
+	g.Expect(endpoints[0].DNSName).To(gomega.Equal("app.example.com"))
+	g.Expect(endpoints[0].Targets).To(gomega.Equal(v1.Targets{"10.0.0.1"}))
+	g.Expect(endpoints[1].Targets).To(gomega.Equal(v1.Targets{"10.0.0.2"}))
+	g.Expect(endpoints[2]).To(gomega.Equal(&v1.Endpoint{
+		DNSName:    "www.example.com",
+		RecordType: string(v1.CNAMERecordType),
+		RecordTTL:  300,
+		Targets:    v1.Targets{"app.example.com"},
+	}))
+}
@@ -6,16 +6,17 @@ import (
 	v1 "github.com/kuadrant/kcp-glbc/pkg/apis/kuadrant/v1"
 )
 
-// TODO once we have a specific Health Check API this should have its own controller rather than piggy backing on the DNSRecord
+// HealthCheckReconciler manages the health checks of the endpoints, as
+// configured by the HealthChecks reconciled by the HealthCheckController.
 type HealthCheckReconciler interface {
-	ReconcileHealthCheck(ctx context.Context, hc v1.HealthCheck, endpoint *v1.Endpoint) error
+	ReconcileHealthCheck(ctx context.Context, hc v1.EndpointHealthCheck, endpoint *v1.Endpoint) error
 
 	DeleteHealthCheck(ctx context.Context, endpoint *v1.Endpoint) error
 }
 
 type fakeHealthCheckReconciler struct{}
 
-func (*fakeHealthCheckReconciler) ReconcileHealthCheck(ctx context.Context, _ v1.HealthCheck, _ *v1.Endpoint) error {
+func (*fakeHealthCheckReconciler) ReconcileHealthCheck(ctx context.Context, _ v1.EndpointHealthCheck, _ *v1.Endpoint) error {
 	return nil
 }
 
@@ -24,3 +25,47 @@ func (*fakeHealthCheckReconciler) DeleteHealthCheck(ctx context.Context, _ *v1.E
 }
 
 var _ HealthCheckReconciler = &fakeHealthCheckReconciler{}
+
+// HealthCheckStatusReader is implemented by the providers that report the
+// health of the endpoints they check.
+type HealthCheckStatusReader interface {
+	// HealthCheckStatus returns the provider ID of the health check of the
+	// endpoint, if any, and the health of the endpoint, as last observed.
+	HealthCheckStatus(ctx context.Context, endpoint *v1.Endpoint) (string, v1.EndpointHealth, error)
+}
+
+// HealthProbeRequester is implemented by the providers that have no health
+// checks of their own, and rely on the GLBC to probe the endpoints instead. The
+// other providers, e.g. the plugins, are given the health checks to reconcile.
+type HealthProbeRequester interface {
+	// RequiresHealthProbes returns whether the endpoints are probed by the
+	// GLBC rather than by the provider.
+	RequiresHealthProbes() bool
+}
+
+// HealthCheckLister is implemented by the providers that can list the health
+// checks they created, so that the ones no endpoint matches anymore are
+// garbage collected.
+type HealthCheckLister interface {
+	// ListHealthChecks returns the IDs of the endpoints, or of the clusters,
+	// of the health checks owned by the GLBC, as returned by idForEndpoint and
+	// idForCluster, by provider ID.
+	ListHealthChecks(ctx context.Context) (map[string]string, error)
+
+	// DeleteHealthCheckByID deletes the health check with the provider ID.
+	DeleteHealthCheckByID(ctx context.Context, providerID string) error
+}
+
+// ClusterHealthCheckReconciler is implemented by the providers that can
+// aggregate the health checks of the endpoints of a cluster, so that the
+// endpoints of a cluster are only published while enough of them are healthy.
+type ClusterHealthCheckReconciler interface {
+	// ReconcileClusterHealthCheck reconciles the health check of the cluster
+	// of the endpoints, that aggregates their health checks, or deletes it if
+	// the health check sets no cluster health threshold.
+	ReconcileClusterHealthCheck(ctx context.Context, hc v1.EndpointHealthCheck, endpoints []*v1.Endpoint) error
+
+	// DeleteClusterHealthCheck deletes the health check of the cluster of the
+	// endpoints.
+	DeleteClusterHealthCheck(ctx context.Context, endpoints []*v1.Endpoint) error
+}
@@ -0,0 +1,189 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/kuadrant/kcp-glbc/pkg/_internal/log"
+	v1 "github.com/kuadrant/kcp-glbc/pkg/apis/kuadrant/v1"
+)
+
+const (
+	// DefaultHealthCheckCollectionInterval is the interval between the garbage
+	// collections of the orphaned health checks.
+	DefaultHealthCheckCollectionInterval = 10 * time.Minute
+
+	// DefaultHealthCheckGracePeriod is the time during which a health check
+	// must remain orphaned before it is deleted.
+	DefaultHealthCheckGracePeriod = time.Hour
+)
+
+// HealthCheckCollectorConfig is the configuration of the HealthCheckCollector.
+type HealthCheckCollectorConfig struct {
+	// Controllers are the DNSRecord controllers, whose DNSRecords hold the
+	// endpoints the health checks are matched against. The health checks of
+	// the DNS provider of the first one are collected.
+	Controllers []*Controller
+	// Interval is the interval between the collections. Defaults to
+	// DefaultHealthCheckCollectionInterval.
+	Interval time.Duration
+	// GracePeriod is the time during which a health check must remain
+	// orphaned before it is deleted. Defaults to DefaultHealthCheckGracePeriod.
+	GracePeriod time.Duration
+}
+
+// HealthCheckCollector periodically deletes the health checks owned by the
+// GLBC that no endpoint of the checked DNSRecords matches anymore, e.g. as
+// their ID was lost when the status of the HealthCheck failed to be updated
+// after they were created, or as the HealthCheck was deleted without its
+// finalizer. The health checks are only deleted once they have been orphaned
+// for the grace period, as they are created before the HealthChecks are updated
+// with their ID.
+type HealthCheckCollector struct {
+	controllers []*Controller
+	interval    time.Duration
+	gracePeriod time.Duration
+	logger      logr.Logger
+	// orphanedSince holds the time the orphaned health checks were first
+	// found orphaned at, by provider ID.
+	orphanedSince map[string]time.Time
+}
+
+// NewHealthCheckCollector returns a new HealthCheckCollector of the health
+// checks of the DNS provider of the controllers.
+func NewHealthCheckCollector(config *HealthCheckCollectorConfig) (*HealthCheckCollector, error) {
+	if len(config.Controllers) == 0 {
+		return nil, fmt.Errorf("no DNSRecord controller to collect the health checks of")
+	}
+	interval := config.Interval
+	if interval <= 0 {
+		interval = DefaultHealthCheckCollectionInterval
+	}
+	gracePeriod := config.GracePeriod
+	if gracePeriod <= 0 {
+		gracePeriod = DefaultHealthCheckGracePeriod
+	}
+	return &HealthCheckCollector{
+		controllers:   config.Controllers,
+		interval:      interval,
+		gracePeriod:   gracePeriod,
+		logger:        log.Logger.WithName("dns-health-check-collector"),
+		orphanedSince: map[string]time.Time{},
+	}, nil
+}
+
+// Start runs the collections until the context is done, unless the DNS
+// provider cannot list its health checks.
+func (c *HealthCheckCollector) Start(ctx context.Context, _ int) {
+	if _, ok := c.controllers[0].dnsProvider.(HealthCheckLister); !ok {
+		c.logger.Info("DNS provider cannot list its health checks, not collecting orphaned health checks")
+		return
+	}
+	c.logger.Info("Starting health check collector", "interval", c.interval, "gracePeriod", c.gracePeriod)
+	defer c.logger.Info("Stopping health check collector")
+	wait.UntilWithContext(ctx, c.collect, c.interval)
+}
+
+func (c *HealthCheckCollector) collect(ctx context.Context) {
+	lister := c.controllers[0].dnsProvider.(HealthCheckLister)
+
+	// The health checks are listed before the DNSRecords, so that the health
+	// checks created in the meantime are not found orphaned
+	healthChecks, err := lister.ListHealthChecks(ctx)
+	if err != nil {
+		c.logger.Error(err, "Failed to list health checks, skipping collection")
+		observeHealthCheckCollection(err)
+		return
+	}
+	live, err := c.liveEndpointIDs()
+	if err != nil {
+		c.logger.Error(err, "Failed to list DNSRecords, skipping collection")
+		observeHealthCheckCollection(err)
+		return
+	}
+
+	var expired []string
+	now := clock.Now()
+	orphanedSince := map[string]time.Time{}
+	for providerID, endpointID := range healthChecks {
+		if _, ok := live[endpointID]; ok {
+			continue
+		}
+		since, ok := c.orphanedSince[providerID]
+		if !ok {
+			since = now
+		}
+		orphanedSince[providerID] = since
+		if now.Sub(since) >= c.gracePeriod {
+			expired = append(expired, providerID)
+		}
+	}
+	// The health checks that are no longer orphaned, or were deleted, are
+	// forgotten
+	c.orphanedSince = orphanedSince
+
+	sort.Strings(expired)
+	for _, providerID := range expired {
+		if err := lister.DeleteHealthCheckByID(ctx, providerID); err != nil {
+			c.logger.Error(err, "Failed to delete orphaned health check", "id", providerID)
+			continue
+		}
+		c.logger.Info("Deleted orphaned health check", "id", providerID, "endpoint", healthChecks[providerID], "orphanedSince", orphanedSince[providerID])
+		delete(c.orphanedSince, providerID)
+		orphanedHealthChecksDeleted.Inc()
+	}
+	orphanedHealthChecks.Set(float64(len(c.orphanedSince)))
+	observeHealthCheckCollection(nil)
+}
+
+// liveEndpointIDs returns the IDs of the endpoints of the DNSRecords checked
+// by a HealthCheck, and of their clusters, including the endpoints still
+// published to their zones, and the ones of the stale health checks, whose
+// health checks are deleted by the HealthCheck controller.
+func (c *HealthCheckCollector) liveEndpointIDs() (map[string]struct{}, error) {
+	live := map[string]struct{}{}
+	for _, controller := range c.controllers {
+		records, err := controller.lister.List(labels.Everything())
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range records {
+			healthChecks, err := controller.healthChecksOf(record)
+			if err != nil {
+				return nil, err
+			}
+			if len(healthChecks) == 0 {
+				continue
+			}
+			endpoints := append([]*v1.Endpoint{}, record.Spec.Endpoints...)
+			for _, zone := range record.Status.Zones {
+				endpoints = append(endpoints, zone.Endpoints...)
+			}
+			for _, ref := range healthCheckRefsOf(healthChecks) {
+				endpoints = append(endpoints, healthCheckRefEndpoint(ref))
+			}
+			for _, endpoint := range endpoints {
+				id, err := idForEndpoint(record, endpoint)
+				if err != nil {
+					return nil, err
+				}
+				live[id] = struct{}{}
+			}
+			for cluster := range endpointsByCluster(endpoints) {
+				id, err := idForCluster(record, cluster)
+				if err != nil {
+					return nil, err
+				}
+				live[id] = struct{}{}
+			}
+		}
+	}
+	return live, nil
+}
@@ -0,0 +1,104 @@
+package dns
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"github.com/kuadrant/kcp-glbc/pkg/_internal/log"
+	v1 "github.com/kuadrant/kcp-glbc/pkg/apis/kuadrant/v1"
+	kuadrantv1lister "github.com/kuadrant/kcp-glbc/pkg/client/kuadrant/listers/kuadrant/v1"
+	"github.com/kuadrant/kcp-glbc/pkg/reconciler"
+)
+
+// healthCheckListerProvider lists the health checks it holds, by provider ID.
+type healthCheckListerProvider struct {
+	FakeProvider
+	healthChecks map[string]string
+}
+
+func (p *healthCheckListerProvider) ListHealthChecks(_ context.Context) (map[string]string, error) {
+	healthChecks := map[string]string{}
+	for providerID, endpointID := range p.healthChecks {
+		healthChecks[providerID] = endpointID
+	}
+	return healthChecks, nil
+}
+
+func (p *healthCheckListerProvider) DeleteHealthCheckByID(_ context.Context, providerID string) error {
+	delete(p.healthChecks, providerID)
+	return nil
+}
+
+func TestHealthCheckCollectorDeletesOrphanedHealthChecks(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	fakeClock := clocktesting.NewFakeClock(time.Now())
+	previous := clock
+	clock = fakeClock
+	t.Cleanup(func() { clock = previous })
+
+	checked := newTestRecord("app", aEndpoint("app.example.com", "10.0.0.1"))
+	checked.Namespace = "default"
+	checked.OwnerReferences = []metav1.OwnerReference{{Kind: "Ingress", Name: "app"}}
+	checked.Spec.Endpoints[0].Labels = v1.Labels{LabelCluster: "east"}
+	checkedID, err := idForEndpoint(checked, checked.Spec.Endpoints[0])
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	clusterID, err := idForCluster(checked, "east")
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	// The health checks of the DNSRecords no longer checked are orphaned
+	unchecked := newTestRecord("other", aEndpoint("other.example.com", "10.0.0.2"))
+	unchecked.Namespace = "default"
+	unchecked.OwnerReferences = []metav1.OwnerReference{{Kind: "Ingress", Name: "other"}}
+	uncheckedID, err := idForEndpoint(unchecked, unchecked.Spec.Endpoints[0])
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	g.Expect(indexer.Add(checked)).To(gomega.Succeed())
+	g.Expect(indexer.Add(unchecked)).To(gomega.Succeed())
+	healthCheckIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{healthCheckTargetIndex: healthCheckTargetIndexFunc})
+	g.Expect(healthCheckIndexer.Add(newTestHealthCheck("app", "app"))).To(gomega.Succeed())
+	provider := &healthCheckListerProvider{healthChecks: map[string]string{
+		"hc-1": checkedID,
+		"hc-2": uncheckedID,
+		"hc-3": "lost",
+		"hc-4": clusterID,
+	}}
+	c := &Controller{
+		Controller:         &reconciler.Controller{Logger: log.Logger},
+		lister:             kuadrantv1lister.NewDNSRecordLister(indexer),
+		healthCheckIndexer: healthCheckIndexer,
+		dnsProvider:        provider,
+	}
+	collector, err := NewHealthCheckCollector(&HealthCheckCollectorConfig{Controllers: []*Controller{c}, GracePeriod: time.Hour})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	// The orphaned health checks are only deleted after the grace period
+	collector.collect(context.TODO())
+	g.Expect(provider.healthChecks).To(gomega.HaveLen(4))
+	g.Expect(testutil.ToFloat64(orphanedHealthChecks)).To(gomega.Equal(float64(2)))
+
+	fakeClock.Step(30 * time.Minute)
+	collector.collect(context.TODO())
+	g.Expect(provider.healthChecks).To(gomega.HaveLen(4))
+
+	// The health checks matched again in the meantime are kept
+	g.Expect(healthCheckIndexer.Add(newTestHealthCheck("other", "other"))).To(gomega.Succeed())
+	fakeClock.Step(30 * time.Minute)
+	deleted := testutil.ToFloat64(orphanedHealthChecksDeleted)
+	collector.collect(context.TODO())
+	g.Expect(provider.healthChecks).To(gomega.HaveKey("hc-1"))
+	g.Expect(provider.healthChecks).To(gomega.HaveKey("hc-2"))
+	g.Expect(provider.healthChecks).NotTo(gomega.HaveKey("hc-3"))
+	g.Expect(provider.healthChecks).To(gomega.HaveKey("hc-4"))
+	g.Expect(testutil.ToFloat64(orphanedHealthChecks)).To(gomega.Equal(float64(0)))
+	g.Expect(testutil.ToFloat64(orphanedHealthChecksDeleted)).To(gomega.Equal(deleted + 1))
+}
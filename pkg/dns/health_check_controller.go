@@ -0,0 +1,244 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+
+	v1 "github.com/kuadrant/kcp-glbc/pkg/apis/kuadrant/v1"
+	kuadrantv1 "github.com/kuadrant/kcp-glbc/pkg/client/kuadrant/clientset/versioned"
+	"github.com/kuadrant/kcp-glbc/pkg/client/kuadrant/informers/externalversions"
+	"github.com/kuadrant/kcp-glbc/pkg/reconciler"
+)
+
+const (
+	defaultHealthCheckControllerName = "kcp-glbc-health-check"
+
+	// healthCheckTargetIndex indexes the HealthChecks by the key of the
+	// DNSRecord of the traffic object they target.
+	healthCheckTargetIndex = "healthCheckTarget"
+
+	// healthRefreshInterval is the interval at which the health of the
+	// endpoints is refreshed, for the providers that report it.
+	healthRefreshInterval = time.Minute
+)
+
+// NewHealthCheckController returns a new HealthCheckController which
+// reconciles HealthCheck.
+func NewHealthCheckController(config *HealthCheckControllerConfig) (*HealthCheckController, error) {
+	controllerName := config.GetName(defaultHealthCheckControllerName)
+	queue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), controllerName)
+	c := &HealthCheckController{
+		Controller:            reconciler.NewController(controllerName, queue),
+		kuadrantClient:        config.KuadrantClient,
+		sharedInformerFactory: config.SharedInformerFactory,
+		healthChecker:         config.DNSProvider,
+	}
+	c.Process = c.process
+
+	// The providers that have no health checks of their own request the
+	// endpoints to be probed by the GLBC instead
+	if probesHealth(config.DNSProvider) {
+		c.prober = NewProber()
+		c.healthChecker = c.prober
+	}
+
+	healthCheckInformer := c.sharedInformerFactory.Kuadrant().V1().HealthChecks().Informer()
+	if err := addHealthCheckTargetIndex(healthCheckInformer); err != nil {
+		return nil, err
+	}
+	healthCheckInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) { c.Enqueue(obj) },
+		UpdateFunc: func(old, obj interface{}) {
+			if old.(*v1.HealthCheck).ResourceVersion != obj.(*v1.HealthCheck).ResourceVersion {
+				c.Enqueue(obj)
+			}
+		},
+		DeleteFunc: func(obj interface{}) { c.Enqueue(obj) },
+	})
+
+	// The HealthChecks are reconciled when the endpoints of the DNSRecord they
+	// target change. The changes of the health of the endpoints, that the
+	// DNSRecord controller copies from the HealthChecks, are ignored.
+	c.sharedInformerFactory.Kuadrant().V1().DNSRecords().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) { c.enqueueHealthChecksOf(obj) },
+		UpdateFunc: func(old, obj interface{}) {
+			if !onlyHealthChanged(old.(*v1.DNSRecord), obj.(*v1.DNSRecord)) {
+				c.enqueueHealthChecksOf(obj)
+			}
+		},
+		DeleteFunc: func(obj interface{}) { c.enqueueHealthChecksOf(obj) },
+	})
+
+	c.indexer = healthCheckInformer.GetIndexer()
+	c.dnsRecordIndexer = c.sharedInformerFactory.Kuadrant().V1().DNSRecords().Informer().GetIndexer()
+
+	return c, nil
+}
+
+type HealthCheckControllerConfig struct {
+	*reconciler.ControllerConfig
+	KuadrantClient        kuadrantv1.ClusterInterface
+	SharedInformerFactory externalversions.SharedInformerFactory
+	// DNSProvider is the provider the health checks are reconciled with, as
+	// returned by the Provider method of the DNSRecord controller, unless it
+	// requests the endpoints to be probed by the GLBC.
+	DNSProvider Provider
+}
+
+type HealthCheckController struct {
+	*reconciler.Controller
+	sharedInformerFactory externalversions.SharedInformerFactory
+	kuadrantClient        kuadrantv1.ClusterInterface
+	indexer               cache.Indexer
+	dnsRecordIndexer      cache.Indexer
+	healthChecker         HealthCheckReconciler
+	// prober probes the endpoints, for the DNS providers that have no health
+	// checks, if so.
+	prober *Prober
+}
+
+// Start runs the workers, along with the prober, if any.
+func (c *HealthCheckController) Start(ctx context.Context, numThreads int) {
+	if c.prober != nil {
+		go c.prober.Start(ctx)
+	}
+	c.Controller.Start(ctx, numThreads)
+}
+
+// enqueueHealthChecksOf enqueues the HealthChecks that target the DNSRecord.
+func (c *HealthCheckController) enqueueHealthChecksOf(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		c.Logger.Error(err, "Failed to get key of DNSRecord")
+		return
+	}
+	healthChecks, err := c.indexer.ByIndex(healthCheckTargetIndex, key)
+	if err != nil {
+		c.Logger.Error(err, "Failed to get HealthChecks of DNSRecord", "key", key)
+		return
+	}
+	for _, healthCheck := range healthChecks {
+		c.Enqueue(healthCheck)
+	}
+}
+
+// onlyHealthChanged returns whether the DNSRecord was only updated with the
+// health of its endpoints.
+func onlyHealthChanged(old, obj *v1.DNSRecord) bool {
+	if old.ResourceVersion == obj.ResourceVersion {
+		return true
+	}
+	old, obj = old.DeepCopy(), obj.DeepCopy()
+	old.Status.Health, obj.Status.Health = nil, nil
+	old.ResourceVersion, obj.ResourceVersion = "", ""
+	old.ManagedFields, obj.ManagedFields = nil, nil
+	return equality.Semantic.DeepEqual(old, obj)
+}
+
+// addHealthCheckTargetIndex indexes the HealthChecks of the informer by the
+// DNSRecord they target, unless another controller sharing the informer
+// already did.
+func addHealthCheckTargetIndex(informer cache.SharedIndexInformer) error {
+	if _, ok := informer.GetIndexer().GetIndexers()[healthCheckTargetIndex]; ok {
+		return nil
+	}
+	return informer.AddIndexers(cache.Indexers{healthCheckTargetIndex: healthCheckTargetIndexFunc})
+}
+
+// healthChecksTargeting returns the HealthChecks that target the traffic object
+// of the DNSRecord, sorted by name.
+func healthChecksTargeting(indexer cache.Indexer, dnsRecord *v1.DNSRecord) ([]*v1.HealthCheck, error) {
+	key, err := cache.MetaNamespaceKeyFunc(dnsRecord)
+	if err != nil {
+		return nil, err
+	}
+	objs, err := indexer.ByIndex(healthCheckTargetIndex, key)
+	if err != nil {
+		return nil, err
+	}
+	var healthChecks []*v1.HealthCheck
+	for _, obj := range objs {
+		healthCheck := obj.(*v1.HealthCheck)
+		for _, owner := range dnsRecord.OwnerReferences {
+			if owner.Kind == healthCheck.Spec.TargetRef.Kind {
+				healthChecks = append(healthChecks, healthCheck)
+				break
+			}
+		}
+	}
+	sort.Slice(healthChecks, func(i, j int) bool { return healthChecks[i].Name < healthChecks[j].Name })
+	return healthChecks, nil
+}
+
+// healthCheckTargetIndexFunc returns the key of the DNSRecord of the traffic
+// object the HealthCheck targets, which shares the name and the namespace of
+// the traffic object.
+func healthCheckTargetIndexFunc(obj interface{}) ([]string, error) {
+	healthCheck, ok := obj.(*v1.HealthCheck)
+	if !ok {
+		return nil, fmt.Errorf("unexpected object of type %T", obj)
+	}
+	key, err := healthCheckTargetKey(healthCheck)
+	if err != nil {
+		return nil, err
+	}
+	return []string{key}, nil
+}
+
+func healthCheckTargetKey(healthCheck *v1.HealthCheck) (string, error) {
+	return cache.MetaNamespaceKeyFunc(&metav1.ObjectMeta{
+		Name:      healthCheck.Spec.TargetRef.Name,
+		Namespace: healthCheck.Namespace,
+		Annotations: map[string]string{
+			logicalcluster.AnnotationKey: logicalcluster.From(healthCheck).String(),
+		},
+	})
+}
+
+func (c *HealthCheckController) process(ctx context.Context, key string) error {
+	object, exists, err := c.indexer.GetByKey(key)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		c.Logger.Info("HealthCheck was deleted", "key", key)
+		return nil
+	}
+
+	previous := object.(*v1.HealthCheck)
+	current := previous.DeepCopy()
+
+	if err = c.reconcile(ctx, current); err != nil {
+		return err
+	}
+
+	if !equality.Semantic.DeepEqual(previous.Status, current.Status) {
+		refresh, err := c.kuadrantClient.Cluster(logicalcluster.From(current)).KuadrantV1().HealthChecks(current.Namespace).UpdateStatus(ctx, current, metav1.UpdateOptions{})
+		if err != nil {
+			return err
+		}
+		current.ObjectMeta.ResourceVersion = refresh.ObjectMeta.ResourceVersion
+	}
+
+	if !equality.Semantic.DeepEqual(previous, current) {
+		_, err := c.kuadrantClient.Cluster(logicalcluster.From(current)).KuadrantV1().HealthChecks(current.Namespace).Update(ctx, current, metav1.UpdateOptions{})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
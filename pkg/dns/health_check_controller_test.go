@@ -0,0 +1,505 @@
+package dns
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+	"github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/kuadrant/kcp-glbc/pkg/_internal/log"
+	v1 "github.com/kuadrant/kcp-glbc/pkg/apis/kuadrant/v1"
+	kuadrantv1 "github.com/kuadrant/kcp-glbc/pkg/client/kuadrant/clientset/versioned"
+	"github.com/kuadrant/kcp-glbc/pkg/client/kuadrant/clientset/versioned/fake"
+	"github.com/kuadrant/kcp-glbc/pkg/client/kuadrant/informers/externalversions"
+	dnsPlugin "github.com/kuadrant/kcp-glbc/pkg/dns/plugin"
+	"github.com/kuadrant/kcp-glbc/pkg/reconciler"
+)
+
+const (
+	testHealthCheckID        = "test/health-check-id"
+	testClusterHealthCheckID = "test/cluster-health-check-id"
+)
+
+// fakeClusterClient serves all the logical clusters with the same clientset.
+type fakeClusterClient struct {
+	*fake.Clientset
+}
+
+func (c *fakeClusterClient) Cluster(_ logicalcluster.Name) kuadrantv1.Interface {
+	return c.Clientset
+}
+
+// healthCheckProvider records the health checks it is asked to reconcile, and
+// reports the endpoints as healthy.
+type healthCheckProvider struct {
+	FakeProvider
+	healthChecks map[string]v1.EndpointHealthCheck
+	health       v1.Health
+	observed     metav1.Time
+}
+
+func (p *healthCheckProvider) ReconcileHealthCheck(_ context.Context, hc v1.EndpointHealthCheck, endpoint *v1.Endpoint) error {
+	p.healthChecks[hc.Id] = hc
+	endpoint.SetProviderSpecific(testHealthCheckID, hc.Id)
+	return nil
+}
+
+func (p *healthCheckProvider) DeleteHealthCheck(_ context.Context, endpoint *v1.Endpoint) error {
+	if id, ok := endpoint.GetProviderSpecific(testHealthCheckID); ok {
+		delete(p.healthChecks, id)
+		endpoint.DeleteProviderSpecific(testHealthCheckID)
+	}
+	return nil
+}
+
+func (p *healthCheckProvider) HealthCheckStatus(_ context.Context, endpoint *v1.Endpoint) (string, v1.EndpointHealth, error) {
+	id, _ := endpoint.GetProviderSpecific(testHealthCheckID)
+	return id, v1.EndpointHealth{DNSName: endpoint.DNSName, SetIdentifier: endpoint.SetIdentifier, Health: p.health, LastObservedTime: p.observed}, nil
+}
+
+// clusterHealthCheckProvider records the health checks of the clusters it is
+// asked to reconcile, by ID.
+type clusterHealthCheckProvider struct {
+	healthCheckProvider
+	clusterHealthChecks map[string]v1.EndpointHealthCheck
+}
+
+func (p *clusterHealthCheckProvider) ReconcileClusterHealthCheck(ctx context.Context, hc v1.EndpointHealthCheck, endpoints []*v1.Endpoint) error {
+	if hc.ClusterHealthThreshold == nil {
+		return p.DeleteClusterHealthCheck(ctx, endpoints)
+	}
+	p.clusterHealthChecks[hc.Id] = hc
+	for _, endpoint := range endpoints {
+		endpoint.SetProviderSpecific(testClusterHealthCheckID, hc.Id)
+	}
+	return nil
+}
+
+func (p *clusterHealthCheckProvider) DeleteClusterHealthCheck(_ context.Context, endpoints []*v1.Endpoint) error {
+	for _, endpoint := range endpoints {
+		if id, ok := endpoint.GetProviderSpecific(testClusterHealthCheckID); ok {
+			delete(p.clusterHealthChecks, id)
+			endpoint.DeleteProviderSpecific(testClusterHealthCheckID)
+		}
+	}
+	return nil
+}
+
+func newTestHealthCheck(name, target string) *v1.HealthCheck {
+	port := int64(8080)
+	return &v1.HealthCheck{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: v1.HealthCheckSpec{
+			TargetRef:           v1.HealthCheckTargetReference{Kind: "Ingress", Name: target},
+			Path:                "/healthz",
+			Port:                &port,
+			ExpectedStatusCodes: []v1.HTTPStatusCode{200},
+		},
+	}
+}
+
+func TestHealthCheckControllerReconcile(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	weighted := aEndpoint("app.example.com", "10.0.0.1")
+	weighted.SetIdentifier = "10.0.0.1"
+	cname := &v1.Endpoint{DNSName: "lb.example.com", RecordType: string(v1.CNAMERecordType), Targets: v1.Targets{"lb.aws.com"}}
+	record := newTestRecord("app", weighted, cname)
+	record.Namespace = "default"
+	record.OwnerReferences = []metav1.OwnerReference{{Kind: "Ingress", Name: "app"}}
+
+	client := &fakeClusterClient{fake.NewSimpleClientset(record)}
+	provider := &healthCheckProvider{healthChecks: map[string]v1.EndpointHealthCheck{}, health: v1.Healthy, observed: metav1.Now()}
+	c := &HealthCheckController{
+		Controller:       &reconciler.Controller{Logger: log.Logger, Queue: workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())},
+		kuadrantClient:   client,
+		indexer:          cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{healthCheckTargetIndex: healthCheckTargetIndexFunc}),
+		dnsRecordIndexer: cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{}),
+		healthChecker:    provider,
+	}
+	t.Cleanup(c.Queue.ShutDown)
+
+	healthCheck := newTestHealthCheck("app", "app")
+	g.Expect(c.indexer.Add(healthCheck)).To(gomega.Succeed())
+
+	// The target DNSRecord does not exist yet
+	g.Expect(c.reconcile(context.TODO(), healthCheck)).To(gomega.Succeed())
+	g.Expect(healthCheck.Finalizers).To(gomega.ConsistOf(HealthCheckFinalizer))
+	g.Expect(healthCheck.Status.Message).To(gomega.Equal("No DNSRecord found for Ingress app"))
+
+	// The endpoints with an address are checked
+	g.Expect(c.dnsRecordIndexer.Add(record)).To(gomega.Succeed())
+	g.Expect(c.reconcile(context.TODO(), healthCheck)).To(gomega.Succeed())
+	g.Expect(provider.healthChecks).To(gomega.HaveLen(1))
+	g.Expect(healthCheck.Status.Message).To(gomega.BeEmpty())
+	g.Expect(healthCheck.Status.Endpoints).To(gomega.HaveLen(1))
+	g.Expect(healthCheck.Status.Endpoints[0].DNSName).To(gomega.Equal("app.example.com"))
+	g.Expect(healthCheck.Status.Endpoints[0].SetIdentifier).To(gomega.Equal("10.0.0.1"))
+	g.Expect(healthCheck.Status.Endpoints[0].Health).To(gomega.Equal(v1.Healthy))
+	for _, hc := range provider.healthChecks {
+		g.Expect(hc.Path).To(gomega.Equal("/healthz"))
+		g.Expect(*hc.Port).To(gomega.Equal(int64(8080)))
+		g.Expect(*hc.Protocol).To(gomega.Equal(v1.HealthCheckProtocolHTTP))
+		g.Expect(healthCheck.Status.Endpoints[0].ProviderID).To(gomega.Equal(hc.Id))
+	}
+
+	g.Expect(healthCheck.Status.Endpoints[0].LastObservedTime).To(gomega.Equal(provider.observed))
+
+	// The health checks are referenced in the status of the HealthCheck, for
+	// the DNSRecord controller to publish the endpoints with them, and the
+	// DNSRecord is left untouched
+	g.Expect(healthCheck.Status.HealthChecks).To(gomega.Equal([]v1.EndpointHealthCheckRef{
+		{DNSName: "app.example.com", SetIdentifier: "10.0.0.1", ProviderSpecific: v1.ProviderSpecific{{Name: testHealthCheckID, Value: healthCheck.Status.Endpoints[0].ProviderID}}},
+	}))
+	updated, err := client.KuadrantV1().DNSRecords("default").Get(context.TODO(), "app", metav1.GetOptions{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(updated).To(gomega.Equal(record))
+
+	// The changes of the health of the endpoints are counted
+	provider.health = v1.Unhealthy
+	g.Expect(c.reconcile(context.TODO(), healthCheck)).To(gomega.Succeed())
+	g.Expect(healthCheck.Status.Endpoints[0].Health).To(gomega.Equal(v1.Unhealthy))
+	g.Expect(testutil.ToFloat64(endpointHealthTransitions.WithLabelValues(string(v1.Unhealthy)))).To(gomega.Equal(float64(1)))
+	g.Expect(testutil.ToFloat64(endpointHealth.WithLabelValues(string(v1.Unhealthy)))).To(gomega.Equal(float64(1)))
+	g.Expect(testutil.ToFloat64(endpointHealth.WithLabelValues(string(v1.Healthy)))).To(gomega.Equal(float64(0)))
+	g.Expect(c.indexer.Update(healthCheck)).To(gomega.Succeed())
+
+	// Another HealthCheck cannot check the same DNSRecord
+	other := newTestHealthCheck("other", "app")
+	g.Expect(c.indexer.Add(other)).To(gomega.Succeed())
+	g.Expect(c.reconcile(context.TODO(), other)).To(gomega.Succeed())
+	g.Expect(other.Status.Message).To(gomega.Equal("Ingress app is already checked by HealthCheck app"))
+	g.Expect(other.Status.Endpoints).To(gomega.BeEmpty())
+	g.Expect(other.Status.HealthChecks).To(gomega.BeEmpty())
+
+	// The health checks are deleted along with the HealthCheck
+	now := metav1.Now()
+	healthCheck.DeletionTimestamp = &now
+	g.Expect(c.reconcile(context.TODO(), healthCheck)).To(gomega.Succeed())
+	g.Expect(healthCheck.Finalizers).To(gomega.BeEmpty())
+	g.Expect(healthCheck.Status.HealthChecks).To(gomega.BeEmpty())
+	g.Expect(provider.healthChecks).To(gomega.BeEmpty())
+	g.Expect(testutil.ToFloat64(endpointHealth.WithLabelValues(string(v1.Unhealthy)))).To(gomega.Equal(float64(0)))
+
+	updated, err = client.KuadrantV1().DNSRecords("default").Get(context.TODO(), "app", metav1.GetOptions{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(updated).To(gomega.Equal(record))
+}
+
+func TestHealthCheckControllerReconcilesClusterHealthChecks(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	clusterEndpoint := func(ip, cluster string) *v1.Endpoint {
+		endpoint := aEndpoint("app.example.com", ip)
+		endpoint.SetIdentifier = ip
+		endpoint.Labels = v1.Labels{LabelCluster: cluster}
+		return endpoint
+	}
+	record := newTestRecord("app", clusterEndpoint("10.0.0.1", "east"), clusterEndpoint("10.0.0.2", "east"), clusterEndpoint("10.0.0.3", "west"))
+	record.Namespace = "default"
+	record.OwnerReferences = []metav1.OwnerReference{{Kind: "Ingress", Name: "app"}}
+
+	client := &fakeClusterClient{fake.NewSimpleClientset(record)}
+	provider := &clusterHealthCheckProvider{
+		healthCheckProvider: healthCheckProvider{healthChecks: map[string]v1.EndpointHealthCheck{}, health: v1.Healthy},
+		clusterHealthChecks: map[string]v1.EndpointHealthCheck{},
+	}
+	c := &HealthCheckController{
+		Controller:       &reconciler.Controller{Logger: log.Logger, Queue: workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())},
+		kuadrantClient:   client,
+		indexer:          cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{healthCheckTargetIndex: healthCheckTargetIndexFunc}),
+		dnsRecordIndexer: cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{}),
+		healthChecker:    provider,
+	}
+	t.Cleanup(c.Queue.ShutDown)
+	g.Expect(c.dnsRecordIndexer.Add(record)).To(gomega.Succeed())
+
+	// The health checks of the clusters are only reconciled with a cluster
+	// health threshold
+	healthCheck := newTestHealthCheck("app", "app")
+	g.Expect(c.indexer.Add(healthCheck)).To(gomega.Succeed())
+	g.Expect(c.reconcile(context.TODO(), healthCheck)).To(gomega.Succeed())
+	g.Expect(provider.healthChecks).To(gomega.HaveLen(3))
+	g.Expect(provider.clusterHealthChecks).To(gomega.BeEmpty())
+
+	threshold := int64(2)
+	healthCheck.Spec.ClusterHealthThreshold = &threshold
+	g.Expect(c.reconcile(context.TODO(), healthCheck)).To(gomega.Succeed())
+	g.Expect(provider.clusterHealthChecks).To(gomega.HaveLen(2))
+	eastID, err := idForCluster(record, "east")
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(provider.clusterHealthChecks).To(gomega.HaveKey(eastID))
+	g.Expect(*provider.clusterHealthChecks[eastID].ClusterHealthThreshold).To(gomega.Equal(threshold))
+
+	g.Expect(healthCheck.Status.HealthChecks).To(gomega.HaveLen(3))
+	for _, ref := range healthCheck.Status.HealthChecks {
+		g.Expect(ref.Cluster).NotTo(gomega.BeEmpty())
+		_, ok := healthCheckRefEndpoint(ref).GetProviderSpecific(testClusterHealthCheckID)
+		g.Expect(ok).To(gomega.BeTrue())
+	}
+
+	// The health checks of the clusters are deleted along with the HealthCheck
+	now := metav1.Now()
+	healthCheck.DeletionTimestamp = &now
+	g.Expect(c.reconcile(context.TODO(), healthCheck)).To(gomega.Succeed())
+	g.Expect(provider.healthChecks).To(gomega.BeEmpty())
+	g.Expect(provider.clusterHealthChecks).To(gomega.BeEmpty())
+}
+
+func TestHealthCheckControllerReconcilesPluginHealthChecks(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	weighted := aEndpoint("app.example.com", "10.0.0.1")
+	weighted.SetIdentifier = "10.0.0.1"
+	record := newTestRecord("app", weighted)
+	record.Namespace = "default"
+	record.OwnerReferences = []metav1.OwnerReference{{Kind: "Ingress", Name: "app"}}
+
+	// The plugin serves a backend that records the health checks
+	backend := &healthCheckProvider{healthChecks: map[string]v1.EndpointHealthCheck{}}
+	address := "unix://" + filepath.Join(t.TempDir(), "dns.sock")
+	listener, err := dnsPlugin.Listen(address)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	server := dnsPlugin.NewServer(backend)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+	provider, err := dnsPlugin.NewProvider(dnsPlugin.Config{Address: address})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	t.Cleanup(func() { _ = provider.Close() })
+
+	// The plugins do not request the endpoints to be probed by the GLBC
+	client := fake.NewSimpleClientset(record)
+	c, err := NewHealthCheckController(&HealthCheckControllerConfig{
+		ControllerConfig:      &reconciler.ControllerConfig{},
+		KuadrantClient:        &fakeClusterClient{client},
+		SharedInformerFactory: externalversions.NewSharedInformerFactory(client, 0),
+		DNSProvider:           provider,
+	})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	t.Cleanup(c.Queue.ShutDown)
+	g.Expect(c.prober).To(gomega.BeNil())
+	g.Expect(c.healthChecker).To(gomega.BeIdenticalTo(provider))
+
+	// The health checks are reconciled by the plugin
+	healthCheck := newTestHealthCheck("app", "app")
+	g.Expect(c.indexer.Add(healthCheck)).To(gomega.Succeed())
+	g.Expect(c.dnsRecordIndexer.Add(record)).To(gomega.Succeed())
+	g.Expect(c.reconcile(context.TODO(), healthCheck)).To(gomega.Succeed())
+	g.Expect(backend.healthChecks).To(gomega.HaveLen(1))
+	for _, hc := range backend.healthChecks {
+		g.Expect(hc.Path).To(gomega.Equal("/healthz"))
+	}
+}
+
+func TestHealthCheckControllerDeletesHealthChecksOnceUnpublished(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	weighted := func(name, ip string) *v1.Endpoint {
+		endpoint := aEndpoint(name, ip)
+		endpoint.SetIdentifier = ip
+		return endpoint
+	}
+	record := newTestRecord("app", weighted("app.example.com", "10.0.0.1"), weighted("www.example.com", "10.0.0.2"))
+	record.Namespace = "default"
+	record.OwnerReferences = []metav1.OwnerReference{{Kind: "Ingress", Name: "app"}}
+
+	client := &fakeClusterClient{fake.NewSimpleClientset(record)}
+	provider := &healthCheckProvider{healthChecks: map[string]v1.EndpointHealthCheck{}, health: v1.Healthy}
+	c := &HealthCheckController{
+		Controller:       &reconciler.Controller{Logger: log.Logger, Queue: workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())},
+		kuadrantClient:   client,
+		indexer:          cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{healthCheckTargetIndex: healthCheckTargetIndexFunc}),
+		dnsRecordIndexer: cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{}),
+		healthChecker:    provider,
+	}
+	t.Cleanup(c.Queue.ShutDown)
+	g.Expect(c.dnsRecordIndexer.Add(record)).To(gomega.Succeed())
+
+	healthCheck := newTestHealthCheck("app", "app")
+	g.Expect(c.indexer.Add(healthCheck)).To(gomega.Succeed())
+	g.Expect(c.reconcile(context.TODO(), healthCheck)).To(gomega.Succeed())
+	g.Expect(provider.healthChecks).To(gomega.HaveLen(2))
+
+	// The DNSRecord controller publishes the endpoints with their health checks
+	publish := func() {
+		updated, err := client.KuadrantV1().DNSRecords("default").Get(context.TODO(), "app", metav1.GetOptions{})
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		updated.Status.Zones = []v1.DNSZoneStatus{{DNSZone: v1.DNSZone{ID: "example.com"}, Endpoints: withHealthChecks(updated, healthCheck.Status.HealthChecks).Spec.Endpoints}}
+		updated, err = client.KuadrantV1().DNSRecords("default").UpdateStatus(context.TODO(), updated, metav1.UpdateOptions{})
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		g.Expect(c.dnsRecordIndexer.Update(updated)).To(gomega.Succeed())
+	}
+	publish()
+
+	// The health check of an endpoint removed from the DNSRecord is only
+	// deleted once the endpoint is no longer published
+	updated, err := client.KuadrantV1().DNSRecords("default").Get(context.TODO(), "app", metav1.GetOptions{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	updated.Spec.Endpoints = updated.Spec.Endpoints[:1]
+	updated, err = client.KuadrantV1().DNSRecords("default").Update(context.TODO(), updated, metav1.UpdateOptions{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(c.dnsRecordIndexer.Update(updated)).To(gomega.Succeed())
+	g.Expect(c.reconcile(context.TODO(), healthCheck)).To(gomega.Succeed())
+	g.Expect(provider.healthChecks).To(gomega.HaveLen(2))
+	g.Expect(healthCheck.Status.HealthChecks).To(gomega.HaveLen(2))
+	g.Expect(healthCheck.Status.HealthChecks[1].DNSName).To(gomega.Equal("www.example.com"))
+	g.Expect(healthCheck.Status.HealthChecks[1].Stale).To(gomega.BeTrue())
+	g.Expect(withHealthChecks(updated, healthCheck.Status.HealthChecks).Spec.Endpoints).To(gomega.HaveLen(1))
+
+	publish()
+	g.Expect(c.reconcile(context.TODO(), healthCheck)).To(gomega.Succeed())
+	g.Expect(provider.healthChecks).To(gomega.HaveLen(1))
+
+	// The health checks are only deleted along with the HealthCheck once the
+	// endpoints are published without them
+	now := metav1.Now()
+	healthCheck.DeletionTimestamp = &now
+	g.Expect(c.reconcile(context.TODO(), healthCheck)).To(gomega.Succeed())
+	g.Expect(healthCheck.Finalizers).To(gomega.ConsistOf(HealthCheckFinalizer))
+	g.Expect(provider.healthChecks).To(gomega.HaveLen(1))
+	g.Expect(healthCheck.Status.HealthChecks).To(gomega.HaveLen(1))
+	g.Expect(healthCheck.Status.HealthChecks[0].Stale).To(gomega.BeTrue())
+
+	publish()
+	g.Expect(c.reconcile(context.TODO(), healthCheck)).To(gomega.Succeed())
+	g.Expect(healthCheck.Finalizers).To(gomega.BeEmpty())
+	g.Expect(provider.healthChecks).To(gomega.BeEmpty())
+	g.Expect(healthCheck.Status.HealthChecks).To(gomega.BeEmpty())
+
+	// The DNSRecord is never updated by the HealthCheck controller
+	updated, err = client.KuadrantV1().DNSRecords("default").Get(context.TODO(), "app", metav1.GetOptions{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(updated.Annotations).To(gomega.Equal(record.Annotations))
+	g.Expect(updated.Finalizers).To(gomega.BeEmpty())
+	g.Expect(updated.Status.Health).To(gomega.BeEmpty())
+}
+
+func TestHealthCheckControllerDeletesHealthChecksOfDeletedRecords(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	weighted := aEndpoint("app.example.com", "10.0.0.1")
+	weighted.SetIdentifier = "10.0.0.1"
+	record := newTestRecord("app", weighted)
+	record.Namespace = "default"
+	record.OwnerReferences = []metav1.OwnerReference{{Kind: "Ingress", Name: "app"}}
+
+	provider := &healthCheckProvider{healthChecks: map[string]v1.EndpointHealthCheck{}, health: v1.Healthy}
+	c := &HealthCheckController{
+		Controller:       &reconciler.Controller{Logger: log.Logger, Queue: workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())},
+		kuadrantClient:   &fakeClusterClient{fake.NewSimpleClientset(record)},
+		indexer:          cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{healthCheckTargetIndex: healthCheckTargetIndexFunc}),
+		dnsRecordIndexer: cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{}),
+		healthChecker:    provider,
+	}
+	t.Cleanup(c.Queue.ShutDown)
+	g.Expect(c.dnsRecordIndexer.Add(record)).To(gomega.Succeed())
+
+	healthCheck := newTestHealthCheck("app", "app")
+	g.Expect(c.indexer.Add(healthCheck)).To(gomega.Succeed())
+	g.Expect(c.reconcile(context.TODO(), healthCheck)).To(gomega.Succeed())
+	g.Expect(provider.healthChecks).To(gomega.HaveLen(1))
+
+	// The health checks are kept while the DNSRecord is being deleted from
+	// the zones it is published to with them
+	published := withHealthChecks(record, healthCheck.Status.HealthChecks)
+	deleting := record.DeepCopy()
+	now := metav1.Now()
+	deleting.DeletionTimestamp = &now
+	deleting.Finalizers = []string{DNSRecordFinalizer}
+	deleting.Status.Zones = []v1.DNSZoneStatus{{DNSZone: v1.DNSZone{ID: "example.com"}, Endpoints: published.Spec.Endpoints}}
+	g.Expect(c.dnsRecordIndexer.Update(deleting)).To(gomega.Succeed())
+	g.Expect(c.reconcile(context.TODO(), healthCheck)).To(gomega.Succeed())
+	g.Expect(healthCheck.Status.Message).To(gomega.Equal("DNSRecord of Ingress app is being deleted"))
+	g.Expect(healthCheck.Status.HealthChecks).To(gomega.HaveLen(1))
+	g.Expect(provider.healthChecks).To(gomega.HaveLen(1))
+
+	// The health checks are deleted once the DNSRecord is
+	g.Expect(c.dnsRecordIndexer.Delete(deleting)).To(gomega.Succeed())
+	g.Expect(c.reconcile(context.TODO(), healthCheck)).To(gomega.Succeed())
+	g.Expect(healthCheck.Status.Message).To(gomega.Equal("No DNSRecord found for Ingress app"))
+	g.Expect(healthCheck.Status.HealthChecks).To(gomega.BeEmpty())
+	g.Expect(provider.healthChecks).To(gomega.BeEmpty())
+}
+
+func TestWithHealthChecks(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	record := newTestRecord("app", aEndpoint("app.example.com", "10.0.0.1"), aEndpoint("www.example.com", "10.0.0.2"))
+	g.Expect(withHealthChecks(record, nil)).To(gomega.BeIdenticalTo(record))
+
+	refs := []v1.EndpointHealthCheckRef{
+		{DNSName: "app.example.com", ProviderSpecific: v1.ProviderSpecific{{Name: testHealthCheckID, Value: "1"}}},
+		{DNSName: "www.example.com", ProviderSpecific: v1.ProviderSpecific{{Name: testHealthCheckID, Value: "2"}}, Stale: true},
+	}
+	checked := withHealthChecks(record, refs)
+	g.Expect(checked.Spec.Endpoints[0].ProviderSpecific).To(gomega.Equal(v1.ProviderSpecific{{Name: testHealthCheckID, Value: "1"}}))
+	g.Expect(checked.Spec.Endpoints[1].ProviderSpecific).To(gomega.BeEmpty())
+	g.Expect(record.Spec.Endpoints[0].ProviderSpecific).To(gomega.BeEmpty())
+
+	// The records are published again when the health checks change
+	zone := v1.DNSZone{ID: "example.com"}
+	record.Status.Zones = []v1.DNSZoneStatus{{DNSZone: zone, Endpoints: record.Spec.Endpoints}}
+	g.Expect(publishedEndpointsChanged(record, checked, zone)).To(gomega.BeTrue())
+	record.Status.Zones[0].Endpoints = checked.Spec.Endpoints
+	g.Expect(publishedEndpointsChanged(record, checked, zone)).To(gomega.BeFalse())
+}
+
+func TestReconcilePublishesHealthChecksOfHealthChecks(t *testing.T) {
+	g := gomega.NewWithT(t)
+	provider := newTestMemoryProvider(t)
+	zone := v1.DNSZone{ID: "example.com"}
+	c := newTestController(t, "health", NewRegistry("glbc-1", provider), []Zone{{DNSZone: zone, Domain: "example.com"}})
+
+	weighted := aEndpoint("app.example.com", "10.0.0.1")
+	weighted.SetIdentifier = "10.0.0.1"
+	record := newTestRecord("app", weighted)
+	record.Namespace = "default"
+	record.OwnerReferences = []metav1.OwnerReference{{Kind: "Ingress", Name: "app"}}
+
+	// The DNSRecord controller reads the health checks, and the health, of
+	// the endpoints from the status of the HealthChecks
+	observed := metav1.Now()
+	healthCheck := newTestHealthCheck("app", "app")
+	healthCheck.Status.Endpoints = []v1.HealthCheckEndpointStatus{{DNSName: "app.example.com", SetIdentifier: "10.0.0.1", Health: v1.Healthy, LastObservedTime: observed}}
+	healthCheck.Status.HealthChecks = []v1.EndpointHealthCheckRef{
+		{DNSName: "app.example.com", SetIdentifier: "10.0.0.1", ProviderSpecific: v1.ProviderSpecific{{Name: testHealthCheckID, Value: "1"}}},
+	}
+	g.Expect(c.healthCheckIndexer.Add(healthCheck)).To(gomega.Succeed())
+
+	g.Expect(c.reconcile(context.TODO(), record)).To(gomega.Succeed())
+	g.Expect(record.Status.Health).To(gomega.Equal([]v1.EndpointHealth{
+		{DNSName: "app.example.com", SetIdentifier: "10.0.0.1", Health: v1.Healthy, LastObservedTime: observed},
+	}))
+	g.Expect(record.Status.Zones).To(gomega.HaveLen(1))
+	g.Expect(record.Status.Zones[0].Endpoints[0].DNSName).To(gomega.Equal("app.example.com"))
+	g.Expect(record.Status.Zones[0].Endpoints[0].ProviderSpecific).To(gomega.Equal(v1.ProviderSpecific{{Name: testHealthCheckID, Value: "1"}}))
+	g.Expect(record.Spec.Endpoints[0].ProviderSpecific).To(gomega.BeEmpty())
+
+	// The HealthChecks of other traffic objects are ignored
+	record.OwnerReferences = []metav1.OwnerReference{{Kind: "Route", Name: "app"}}
+	g.Expect(c.reconcile(context.TODO(), record)).To(gomega.Succeed())
+	g.Expect(record.Status.Health).To(gomega.BeEmpty())
+	g.Expect(record.Status.Zones[0].Endpoints[0].ProviderSpecific).To(gomega.BeEmpty())
+}
+
+func TestOnlyHealthChanged(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	old := newTestRecord("app", aEndpoint("app.example.com", "10.0.0.1"))
+	old.ResourceVersion = "1"
+
+	obj := old.DeepCopy()
+	obj.ResourceVersion = "2"
+	obj.Status.Health = []v1.EndpointHealth{{DNSName: "app.example.com", Health: v1.Healthy}}
+	g.Expect(onlyHealthChanged(old, obj)).To(gomega.BeTrue())
+
+	obj.Spec.Endpoints[0].Targets = v1.Targets{"10.0.0.2"}
+	g.Expect(onlyHealthChanged(old, obj)).To(gomega.BeFalse())
+}
@@ -3,205 +3,484 @@ package dns
 import (
 	"context"
 	"crypto/md5"
-	"errors"
 	"fmt"
 	"io"
-	"strconv"
-	"strings"
+	"sort"
 
-	"github.com/aws/aws-sdk-go/aws"
+	"github.com/kcp-dev/logicalcluster/v2"
 
+	"github.com/kuadrant/kcp-glbc/pkg/_internal/metadata"
 	v1 "github.com/kuadrant/kcp-glbc/pkg/apis/kuadrant/v1"
+	dnserrors "github.com/kuadrant/kcp-glbc/pkg/dns/errors"
 )
 
-const ANNOTATION_HEALTH_CHECK_PREFIX = "kuadrant.experimental/health-"
+const (
+	// HealthCheckFinalizer is set on the HealthChecks until the health checks
+	// of the endpoints they check are deleted.
+	HealthCheckFinalizer = "kuadrant.dev/health-check"
 
-// healthChecksConfig represents the user configuration for the health checks
-type healthChecksConfig struct {
-	Endpoint         string
-	Port             *int64
-	FailureThreshold *int64
-	Protocol         *v1.HealthCheckProtocol
-}
+	defaultHealthCheckPort = 80
+)
 
-// annotationsConfigMap contains the logic to map an annotation-based configuration
-// value into a mutation of the healthChecksConfig.
-var annotationsConfigMap = map[string]func(string, *healthChecksConfig) error{
-	"endpoint": notNilConfig(func(endpoint string, s *healthChecksConfig) error {
-		s.Endpoint = endpoint
-		return nil
-	}),
-	"port": notNilConfig(configInt64(func(port int64, c *healthChecksConfig) {
-		c.Port = &port
-	})),
-	"protocol": notNilConfig(func(protocol string, c *healthChecksConfig) error {
-		var value v1.HealthCheckProtocol
-		switch protocol {
-		case string(v1.HealthCheckProtocolHTTP):
-		case string(v1.HealthCheckProtocolHTTPS):
-			value = v1.HealthCheckProtocol(protocol)
+func (c *HealthCheckController) reconcile(ctx context.Context, healthCheck *v1.HealthCheck) error {
+	c.Logger.V(3).Info("starting reconcile of HealthCheck", "name", healthCheck.Name, "namespace", healthCheck.Namespace, "cluster", logicalcluster.From(healthCheck))
+
+	record, err := c.targetRecord(healthCheck)
+	if err != nil {
+		return err
+	}
+
+	// If the HealthCheck was deleted, clean up and return.
+	if healthCheck.DeletionTimestamp != nil && !healthCheck.DeletionTimestamp.IsZero() {
+		endpointHealthMetrics.delete(healthCheck)
+		healthCheck.Status.Endpoints = nil
+		released, err := c.releaseHealthChecks(ctx, healthCheck, record)
+		if err != nil {
+			return err
+		}
+		// The HealthCheck is reconciled again once the endpoints are
+		// published without their health checks
+		if !released {
+			c.EnqueueAfter(healthCheck, healthRefreshInterval)
+			return nil
 		}
+		metadata.RemoveFinalizer(healthCheck, HealthCheckFinalizer)
+		return nil
+	}
 
-		if value == "" {
-			return fmt.Errorf("invalid protocol %s. Only supported values are HTTP and HTTPS", protocol)
+	metadata.AddFinalizer(healthCheck, HealthCheckFinalizer)
+	healthCheck.Status.ObservedGeneration = healthCheck.Generation
+
+	// The health checks are deleted when the DNSRecord is deleted, or checked
+	// by another HealthCheck
+	var message string
+	switch {
+	case record == nil:
+		message = fmt.Sprintf("No DNSRecord found for %s %s", healthCheck.Spec.TargetRef.Kind, healthCheck.Spec.TargetRef.Name)
+	case record.DeletionTimestamp != nil && !record.DeletionTimestamp.IsZero():
+		message = fmt.Sprintf("DNSRecord of %s %s is being deleted", healthCheck.Spec.TargetRef.Kind, healthCheck.Spec.TargetRef.Name)
+	default:
+		owner, err := c.checkingHealthCheck(record)
+		if err != nil {
+			return err
 		}
+		if owner != nil && owner.Name != healthCheck.Name {
+			message = fmt.Sprintf("%s %s is already checked by HealthCheck %s", healthCheck.Spec.TargetRef.Kind, healthCheck.Spec.TargetRef.Name, owner.Name)
+		}
+	}
+	if message != "" {
+		endpointHealthMetrics.delete(healthCheck)
+		healthCheck.Status.Endpoints = nil
+		healthCheck.Status.Message = message
+		_, err := c.releaseHealthChecks(ctx, healthCheck, record)
+		return err
+	}
 
-		c.Protocol = &value
+	// The health checks are reconciled for copies of the endpoints, with the
+	// provider specific properties of their health checks recorded in the
+	// status of the HealthCheck, for the DNSRecord controller to publish the
+	// endpoints with them, as the spec of the DNSRecord is left to the traffic
+	// controller
+	statuses, checked, err := c.reconcileHealthChecks(ctx, healthCheck, withHealthChecks(record.DeepCopy(), healthCheck.Status.HealthChecks))
+	if dnserrors.ReasonForError(err) == dnserrors.ReasonInvalidRecord {
+		// The health checks rejected by the DNS provider are reported, rather
+		// than reconciled again until the HealthCheck is modified
+		c.Logger.Error(err, "Health checks rejected by the DNS provider", "healthCheck", healthCheck.Name)
+		healthCheck.Status.Message = err.Error()
 		return nil
-	}),
-	"failure-threshold": notNilConfig(configInt64(func(v int64, c *healthChecksConfig) {
-		c.FailureThreshold = &v
-	})),
-}
-
-func (c *Controller) ReconcileHealthChecks(ctx context.Context, dnsRecord *v1.DNSRecord) error {
-	config, err := configFromAnnotations(dnsRecord.Annotations)
+	}
 	if err != nil {
 		return err
 	}
 
-	if config == nil {
-		return c.reconcileHealthCheckDeletion(ctx, dnsRecord)
+	for _, changed := range endpointHealthMetrics.observe(healthCheck, healthCheck.Status.Endpoints, statuses) {
+		c.Logger.Info("Endpoint health changed", "record", record.Name, "name", changed.DNSName, "identifier", changed.SetIdentifier, "health", changed.Health)
 	}
-
-	if err = validateHealthChecksConfig(config); err != nil {
+	healthCheck.Status.Endpoints = statuses
+	healthCheck.Status.Message = ""
+	active := healthCheckRefs(record, checked)
+	refs, err := c.deleteStaleHealthChecks(ctx, record, append(active, staleHealthCheckRefs(healthCheck.Status.HealthChecks, active)...))
+	if err != nil {
 		return err
 	}
+	healthCheck.Status.HealthChecks = refs
 
-	return c.reconcileHealthCheck(ctx, config, dnsRecord)
-}
+	// The health of the endpoints is refreshed periodically
+	if _, ok := c.healthChecker.(HealthCheckStatusReader); ok {
+		c.EnqueueAfter(healthCheck, healthRefreshInterval)
+	}
 
-func (c *Controller) reconcileHealthCheck(ctx context.Context, config *healthChecksConfig, dnsRecord *v1.DNSRecord) error {
+	return nil
+}
 
+// reconcileHealthChecks reconciles the health checks of the endpoints of the
+// DNSRecord, and returns the status of the endpoints, along with the checked
+// endpoints. The endpoints are updated with the provider specific properties
+// of their health checks.
+func (c *HealthCheckController) reconcileHealthChecks(ctx context.Context, healthCheck *v1.HealthCheck, dnsRecord *v1.DNSRecord) ([]v1.HealthCheckEndpointStatus, []*v1.Endpoint, error) {
+	var statuses []v1.HealthCheckEndpointStatus
+	var checked []*v1.Endpoint
 	for _, dnsEndpoint := range dnsRecord.Spec.Endpoints {
-		ok := false
-		if _, ok = dnsEndpoint.GetAddress(); !ok {
-			c.Logger.Info("Skipping health check creation: no address set", "record", dnsRecord, "endpoint", dnsEndpoint.DNSName)
+		// The CAA records have no target to check
+		if dnsEndpoint.RecordType == string(v1.CAARecordType) {
+			continue
+		}
+		if dnsEndpoint.RecordType == string(v1.CNAMERecordType) {
+			c.Logger.V(3).Info("Skipping health check creation: CNAME record", "record", dnsRecord.Name, "endpoint", dnsEndpoint.DNSName)
+			continue
+		}
+		if _, ok := dnsEndpoint.GetAddress(); !ok {
+			c.Logger.Info("Skipping health check creation: no address set", "record", dnsRecord.Name, "endpoint", dnsEndpoint.DNSName)
 			continue
 		}
 
 		endpointId, err := idForEndpoint(dnsRecord, dnsEndpoint)
 		if err != nil {
-			return err
+			return nil, nil, err
 		}
 
-		spec := v1.HealthCheck{
-			Id:               endpointId,
-			Name:             fmt.Sprintf("%s-%s", dnsEndpoint.DNSName, dnsEndpoint.SetIdentifier),
-			Path:             config.Endpoint,
-			Port:             config.Port,
-			Protocol:         config.Protocol,
-			FailureThreshold: config.FailureThreshold,
-		}
+		spec := endpointHealthCheck(healthCheck, endpointId, fmt.Sprintf("%s-%s", dnsEndpoint.DNSName, dnsEndpoint.SetIdentifier))
 
 		c.Logger.Info("Reconciling health check for endpoint", "name", dnsEndpoint.DNSName, "identifier", dnsEndpoint.SetIdentifier)
 
-		err = c.dnsProvider.ReconcileHealthCheck(ctx, spec, dnsEndpoint)
+		if err := c.healthChecker.ReconcileHealthCheck(ctx, spec, dnsEndpoint); err != nil {
+			return nil, nil, err
+		}
+
+		statuses = append(statuses, c.endpointStatus(ctx, dnsEndpoint))
+		checked = append(checked, dnsEndpoint)
+	}
+
+	if err := c.reconcileClusterHealthChecks(ctx, healthCheck, dnsRecord, checked); err != nil {
+		return nil, nil, err
+	}
+
+	return statuses, checked, nil
+}
+
+// reconcileClusterHealthChecks reconciles the health checks of the clusters of
+// the checked endpoints, that aggregate the health checks of their endpoints,
+// for the DNS providers that support it.
+func (c *HealthCheckController) reconcileClusterHealthChecks(ctx context.Context, healthCheck *v1.HealthCheck, dnsRecord *v1.DNSRecord, endpoints []*v1.Endpoint) error {
+	reconciler, ok := c.healthChecker.(ClusterHealthCheckReconciler)
+	if !ok {
+		return nil
+	}
+	clusters := endpointsByCluster(endpoints)
+	for _, cluster := range sortedClusters(clusters) {
+		clusterId, err := idForCluster(dnsRecord, cluster)
 		if err != nil {
 			return err
 		}
+		spec := v1.EndpointHealthCheck{
+			Id:                     clusterId,
+			Name:                   fmt.Sprintf("%s-%s", dnsRecord.Name, cluster),
+			ClusterHealthThreshold: healthCheck.Spec.ClusterHealthThreshold,
+		}
+		if err := reconciler.ReconcileClusterHealthCheck(ctx, spec, clusters[cluster]); err != nil {
+			return err
+		}
 	}
-
 	return nil
 }
 
-func (c *Controller) reconcileHealthCheckDeletion(ctx context.Context, dnsRecord *v1.DNSRecord) error {
-
-	for _, zone := range dnsRecord.Status.Zones {
-		for _, endpoint := range zone.Endpoints {
-			if err := c.dnsProvider.DeleteHealthCheck(ctx, endpoint); err != nil {
-				return err
-			}
+// deleteClusterHealthChecks deletes the health checks of the given clusters,
+// for the DNS providers that support them.
+func (c *HealthCheckController) deleteClusterHealthChecks(ctx context.Context, clusters map[string][]*v1.Endpoint) error {
+	reconciler, ok := c.healthChecker.(ClusterHealthCheckReconciler)
+	if !ok {
+		return nil
+	}
+	for _, cluster := range sortedClusters(clusters) {
+		if err := reconciler.DeleteClusterHealthCheck(ctx, clusters[cluster]); err != nil {
+			return err
 		}
 	}
-
 	return nil
 }
 
-// idForEndpoint returns a unique identifier for an endpoint
-func idForEndpoint(dnsRecord *v1.DNSRecord, endpoint *v1.Endpoint) (string, error) {
-	hash := md5.New()
-	if _, err := io.WriteString(hash, fmt.Sprintf("%s/%s@%s", dnsRecord.Name, endpoint.SetIdentifier, endpoint.DNSName)); err != nil {
-		return "", fmt.Errorf("unexpected error creating ID for endpoint %s", endpoint.SetIdentifier)
+// endpointStatus returns the status of the endpoint, whose health is unknown
+// unless reported by the DNS provider.
+func (c *HealthCheckController) endpointStatus(ctx context.Context, endpoint *v1.Endpoint) v1.HealthCheckEndpointStatus {
+	status := v1.HealthCheckEndpointStatus{
+		DNSName:       endpoint.DNSName,
+		SetIdentifier: endpoint.SetIdentifier,
+		Health:        v1.HealthUnknown,
 	}
-	return fmt.Sprintf("%x", hash.Sum(nil)), nil
+	reader, ok := c.healthChecker.(HealthCheckStatusReader)
+	if !ok {
+		return status
+	}
+	providerID, observed, err := reader.HealthCheckStatus(ctx, endpoint)
+	if err != nil {
+		c.Logger.Error(err, "Failed to get health of endpoint", "name", endpoint.DNSName, "identifier", endpoint.SetIdentifier)
+		return status
+	}
+	status.ProviderID = providerID
+	if observed.Health != "" {
+		status.Health = observed.Health
+		status.LastObservedTime = observed.LastObservedTime
+	}
+	return status
 }
 
-// configFromAnnotations populates a healthChecksConfig instance from the
-// annotations map. If no relevant annotation are found, returns nil
-func configFromAnnotations(annotations map[string]string) (*healthChecksConfig, error) {
-	if annotations == nil {
-		return nil, nil
+// deleteStaleHealthChecks deletes the health checks of the stale references,
+// once the endpoints of the DNSRecord are no longer published with them, as
+// the DNS providers may refuse to delete the health checks in use. It returns
+// the references that are not stale, along with the stale ones whose health
+// checks are still in use.
+func (c *HealthCheckController) deleteStaleHealthChecks(ctx context.Context, dnsRecord *v1.DNSRecord, refs []v1.EndpointHealthCheckRef) ([]v1.EndpointHealthCheckRef, error) {
+	var kept []v1.EndpointHealthCheckRef
+	var stale []*v1.Endpoint
+	for _, ref := range refs {
+		if !ref.Stale || isPublishedWith(dnsRecord, ref) {
+			kept = append(kept, ref)
+			continue
+		}
+		stale = append(stale, healthCheckRefEndpoint(ref))
 	}
 
-	var result *healthChecksConfig
-
-	for k, v := range annotations {
-		// The annotation is not for the health check. Skip it
-		if !strings.HasPrefix(k, ANNOTATION_HEALTH_CHECK_PREFIX) {
-			continue
+	// The health checks of the clusters that have no endpoint left are deleted
+	// first, as they aggregate the health checks of their endpoints
+	staleClusters := endpointsByCluster(stale)
+	for _, ref := range kept {
+		delete(staleClusters, ref.Cluster)
+	}
+	if err := c.deleteClusterHealthChecks(ctx, staleClusters); err != nil {
+		return nil, err
+	}
+	for _, endpoint := range stale {
+		if err := c.healthChecker.DeleteHealthCheck(ctx, endpoint); err != nil {
+			return nil, err
 		}
+	}
+	return kept, nil
+}
 
-		// Get the configuration key
-		field := strings.TrimPrefix(k, ANNOTATION_HEALTH_CHECK_PREFIX)
+// releaseHealthChecks deletes the health checks of the HealthCheck. The health
+// checks are first marked as stale, for the DNSRecord controller to publish the
+// endpoints again without them, and are only deleted once they are no longer in
+// use. It returns false until then.
+func (c *HealthCheckController) releaseHealthChecks(ctx context.Context, healthCheck *v1.HealthCheck, dnsRecord *v1.DNSRecord) (bool, error) {
+	refs := make([]v1.EndpointHealthCheckRef, 0, len(healthCheck.Status.HealthChecks))
+	for _, ref := range healthCheck.Status.HealthChecks {
+		ref.Stale = true
+		refs = append(refs, ref)
+	}
+	kept, err := c.deleteStaleHealthChecks(ctx, dnsRecord, refs)
+	if err != nil {
+		return false, err
+	}
+	healthCheck.Status.HealthChecks = kept
+	if len(kept) > 0 {
+		c.Logger.Info("Waiting for endpoints to be published without their health checks", "healthCheck", healthCheck.Name, "endpoints", len(kept))
+		return false, nil
+	}
+	return true, nil
+}
 
-		// Get the set value function for the configuration key
-		setValue, ok := annotationsConfigMap[field]
-		if !ok {
-			return nil, fmt.Errorf("invalid value for annotation %s: %s", k, v)
+// healthCheckRefs returns the references of the health checks of the checked
+// endpoints, by the provider specific properties the health checker set on the
+// endpoints, that their counterparts in the spec of the DNSRecord do not have.
+func healthCheckRefs(dnsRecord *v1.DNSRecord, checked []*v1.Endpoint) []v1.EndpointHealthCheckRef {
+	specified := map[string]*v1.Endpoint{}
+	for _, endpoint := range dnsRecord.Spec.Endpoints {
+		specified[endpointKey(endpoint)] = endpoint
+	}
+	var refs []v1.EndpointHealthCheckRef
+	for _, endpoint := range checked {
+		var properties v1.ProviderSpecific
+		for _, property := range endpoint.ProviderSpecific {
+			if spec, ok := specified[endpointKey(endpoint)]; ok {
+				if _, ok := spec.GetProviderSpecific(property.Name); ok {
+					continue
+				}
+			}
+			properties = append(properties, property)
+		}
+		if len(properties) == 0 {
+			continue
 		}
+		refs = append(refs, v1.EndpointHealthCheckRef{
+			DNSName:          endpoint.DNSName,
+			SetIdentifier:    endpoint.SetIdentifier,
+			Cluster:          endpoint.Labels[LabelCluster],
+			ProviderSpecific: properties,
+		})
+	}
+	return refs
+}
 
-		if result == nil {
-			result = &healthChecksConfig{}
+// staleHealthCheckRefs returns the previous references that are stale, as
+// their endpoints are no longer checked.
+func staleHealthCheckRefs(previous, active []v1.EndpointHealthCheckRef) []v1.EndpointHealthCheckRef {
+	checked := map[string]struct{}{}
+	for _, ref := range active {
+		checked[ref.DNSName+"/"+ref.SetIdentifier] = struct{}{}
+	}
+	var stale []v1.EndpointHealthCheckRef
+	for _, ref := range previous {
+		if _, ok := checked[ref.DNSName+"/"+ref.SetIdentifier]; ok && !ref.Stale {
+			continue
 		}
+		ref.Stale = true
+		stale = append(stale, ref)
+	}
+	return stale
+}
 
-		// Apply the value into the resulting configuration instance
-		if err := setValue(v, result); err != nil {
-			return nil, fmt.Errorf("invalid value for annotation %s: %v", k, err)
+// isPublishedWith returns whether an endpoint of the DNSRecord, if any, is
+// still published with one of the health checks of the reference, as recorded
+// in its zone statuses, until the DNSRecord is deleted from the zones.
+func isPublishedWith(dnsRecord *v1.DNSRecord, ref v1.EndpointHealthCheckRef) bool {
+	if dnsRecord == nil || dnsRecord.DeletionTimestamp != nil && !metadata.HasFinalizer(dnsRecord, DNSRecordFinalizer) {
+		return false
+	}
+	for _, zone := range dnsRecord.Status.Zones {
+		for _, endpoint := range zone.Endpoints {
+			if endpoint.DNSName != ref.DNSName || endpoint.SetIdentifier != ref.SetIdentifier {
+				continue
+			}
+			for _, property := range ref.ProviderSpecific {
+				if value, ok := endpoint.GetProviderSpecific(property.Name); ok && value == property.Value {
+					return true
+				}
+			}
 		}
+	}
+	return false
+}
 
+// healthCheckRefEndpoint returns an endpoint with the provider specific
+// properties of the health checks of the reference.
+func healthCheckRefEndpoint(ref v1.EndpointHealthCheckRef) *v1.Endpoint {
+	endpoint := &v1.Endpoint{
+		DNSName:          ref.DNSName,
+		SetIdentifier:    ref.SetIdentifier,
+		ProviderSpecific: append(v1.ProviderSpecific{}, ref.ProviderSpecific...),
+	}
+	if ref.Cluster != "" {
+		endpoint.Labels = v1.Labels{LabelCluster: ref.Cluster}
 	}
+	return endpoint
+}
 
-	return result, nil
+// targetRecord returns the DNSRecord of the traffic object the HealthCheck
+// targets, or nil if it does not exist.
+func (c *HealthCheckController) targetRecord(healthCheck *v1.HealthCheck) (*v1.DNSRecord, error) {
+	key, err := healthCheckTargetKey(healthCheck)
+	if err != nil {
+		return nil, err
+	}
+	obj, exists, err := c.dnsRecordIndexer.GetByKey(key)
+	if err != nil || !exists {
+		return nil, err
+	}
+	record := obj.(*v1.DNSRecord)
+	for _, owner := range record.OwnerReferences {
+		if owner.Kind == healthCheck.Spec.TargetRef.Kind {
+			return record, nil
+		}
+	}
+	return nil, nil
 }
 
-func validateHealthChecksConfig(config *healthChecksConfig) error {
-	if config == nil {
-		return errors.New("health checks config can't be nil")
+// checkingHealthCheck returns the HealthCheck that checks the DNSRecord, as a
+// DNSRecord is only checked by a single HealthCheck: the one whose health
+// checks the endpoints are published with, if any, or else the oldest one
+// that targets it and is not being deleted.
+func (c *HealthCheckController) checkingHealthCheck(dnsRecord *v1.DNSRecord) (*v1.HealthCheck, error) {
+	healthChecks, err := healthChecksTargeting(c.indexer, dnsRecord)
+	if err != nil {
+		return nil, err
 	}
+	var oldest *v1.HealthCheck
+	for _, healthCheck := range healthChecks {
+		if healthCheck.DeletionTimestamp != nil && !healthCheck.DeletionTimestamp.IsZero() {
+			continue
+		}
+		for _, ref := range healthCheck.Status.HealthChecks {
+			if !ref.Stale {
+				return healthCheck, nil
+			}
+		}
+		if oldest == nil || healthCheck.CreationTimestamp.Before(&oldest.CreationTimestamp) {
+			oldest = healthCheck
+		}
+	}
+	return oldest, nil
+}
 
-	if config.Endpoint == "" {
-		return errors.New("endpoint is a required value to configure health checks")
+// endpointHealthCheck returns the health check of an endpoint, as configured
+// by the HealthCheck.
+func endpointHealthCheck(healthCheck *v1.HealthCheck, id, name string) v1.EndpointHealthCheck {
+	spec := v1.EndpointHealthCheck{
+		Id:                  id,
+		Name:                name,
+		Path:                healthCheck.Spec.Path,
+		Port:                healthCheck.Spec.Port,
+		Protocol:            healthCheck.Spec.Protocol,
+		FailureThreshold:    healthCheck.Spec.FailureThreshold,
+		ExpectedStatusCodes: healthCheck.Spec.ExpectedStatusCodes,
+		HostHeader:          healthCheck.Spec.HostHeader,
+		SearchString:        healthCheck.Spec.SearchString,
+		Regions:             healthCheck.Spec.Regions,
+		Inverted:            healthCheck.Spec.Inverted,
 	}
-	if config.Port == nil {
-		config.Port = aws.Int64(80)
+	if spec.Port == nil {
+		port := int64(defaultHealthCheckPort)
+		spec.Port = &port
 	}
-	if config.Protocol == nil {
-		defaultProtocol := v1.HealthCheckProtocolHTTP
-		config.Protocol = &defaultProtocol
+	if spec.Protocol == nil {
+		protocol := v1.HealthCheckProtocolHTTP
+		spec.Protocol = &protocol
 	}
-
-	return nil
+	if healthCheck.Spec.Interval != nil {
+		spec.Interval = healthCheck.Spec.Interval.Duration
+	}
+	return spec
 }
 
-func configInt64(f func(int64, *healthChecksConfig)) func(string, *healthChecksConfig) error {
-	return func(s string, c *healthChecksConfig) error {
-		intValue, err := strconv.Atoi(s)
-		if err != nil {
-			return err
+// endpointsByCluster returns the endpoints labelled with their cluster, by
+// cluster.
+func endpointsByCluster(endpoints []*v1.Endpoint) map[string][]*v1.Endpoint {
+	clusters := map[string][]*v1.Endpoint{}
+	for _, endpoint := range endpoints {
+		if cluster := endpoint.Labels[LabelCluster]; cluster != "" {
+			clusters[cluster] = append(clusters[cluster], endpoint)
 		}
+	}
+	return clusters
+}
 
-		f(int64(intValue), c)
-		return nil
+func sortedClusters(clusters map[string][]*v1.Endpoint) []string {
+	names := make([]string, 0, len(clusters))
+	for cluster := range clusters {
+		names = append(names, cluster)
 	}
+	sort.Strings(names)
+	return names
 }
 
-func notNilConfig(f func(string, *healthChecksConfig) error) func(string, *healthChecksConfig) error {
-	return func(s string, hcc *healthChecksConfig) error {
-		if hcc == nil {
-			return errors.New("health check config can't be nil")
-		}
+func endpointKey(endpoint *v1.Endpoint) string {
+	return endpoint.DNSName + "/" + endpoint.SetIdentifier
+}
+
+// idForEndpoint returns a unique identifier for an endpoint
+func idForEndpoint(dnsRecord *v1.DNSRecord, endpoint *v1.Endpoint) (string, error) {
+	hash := md5.New()
+	if _, err := io.WriteString(hash, fmt.Sprintf("%s/%s@%s", dnsRecord.Name, endpoint.SetIdentifier, endpoint.DNSName)); err != nil {
+		return "", fmt.Errorf("unexpected error creating ID for endpoint %s", endpoint.SetIdentifier)
+	}
+	return fmt.Sprintf("%x", hash.Sum(nil)), nil
+}
 
-		return f(s, hcc)
+// idForCluster returns a unique identifier for a cluster of the endpoints of a
+// DNSRecord
+func idForCluster(dnsRecord *v1.DNSRecord, cluster string) (string, error) {
+	hash := md5.New()
+	if _, err := io.WriteString(hash, fmt.Sprintf("%s/cluster:%s", dnsRecord.Name, cluster)); err != nil {
+		return "", fmt.Errorf("unexpected error creating ID for cluster %s", cluster)
 	}
+	return fmt.Sprintf("%x", hash.Sum(nil)), nil
 }
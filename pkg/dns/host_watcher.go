@@ -125,7 +125,7 @@ func (w *RecordWatcher) watch(ctx context.Context) {
 				w.onChange(w.key)
 			}
 
-			ttl := w.records[0].TTL
+			ttl := minTTL(w.records)
 			refreshInterval := w.watchInterval(ttl)
 			time.Sleep(refreshInterval)
 			w.logger.V(3).Info("Refreshing records for host", "TTL", int(ttl.Seconds()), "interval", int(refreshInterval.Seconds()))
@@ -160,6 +160,18 @@ func (w *RecordWatcher) updateRecords(newRecords []HostAddress) bool {
 	return updatedIPs
 }
 
+// minTTL returns the lowest TTL of the records, as the IPv4 and IPv6 addresses
+// of a dual-stack host can have distinct TTLs.
+func minTTL(records []HostAddress) time.Duration {
+	ttl := records[0].TTL
+	for _, record := range records[1:] {
+		if record.TTL < ttl {
+			ttl = record.TTL
+		}
+	}
+	return ttl
+}
+
 func (w *RecordWatcher) stop() {
 	w.logger.V(3).Info("Stopping host watcher")
 	w.cancel()
@@ -108,44 +108,64 @@ func (r *ConfigMapHostResolver) TxtRecordExists(ctx context.Context, domain stri
 
 type DefaultHostResolver struct {
 	Client dns.Client
+	// Nameservers are the addresses (host:port) of the name servers to query.
+	// Defaults to the name servers from /etc/resolv.conf.
+	Nameservers []string
 }
 
-func NewDefaultHostResolver() *DefaultHostResolver {
+func NewDefaultHostResolver(nameservers ...string) *DefaultHostResolver {
 	return &DefaultHostResolver{
-		Client: dns.Client{},
+		Client:      dns.Client{},
+		Nameservers: nameservers,
 	}
 }
 
 func (hr *DefaultHostResolver) LookupIPAddr(ctx context.Context, host string) ([]HostAddress, error) {
-	cfg, err := dns.ClientConfigFromFile("/etc/resolv.conf")
-	if err != nil {
-		return nil, err
-	}
-
-	for _, server := range cfg.Servers {
-		m := dns.Msg{}
-		m.SetQuestion(fmt.Sprintf("%s.", host), dns.TypeA)
-
-		r, _, err := hr.Client.ExchangeContext(ctx, &m, fmt.Sprintf("%s:53", server))
+	nameservers := hr.Nameservers
+	if len(nameservers) == 0 {
+		cfg, err := dns.ClientConfigFromFile("/etc/resolv.conf")
 		if err != nil {
 			return nil, err
 		}
-
-		if len(r.Answer) == 0 {
-			continue
+		for _, server := range cfg.Servers {
+			nameservers = append(nameservers, gonet.JoinHostPort(server, "53"))
 		}
+	}
+
+	for _, server := range nameservers {
+		// Look up both the IPv4 and IPv6 addresses, to support dual-stack and IPv6 only hosts
+		var results []HostAddress
+		for _, qtype := range []uint16{dns.TypeA, dns.TypeAAAA} {
+			m := dns.Msg{}
+			m.SetQuestion(fmt.Sprintf("%s.", host), qtype)
+
+			r, _, err := hr.Client.ExchangeContext(ctx, &m, server)
+			if err != nil {
+				return nil, err
+			}
 
-		results := make([]HostAddress, 0, len(r.Answer))
-		for _, answer := range r.Answer {
-			if a, ok := answer.(*dns.A); ok {
-				results = append(results, HostAddress{
-					Host: host,
-					IP:   a.A,
-					TTL:  time.Duration(a.Hdr.Ttl) * time.Second,
-				})
+			for _, answer := range r.Answer {
+				switch rr := answer.(type) {
+				case *dns.A:
+					results = append(results, HostAddress{
+						Host: host,
+						IP:   rr.A,
+						TTL:  time.Duration(rr.Hdr.Ttl) * time.Second,
+					})
+				case *dns.AAAA:
+					results = append(results, HostAddress{
+						Host: host,
+						IP:   rr.AAAA,
+						TTL:  time.Duration(rr.Hdr.Ttl) * time.Second,
+					})
+				}
 			}
 		}
 
+		if len(results) == 0 {
+			continue
+		}
+
 		return results, nil
 	}
 
@@ -0,0 +1,49 @@
+package dns
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+
+	v1 "github.com/kuadrant/kcp-glbc/pkg/apis/kuadrant/v1"
+)
+
+func TestDefaultHostResolverLookupIPAddr(t *testing.T) {
+	g := gomega.NewWithT(t)
+	provider := newTestMemoryProvider(t)
+	zone := v1.DNSZone{ID: "example.com"}
+
+	record := &v1.DNSRecord{
+		Spec: v1.DNSRecordSpec{
+			Endpoints: []*v1.Endpoint{
+				{DNSName: "lb.example.com", RecordType: string(v1.ARecordType), RecordTTL: 60, Targets: v1.Targets{"10.0.0.1"}},
+				{DNSName: "lb.example.com", RecordType: string(v1.AAAARecordType), RecordTTL: 30, Targets: v1.Targets{"2001:db8::1"}},
+				{DNSName: "lb6.example.com", RecordType: string(v1.AAAARecordType), RecordTTL: 60, Targets: v1.Targets{"2001:db8::2"}},
+			},
+		},
+	}
+	g.Expect(provider.Ensure(context.TODO(), record, zone)).To(gomega.Succeed())
+
+	resolver := NewDefaultHostResolver(provider.Addr())
+
+	// Dual-stack hosts resolve to both their IPv4 and IPv6 addresses
+	addresses, err := resolver.LookupIPAddr(context.TODO(), "lb.example.com")
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(addresses).To(gomega.HaveLen(2))
+	g.Expect(addresses[0].IP.String()).To(gomega.Equal("10.0.0.1"))
+	g.Expect(addresses[0].TTL).To(gomega.Equal(60 * time.Second))
+	g.Expect(addresses[1].IP.String()).To(gomega.Equal("2001:db8::1"))
+	g.Expect(addresses[1].TTL).To(gomega.Equal(30 * time.Second))
+	g.Expect(minTTL(addresses)).To(gomega.Equal(30 * time.Second))
+
+	// IPv6 only hosts
+	addresses, err = resolver.LookupIPAddr(context.TODO(), "lb6.example.com")
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(addresses).To(gomega.HaveLen(1))
+	g.Expect(addresses[0].IP.String()).To(gomega.Equal("2001:db8::2"))
+
+	_, err = resolver.LookupIPAddr(context.TODO(), "missing.example.com")
+	g.Expect(err).To(gomega.HaveOccurred())
+}
@@ -0,0 +1,277 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/go-logr/logr"
+
+	"github.com/kuadrant/kcp-glbc/pkg/_internal/log"
+	v1 "github.com/kuadrant/kcp-glbc/pkg/apis/kuadrant/v1"
+	dnserrors "github.com/kuadrant/kcp-glbc/pkg/dns/errors"
+)
+
+const (
+	// ProviderSpecificWeight overrides the weight of the weighted routing policy.
+	ProviderSpecificWeight = "memory/weight"
+
+	PortEnvVar   = "MEMORY_DNS_PORT"
+	ZoneIDEnvVar = "MEMORY_DNS_ZONE"
+
+	DefaultPort = 1053
+)
+
+// Provider stores the endpoints of the DNSRecords in memory, keyed by zone,
+// and serves them from an embedded authoritative DNS server. It is meant for
+// local development and testing, where GLBC can be exercised end to end
+// without a cloud DNS provider.
+// The zone ID is expected to be the name of the zone, e.g. example.com.
+type Provider struct {
+	server *server
+	logger logr.Logger
+
+	mu    sync.RWMutex
+	zones map[string]map[endpointKey]*v1.Endpoint
+}
+
+// Config is the necessary input to configure the provider.
+type Config struct {
+	// Address is the host the embedded DNS server listens on. Defaults to all interfaces.
+	Address string
+	// Port is the UDP and TCP port the embedded DNS server listens on.
+	// Defaults to DefaultPort, a value of -1 picks a random free port.
+	Port int
+}
+
+// endpointKey identifies an endpoint within a zone.
+type endpointKey struct {
+	dnsName       string
+	recordType    string
+	setIdentifier string
+}
+
+func keyForEndpoint(endpoint *v1.Endpoint) endpointKey {
+	return endpointKey{
+		dnsName:       normalizeName(endpoint.DNSName),
+		recordType:    endpoint.RecordType,
+		setIdentifier: endpoint.SetIdentifier,
+	}
+}
+
+func NewProvider(config Config) (*Provider, error) {
+	switch {
+	case config.Port == 0:
+		config.Port = DefaultPort
+	case config.Port < 0:
+		config.Port = 0
+	}
+
+	p := &Provider{
+		zones:  map[string]map[endpointKey]*v1.Endpoint{},
+		logger: log.Logger.WithName("memory-dns"),
+	}
+
+	server, err := newServer(p, fmt.Sprintf("%s:%d", config.Address, config.Port))
+	if err != nil {
+		return nil, fmt.Errorf("failed to start embedded DNS server: %v", err)
+	}
+	p.server = server
+	p.logger = p.logger.WithValues("address", server.Addr())
+	p.logger.Info("Serving DNS records")
+
+	return p, nil
+}
+
+// Addr returns the address the embedded DNS server listens on, for both UDP and TCP.
+func (p *Provider) Addr() string {
+	return p.server.Addr()
+}
+
+// Shutdown stops the embedded DNS server.
+func (p *Provider) Shutdown() error {
+	return p.server.Shutdown()
+}
+
+func (p *Provider) Ensure(ctx context.Context, record *v1.DNSRecord, zone v1.DNSZone) error {
+	for _, endpoint := range record.Spec.Endpoints {
+		if err := validateEndpoint(endpoint); err != nil {
+			return err
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	endpoints := p.zoneEndpoints(zone.ID)
+
+	// Delete any previously published endpoints that are no longer present in record.Spec.Endpoints
+	desired := make(map[endpointKey]struct{}, len(record.Spec.Endpoints))
+	for _, endpoint := range record.Spec.Endpoints {
+		desired[keyForEndpoint(endpoint)] = struct{}{}
+	}
+	for _, endpoint := range endpointsFromZoneStatus(record, zone.ID) {
+		if _, found := desired[keyForEndpoint(endpoint)]; !found {
+			delete(endpoints, keyForEndpoint(endpoint))
+		}
+	}
+
+	for _, endpoint := range record.Spec.Endpoints {
+		endpoints[keyForEndpoint(endpoint)] = endpoint.DeepCopy()
+	}
+
+	p.logger.Info("Upserted DNS record", "record", record.Spec, "zone", zone)
+	return nil
+}
+
+func (p *Provider) Delete(ctx context.Context, record *v1.DNSRecord, zone v1.DNSZone) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	endpoints := p.zoneEndpoints(zone.ID)
+	for _, endpoint := range record.Spec.Endpoints {
+		delete(endpoints, keyForEndpoint(endpoint))
+	}
+
+	p.logger.Info("Deleted DNS record", "record", record.Spec, "zone", zone)
+	return nil
+}
+
+// GetRecords returns the endpoints of the zone with the given name and type.
+func (p *Provider) GetRecords(ctx context.Context, zone v1.DNSZone, dnsName, recordType string) ([]*v1.Endpoint, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	var endpoints []*v1.Endpoint
+	for key, endpoint := range p.zones[normalizeName(zone.ID)] {
+		if key.dnsName == normalizeName(dnsName) && key.recordType == recordType {
+			endpoints = append(endpoints, endpoint.DeepCopy())
+		}
+	}
+	return endpoints, nil
+}
+
+// ListRecords returns all the records of the zone.
+func (p *Provider) ListRecords(ctx context.Context, zone v1.DNSZone) ([]*v1.Endpoint, error) {
+	return p.Endpoints(zone.ID), nil
+}
+
+// RequiresHealthProbes returns true, as the memory provider has no health checks
+// of its own, the endpoints are probed by the GLBC instead.
+func (p *Provider) RequiresHealthProbes() bool {
+	return true
+}
+
+// ReconcileHealthCheck is a no-op, all the endpoints are considered healthy.
+func (p *Provider) ReconcileHealthCheck(ctx context.Context, _ v1.EndpointHealthCheck, endpoint *v1.Endpoint) error {
+	p.logger.V(3).Info("Health checks are not supported by the memory provider, skipping", "endpoint", endpoint.SetID())
+	return nil
+}
+
+func (p *Provider) DeleteHealthCheck(ctx context.Context, _ *v1.Endpoint) error {
+	return nil
+}
+
+// Endpoints returns a copy of the endpoints currently published in the zone.
+func (p *Provider) Endpoints(zoneID string) []*v1.Endpoint {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	endpoints := make([]*v1.Endpoint, 0, len(p.zones[normalizeName(zoneID)]))
+	for _, endpoint := range p.zones[normalizeName(zoneID)] {
+		endpoints = append(endpoints, endpoint.DeepCopy())
+	}
+	return endpoints
+}
+
+// zoneEndpoints returns the endpoints of the zone, creating it if needed.
+// Callers must hold the write lock.
+func (p *Provider) zoneEndpoints(zoneID string) map[endpointKey]*v1.Endpoint {
+	name := normalizeName(zoneID)
+	endpoints, ok := p.zones[name]
+	if !ok {
+		endpoints = map[endpointKey]*v1.Endpoint{}
+		p.zones[name] = endpoints
+	}
+	return endpoints
+}
+
+// lookup returns the zone the name belongs to, and the endpoints matching the
+// name, regardless of their type.
+func (p *Provider) lookup(name string) (string, []*v1.Endpoint, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	name = normalizeName(name)
+
+	// Pick the most specific zone the name belongs to
+	zoneName := ""
+	for zone := range p.zones {
+		if (name == zone || strings.HasSuffix(name, "."+zone)) && len(zone) > len(zoneName) {
+			zoneName = zone
+		}
+	}
+	if zoneName == "" {
+		return "", nil, false
+	}
+
+	var endpoints []*v1.Endpoint
+	for key, endpoint := range p.zones[zoneName] {
+		if key.dnsName == name {
+			endpoints = append(endpoints, endpoint.DeepCopy())
+		}
+	}
+	return zoneName, endpoints, true
+}
+
+func validateEndpoint(endpoint *v1.Endpoint) error {
+	switch endpoint.RecordType {
+	case string(v1.ARecordType), string(v1.AAAARecordType), string(v1.CNAMERecordType), string(v1.TXTRecordType), string(v1.CAARecordType):
+	default:
+		return dnserrors.Errorf(dnserrors.ReasonInvalidRecord, "unsupported record type %s", endpoint.RecordType)
+	}
+	if len(endpoint.DNSName) == 0 {
+		return dnserrors.Errorf(dnserrors.ReasonInvalidRecord, "domain is required")
+	}
+	if len(endpoint.Targets) == 0 {
+		return dnserrors.Errorf(dnserrors.ReasonInvalidRecord, "targets is required")
+	}
+	if endpoint.RecordType == string(v1.CAARecordType) {
+		for _, target := range endpoint.Targets {
+			if _, _, _, err := v1.ParseCAATarget(target); err != nil {
+				return dnserrors.Errorf(dnserrors.ReasonInvalidRecord, "%v", err)
+			}
+		}
+	}
+	if endpoint.RecordType == string(v1.ARecordType) {
+		for _, target := range endpoint.Targets {
+			if net.ParseIP(target).To4() == nil {
+				return dnserrors.Errorf(dnserrors.ReasonInvalidRecord, "invalid A record target %s", target)
+			}
+		}
+	}
+	if endpoint.RecordType == string(v1.AAAARecordType) {
+		for _, target := range endpoint.Targets {
+			if ip := net.ParseIP(target); ip == nil || ip.To4() != nil {
+				return dnserrors.Errorf(dnserrors.ReasonInvalidRecord, "invalid AAAA record target %s", target)
+			}
+		}
+	}
+	return nil
+}
+
+// normalizeName returns the lower case name, without the trailing dot.
+func normalizeName(name string) string {
+	return strings.ToLower(strings.TrimSuffix(name, "."))
+}
+
+func endpointsFromZoneStatus(record *v1.DNSRecord, zoneID string) []*v1.Endpoint {
+	for _, zoneStatus := range record.Status.Zones {
+		if zoneStatus.DNSZone.ID == zoneID {
+			return zoneStatus.Endpoints
+		}
+	}
+	return []*v1.Endpoint{}
+}
@@ -0,0 +1,212 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/onsi/gomega"
+
+	v1 "github.com/kuadrant/kcp-glbc/pkg/apis/kuadrant/v1"
+)
+
+func newTestProvider(t *testing.T) *Provider {
+	provider, err := NewProvider(Config{Address: "127.0.0.1", Port: -1})
+	if err != nil {
+		t.Fatalf("unexpected error creating provider: %v", err)
+	}
+	t.Cleanup(func() { _ = provider.Shutdown() })
+	return provider
+}
+
+func query(t *testing.T, provider *Provider, name string, qtype uint16) *dns.Msg {
+	m := new(dns.Msg)
+	m.SetQuestion(dns.Fqdn(name), qtype)
+	r, _, err := (&dns.Client{Net: "tcp"}).Exchange(m, provider.Addr())
+	if err != nil {
+		t.Fatalf("unexpected error querying %s: %v", name, err)
+	}
+	return r
+}
+
+func answerData(r *dns.Msg) []string {
+	data := make([]string, 0, len(r.Answer))
+	for _, rr := range r.Answer {
+		data = append(data, rr.String())
+	}
+	sort.Strings(data)
+	return data
+}
+
+func weightedEndpoint(ip string, weight int64) *v1.Endpoint {
+	return &v1.Endpoint{
+		DNSName:       "app.example.com",
+		RecordType:    string(v1.ARecordType),
+		RecordTTL:     60,
+		SetIdentifier: ip,
+		Targets:       v1.Targets{ip},
+		RoutingPolicy: &v1.RoutingPolicy{Weighted: &v1.WeightedRoutingPolicy{Weight: weight}},
+	}
+}
+
+func TestServeSimpleRecords(t *testing.T) {
+	g := gomega.NewWithT(t)
+	provider := newTestProvider(t)
+	zone := v1.DNSZone{ID: "example.com"}
+
+	record := &v1.DNSRecord{
+		Spec: v1.DNSRecordSpec{
+			Endpoints: []*v1.Endpoint{
+				{
+					DNSName:    "app.example.com",
+					RecordType: string(v1.ARecordType),
+					RecordTTL:  60,
+					Targets:    v1.Targets{"10.0.0.1", "10.0.0.2"},
+				},
+				{
+					DNSName:    "app.example.com",
+					RecordType: string(v1.AAAARecordType),
+					RecordTTL:  60,
+					Targets:    v1.Targets{"2001:db8::1"},
+				},
+				{
+					DNSName:    "www.example.com",
+					RecordType: string(v1.CNAMERecordType),
+					RecordTTL:  300,
+					Targets:    v1.Targets{"app.example.com"},
+				},
+				{
+					DNSName:    "app.example.com",
+					RecordType: string(v1.CAARecordType),
+					RecordTTL:  300,
+					Targets:    v1.Targets{`0 issue "letsencrypt.org"`},
+				},
+				{
+					DNSName:    "_glbc-owner.app.example.com",
+					RecordType: string(v1.TXTRecordType),
+					RecordTTL:  300,
+					Targets:    v1.Targets{`"heritage=kcp-glbc,kcp-glbc/owner=glbc-1"`},
+				},
+			},
+		},
+	}
+	g.Expect(provider.Ensure(context.TODO(), record, zone)).To(gomega.Succeed())
+
+	r := query(t, provider, "app.example.com", dns.TypeA)
+	g.Expect(r.Authoritative).To(gomega.BeTrue())
+	g.Expect(answerData(r)).To(gomega.Equal([]string{
+		"app.example.com.\t60\tIN\tA\t10.0.0.1",
+		"app.example.com.\t60\tIN\tA\t10.0.0.2",
+	}))
+
+	r = query(t, provider, "app.example.com", dns.TypeAAAA)
+	g.Expect(answerData(r)).To(gomega.Equal([]string{
+		"app.example.com.\t60\tIN\tAAAA\t2001:db8::1",
+	}))
+
+	// CNAME records are followed within the zone
+	r = query(t, provider, "www.example.com", dns.TypeA)
+	g.Expect(answerData(r)).To(gomega.Equal([]string{
+		"app.example.com.\t60\tIN\tA\t10.0.0.1",
+		"app.example.com.\t60\tIN\tA\t10.0.0.2",
+		"www.example.com.\t300\tIN\tCNAME\tapp.example.com.",
+	}))
+
+	// TXT records are served unquoted
+	r = query(t, provider, "_glbc-owner.app.example.com", dns.TypeTXT)
+	g.Expect(r.Answer).To(gomega.HaveLen(1))
+	g.Expect(r.Answer[0].(*dns.TXT).Txt).To(gomega.Equal([]string{"heritage=kcp-glbc,kcp-glbc/owner=glbc-1"}))
+
+	r = query(t, provider, "app.example.com", dns.TypeCAA)
+	g.Expect(answerData(r)).To(gomega.Equal([]string{
+		"app.example.com.\t300\tIN\tCAA\t0 issue \"letsencrypt.org\"",
+	}))
+
+	// Names without records
+	r = query(t, provider, "missing.example.com", dns.TypeA)
+	g.Expect(r.Rcode).To(gomega.Equal(dns.RcodeNameError))
+	g.Expect(r.Ns).To(gomega.HaveLen(1))
+
+	r = query(t, provider, "app.example.com", dns.TypeMX)
+	g.Expect(r.Rcode).To(gomega.Equal(dns.RcodeSuccess))
+	g.Expect(r.Answer).To(gomega.BeEmpty())
+
+	// Names outside of the zones
+	r = query(t, provider, "app.example.org", dns.TypeA)
+	g.Expect(r.Rcode).To(gomega.Equal(dns.RcodeRefused))
+}
+
+func TestServeWeightedRecords(t *testing.T) {
+	g := gomega.NewWithT(t)
+	provider := newTestProvider(t)
+	zone := v1.DNSZone{ID: "example.com"}
+
+	record := &v1.DNSRecord{
+		Spec: v1.DNSRecordSpec{
+			Endpoints: []*v1.Endpoint{
+				weightedEndpoint("10.0.0.1", 120),
+				weightedEndpoint("10.0.0.2", 120),
+				weightedEndpoint("10.0.0.3", 0),
+			},
+		},
+	}
+	g.Expect(provider.Ensure(context.TODO(), record, zone)).To(gomega.Succeed())
+
+	seen := map[string]int{}
+	for i := 0; i < 50; i++ {
+		r := query(t, provider, "app.example.com", dns.TypeA)
+		g.Expect(r.Answer).To(gomega.HaveLen(1))
+		seen[r.Answer[0].(*dns.A).A.String()]++
+	}
+	g.Expect(seen).To(gomega.HaveKey("10.0.0.1"))
+	g.Expect(seen).To(gomega.HaveKey("10.0.0.2"))
+	g.Expect(seen).NotTo(gomega.HaveKey("10.0.0.3"))
+}
+
+func TestEnsureAndDelete(t *testing.T) {
+	g := gomega.NewWithT(t)
+	provider := newTestProvider(t)
+	zone := v1.DNSZone{ID: "example.com"}
+
+	record := &v1.DNSRecord{
+		Spec: v1.DNSRecordSpec{
+			Endpoints: []*v1.Endpoint{
+				weightedEndpoint("10.0.0.1", 120),
+				weightedEndpoint("10.0.0.2", 120),
+			},
+		},
+	}
+	g.Expect(provider.Ensure(context.TODO(), record, zone)).To(gomega.Succeed())
+	g.Expect(provider.Endpoints("example.com")).To(gomega.HaveLen(2))
+
+	// Endpoints no longer present in the spec are removed based on the zone status
+	record.Status.Zones = []v1.DNSZoneStatus{{DNSZone: zone, Endpoints: record.Spec.Endpoints}}
+	record.Spec.Endpoints = []*v1.Endpoint{weightedEndpoint("10.0.0.2", 120)}
+	g.Expect(provider.Ensure(context.TODO(), record, zone)).To(gomega.Succeed())
+
+	endpoints := provider.Endpoints("example.com")
+	g.Expect(endpoints).To(gomega.HaveLen(1))
+	g.Expect(endpoints[0].SetIdentifier).To(gomega.Equal("10.0.0.2"))
+
+	g.Expect(provider.Delete(context.TODO(), record, zone)).To(gomega.Succeed())
+	g.Expect(provider.Endpoints("example.com")).To(gomega.BeEmpty())
+
+	r := query(t, provider, "app.example.com", dns.TypeA)
+	g.Expect(r.Rcode).To(gomega.Equal(dns.RcodeNameError))
+}
+
+func TestEnsureInvalidEndpoint(t *testing.T) {
+	g := gomega.NewWithT(t)
+	provider := newTestProvider(t)
+
+	record := &v1.DNSRecord{
+		Spec: v1.DNSRecordSpec{
+			Endpoints: []*v1.Endpoint{{DNSName: "app.example.com", RecordType: string(v1.ARecordType), Targets: v1.Targets{"lb.example.com"}}},
+		},
+	}
+	g.Expect(provider.Ensure(context.TODO(), record, v1.DNSZone{ID: "example.com"})).To(gomega.MatchError("invalid A record target lb.example.com"))
+
+	record.Spec.Endpoints = []*v1.Endpoint{{DNSName: "app.example.com", RecordType: string(v1.AAAARecordType), Targets: v1.Targets{"10.0.0.1"}}}
+	g.Expect(provider.Ensure(context.TODO(), record, v1.DNSZone{ID: "example.com"})).To(gomega.MatchError("invalid AAAA record target 10.0.0.1"))
+}
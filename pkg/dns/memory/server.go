@@ -0,0 +1,290 @@
+package memory
+
+import (
+	"math/rand"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	v1 "github.com/kuadrant/kcp-glbc/pkg/apis/kuadrant/v1"
+)
+
+const (
+	// maxCNAMEChain is the maximum number of CNAME records followed when answering a query.
+	maxCNAMEChain = 8
+	// negativeTTL is the TTL of the SOA record returned with negative answers.
+	negativeTTL = 30
+	// maxRandomPortAttempts is the number of random ports tried before giving up.
+	maxRandomPortAttempts = 10
+)
+
+// server is an authoritative DNS server answering queries from the endpoints
+// stored by the provider, over UDP and TCP.
+//
+// Endpoints sharing the same name and type but with distinct set identifiers
+// are answered as a weighted routing policy: a single endpoint is picked for
+// each query, with a probability proportional to its weight.
+type server struct {
+	provider *Provider
+	udp      *dns.Server
+	tcp      *dns.Server
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+func newServer(provider *Provider, addr string) (*server, error) {
+	packetConn, listener, err := listen(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &server{
+		provider: provider,
+		rand:     rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	s.udp = &dns.Server{PacketConn: packetConn, Handler: s}
+	s.tcp = &dns.Server{Listener: listener, Handler: s}
+
+	for _, srv := range []*dns.Server{s.udp, s.tcp} {
+		started := make(chan struct{})
+		srv.NotifyStartedFunc = func() { close(started) }
+		go func(srv *dns.Server) {
+			if err := srv.ActivateAndServe(); err != nil {
+				provider.logger.Error(err, "Embedded DNS server stopped")
+			}
+		}(srv)
+		<-started
+	}
+
+	return s, nil
+}
+
+// listen listens on the address over UDP and TCP. When a random port is
+// picked, the UDP port may already be taken over TCP, in which case another
+// random port is picked.
+func listen(addr string) (net.PacketConn, net.Listener, error) {
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, nil, err
+	}
+	attempts := 1
+	if port == "0" {
+		attempts = maxRandomPortAttempts
+	}
+	for i := 1; ; i++ {
+		packetConn, err := net.ListenPacket("udp", addr)
+		if err != nil {
+			return nil, nil, err
+		}
+		// Listen on the same port for TCP, which matters when a random port was picked
+		listener, err := net.Listen("tcp", packetConn.LocalAddr().String())
+		if err == nil {
+			return packetConn, listener, nil
+		}
+		_ = packetConn.Close()
+		if i == attempts {
+			return nil, nil, err
+		}
+	}
+}
+
+func (s *server) Addr() string {
+	return s.udp.PacketConn.LocalAddr().String()
+}
+
+func (s *server) Shutdown() error {
+	var errs []error
+	for _, srv := range []*dns.Server{s.udp, s.tcp} {
+		if err := srv.Shutdown(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return kerrors.NewAggregate(errs)
+}
+
+func (s *server) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
+	m := new(dns.Msg)
+	m.SetReply(r)
+	m.Authoritative = true
+
+	if r.Opcode != dns.OpcodeQuery || len(r.Question) != 1 {
+		m.SetRcode(r, dns.RcodeNotImplemented)
+		_ = w.WriteMsg(m)
+		return
+	}
+
+	s.answer(m, r.Question[0])
+	_ = w.WriteMsg(m)
+}
+
+// answer fills the message with the answer to the question, following CNAME
+// records within the zones served by the provider.
+func (s *server) answer(m *dns.Msg, question dns.Question) {
+	name := question.Name
+	for i := 0; i < maxCNAMEChain; i++ {
+		zone, endpoints, ok := s.provider.lookup(name)
+		if !ok {
+			if i == 0 {
+				m.Rcode = dns.RcodeRefused
+				m.Authoritative = false
+			}
+			return
+		}
+		if len(endpoints) == 0 {
+			if i == 0 {
+				m.Rcode = dns.RcodeNameError
+			}
+			m.Ns = append(m.Ns, soa(zone))
+			return
+		}
+
+		if answer := s.records(name, dns.TypeToString[question.Qtype], endpoints); len(answer) > 0 {
+			m.Answer = append(m.Answer, answer...)
+			return
+		}
+
+		if question.Qtype == dns.TypeCNAME {
+			break
+		}
+		cname := s.records(name, string(v1.CNAMERecordType), endpoints)
+		if len(cname) == 0 {
+			break
+		}
+		m.Answer = append(m.Answer, cname...)
+		name = cname[0].(*dns.CNAME).Target
+	}
+
+	if len(m.Answer) == 0 {
+		zone, _, _ := s.provider.lookup(question.Name)
+		m.Ns = append(m.Ns, soa(zone))
+	}
+}
+
+// records returns the resource records of the endpoints of the given type.
+func (s *server) records(name, recordType string, endpoints []*v1.Endpoint) []dns.RR {
+	var candidates []*v1.Endpoint
+	for _, endpoint := range endpoints {
+		if endpoint.RecordType == recordType {
+			candidates = append(candidates, endpoint)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	var rrs []dns.RR
+	for _, endpoint := range s.selectEndpoints(candidates) {
+		header := dns.RR_Header{
+			Name:  dns.Fqdn(name),
+			Class: dns.ClassINET,
+			Ttl:   uint32(endpoint.RecordTTL),
+		}
+		for _, target := range endpoint.Targets {
+			switch recordType {
+			case string(v1.ARecordType):
+				header.Rrtype = dns.TypeA
+				rrs = append(rrs, &dns.A{Hdr: header, A: net.ParseIP(target).To4()})
+			case string(v1.AAAARecordType):
+				header.Rrtype = dns.TypeAAAA
+				rrs = append(rrs, &dns.AAAA{Hdr: header, AAAA: net.ParseIP(target)})
+			case string(v1.CNAMERecordType):
+				header.Rrtype = dns.TypeCNAME
+				rrs = append(rrs, &dns.CNAME{Hdr: header, Target: dns.Fqdn(target)})
+			case string(v1.TXTRecordType):
+				header.Rrtype = dns.TypeTXT
+				if unquoted, err := strconv.Unquote(target); err == nil {
+					target = unquoted
+				}
+				rrs = append(rrs, &dns.TXT{Hdr: header, Txt: []string{target}})
+			case string(v1.CAARecordType):
+				header.Rrtype = dns.TypeCAA
+				if flags, tag, value, err := v1.ParseCAATarget(target); err == nil {
+					rrs = append(rrs, &dns.CAA{Hdr: header, Flag: flags, Tag: tag, Value: value})
+				}
+			}
+		}
+	}
+	return rrs
+}
+
+// selectEndpoints returns all the endpoints if none of them has a set
+// identifier. Otherwise a single endpoint is picked, with a probability
+// proportional to its weight. If all the weights are 0, the endpoints are
+// picked with an equal probability.
+func (s *server) selectEndpoints(endpoints []*v1.Endpoint) []*v1.Endpoint {
+	weighted := false
+	for _, endpoint := range endpoints {
+		if endpoint.SetIdentifier != "" {
+			weighted = true
+			break
+		}
+	}
+	if !weighted {
+		return endpoints
+	}
+
+	var total int64
+	weights := make([]int64, len(endpoints))
+	for i, endpoint := range endpoints {
+		weights[i] = s.weight(endpoint)
+		total += weights[i]
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if total == 0 {
+		return []*v1.Endpoint{endpoints[s.rand.Intn(len(endpoints))]}
+	}
+	n := s.rand.Int63n(total)
+	for i, endpoint := range endpoints {
+		if n < weights[i] {
+			return []*v1.Endpoint{endpoint}
+		}
+		n -= weights[i]
+	}
+	return nil
+}
+
+func (s *server) weight(endpoint *v1.Endpoint) int64 {
+	if value, ok := endpoint.GetProviderSpecific(ProviderSpecificWeight); ok {
+		weight, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || weight < 0 {
+			s.provider.logger.Error(err, "Failed parsing value, using weight of 0", "weight", ProviderSpecificWeight, "value", value)
+			return 0
+		}
+		return weight
+	}
+	if policy := endpoint.GetRoutingPolicy(); policy != nil && policy.Weighted != nil {
+		if policy.Weighted.Weight < 0 {
+			return 0
+		}
+		return policy.Weighted.Weight
+	}
+	return 1
+}
+
+// soa returns a synthetic SOA record for the zone, returned in the authority
+// section of negative answers.
+func soa(zone string) dns.RR {
+	return &dns.SOA{
+		Hdr: dns.RR_Header{
+			Name:   dns.Fqdn(zone),
+			Rrtype: dns.TypeSOA,
+			Class:  dns.ClassINET,
+			Ttl:    negativeTTL,
+		},
+		Ns:      dns.Fqdn("ns." + zone),
+		Mbox:    dns.Fqdn("hostmaster." + zone),
+		Serial:  1,
+		Refresh: 3600,
+		Retry:   600,
+		Expire:  86400,
+		Minttl:  negativeTTL,
+	}
+}
@@ -0,0 +1,223 @@
+package dns
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"k8s.io/client-go/tools/cache"
+
+	v1 "github.com/kuadrant/kcp-glbc/pkg/apis/kuadrant/v1"
+	"github.com/kuadrant/kcp-glbc/pkg/metrics"
+)
+
+const (
+	zoneLabel   = "zone"
+	resultLabel = "result"
+	healthLabel = "health"
+
+	resultSuccess = "success"
+	resultError   = "error"
+)
+
+var (
+	// zoneAuditTotal is a prometheus counter metric which holds the number of
+	// audits of the DNS zones, by result.
+	zoneAuditTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "glbc_dns_zone_audit_total",
+			Help: "Total number of audits of the DNS zones",
+		},
+		[]string{
+			zoneLabel,
+			resultLabel,
+		},
+	)
+	// zoneDriftedRecords is a prometheus gauge metric which holds the number
+	// of DNSRecords whose records have drifted in the zone, as of the last audit.
+	zoneDriftedRecords = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "glbc_dns_zone_drifted_records",
+			Help: "Number of DNSRecords whose records have drifted in the DNS zone, as of the last audit",
+		},
+		[]string{
+			zoneLabel,
+		},
+	)
+	// zoneOrphanedNames is a prometheus gauge metric which holds the number of
+	// names owned by the GLBC that no DNSRecord owns, as of the last audit.
+	zoneOrphanedNames = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "glbc_dns_zone_orphaned_names",
+			Help: "Number of names of the DNS zone owned by the GLBC that no DNSRecord owns, as of the last audit",
+		},
+		[]string{
+			zoneLabel,
+		},
+	)
+	// zoneOrphanedNamesDeleted is a prometheus counter metric which holds the
+	// number of orphaned names whose records have been deleted.
+	zoneOrphanedNamesDeleted = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "glbc_dns_zone_orphaned_names_deleted_total",
+			Help: "Total number of orphaned names whose records have been deleted from the DNS zone",
+		},
+		[]string{
+			zoneLabel,
+		},
+	)
+	// endpointHealth is a prometheus gauge metric which holds the number of
+	// endpoints checked by the HealthChecks, by health.
+	endpointHealth = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "glbc_dns_endpoint_health",
+			Help: "Number of endpoints checked by the HealthChecks, by health",
+		},
+		[]string{
+			healthLabel,
+		},
+	)
+	// endpointHealthTransitions is a prometheus counter metric which holds the
+	// number of changes of the health of the endpoints, by new health.
+	endpointHealthTransitions = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "glbc_dns_endpoint_health_transitions_total",
+			Help: "Total number of changes of the health of the endpoints checked by the HealthChecks, by new health",
+		},
+		[]string{
+			healthLabel,
+		},
+	)
+	// healthCheckCollectionTotal is a prometheus counter metric which holds the
+	// number of garbage collections of the orphaned health checks, by result.
+	healthCheckCollectionTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "glbc_dns_health_check_collection_total",
+			Help: "Total number of garbage collections of the orphaned health checks of the DNS provider",
+		},
+		[]string{
+			resultLabel,
+		},
+	)
+	// orphanedHealthChecks is a prometheus gauge metric which holds the number
+	// of health checks owned by the GLBC that no DNSRecord endpoint matches,
+	// as of the last collection.
+	orphanedHealthChecks = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "glbc_dns_orphaned_health_checks",
+			Help: "Number of health checks of the DNS provider owned by the GLBC that no DNSRecord endpoint matches, as of the last collection",
+		},
+	)
+	// orphanedHealthChecksDeleted is a prometheus counter metric which holds
+	// the number of orphaned health checks that have been deleted.
+	orphanedHealthChecksDeleted = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "glbc_dns_orphaned_health_checks_deleted_total",
+			Help: "Total number of orphaned health checks deleted from the DNS provider",
+		},
+	)
+)
+
+// endpointHealthMetrics maintains the endpoint health metrics across the
+// HealthCheck controllers.
+var endpointHealthMetrics = &healthMetrics{counts: map[string]map[v1.Health]int{}}
+
+func init() {
+	// Register metrics with the global prometheus registry
+	metrics.Registry.MustRegister(
+		zoneAuditTotal,
+		zoneDriftedRecords,
+		zoneOrphanedNames,
+		zoneOrphanedNamesDeleted,
+		endpointHealth,
+		endpointHealthTransitions,
+		healthCheckCollectionTotal,
+		orphanedHealthChecks,
+		orphanedHealthChecksDeleted,
+	)
+	for _, health := range []v1.Health{v1.Healthy, v1.Unhealthy, v1.HealthUnknown} {
+		endpointHealth.WithLabelValues(string(health)).Set(0)
+	}
+}
+
+func observeAudit(zoneID string, err error) {
+	result := resultSuccess
+	if err != nil {
+		result = resultError
+	}
+	zoneAuditTotal.WithLabelValues(zoneID, result).Inc()
+}
+
+func observeHealthCheckCollection(err error) {
+	result := resultSuccess
+	if err != nil {
+		result = resultError
+	}
+	healthCheckCollectionTotal.WithLabelValues(result).Inc()
+}
+
+// healthMetrics holds the number of endpoints checked by each HealthCheck, by
+// health, the endpointHealth metric being their sum.
+type healthMetrics struct {
+	mu     sync.Mutex
+	counts map[string]map[v1.Health]int
+}
+
+// observe records the health of the endpoints checked by the HealthCheck, and
+// returns the endpoints whose health changed since the previous observation.
+func (m *healthMetrics) observe(healthCheck *v1.HealthCheck, previous, current []v1.HealthCheckEndpointStatus) []v1.HealthCheckEndpointStatus {
+	previousHealth := map[string]v1.Health{}
+	for _, endpoint := range previous {
+		previousHealth[endpointHealthKey(endpoint)] = endpoint.Health
+	}
+	var changed []v1.HealthCheckEndpointStatus
+	counts := map[v1.Health]int{}
+	for _, endpoint := range current {
+		counts[endpoint.Health]++
+		if health, ok := previousHealth[endpointHealthKey(endpoint)]; ok && health != endpoint.Health {
+			endpointHealthTransitions.WithLabelValues(string(endpoint.Health)).Inc()
+			changed = append(changed, endpoint)
+		}
+	}
+
+	key, err := cache.MetaNamespaceKeyFunc(healthCheck)
+	if err != nil {
+		return changed
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.counts[key] = counts
+	m.update()
+	return changed
+}
+
+// delete removes the endpoints checked by the HealthCheck from the metrics.
+func (m *healthMetrics) delete(healthCheck *v1.HealthCheck) {
+	key, err := cache.MetaNamespaceKeyFunc(healthCheck)
+	if err != nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.counts[key]; !ok {
+		return
+	}
+	delete(m.counts, key)
+	m.update()
+}
+
+func endpointHealthKey(endpoint v1.HealthCheckEndpointStatus) string {
+	return endpoint.DNSName + "/" + endpoint.SetIdentifier
+}
+
+func (m *healthMetrics) update() {
+	totals := map[v1.Health]int{}
+	for _, counts := range m.counts {
+		for health, count := range counts {
+			totals[health] += count
+		}
+	}
+	for _, health := range []v1.Health{v1.Healthy, v1.Unhealthy, v1.HealthUnknown} {
+		endpointHealth.WithLabelValues(string(health)).Set(float64(totals[health]))
+	}
+}
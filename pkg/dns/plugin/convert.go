@@ -0,0 +1,224 @@
+package plugin
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/kuadrant/kcp-glbc/pkg/apis/kuadrant/v1"
+)
+
+func zoneToMessage(zone v1.DNSZone) *Zone {
+	return &Zone{Id: zone.ID, Tags: zone.Tags}
+}
+
+func zoneFromMessage(zone *Zone) v1.DNSZone {
+	if zone == nil {
+		return v1.DNSZone{}
+	}
+	return v1.DNSZone{ID: zone.Id, Tags: zone.Tags}
+}
+
+// recordToMessage converts the DNSRecord, along with the endpoints last
+// published to each of its zones, that the plugins rely on to delete the
+// endpoints the record no longer has.
+func recordToMessage(record *v1.DNSRecord) *Record {
+	message := &Record{
+		Name:      record.Name,
+		Namespace: record.Namespace,
+		Endpoints: endpointsToMessages(record.Spec.Endpoints),
+	}
+	for _, zone := range record.Status.Zones {
+		message.Zones = append(message.Zones, &ZoneStatus{
+			Zone:      zoneToMessage(zone.DNSZone),
+			Endpoints: endpointsToMessages(zone.Endpoints),
+		})
+	}
+	return message
+}
+
+func recordFromMessage(record *Record) *v1.DNSRecord {
+	if record == nil {
+		return &v1.DNSRecord{}
+	}
+	out := &v1.DNSRecord{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      record.Name,
+			Namespace: record.Namespace,
+		},
+		Spec: v1.DNSRecordSpec{
+			Endpoints: endpointsFromMessages(record.Endpoints),
+		},
+	}
+	for _, zone := range record.Zones {
+		out.Status.Zones = append(out.Status.Zones, v1.DNSZoneStatus{
+			DNSZone:   zoneFromMessage(zone.Zone),
+			Endpoints: endpointsFromMessages(zone.Endpoints),
+		})
+	}
+	return out
+}
+
+// endpointToMessage converts the endpoint, with its routing policy resolved
+// from its legacy provider specific properties if it has none.
+func endpointToMessage(endpoint *v1.Endpoint) *Endpoint {
+	if endpoint == nil {
+		return nil
+	}
+	message := &Endpoint{
+		DnsName:       endpoint.DNSName,
+		Targets:       endpoint.Targets,
+		RecordType:    endpoint.RecordType,
+		SetIdentifier: endpoint.SetIdentifier,
+		RecordTtl:     int64(endpoint.RecordTTL),
+		Labels:        endpoint.Labels,
+		RoutingPolicy: routingPolicyToMessage(endpoint.GetRoutingPolicy()),
+	}
+	for _, property := range endpoint.ProviderSpecific {
+		if v1.IsLegacyRoutingProperty(property.Name) {
+			continue
+		}
+		message.ProviderSpecific = append(message.ProviderSpecific, &ProviderSpecificProperty{Name: property.Name, Value: property.Value})
+	}
+	return message
+}
+
+func endpointFromMessage(endpoint *Endpoint) *v1.Endpoint {
+	if endpoint == nil {
+		return nil
+	}
+	out := &v1.Endpoint{
+		DNSName:       endpoint.DnsName,
+		Targets:       endpoint.Targets,
+		RecordType:    endpoint.RecordType,
+		SetIdentifier: endpoint.SetIdentifier,
+		RecordTTL:     v1.TTL(endpoint.RecordTtl),
+		Labels:        endpoint.Labels,
+		RoutingPolicy: routingPolicyFromMessage(endpoint.RoutingPolicy),
+	}
+	for _, property := range endpoint.ProviderSpecific {
+		out.ProviderSpecific = append(out.ProviderSpecific, v1.ProviderSpecificProperty{Name: property.Name, Value: property.Value})
+	}
+	return out
+}
+
+func endpointsToMessages(endpoints []*v1.Endpoint) []*Endpoint {
+	var messages []*Endpoint
+	for _, endpoint := range endpoints {
+		messages = append(messages, endpointToMessage(endpoint))
+	}
+	return messages
+}
+
+func endpointsFromMessages(messages []*Endpoint) []*v1.Endpoint {
+	var endpoints []*v1.Endpoint
+	for _, message := range messages {
+		endpoints = append(endpoints, endpointFromMessage(message))
+	}
+	return endpoints
+}
+
+func routingPolicyToMessage(policy *v1.RoutingPolicy) *RoutingPolicy {
+	if policy == nil {
+		return nil
+	}
+	message := &RoutingPolicy{}
+	if policy.Weighted != nil {
+		message.Weighted = &WeightedRoutingPolicy{Weight: policy.Weighted.Weight}
+	}
+	if policy.Failover != nil {
+		message.Failover = &FailoverRoutingPolicy{Role: string(policy.Failover.Role)}
+	}
+	if policy.Geo != nil {
+		message.Geo = &GeoRoutingPolicy{Continent: policy.Geo.Continent, Country: policy.Geo.Country, IsDefault: policy.Geo.Default}
+	}
+	if policy.Latency != nil {
+		message.Latency = &LatencyRoutingPolicy{Region: policy.Latency.Region}
+	}
+	return message
+}
+
+func routingPolicyFromMessage(message *RoutingPolicy) *v1.RoutingPolicy {
+	if message == nil {
+		return nil
+	}
+	policy := &v1.RoutingPolicy{}
+	if message.Weighted != nil {
+		policy.Weighted = &v1.WeightedRoutingPolicy{Weight: message.Weighted.Weight}
+	}
+	if message.Failover != nil {
+		policy.Failover = &v1.FailoverRoutingPolicy{Role: v1.FailoverRole(message.Failover.Role)}
+	}
+	if message.Geo != nil {
+		policy.Geo = &v1.GeoRoutingPolicy{Continent: message.Geo.Continent, Country: message.Geo.Country, Default: message.Geo.IsDefault}
+	}
+	if message.Latency != nil {
+		policy.Latency = &v1.LatencyRoutingPolicy{Region: message.Latency.Region}
+	}
+	return policy
+}
+
+func healthCheckToMessage(hc v1.EndpointHealthCheck) *HealthCheck {
+	message := &HealthCheck{
+		Id:              hc.Id,
+		Name:            hc.Name,
+		Path:            hc.Path,
+		IntervalSeconds: int64(hc.Interval / time.Second),
+		HostHeader:      hc.HostHeader,
+		SearchString:    hc.SearchString,
+		Regions:         hc.Regions,
+		Inverted:        hc.Inverted,
+	}
+	for _, code := range hc.ExpectedStatusCodes {
+		message.ExpectedStatusCodes = append(message.ExpectedStatusCodes, int64(code))
+	}
+	if hc.Port != nil {
+		message.Port = *hc.Port
+	}
+	if hc.FailureThreshold != nil {
+		message.FailureThreshold = *hc.FailureThreshold
+	}
+	if hc.Protocol != nil {
+		message.Protocol = string(*hc.Protocol)
+	}
+	if hc.ClusterHealthThreshold != nil {
+		message.ClusterHealthThreshold = *hc.ClusterHealthThreshold
+	}
+	return message
+}
+
+func healthCheckFromMessage(message *HealthCheck) v1.EndpointHealthCheck {
+	if message == nil {
+		return v1.EndpointHealthCheck{}
+	}
+	hc := v1.EndpointHealthCheck{
+		Id:           message.Id,
+		Name:         message.Name,
+		Path:         message.Path,
+		Interval:     time.Duration(message.IntervalSeconds) * time.Second,
+		HostHeader:   message.HostHeader,
+		SearchString: message.SearchString,
+		Regions:      message.Regions,
+		Inverted:     message.Inverted,
+	}
+	for _, code := range message.ExpectedStatusCodes {
+		hc.ExpectedStatusCodes = append(hc.ExpectedStatusCodes, v1.HTTPStatusCode(code))
+	}
+	if message.Port != 0 {
+		port := message.Port
+		hc.Port = &port
+	}
+	if message.FailureThreshold != 0 {
+		failureThreshold := message.FailureThreshold
+		hc.FailureThreshold = &failureThreshold
+	}
+	if message.Protocol != "" {
+		protocol := v1.HealthCheckProtocol(message.Protocol)
+		hc.Protocol = &protocol
+	}
+	if message.ClusterHealthThreshold != 0 {
+		clusterHealthThreshold := message.ClusterHealthThreshold
+		hc.ClusterHealthThreshold = &clusterHealthThreshold
+	}
+	return hc
+}
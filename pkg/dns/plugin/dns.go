@@ -0,0 +1,142 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/kuadrant/kcp-glbc/pkg/_internal/log"
+	v1 "github.com/kuadrant/kcp-glbc/pkg/apis/kuadrant/v1"
+)
+
+const (
+	AddressEnvVar = "GRPC_DNS_PLUGIN_ADDRESS"
+	TimeoutEnvVar = "GRPC_DNS_PLUGIN_TIMEOUT"
+	ZoneIDEnvVar  = "GRPC_DNS_ZONE_ID"
+
+	DefaultTimeout = 30 * time.Second
+)
+
+// Provider forwards the records to an out-of-process DNS provider plugin,
+// serving the DNSProvider service of dnsprovider.proto.
+type Provider struct {
+	conn    *grpc.ClientConn
+	client  DNSProviderClient
+	timeout time.Duration
+	logger  logr.Logger
+}
+
+// Config is the necessary input to configure the provider.
+type Config struct {
+	// Address is the address of the plugin, either a Unix socket, e.g.
+	// unix:///run/glbc/dns.sock, or a TCP address, e.g. localhost:50051.
+	Address string
+	// Timeout is the timeout of the calls to the plugin. Defaults to DefaultTimeout.
+	Timeout time.Duration
+}
+
+// NewProvider returns a provider connected to the plugin. The connection is
+// established in the background, so that the plugin can be started after the
+// GLBC, e.g. as a sidecar.
+func NewProvider(config Config) (*Provider, error) {
+	if config.Address == "" {
+		return nil, fmt.Errorf("the address of the DNS provider plugin is required, set %s", AddressEnvVar)
+	}
+	if config.Timeout == 0 {
+		config.Timeout = DefaultTimeout
+	}
+
+	// The plugins are expected to be local, e.g. reached over a Unix socket
+	// shared with a sidecar, hence the insecure credentials.
+	conn, err := grpc.Dial(config.Address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to DNS provider plugin %s: %v", config.Address, err)
+	}
+
+	p := &Provider{
+		conn:    conn,
+		client:  NewDNSProviderClient(conn),
+		timeout: config.Timeout,
+		logger:  log.Logger.WithName("grpc-dns").WithValues("address", config.Address),
+	}
+	p.logger.Info("Using DNS provider plugin")
+
+	return p, nil
+}
+
+// Close closes the connection to the plugin.
+func (p *Provider) Close() error {
+	return p.conn.Close()
+}
+
+func (p *Provider) Ensure(ctx context.Context, record *v1.DNSRecord, zone v1.DNSZone) error {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	_, err := p.client.Ensure(ctx, &EnsureRequest{Record: recordToMessage(record), Zone: zoneToMessage(zone)})
+	if err != nil {
+		return fmt.Errorf("failed to ensure DNS record %s in zone %s: %w", record.Name, zone.ID, errorFromStatus(err))
+	}
+	return nil
+}
+
+func (p *Provider) Delete(ctx context.Context, record *v1.DNSRecord, zone v1.DNSZone) error {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	_, err := p.client.Delete(ctx, &DeleteRequest{Record: recordToMessage(record), Zone: zoneToMessage(zone)})
+	if err != nil {
+		return fmt.Errorf("failed to delete DNS record %s in zone %s: %w", record.Name, zone.ID, errorFromStatus(err))
+	}
+	return nil
+}
+
+// GetRecords returns the records of the zone with the given name and type.
+func (p *Provider) GetRecords(ctx context.Context, zone v1.DNSZone, dnsName, recordType string) ([]*v1.Endpoint, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	response, err := p.client.GetRecords(ctx, &GetRecordsRequest{Zone: zoneToMessage(zone), DnsName: dnsName, RecordType: recordType})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get records %s in zone %s: %w", dnsName, zone.ID, errorFromStatus(err))
+	}
+	return endpointsFromMessages(response.Endpoints), nil
+}
+
+// ListRecords returns all the records of the zone.
+func (p *Provider) ListRecords(ctx context.Context, zone v1.DNSZone) ([]*v1.Endpoint, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	response, err := p.client.ListRecords(ctx, &ListRecordsRequest{Zone: zoneToMessage(zone)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list records in zone %s: %w", zone.ID, errorFromStatus(err))
+	}
+	return endpointsFromMessages(response.Endpoints), nil
+}
+
+func (p *Provider) ReconcileHealthCheck(ctx context.Context, hc v1.EndpointHealthCheck, endpoint *v1.Endpoint) error {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	_, err := p.client.ReconcileHealthCheck(ctx, &ReconcileHealthCheckRequest{HealthCheck: healthCheckToMessage(hc), Endpoint: endpointToMessage(endpoint)})
+	if err != nil {
+		return fmt.Errorf("failed to reconcile health check of endpoint %s: %w", endpoint.SetID(), errorFromStatus(err))
+	}
+	return nil
+}
+
+func (p *Provider) DeleteHealthCheck(ctx context.Context, endpoint *v1.Endpoint) error {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	_, err := p.client.DeleteHealthCheck(ctx, &DeleteHealthCheckRequest{Endpoint: endpointToMessage(endpoint)})
+	if err != nil {
+		return fmt.Errorf("failed to delete health check of endpoint %s: %w", endpoint.SetID(), errorFromStatus(err))
+	}
+	return nil
+}
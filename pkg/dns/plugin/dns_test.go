@@ -0,0 +1,167 @@
+package plugin
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+
+	v1 "github.com/kuadrant/kcp-glbc/pkg/apis/kuadrant/v1"
+	dnserrors "github.com/kuadrant/kcp-glbc/pkg/dns/errors"
+	dnsMemory "github.com/kuadrant/kcp-glbc/pkg/dns/memory"
+)
+
+// newTestProvider returns a provider connected to a plugin serving the backend
+// over a Unix socket.
+func newTestProvider(t *testing.T, backend Backend) *Provider {
+	address := "unix://" + filepath.Join(t.TempDir(), "dns.sock")
+	listener, err := Listen(address)
+	if err != nil {
+		t.Fatalf("unexpected error listening on %s: %v", address, err)
+	}
+	server := NewServer(backend)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	provider, err := NewProvider(Config{Address: address})
+	if err != nil {
+		t.Fatalf("unexpected error creating provider: %v", err)
+	}
+	t.Cleanup(func() { _ = provider.Close() })
+	return provider
+}
+
+func newTestMemoryProvider(t *testing.T) *dnsMemory.Provider {
+	provider, err := dnsMemory.NewProvider(dnsMemory.Config{Address: "127.0.0.1", Port: -1})
+	if err != nil {
+		t.Fatalf("unexpected error creating memory provider: %v", err)
+	}
+	t.Cleanup(func() { _ = provider.Shutdown() })
+	return provider
+}
+
+func weightedEndpoint(ip string, weight int64) *v1.Endpoint {
+	return &v1.Endpoint{
+		DNSName:       "app.example.com",
+		RecordType:    string(v1.ARecordType),
+		SetIdentifier: ip,
+		RecordTTL:     60,
+		Targets:       v1.Targets{ip},
+		RoutingPolicy: &v1.RoutingPolicy{Weighted: &v1.WeightedRoutingPolicy{Weight: weight}},
+	}
+}
+
+func TestEnsureAndDelete(t *testing.T) {
+	g := gomega.NewWithT(t)
+	backend := newTestMemoryProvider(t)
+	provider := newTestProvider(t, backend)
+	zone := v1.DNSZone{ID: "example.com"}
+
+	record := &v1.DNSRecord{Spec: v1.DNSRecordSpec{Endpoints: []*v1.Endpoint{
+		weightedEndpoint("10.0.0.1", 120),
+		weightedEndpoint("10.0.0.2", 0),
+	}}}
+	g.Expect(provider.Ensure(context.TODO(), record, zone)).To(gomega.Succeed())
+
+	endpoints, err := provider.GetRecords(context.TODO(), zone, "app.example.com", string(v1.ARecordType))
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(endpoints).To(gomega.HaveLen(2))
+	g.Expect(endpoints).To(gomega.ConsistOf(record.Spec.Endpoints[0], record.Spec.Endpoints[1]))
+
+	// The endpoints last published to the zone, that the record no longer
+	// has, are deleted
+	record.Status.Zones = []v1.DNSZoneStatus{{DNSZone: zone, Endpoints: record.Spec.Endpoints}}
+	record.Spec.Endpoints = []*v1.Endpoint{weightedEndpoint("10.0.0.1", 120)}
+	g.Expect(provider.Ensure(context.TODO(), record, zone)).To(gomega.Succeed())
+	g.Expect(backend.Endpoints(zone.ID)).To(gomega.HaveLen(1))
+
+	endpoints, err = provider.ListRecords(context.TODO(), zone)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(endpoints).To(gomega.Equal(record.Spec.Endpoints))
+
+	g.Expect(provider.Delete(context.TODO(), record, zone)).To(gomega.Succeed())
+	g.Expect(backend.Endpoints(zone.ID)).To(gomega.BeEmpty())
+}
+
+func TestEnsureReturnsProviderError(t *testing.T) {
+	g := gomega.NewWithT(t)
+	provider := newTestProvider(t, newTestMemoryProvider(t))
+
+	record := &v1.DNSRecord{Spec: v1.DNSRecordSpec{Endpoints: []*v1.Endpoint{{
+		DNSName:    "app.example.com",
+		RecordType: string(v1.ARecordType),
+		Targets:    v1.Targets{"not-an-ip"},
+	}}}}
+	err := provider.Ensure(context.TODO(), record, v1.DNSZone{ID: "example.com"})
+	g.Expect(err).To(gomega.MatchError(gomega.ContainSubstring("invalid A record target not-an-ip")))
+	g.Expect(dnserrors.IsInvalidRecord(err)).To(gomega.BeTrue())
+}
+
+// fakeBackend records the health checks it is asked to reconcile.
+type fakeBackend struct {
+	Backend
+	healthChecks map[string]v1.EndpointHealthCheck
+}
+
+func (b *fakeBackend) ReconcileHealthCheck(_ context.Context, hc v1.EndpointHealthCheck, endpoint *v1.Endpoint) error {
+	b.healthChecks[endpoint.SetID()] = hc
+	return nil
+}
+
+func (b *fakeBackend) DeleteHealthCheck(_ context.Context, endpoint *v1.Endpoint) error {
+	if _, ok := b.healthChecks[endpoint.SetID()]; !ok {
+		return dnserrors.Errorf(dnserrors.ReasonNotFound, "no health check for endpoint %s", endpoint.SetID())
+	}
+	delete(b.healthChecks, endpoint.SetID())
+	return nil
+}
+
+func TestHealthChecks(t *testing.T) {
+	g := gomega.NewWithT(t)
+	backend := &fakeBackend{healthChecks: map[string]v1.EndpointHealthCheck{}}
+	provider := newTestProvider(t, backend)
+
+	port := int64(443)
+	protocol := v1.HealthCheckProtocolHTTPS
+	hc := v1.EndpointHealthCheck{
+		Name:                "app",
+		Port:                &port,
+		Path:                "/healthz",
+		Protocol:            &protocol,
+		Interval:            10 * time.Second,
+		ExpectedStatusCodes: []v1.HTTPStatusCode{200, 204},
+		HostHeader:          "app.example.com",
+		SearchString:        "ok",
+		Regions:             []string{"us-east-1", "eu-west-1", "ap-southeast-1"},
+		Inverted:            true,
+	}
+	endpoint := weightedEndpoint("10.0.0.1", 120)
+
+	g.Expect(provider.ReconcileHealthCheck(context.TODO(), hc, endpoint)).To(gomega.Succeed())
+	g.Expect(backend.healthChecks).To(gomega.HaveKeyWithValue(endpoint.SetID(), hc))
+
+	g.Expect(provider.DeleteHealthCheck(context.TODO(), endpoint)).To(gomega.Succeed())
+	g.Expect(backend.healthChecks).To(gomega.BeEmpty())
+
+	err := provider.DeleteHealthCheck(context.TODO(), endpoint)
+	g.Expect(dnserrors.IsNotFound(err)).To(gomega.BeTrue())
+}
+
+func TestEndpointLegacyRoutingPolicy(t *testing.T) {
+	g := gomega.NewWithT(t)
+	endpoint := &v1.Endpoint{
+		DNSName:    "app.example.com",
+		RecordType: string(v1.ARecordType),
+		ProviderSpecific: v1.ProviderSpecific{
+			{Name: v1.LegacyProviderSpecificWeight, Value: "120"},
+			{Name: "internal/view", Value: "private"},
+		},
+	}
+
+	// The legacy routing properties are sent as a typed routing policy
+	converted := endpointFromMessage(endpointToMessage(endpoint))
+	g.Expect(converted.RoutingPolicy).To(gomega.Equal(&v1.RoutingPolicy{Weighted: &v1.WeightedRoutingPolicy{Weight: 120}}))
+	g.Expect(converted.ProviderSpecific).To(gomega.Equal(v1.ProviderSpecific{{Name: "internal/view", Value: "private"}}))
+}
@@ -0,0 +1,303 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             (unknown)
+// source: dnsprovider.proto
+
+package plugin
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// DNSProviderClient is the client API for DNSProvider service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type DNSProviderClient interface {
+	// Ensure creates or updates the endpoints of the record in the zone, and
+	// deletes the endpoints last published to the zone that the record no longer
+	// has.
+	Ensure(ctx context.Context, in *EnsureRequest, opts ...grpc.CallOption) (*EnsureResponse, error)
+	// Delete deletes the endpoints of the record from the zone. Deleting
+	// endpoints that do not exist is not an error.
+	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error)
+	// GetRecords returns the endpoints of the zone with the given name and type.
+	GetRecords(ctx context.Context, in *GetRecordsRequest, opts ...grpc.CallOption) (*GetRecordsResponse, error)
+	// ListRecords returns all the endpoints of the zone.
+	ListRecords(ctx context.Context, in *ListRecordsRequest, opts ...grpc.CallOption) (*ListRecordsResponse, error)
+	// ReconcileHealthCheck creates or updates the health check of the endpoint.
+	ReconcileHealthCheck(ctx context.Context, in *ReconcileHealthCheckRequest, opts ...grpc.CallOption) (*ReconcileHealthCheckResponse, error)
+	// DeleteHealthCheck deletes the health check of the endpoint, if any.
+	DeleteHealthCheck(ctx context.Context, in *DeleteHealthCheckRequest, opts ...grpc.CallOption) (*DeleteHealthCheckResponse, error)
+}
+
+type dNSProviderClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDNSProviderClient(cc grpc.ClientConnInterface) DNSProviderClient {
+	return &dNSProviderClient{cc}
+}
+
+func (c *dNSProviderClient) Ensure(ctx context.Context, in *EnsureRequest, opts ...grpc.CallOption) (*EnsureResponse, error) {
+	out := new(EnsureResponse)
+	err := c.cc.Invoke(ctx, "/glbc.dns.plugin.v1.DNSProvider/Ensure", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dNSProviderClient) Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error) {
+	out := new(DeleteResponse)
+	err := c.cc.Invoke(ctx, "/glbc.dns.plugin.v1.DNSProvider/Delete", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dNSProviderClient) GetRecords(ctx context.Context, in *GetRecordsRequest, opts ...grpc.CallOption) (*GetRecordsResponse, error) {
+	out := new(GetRecordsResponse)
+	err := c.cc.Invoke(ctx, "/glbc.dns.plugin.v1.DNSProvider/GetRecords", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dNSProviderClient) ListRecords(ctx context.Context, in *ListRecordsRequest, opts ...grpc.CallOption) (*ListRecordsResponse, error) {
+	out := new(ListRecordsResponse)
+	err := c.cc.Invoke(ctx, "/glbc.dns.plugin.v1.DNSProvider/ListRecords", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dNSProviderClient) ReconcileHealthCheck(ctx context.Context, in *ReconcileHealthCheckRequest, opts ...grpc.CallOption) (*ReconcileHealthCheckResponse, error) {
+	out := new(ReconcileHealthCheckResponse)
+	err := c.cc.Invoke(ctx, "/glbc.dns.plugin.v1.DNSProvider/ReconcileHealthCheck", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dNSProviderClient) DeleteHealthCheck(ctx context.Context, in *DeleteHealthCheckRequest, opts ...grpc.CallOption) (*DeleteHealthCheckResponse, error) {
+	out := new(DeleteHealthCheckResponse)
+	err := c.cc.Invoke(ctx, "/glbc.dns.plugin.v1.DNSProvider/DeleteHealthCheck", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DNSProviderServer is the server API for DNSProvider service.
+// All implementations must embed UnimplementedDNSProviderServer
+// for forward compatibility
+type DNSProviderServer interface {
+	// Ensure creates or updates the endpoints of the record in the zone, and
+	// deletes the endpoints last published to the zone that the record no longer
+	// has.
+	Ensure(context.Context, *EnsureRequest) (*EnsureResponse, error)
+	// Delete deletes the endpoints of the record from the zone. Deleting
+	// endpoints that do not exist is not an error.
+	Delete(context.Context, *DeleteRequest) (*DeleteResponse, error)
+	// GetRecords returns the endpoints of the zone with the given name and type.
+	GetRecords(context.Context, *GetRecordsRequest) (*GetRecordsResponse, error)
+	// ListRecords returns all the endpoints of the zone.
+	ListRecords(context.Context, *ListRecordsRequest) (*ListRecordsResponse, error)
+	// ReconcileHealthCheck creates or updates the health check of the endpoint.
+	ReconcileHealthCheck(context.Context, *ReconcileHealthCheckRequest) (*ReconcileHealthCheckResponse, error)
+	// DeleteHealthCheck deletes the health check of the endpoint, if any.
+	DeleteHealthCheck(context.Context, *DeleteHealthCheckRequest) (*DeleteHealthCheckResponse, error)
+	mustEmbedUnimplementedDNSProviderServer()
+}
+
+// UnimplementedDNSProviderServer must be embedded to have forward compatible implementations.
+type UnimplementedDNSProviderServer struct {
+}
+
+func (UnimplementedDNSProviderServer) Ensure(context.Context, *EnsureRequest) (*EnsureResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Ensure not implemented")
+}
+func (UnimplementedDNSProviderServer) Delete(context.Context, *DeleteRequest) (*DeleteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (UnimplementedDNSProviderServer) GetRecords(context.Context, *GetRecordsRequest) (*GetRecordsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRecords not implemented")
+}
+func (UnimplementedDNSProviderServer) ListRecords(context.Context, *ListRecordsRequest) (*ListRecordsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListRecords not implemented")
+}
+func (UnimplementedDNSProviderServer) ReconcileHealthCheck(context.Context, *ReconcileHealthCheckRequest) (*ReconcileHealthCheckResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReconcileHealthCheck not implemented")
+}
+func (UnimplementedDNSProviderServer) DeleteHealthCheck(context.Context, *DeleteHealthCheckRequest) (*DeleteHealthCheckResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteHealthCheck not implemented")
+}
+func (UnimplementedDNSProviderServer) mustEmbedUnimplementedDNSProviderServer() {}
+
+// UnsafeDNSProviderServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to DNSProviderServer will
+// result in compilation errors.
+type UnsafeDNSProviderServer interface {
+	mustEmbedUnimplementedDNSProviderServer()
+}
+
+func RegisterDNSProviderServer(s grpc.ServiceRegistrar, srv DNSProviderServer) {
+	s.RegisterService(&DNSProvider_ServiceDesc, srv)
+}
+
+func _DNSProvider_Ensure_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EnsureRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DNSProviderServer).Ensure(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/glbc.dns.plugin.v1.DNSProvider/Ensure",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DNSProviderServer).Ensure(ctx, req.(*EnsureRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DNSProvider_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DNSProviderServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/glbc.dns.plugin.v1.DNSProvider/Delete",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DNSProviderServer).Delete(ctx, req.(*DeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DNSProvider_GetRecords_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRecordsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DNSProviderServer).GetRecords(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/glbc.dns.plugin.v1.DNSProvider/GetRecords",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DNSProviderServer).GetRecords(ctx, req.(*GetRecordsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DNSProvider_ListRecords_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRecordsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DNSProviderServer).ListRecords(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/glbc.dns.plugin.v1.DNSProvider/ListRecords",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DNSProviderServer).ListRecords(ctx, req.(*ListRecordsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DNSProvider_ReconcileHealthCheck_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReconcileHealthCheckRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DNSProviderServer).ReconcileHealthCheck(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/glbc.dns.plugin.v1.DNSProvider/ReconcileHealthCheck",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DNSProviderServer).ReconcileHealthCheck(ctx, req.(*ReconcileHealthCheckRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DNSProvider_DeleteHealthCheck_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteHealthCheckRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DNSProviderServer).DeleteHealthCheck(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/glbc.dns.plugin.v1.DNSProvider/DeleteHealthCheck",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DNSProviderServer).DeleteHealthCheck(ctx, req.(*DeleteHealthCheckRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// DNSProvider_ServiceDesc is the grpc.ServiceDesc for DNSProvider service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var DNSProvider_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "glbc.dns.plugin.v1.DNSProvider",
+	HandlerType: (*DNSProviderServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Ensure",
+			Handler:    _DNSProvider_Ensure_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _DNSProvider_Delete_Handler,
+		},
+		{
+			MethodName: "GetRecords",
+			Handler:    _DNSProvider_GetRecords_Handler,
+		},
+		{
+			MethodName: "ListRecords",
+			Handler:    _DNSProvider_ListRecords_Handler,
+		},
+		{
+			MethodName: "ReconcileHealthCheck",
+			Handler:    _DNSProvider_ReconcileHealthCheck_Handler,
+		},
+		{
+			MethodName: "DeleteHealthCheck",
+			Handler:    _DNSProvider_DeleteHealthCheck_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "dnsprovider.proto",
+}
@@ -0,0 +1,56 @@
+package plugin
+
+import (
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	dnserrors "github.com/kuadrant/kcp-glbc/pkg/dns/errors"
+)
+
+// errorFromStatus returns the error of a failed RPC, with the reason of its
+// status code, as documented in dnsprovider.proto.
+func errorFromStatus(err error) error {
+	s, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	reason := dnserrors.ReasonUnknown
+	switch s.Code() {
+	case codes.NotFound:
+		reason = dnserrors.ReasonNotFound
+	case codes.ResourceExhausted:
+		reason = dnserrors.ReasonThrottled
+	case codes.InvalidArgument:
+		reason = dnserrors.ReasonInvalidRecord
+	case codes.PermissionDenied, codes.Unauthenticated:
+		reason = dnserrors.ReasonPermissionDenied
+	case codes.FailedPrecondition:
+		reason = dnserrors.ReasonZoneNotFound
+	}
+	return dnserrors.New(reason, err)
+}
+
+// statusFromError returns the status of the RPC that failed with the error of
+// a provider, with the status code of its reason.
+func statusFromError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	code := codes.Unknown
+	switch dnserrors.ReasonForError(err) {
+	case dnserrors.ReasonNotFound:
+		code = codes.NotFound
+	case dnserrors.ReasonThrottled:
+		code = codes.ResourceExhausted
+	case dnserrors.ReasonInvalidRecord:
+		code = codes.InvalidArgument
+	case dnserrors.ReasonPermissionDenied:
+		code = codes.PermissionDenied
+	case dnserrors.ReasonZoneNotFound:
+		code = codes.FailedPrecondition
+	}
+	return status.Error(code, err.Error())
+}
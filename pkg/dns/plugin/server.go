@@ -0,0 +1,104 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	v1 "github.com/kuadrant/kcp-glbc/pkg/apis/kuadrant/v1"
+)
+
+// Backend is the DNS backend a plugin serves, with the same methods as the
+// in-process DNS providers, so that any of them can be served as a plugin.
+type Backend interface {
+	Ensure(ctx context.Context, record *v1.DNSRecord, zone v1.DNSZone) error
+	Delete(ctx context.Context, record *v1.DNSRecord, zone v1.DNSZone) error
+	GetRecords(ctx context.Context, zone v1.DNSZone, dnsName, recordType string) ([]*v1.Endpoint, error)
+	ListRecords(ctx context.Context, zone v1.DNSZone) ([]*v1.Endpoint, error)
+	ReconcileHealthCheck(ctx context.Context, hc v1.EndpointHealthCheck, endpoint *v1.Endpoint) error
+	DeleteHealthCheck(ctx context.Context, endpoint *v1.Endpoint) error
+}
+
+// NewServer returns a gRPC server serving the backend as a DNSProvider. The
+// errors of the backend are returned with the status code of their reason.
+func NewServer(backend Backend, opts ...grpc.ServerOption) *grpc.Server {
+	server := grpc.NewServer(opts...)
+	RegisterDNSProviderServer(server, &backendServer{backend: backend})
+	return server
+}
+
+// Listen listens on the address of a plugin, either a Unix socket, e.g.
+// unix:///run/glbc/dns.sock, or a TCP address, e.g. :50051. A stale Unix
+// socket left by a previous run is removed.
+func Listen(address string) (net.Listener, error) {
+	if path := strings.TrimPrefix(address, "unix://"); path != address {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to remove stale socket %s: %v", path, err)
+		}
+		return net.Listen("unix", path)
+	}
+	return net.Listen("tcp", address)
+}
+
+type backendServer struct {
+	UnimplementedDNSProviderServer
+	backend Backend
+}
+
+var _ DNSProviderServer = &backendServer{}
+
+func (s *backendServer) Ensure(ctx context.Context, request *EnsureRequest) (*EnsureResponse, error) {
+	if err := s.backend.Ensure(ctx, recordFromMessage(request.Record), zoneFromMessage(request.Zone)); err != nil {
+		return nil, statusFromError(err)
+	}
+	return &EnsureResponse{}, nil
+}
+
+func (s *backendServer) Delete(ctx context.Context, request *DeleteRequest) (*DeleteResponse, error) {
+	if err := s.backend.Delete(ctx, recordFromMessage(request.Record), zoneFromMessage(request.Zone)); err != nil {
+		return nil, statusFromError(err)
+	}
+	return &DeleteResponse{}, nil
+}
+
+func (s *backendServer) GetRecords(ctx context.Context, request *GetRecordsRequest) (*GetRecordsResponse, error) {
+	endpoints, err := s.backend.GetRecords(ctx, zoneFromMessage(request.Zone), request.DnsName, request.RecordType)
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+	return &GetRecordsResponse{Endpoints: endpointsToMessages(endpoints)}, nil
+}
+
+func (s *backendServer) ListRecords(ctx context.Context, request *ListRecordsRequest) (*ListRecordsResponse, error) {
+	endpoints, err := s.backend.ListRecords(ctx, zoneFromMessage(request.Zone))
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+	return &ListRecordsResponse{Endpoints: endpointsToMessages(endpoints)}, nil
+}
+
+func (s *backendServer) ReconcileHealthCheck(ctx context.Context, request *ReconcileHealthCheckRequest) (*ReconcileHealthCheckResponse, error) {
+	if request.Endpoint == nil {
+		return nil, status.Error(codes.InvalidArgument, "endpoint is required")
+	}
+	if err := s.backend.ReconcileHealthCheck(ctx, healthCheckFromMessage(request.HealthCheck), endpointFromMessage(request.Endpoint)); err != nil {
+		return nil, statusFromError(err)
+	}
+	return &ReconcileHealthCheckResponse{}, nil
+}
+
+func (s *backendServer) DeleteHealthCheck(ctx context.Context, request *DeleteHealthCheckRequest) (*DeleteHealthCheckResponse, error) {
+	if request.Endpoint == nil {
+		return nil, status.Error(codes.InvalidArgument, "endpoint is required")
+	}
+	if err := s.backend.DeleteHealthCheck(ctx, endpointFromMessage(request.Endpoint)); err != nil {
+		return nil, statusFromError(err)
+	}
+	return &DeleteHealthCheckResponse{}, nil
+}
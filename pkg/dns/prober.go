@@ -0,0 +1,284 @@
+package dns
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	v1 "github.com/kuadrant/kcp-glbc/pkg/apis/kuadrant/v1"
+)
+
+const (
+	// defaultProbeInterval and defaultProbeFailureThreshold are the interval
+	// and the failure threshold of the health checks that do not set them, as
+	// for the Route53 health checks.
+	defaultProbeInterval         = 30 * time.Second
+	defaultProbeFailureThreshold = 3
+
+	// probeTimeout is the time the endpoints have to answer a probe.
+	probeTimeout = 5 * time.Second
+
+	// probeSchedulingInterval is the interval at which the probes that are due
+	// are sent.
+	probeSchedulingInterval = time.Second
+
+	// searchStringBodyLimit is the number of bytes of the body of the responses
+	// searched for the search string, as for the Route53 health checks.
+	searchStringBodyLimit = 5120
+)
+
+// Prober performs the health checks of the endpoints from the GLBC, for the DNS
+// providers that have no health checks of their own. The endpoints are probed
+// at their address, with the host name of the health check as the Host header
+// and the TLS server name. An endpoint changes health once it has failed, or
+// succeeded, as many consecutive probes as the failure threshold. The regions
+// and the cluster health threshold of the health checks are ignored.
+type Prober struct {
+	mu     sync.Mutex
+	probes map[string]*probe
+}
+
+// probe holds the health check of an endpoint, along with the results of the
+// probes sent to the endpoint.
+type probe struct {
+	spec    v1.EndpointHealthCheck
+	address string
+	host    string
+
+	next     time.Time
+	running  bool
+	failures int
+	success  int
+	health   v1.Health
+	observed metav1.Time
+}
+
+var _ HealthCheckReconciler = &Prober{}
+var _ HealthCheckStatusReader = &Prober{}
+
+// NewProber returns a Prober, whose probes are sent once it is started.
+func NewProber() *Prober {
+	return &Prober{probes: map[string]*probe{}}
+}
+
+// probesHealth returns whether the health of the endpoints is probed by the
+// GLBC, as requested by the DNS provider, in which case the DNS controller does
+// not publish the unhealthy endpoints.
+func probesHealth(provider HealthCheckReconciler) bool {
+	requester, ok := provider.(HealthProbeRequester)
+	return ok && requester.RequiresHealthProbes()
+}
+
+// Start sends the probes that are due until the context is done.
+func (p *Prober) Start(ctx context.Context) {
+	wait.UntilWithContext(ctx, p.probeDue, probeSchedulingInterval)
+}
+
+func (p *Prober) ReconcileHealthCheck(_ context.Context, spec v1.EndpointHealthCheck, endpoint *v1.Endpoint) error {
+	address, ok := endpoint.GetAddress()
+	if !ok {
+		return nil
+	}
+	host := endpoint.DNSName
+	if spec.HostHeader != "" {
+		host = spec.HostHeader
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	key := endpointKey(endpoint)
+	if existing, ok := p.probes[key]; ok && existing.address == address && existing.host == host && equality.Semantic.DeepEqual(existing.spec, spec) {
+		return nil
+	}
+	// The results of the probes are only relevant to the health check they
+	// were sent for
+	p.probes[key] = &probe{
+		spec:    spec,
+		address: address,
+		host:    host,
+		next:    clock.Now(),
+		health:  v1.HealthUnknown,
+	}
+	return nil
+}
+
+func (p *Prober) DeleteHealthCheck(_ context.Context, endpoint *v1.Endpoint) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.probes, endpointKey(endpoint))
+	return nil
+}
+
+// HealthCheckStatus returns the health of the endpoint, as last observed by the
+// probes. The health checks of the GLBC have no provider ID.
+func (p *Prober) HealthCheckStatus(_ context.Context, endpoint *v1.Endpoint) (string, v1.EndpointHealth, error) {
+	health := v1.EndpointHealth{
+		DNSName:       endpoint.DNSName,
+		SetIdentifier: endpoint.SetIdentifier,
+		Health:        v1.HealthUnknown,
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if probe, ok := p.probes[endpointKey(endpoint)]; ok {
+		health.Health = probe.health
+		health.LastObservedTime = probe.observed
+	}
+	return "", health, nil
+}
+
+// probeDue sends the probes of the endpoints that are due, that are not already
+// in flight.
+func (p *Prober) probeDue(ctx context.Context) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := clock.Now()
+	for _, due := range p.probes {
+		if due.running || now.Before(due.next) {
+			continue
+		}
+		due.running = true
+		go func(due *probe) {
+			err := sendProbe(ctx, due.spec, due.address, due.host)
+			p.record(due, err)
+		}(due)
+	}
+}
+
+// record records the result of a probe, unless the health check of the
+// endpoint was changed or deleted in the meantime.
+func (p *Prober) record(probe *probe, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := clock.Now()
+	probe.running = false
+	probe.next = now.Add(probeInterval(probe.spec))
+	probe.observed = metav1.NewTime(now)
+	if err != nil {
+		probe.failures, probe.success = probe.failures+1, 0
+	} else {
+		probe.failures, probe.success = 0, probe.success+1
+	}
+	threshold := probeFailureThreshold(probe.spec)
+	switch {
+	case probe.failures >= threshold:
+		probe.health = v1.Unhealthy
+	case probe.success >= threshold:
+		probe.health = v1.Healthy
+	}
+}
+
+// sendProbe probes the endpoint at the given address, and returns an error if
+// it is unhealthy, or healthy for the inverted health checks.
+func sendProbe(ctx context.Context, spec v1.EndpointHealthCheck, address, host string) error {
+	err := probeEndpoint(ctx, spec, address, host)
+	if !spec.Inverted {
+		return err
+	}
+	if err != nil {
+		return nil
+	}
+	return fmt.Errorf("inverted health check succeeded")
+}
+
+// probeEndpoint probes the endpoint at the given address, and returns an error
+// if the probe fails. The HTTPS probes do not verify the certificate of the
+// endpoint, as the Route53 health checks.
+func probeEndpoint(ctx context.Context, spec v1.EndpointHealthCheck, address, host string) error {
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+
+	port := int64(defaultHealthCheckPort)
+	if spec.Port != nil {
+		port = *spec.Port
+	}
+	target := net.JoinHostPort(address, strconv.FormatInt(port, 10))
+
+	protocol := v1.HealthCheckProtocolHTTP
+	if spec.Protocol != nil {
+		protocol = *spec.Protocol
+	}
+	scheme := "http"
+	switch protocol {
+	case v1.HealthCheckProtocolTCP:
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, "tcp", target)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	case v1.HealthCheckProtocolHTTPS:
+		scheme = "https"
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s://%s%s", scheme, target, spec.Path), nil)
+	if err != nil {
+		return err
+	}
+	request.Host = host
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig:   &tls.Config{ServerName: host, InsecureSkipVerify: true},
+			DisableKeepAlives: true,
+		},
+		// The redirections are responses of the endpoint, as any other
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(response.Body, searchStringBodyLimit))
+	if err != nil {
+		return err
+	}
+
+	if !isExpectedStatusCode(spec, response.StatusCode) {
+		return fmt.Errorf("unexpected status code %d", response.StatusCode)
+	}
+	if spec.SearchString != "" && !strings.Contains(string(body), spec.SearchString) {
+		return fmt.Errorf("search string %q not found in response", spec.SearchString)
+	}
+	return nil
+}
+
+// isExpectedStatusCode returns whether the status code is one of the expected
+// status codes of the health check, that defaults to the 2xx and 3xx ones.
+func isExpectedStatusCode(spec v1.EndpointHealthCheck, code int) bool {
+	if len(spec.ExpectedStatusCodes) == 0 {
+		return code >= 200 && code < 400
+	}
+	for _, expected := range spec.ExpectedStatusCodes {
+		if int(expected) == code {
+			return true
+		}
+	}
+	return false
+}
+
+func probeInterval(spec v1.EndpointHealthCheck) time.Duration {
+	if spec.Interval > 0 {
+		return spec.Interval
+	}
+	return defaultProbeInterval
+}
+
+func probeFailureThreshold(spec v1.EndpointHealthCheck) int {
+	if spec.FailureThreshold != nil && *spec.FailureThreshold > 0 {
+		return int(*spec.FailureThreshold)
+	}
+	return defaultProbeFailureThreshold
+}
@@ -0,0 +1,191 @@
+package dns
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+
+	v1 "github.com/kuadrant/kcp-glbc/pkg/apis/kuadrant/v1"
+)
+
+// serverHealthCheck returns the health check of the endpoints probed at the
+// port of the test server.
+func serverHealthCheck(t *testing.T, server *httptest.Server, protocol v1.HealthCheckProtocol) v1.EndpointHealthCheck {
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error parsing server URL: %v", err)
+	}
+	port, err := strconv.ParseInt(u.Port(), 10, 64)
+	if err != nil {
+		t.Fatalf("unexpected error parsing server port: %v", err)
+	}
+	failureThreshold := int64(2)
+	return v1.EndpointHealthCheck{
+		Id:               "app",
+		Path:             "/healthz",
+		Port:             &port,
+		Protocol:         &protocol,
+		Interval:         time.Millisecond,
+		FailureThreshold: &failureThreshold,
+	}
+}
+
+func TestSendProbe(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	var host, serverName string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host = r.Host
+		if r.TLS != nil {
+			serverName = r.TLS.ServerName
+		}
+		if r.URL.Path != "/healthz" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+		_, _ = w.Write([]byte("status: ok"))
+	})
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	spec := serverHealthCheck(t, server, v1.HealthCheckProtocolHTTP)
+	g.Expect(sendProbe(context.TODO(), spec, "127.0.0.1", "app.example.com")).To(gomega.Succeed())
+	g.Expect(host).To(gomega.Equal("app.example.com"))
+
+	// The responses must have one of the expected status codes
+	spec.ExpectedStatusCodes = []v1.HTTPStatusCode{http.StatusNoContent}
+	g.Expect(sendProbe(context.TODO(), spec, "127.0.0.1", "app.example.com")).To(gomega.MatchError("unexpected status code 200"))
+	spec.ExpectedStatusCodes = nil
+
+	// The responses must contain the search string, if any
+	spec.SearchString = "status: ok"
+	g.Expect(sendProbe(context.TODO(), spec, "127.0.0.1", "app.example.com")).To(gomega.Succeed())
+	spec.SearchString = "status: failed"
+	g.Expect(sendProbe(context.TODO(), spec, "127.0.0.1", "app.example.com")).To(gomega.MatchError(`search string "status: failed" not found in response`))
+	spec.SearchString = ""
+
+	// The inverted health checks succeed when the probes fail
+	spec.Inverted = true
+	g.Expect(sendProbe(context.TODO(), spec, "127.0.0.1", "app.example.com")).To(gomega.MatchError("inverted health check succeeded"))
+	spec.Path = "/"
+	g.Expect(sendProbe(context.TODO(), spec, "127.0.0.1", "app.example.com")).To(gomega.Succeed())
+	spec.Inverted = false
+	g.Expect(sendProbe(context.TODO(), spec, "127.0.0.1", "app.example.com")).To(gomega.MatchError("unexpected status code 404"))
+
+	// The host name is the TLS server name of the HTTPS probes
+	tlsServer := httptest.NewTLSServer(handler)
+	t.Cleanup(tlsServer.Close)
+	spec = serverHealthCheck(t, tlsServer, v1.HealthCheckProtocolHTTPS)
+	g.Expect(sendProbe(context.TODO(), spec, "127.0.0.1", "app.example.com")).To(gomega.Succeed())
+	g.Expect(serverName).To(gomega.Equal("app.example.com"))
+
+	// The TCP probes only open a connection
+	spec = serverHealthCheck(t, server, v1.HealthCheckProtocolTCP)
+	g.Expect(sendProbe(context.TODO(), spec, "127.0.0.1", "app.example.com")).To(gomega.Succeed())
+	server.Close()
+	g.Expect(sendProbe(context.TODO(), spec, "127.0.0.1", "app.example.com")).NotTo(gomega.Succeed())
+}
+
+func TestProber(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	unhealthy := int32(0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&unhealthy) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	endpoint := aEndpoint("app.example.com", "127.0.0.1")
+	endpoint.SetIdentifier = "127.0.0.1"
+	prober := NewProber()
+	g.Expect(prober.ReconcileHealthCheck(context.TODO(), serverHealthCheck(t, server, v1.HealthCheckProtocolHTTP), endpoint)).To(gomega.Succeed())
+
+	health := func() v1.Health {
+		_, health, err := prober.HealthCheckStatus(context.TODO(), endpoint)
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		return health.Health
+	}
+	g.Expect(health()).To(gomega.Equal(v1.HealthUnknown))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	probe := func() v1.Health {
+		prober.probeDue(ctx)
+		return health()
+	}
+
+	// The endpoints change health after as many consecutive probes as the
+	// failure threshold
+	g.Eventually(probe, time.Second, 5*time.Millisecond).Should(gomega.Equal(v1.Healthy))
+	atomic.StoreInt32(&unhealthy, 1)
+	g.Eventually(probe, time.Second, 5*time.Millisecond).Should(gomega.Equal(v1.Unhealthy))
+
+	// The probes of the deleted health checks are stopped
+	g.Expect(prober.DeleteHealthCheck(context.TODO(), endpoint)).To(gomega.Succeed())
+	g.Expect(health()).To(gomega.Equal(v1.HealthUnknown))
+	g.Expect(prober.probes).To(gomega.BeEmpty())
+}
+
+func TestProberIsOnlyUsedWhenRequested(t *testing.T) {
+	g := gomega.NewWithT(t)
+	g.Expect(probesHealth(newTestMemoryProvider(t))).To(gomega.BeTrue())
+	g.Expect(probesHealth(&FakeProvider{})).To(gomega.BeFalse())
+	g.Expect(probesHealth(&healthCheckProvider{})).To(gomega.BeFalse())
+}
+
+func TestWithholdUnhealthyEndpoints(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	weighted := func(ip string) *v1.Endpoint {
+		endpoint := aEndpoint("app.example.com", ip)
+		endpoint.SetIdentifier = ip
+		return endpoint
+	}
+	record := newTestRecord("app", weighted("10.0.0.1"), weighted("10.0.0.2"), aEndpoint("other.example.com", "10.0.0.3"))
+	g.Expect(withholdUnhealthyEndpoints(record)).To(gomega.BeIdenticalTo(record))
+
+	record.Status.Health = []v1.EndpointHealth{
+		{DNSName: "app.example.com", SetIdentifier: "10.0.0.1", Health: v1.Unhealthy},
+		{DNSName: "app.example.com", SetIdentifier: "10.0.0.2", Health: v1.Healthy},
+		{DNSName: "other.example.com", Health: v1.Unhealthy},
+	}
+	withheld := withholdUnhealthyEndpoints(record)
+	g.Expect(withheld.Spec.Endpoints).To(gomega.ConsistOf(weighted("10.0.0.2"), aEndpoint("other.example.com", "10.0.0.3")))
+	g.Expect(record.Spec.Endpoints).To(gomega.HaveLen(3))
+
+	// The records are published again when the withheld endpoints change
+	zone := v1.DNSZone{ID: "example.com"}
+	record.Status.Zones = []v1.DNSZoneStatus{{DNSZone: zone, Endpoints: record.Spec.Endpoints}}
+	g.Expect(publishedEndpointsChanged(record, withheld, zone)).To(gomega.BeTrue())
+	record.Status.Zones[0].Endpoints = withheld.Spec.Endpoints
+	g.Expect(publishedEndpointsChanged(record, withheld, zone)).To(gomega.BeFalse())
+	g.Expect(publishedEndpointsChanged(record, record, zone)).To(gomega.BeTrue())
+
+	// The last endpoints of a name are kept
+	record.Status.Health[1].Health = v1.Unhealthy
+	g.Expect(withholdUnhealthyEndpoints(record).Spec.Endpoints).To(gomega.HaveLen(3))
+}
+
+func TestSendProbeTimeout(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	t.Cleanup(func() { _ = listener.Close() })
+
+	// The connections are accepted, but never answered
+	port := int64(listener.Addr().(*net.TCPAddr).Port)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err = sendProbe(ctx, v1.EndpointHealthCheck{Path: "/", Port: &port}, "127.0.0.1", "app.example.com")
+	g.Expect(err).To(gomega.HaveOccurred())
+}
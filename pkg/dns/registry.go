@@ -0,0 +1,357 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+
+	v1 "github.com/kuadrant/kcp-glbc/pkg/apis/kuadrant/v1"
+)
+
+const (
+	// DefaultOwnerID is the owner ID recorded in the ownership records when none is configured.
+	DefaultOwnerID = "kcp-glbc"
+
+	// ownershipRecordPrefix is prepended to the names managed by the GLBC, to get
+	// the name of the TXT record holding their ownership. The ownership cannot be
+	// recorded under the same name, as a CNAME record cannot coexist with other records.
+	ownershipRecordPrefix = "_glbc-owner."
+	ownershipRecordTTL    = 300
+
+	// ownershipCacheTTL bounds how long the ownership of a name is trusted
+	// without being looked up again, so that the names taken over by hand or by
+	// another owner are eventually noticed.
+	ownershipCacheTTL = 10 * time.Minute
+
+	ownershipHeritage      = "kcp-glbc"
+	ownershipHeritageLabel = "heritage"
+	ownershipOwnerLabel    = "kcp-glbc/owner"
+	ownershipResourceLabel = "kcp-glbc/resource"
+
+	// annotationTrafficKey mirrors traffic.ANNOTATION_TRAFFIC_KEY, set on the
+	// DNSRecords created for traffic objects.
+	annotationTrafficKey = "kuadrant.dev/traffic-key"
+)
+
+// OwnershipConflictError is returned when a name of a DNSRecord is owned by
+// another owner, or by another DNSRecord, or is already used by records that
+// are not owned by the GLBC.
+type OwnershipConflictError struct {
+	DNSName string
+	// Owner is the ID of the owner of the name, empty when the name is used
+	// by records without ownership record.
+	Owner string
+	// Resource is the resource recorded in the ownership record of the name,
+	// only set when the name is owned by this GLBC for another DNSRecord.
+	Resource string
+}
+
+func (e *OwnershipConflictError) Error() string {
+	if e.Owner == "" {
+		return fmt.Sprintf("name %s is already in use by records not owned by any GLBC", e.DNSName)
+	}
+	if e.Resource != "" {
+		return fmt.Sprintf("name %s is owned by %s for %s", e.DNSName, e.Owner, e.Resource)
+	}
+	return fmt.Sprintf("name %s is owned by %s", e.DNSName, e.Owner)
+}
+
+// Registry records the ownership of the names published to the zones, with a
+// companion TXT record per name holding the owner ID and the traffic key of the
+// DNSRecord, similarly to the external-dns TXT registry. It refuses to modify
+// or delete the names owned by another owner, or by another DNSRecord.
+//
+// The names it has published are cached for ownershipCacheTTL, so that the
+// DNSRecords are not looked up in the zones on every reconciliation, which
+// would quickly exhaust the rate limits of the DNS provider APIs.
+type Registry struct {
+	ownerID  string
+	provider Provider
+
+	mu    sync.Mutex
+	owned map[ownershipKey]ownershipEntry
+}
+
+type ownershipKey struct {
+	zoneID string
+	name   string
+}
+
+type ownershipEntry struct {
+	resource string
+	expires  time.Time
+}
+
+// NewRegistry returns a Registry publishing the records with the provider on
+// behalf of the owner, defaulting to DefaultOwnerID. A single Registry is meant
+// to be shared by all the DNSRecord controllers using the provider, for them to
+// share its ownership cache.
+func NewRegistry(ownerID string, provider Provider) *Registry {
+	if ownerID == "" {
+		ownerID = DefaultOwnerID
+	}
+	return &Registry{
+		ownerID:  ownerID,
+		provider: provider,
+		owned:    map[ownershipKey]ownershipEntry{},
+	}
+}
+
+// Ensure publishes the record to the zone, along with the ownership records of
+// its names, once it has verified that none of them is owned by someone else.
+// It returns the published endpoints, including the ownership records. The
+// records of the providers that record the ownership of the names themselves
+// are published as is.
+func (r *Registry) Ensure(ctx context.Context, record *v1.DNSRecord, zone v1.DNSZone) ([]*v1.Endpoint, error) {
+	if RegistersOwnership(r.provider) {
+		published := record.DeepCopy()
+		if err := r.provider.Ensure(ctx, published, zone); err != nil {
+			return nil, err
+		}
+		return published.Spec.Endpoints, nil
+	}
+
+	published := publishedEndpoints(record, zone)
+	endpoints := append(append([]*v1.Endpoint{}, record.Spec.Endpoints...), published...)
+	if err := r.verifyOwnership(ctx, zone, ownershipResource(record), endpoints, published); err != nil {
+		return nil, err
+	}
+
+	owned := record.DeepCopy()
+	owned.Spec.Endpoints = append(owned.Spec.Endpoints, r.ownershipEndpoints(record)...)
+	if err := r.provider.Ensure(ctx, owned, zone); err != nil {
+		return nil, err
+	}
+	r.forget(zone, managedNames(published))
+	r.remember(zone, managedNames(record.Spec.Endpoints), ownershipResource(record))
+	return owned.Spec.Endpoints, nil
+}
+
+// Delete deletes the record from the zone, along with the ownership records of
+// its names, once it has verified that none of them is owned by someone else.
+func (r *Registry) Delete(ctx context.Context, record *v1.DNSRecord, zone v1.DNSZone) error {
+	if RegistersOwnership(r.provider) {
+		return r.provider.Delete(ctx, record, zone)
+	}
+
+	published := publishedEndpoints(record, zone)
+	if err := r.verifyOwnership(ctx, zone, ownershipResource(record), record.Spec.Endpoints, published); err != nil {
+		return err
+	}
+
+	owned := record.DeepCopy()
+	owned.Spec.Endpoints = append(owned.Spec.Endpoints, r.ownershipEndpoints(record)...)
+	r.forget(zone, managedNames(record.Spec.Endpoints))
+	return r.provider.Delete(ctx, owned, zone)
+}
+
+// verifyOwnership returns an OwnershipConflictError if one of the names of the
+// endpoints is owned by another owner, or by another DNSRecord than the one of
+// the resource. A name without ownership record is only considered owned if it
+// was previously published from the DNSRecord, or if it is not used by any
+// record yet.
+func (r *Registry) verifyOwnership(ctx context.Context, zone v1.DNSZone, resource string, endpoints, published []*v1.Endpoint) error {
+	previouslyPublished := map[string]struct{}{}
+	for _, endpoint := range published {
+		previouslyPublished[normalizeDNSName(endpoint.DNSName)] = struct{}{}
+	}
+
+	for _, name := range managedNames(endpoints) {
+		if r.isOwned(zone, name, resource) {
+			continue
+		}
+
+		labels, found, err := r.owner(ctx, zone, name)
+		if err != nil {
+			return err
+		}
+		if found {
+			if owner := labels[ownershipOwnerLabel]; owner != r.ownerID {
+				return &OwnershipConflictError{DNSName: name, Owner: owner}
+			}
+			// The ownership records written before the resource was recorded
+			// do not hold it, and are adopted.
+			if other := labels[ownershipResourceLabel]; other != "" && other != resource {
+				return &OwnershipConflictError{DNSName: name, Owner: r.ownerID, Resource: other}
+			}
+			continue
+		}
+
+		if _, ok := previouslyPublished[name]; ok {
+			continue
+		}
+		for _, recordType := range []v1.DNSRecordType{v1.ARecordType, v1.AAAARecordType, v1.CNAMERecordType, v1.TXTRecordType, v1.CAARecordType} {
+			existing, err := r.provider.GetRecords(ctx, zone, name, string(recordType))
+			if err != nil {
+				return err
+			}
+			if len(existing) > 0 {
+				return &OwnershipConflictError{DNSName: name}
+			}
+		}
+	}
+	return nil
+}
+
+// owner returns the labels of the ownership record of the name, if any.
+func (r *Registry) owner(ctx context.Context, zone v1.DNSZone, name string) (map[string]string, bool, error) {
+	records, err := r.provider.GetRecords(ctx, zone, ownershipRecordName(name), string(v1.TXTRecordType))
+	if err != nil {
+		return nil, false, err
+	}
+	for _, record := range records {
+		for _, target := range record.Targets {
+			labels, ok := parseOwnershipLabels(target)
+			if !ok {
+				continue
+			}
+			return labels, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+// isOwned returns whether the name was published to the zone for the resource
+// less than ownershipCacheTTL ago.
+func (r *Registry) isOwned(zone v1.DNSZone, name, resource string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := ownershipKey{zoneID: zone.ID, name: name}
+	entry, ok := r.owned[key]
+	if !ok {
+		return false
+	}
+	if !clock.Now().Before(entry.expires) {
+		delete(r.owned, key)
+		return false
+	}
+	return entry.resource == resource
+}
+
+// remember caches the ownership of the names published to the zone for the resource.
+func (r *Registry) remember(zone v1.DNSZone, names []string, resource string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	expires := clock.Now().Add(ownershipCacheTTL)
+	for _, name := range names {
+		r.owned[ownershipKey{zoneID: zone.ID, name: name}] = ownershipEntry{resource: resource, expires: expires}
+	}
+}
+
+// forget evicts the names from the ownership cache of the zone.
+func (r *Registry) forget(zone v1.DNSZone, names []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, name := range names {
+		delete(r.owned, ownershipKey{zoneID: zone.ID, name: name})
+	}
+}
+
+// ownershipEndpoints returns the ownership records of the names of the record.
+func (r *Registry) ownershipEndpoints(record *v1.DNSRecord) []*v1.Endpoint {
+	value := strconv.Quote(fmt.Sprintf("%s=%s,%s=%s,%s=%s",
+		ownershipHeritageLabel, ownershipHeritage,
+		ownershipOwnerLabel, r.ownerID,
+		ownershipResourceLabel, ownershipResource(record)))
+
+	names := managedNames(record.Spec.Endpoints)
+	endpoints := make([]*v1.Endpoint, 0, len(names))
+	for _, name := range names {
+		endpoints = append(endpoints, &v1.Endpoint{
+			DNSName:    ownershipRecordName(name),
+			RecordType: string(v1.TXTRecordType),
+			RecordTTL:  ownershipRecordTTL,
+			Targets:    v1.Targets{value},
+		})
+	}
+	return endpoints
+}
+
+// ownedNames returns the names owned by this GLBC, according to the ownership
+// records among the endpoints, along with the resource recorded for each of them.
+func (r *Registry) ownedNames(endpoints []*v1.Endpoint) map[string]string {
+	names := map[string]string{}
+	for _, endpoint := range endpoints {
+		if !isOwnershipRecord(endpoint) {
+			continue
+		}
+		for _, target := range endpoint.Targets {
+			labels, ok := parseOwnershipLabels(target)
+			if !ok || labels[ownershipOwnerLabel] != r.ownerID {
+				continue
+			}
+			names[strings.TrimPrefix(normalizeDNSName(endpoint.DNSName), ownershipRecordPrefix)] = labels[ownershipResourceLabel]
+		}
+	}
+	return names
+}
+
+// ownershipResource returns the resource recorded in the ownership records of
+// the names of the record, i.e. its traffic key, or its cluster, namespace and
+// name when it was not created for a traffic object.
+func ownershipResource(record *v1.DNSRecord) string {
+	if resource := record.Annotations[annotationTrafficKey]; resource != "" {
+		return resource
+	}
+	return logicalcluster.From(record).String() + "|" + record.Namespace + "/" + record.Name
+}
+
+// managedNames returns the sorted names of the endpoints, excluding the
+// ownership records.
+func managedNames(endpoints []*v1.Endpoint) []string {
+	seen := map[string]struct{}{}
+	var names []string
+	for _, endpoint := range endpoints {
+		name := normalizeDNSName(endpoint.DNSName)
+		if isOwnershipRecord(endpoint) {
+			continue
+		}
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func isOwnershipRecord(endpoint *v1.Endpoint) bool {
+	return endpoint.RecordType == string(v1.TXTRecordType) && strings.HasPrefix(normalizeDNSName(endpoint.DNSName), ownershipRecordPrefix)
+}
+
+func ownershipRecordName(name string) string {
+	return ownershipRecordPrefix + name
+}
+
+// parseOwnershipLabels parses the value of an ownership record, e.g.
+// "heritage=kcp-glbc,kcp-glbc/owner=glbc-1,kcp-glbc/resource=ingress-1".
+// It returns false if the value is not an ownership record written by a GLBC.
+func parseOwnershipLabels(value string) (map[string]string, bool) {
+	if unquoted, err := strconv.Unquote(value); err == nil {
+		value = unquoted
+	}
+	labels := map[string]string{}
+	for _, pair := range strings.Split(value, ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			return nil, false
+		}
+		labels[kv[0]] = kv[1]
+	}
+	if labels[ownershipHeritageLabel] != ownershipHeritage {
+		return nil, false
+	}
+	return labels, true
+}
+
+// normalizeDNSName returns the lower case name, without the trailing dot.
+func normalizeDNSName(name string) string {
+	return strings.ToLower(strings.TrimSuffix(name, "."))
+}
@@ -0,0 +1,277 @@
+package dns
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	v1 "github.com/kuadrant/kcp-glbc/pkg/apis/kuadrant/v1"
+	dnsExternalDNS "github.com/kuadrant/kcp-glbc/pkg/dns/externaldns"
+	dnsMemory "github.com/kuadrant/kcp-glbc/pkg/dns/memory"
+)
+
+func newTestMemoryProvider(t *testing.T) *dnsMemory.Provider {
+	provider, err := dnsMemory.NewProvider(dnsMemory.Config{Address: "127.0.0.1", Port: -1})
+	if err != nil {
+		t.Fatalf("unexpected error creating provider: %v", err)
+	}
+	t.Cleanup(func() { _ = provider.Shutdown() })
+	return provider
+}
+
+func newTestRecord(name string, endpoints ...*v1.Endpoint) *v1.DNSRecord {
+	return &v1.DNSRecord{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Annotations: map[string]string{annotationTrafficKey: "root:org:ws|default/" + name},
+		},
+		Spec: v1.DNSRecordSpec{Endpoints: endpoints},
+	}
+}
+
+func aEndpoint(name, ip string) *v1.Endpoint {
+	return &v1.Endpoint{
+		DNSName:    name,
+		RecordType: string(v1.ARecordType),
+		RecordTTL:  60,
+		Targets:    v1.Targets{ip},
+	}
+}
+
+func TestRegistryEnsureWritesOwnershipRecords(t *testing.T) {
+	g := gomega.NewWithT(t)
+	provider := newTestMemoryProvider(t)
+	zone := v1.DNSZone{ID: "example.com"}
+	r := NewRegistry("glbc-1", provider)
+
+	record := newTestRecord("app", aEndpoint("app.example.com", "10.0.0.1"))
+	endpoints, err := r.Ensure(context.TODO(), record, zone)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(endpoints).To(gomega.HaveLen(2))
+
+	txt, err := provider.GetRecords(context.TODO(), zone, "_glbc-owner.app.example.com", string(v1.TXTRecordType))
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(txt).To(gomega.HaveLen(1))
+	g.Expect(txt[0].Targets).To(gomega.Equal(v1.Targets{`"heritage=kcp-glbc,kcp-glbc/owner=glbc-1,kcp-glbc/resource=root:org:ws|default/app"`}))
+
+	// The ownership records of the names no longer managed are cleaned up
+	record.Status.Zones = []v1.DNSZoneStatus{{DNSZone: zone, Endpoints: endpoints}}
+	record.Spec.Endpoints = []*v1.Endpoint{aEndpoint("www.example.com", "10.0.0.1")}
+	_, err = r.Ensure(context.TODO(), record, zone)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	txt, err = provider.GetRecords(context.TODO(), zone, "_glbc-owner.app.example.com", string(v1.TXTRecordType))
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(txt).To(gomega.BeEmpty())
+	txt, err = provider.GetRecords(context.TODO(), zone, "_glbc-owner.www.example.com", string(v1.TXTRecordType))
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(txt).To(gomega.HaveLen(1))
+}
+
+func TestRegistryRefusesForeignNames(t *testing.T) {
+	g := gomega.NewWithT(t)
+	provider := newTestMemoryProvider(t)
+	zone := v1.DNSZone{ID: "example.com"}
+
+	// A name owned by another GLBC
+	_, err := NewRegistry("glbc-1", provider).Ensure(context.TODO(), newTestRecord("app", aEndpoint("app.example.com", "10.0.0.1")), zone)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	other := NewRegistry("glbc-2", provider)
+	record := newTestRecord("app", aEndpoint("app.example.com", "10.0.0.2"))
+	_, err = other.Ensure(context.TODO(), record, zone)
+	g.Expect(err).To(gomega.MatchError(&OwnershipConflictError{DNSName: "app.example.com", Owner: "glbc-1"}))
+	g.Expect(other.Delete(context.TODO(), record, zone)).To(gomega.MatchError(&OwnershipConflictError{DNSName: "app.example.com", Owner: "glbc-1"}))
+
+	a, err := provider.GetRecords(context.TODO(), zone, "app.example.com", string(v1.ARecordType))
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(a).To(gomega.HaveLen(1))
+	g.Expect(a[0].Targets).To(gomega.Equal(v1.Targets{"10.0.0.1"}))
+
+	// A name used by records without ownership record
+	g.Expect(provider.Ensure(context.TODO(), newTestRecord("manual", aEndpoint("manual.example.com", "10.0.0.3")), zone)).To(gomega.Succeed())
+	_, err = other.Ensure(context.TODO(), newTestRecord("manual", aEndpoint("manual.example.com", "10.0.0.4")), zone)
+	g.Expect(err).To(gomega.MatchError(&OwnershipConflictError{DNSName: "manual.example.com"}))
+
+	// A name only used by TXT or CAA records without ownership record
+	txt := &v1.Endpoint{DNSName: "txt.example.com", RecordType: string(v1.TXTRecordType), RecordTTL: 60, Targets: v1.Targets{"\"v=spf1 -all\""}}
+	g.Expect(provider.Ensure(context.TODO(), newTestRecord("manual", txt), zone)).To(gomega.Succeed())
+	_, err = other.Ensure(context.TODO(), newTestRecord("txt", aEndpoint("txt.example.com", "10.0.0.5")), zone)
+	g.Expect(err).To(gomega.MatchError(&OwnershipConflictError{DNSName: "txt.example.com"}))
+
+	caa := &v1.Endpoint{DNSName: "caa.example.com", RecordType: string(v1.CAARecordType), RecordTTL: 60, Targets: v1.Targets{"0 issue \"letsencrypt.org\""}}
+	g.Expect(provider.Ensure(context.TODO(), newTestRecord("manual", caa), zone)).To(gomega.Succeed())
+	_, err = other.Ensure(context.TODO(), newTestRecord("caa", aEndpoint("caa.example.com", "10.0.0.6")), zone)
+	g.Expect(err).To(gomega.MatchError(&OwnershipConflictError{DNSName: "caa.example.com"}))
+}
+
+func TestRegistryRefusesNamesOfOtherRecords(t *testing.T) {
+	g := gomega.NewWithT(t)
+	provider := newTestMemoryProvider(t)
+	zone := v1.DNSZone{ID: "example.com"}
+	r := NewRegistry("glbc-1", provider)
+
+	_, err := r.Ensure(context.TODO(), newTestRecord("app", aEndpoint("app.example.com", "10.0.0.1")), zone)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	// Another DNSRecord of the same GLBC, both with the ownership cached and looked up
+	record := newTestRecord("other", aEndpoint("app.example.com", "10.0.0.2"))
+	conflict := &OwnershipConflictError{DNSName: "app.example.com", Owner: "glbc-1", Resource: "root:org:ws|default/app"}
+	_, err = r.Ensure(context.TODO(), record, zone)
+	g.Expect(err).To(gomega.MatchError(conflict))
+	_, err = NewRegistry("glbc-1", provider).Ensure(context.TODO(), record, zone)
+	g.Expect(err).To(gomega.MatchError(conflict))
+	g.Expect(r.Delete(context.TODO(), record, zone)).To(gomega.MatchError(conflict))
+
+	a, err := provider.GetRecords(context.TODO(), zone, "app.example.com", string(v1.ARecordType))
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(a).To(gomega.HaveLen(1))
+	g.Expect(a[0].Targets).To(gomega.Equal(v1.Targets{"10.0.0.1"}))
+}
+
+// countingProvider counts the lookups of the records.
+type countingProvider struct {
+	Provider
+	lookups int
+}
+
+func (p *countingProvider) GetRecords(ctx context.Context, zone v1.DNSZone, dnsName, recordType string) ([]*v1.Endpoint, error) {
+	p.lookups++
+	return p.Provider.GetRecords(ctx, zone, dnsName, recordType)
+}
+
+func TestRegistryCachesOwnership(t *testing.T) {
+	g := gomega.NewWithT(t)
+	fakeClock := clocktesting.NewFakeClock(time.Now())
+	previous := clock
+	clock = fakeClock
+	t.Cleanup(func() { clock = previous })
+
+	provider := &countingProvider{Provider: newTestMemoryProvider(t)}
+	zone := v1.DNSZone{ID: "example.com"}
+	r := NewRegistry("glbc-1", provider)
+
+	record := newTestRecord("app", aEndpoint("app.example.com", "10.0.0.1"), aEndpoint("www.example.com", "10.0.0.1"))
+	endpoints, err := r.Ensure(context.TODO(), record, zone)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	// The ownership record and the A, AAAA, CNAME, TXT and CAA records of each name
+	g.Expect(provider.lookups).To(gomega.Equal(12))
+
+	record.Status.Zones = []v1.DNSZoneStatus{{DNSZone: zone, Endpoints: endpoints}}
+	provider.lookups = 0
+	_, err = r.Ensure(context.TODO(), record, zone)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(provider.lookups).To(gomega.BeZero())
+
+	// The ownership is looked up again once the cache has expired
+	fakeClock.Step(ownershipCacheTTL)
+	_, err = r.Ensure(context.TODO(), record, zone)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(provider.lookups).To(gomega.Equal(2))
+
+	// The deleted names are evicted
+	g.Expect(r.Delete(context.TODO(), record, zone)).To(gomega.Succeed())
+	g.Expect(r.isOwned(zone, "app.example.com", ownershipResource(record))).To(gomega.BeFalse())
+}
+
+func TestRegistryAdoptsPreviouslyPublishedNames(t *testing.T) {
+	g := gomega.NewWithT(t)
+	provider := newTestMemoryProvider(t)
+	zone := v1.DNSZone{ID: "example.com"}
+
+	// Records published before the ownership records were introduced
+	record := newTestRecord("app", aEndpoint("app.example.com", "10.0.0.1"))
+	g.Expect(provider.Ensure(context.TODO(), record, zone)).To(gomega.Succeed())
+	record.Status.Zones = []v1.DNSZoneStatus{{DNSZone: zone, Endpoints: record.Spec.Endpoints}}
+
+	_, err := NewRegistry("glbc-1", provider).Ensure(context.TODO(), record, zone)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	txt, err := provider.GetRecords(context.TODO(), zone, "_glbc-owner.app.example.com", string(v1.TXTRecordType))
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(txt).To(gomega.HaveLen(1))
+}
+
+func TestParseOwnershipLabels(t *testing.T) {
+	cases := []struct {
+		Name   string
+		Value  string
+		Owner  string
+		Parsed bool
+	}{
+		{
+			Name:   "quoted ownership record",
+			Value:  `"heritage=kcp-glbc,kcp-glbc/owner=glbc-1,kcp-glbc/resource=app"`,
+			Owner:  "glbc-1",
+			Parsed: true,
+		},
+		{
+			Name:   "unquoted ownership record",
+			Value:  "heritage=kcp-glbc,kcp-glbc/owner=glbc-1,kcp-glbc/resource=app",
+			Owner:  "glbc-1",
+			Parsed: true,
+		},
+		{
+			Name:  "external-dns ownership record",
+			Value: `"heritage=external-dns,external-dns/owner=default"`,
+		},
+		{
+			Name:  "other TXT record",
+			Value: `"v=spf1 -all"`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+			labels, ok := parseOwnershipLabels(tc.Value)
+			g.Expect(ok).To(gomega.Equal(tc.Parsed))
+			if tc.Parsed {
+				g.Expect(labels[ownershipOwnerLabel]).To(gomega.Equal(tc.Owner))
+			}
+		})
+	}
+}
+
+// registeringProvider records the ownership of the names itself, like the
+// external-dns provider.
+type registeringProvider struct {
+	Provider
+}
+
+func (p *registeringProvider) RegistersOwnership() bool {
+	return true
+}
+
+func TestRegistryBypassedForRegisteringProviders(t *testing.T) {
+	g := gomega.NewWithT(t)
+	provider := newTestMemoryProvider(t)
+	zone := v1.DNSZone{ID: "example.com"}
+	r := NewRegistry("glbc-1", &registeringProvider{Provider: provider})
+	g.Expect(RegistersOwnership(&dnsExternalDNS.Provider{})).To(gomega.BeTrue())
+	g.Expect(RegistersOwnership(provider)).To(gomega.BeFalse())
+
+	// The names are published without ownership records
+	record := newTestRecord("app", aEndpoint("app.example.com", "10.0.0.1"))
+	endpoints, err := r.Ensure(context.TODO(), record, zone)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(endpoints).To(gomega.Equal(record.Spec.Endpoints))
+	g.Expect(provider.GetRecords(context.TODO(), zone, "_glbc-owner.app.example.com", string(v1.TXTRecordType))).To(gomega.BeEmpty())
+
+	// The ownership of the names is left to the provider
+	g.Expect(provider.Ensure(context.TODO(), newTestRecord("manual", aEndpoint("manual.example.com", "10.0.0.2")), zone)).To(gomega.Succeed())
+	_, err = r.Ensure(context.TODO(), newTestRecord("manual", aEndpoint("manual.example.com", "10.0.0.3")), zone)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	g.Expect(r.Delete(context.TODO(), record, zone)).To(gomega.Succeed())
+	g.Expect(provider.GetRecords(context.TODO(), zone, "app.example.com", string(v1.ARecordType))).To(gomega.BeEmpty())
+
+	// The zones are not audited
+	_, err = NewAuditor(&AuditorConfig{Controllers: []*Controller{{}}, Registry: r})
+	g.Expect(err).To(gomega.HaveOccurred())
+}
@@ -0,0 +1,447 @@
+package rfc2136
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/miekg/dns"
+
+	"github.com/kuadrant/kcp-glbc/pkg/_internal/log"
+	v1 "github.com/kuadrant/kcp-glbc/pkg/apis/kuadrant/v1"
+	dnserrors "github.com/kuadrant/kcp-glbc/pkg/dns/errors"
+)
+
+const (
+	NameserverEnvVar    = "RFC2136_NAMESERVER"
+	TSIGKeyNameEnvVar   = "RFC2136_TSIG_KEY_NAME"
+	TSIGSecretEnvVar    = "RFC2136_TSIG_SECRET"
+	TSIGAlgorithmEnvVar = "RFC2136_TSIG_ALGORITHM"
+	ZoneIDEnvVar        = "RFC2136_ZONE"
+
+	defaultTimeout = 10 * time.Second
+	tsigFudge      = 300
+)
+
+// Provider publishes DNSRecords to an authoritative name server using RFC 2136
+// dynamic updates, optionally authenticated with TSIG (RFC 2845).
+// The zone ID is expected to be the name of the zone, e.g. example.com.
+//
+// Plain DNS has no weighted routing policy: endpoints sharing the same name
+// and type are published as a single RRset, holding all the targets with a
+// non-zero weight.
+type Provider struct {
+	client *dns.Client
+	config Config
+	logger logr.Logger
+}
+
+// Config is the necessary input to configure the provider.
+type Config struct {
+	// Nameserver is the host:port of the name server accepting dynamic updates.
+	Nameserver string
+	// Net is the transport used to send the updates, "tcp" or "udp". Defaults to "tcp".
+	Net string
+	// TSIGKeyName, TSIGSecret and TSIGAlgorithm configure the signing of the
+	// updates. Updates are not signed when TSIGKeyName is empty.
+	TSIGKeyName   string
+	TSIGSecret    string
+	TSIGAlgorithm string
+	// Timeout of the update exchanges. Defaults to 10 seconds.
+	Timeout time.Duration
+}
+
+func NewProvider(config Config) (*Provider, error) {
+	if config.Nameserver == "" {
+		return nil, fmt.Errorf("nameserver is required, set %s", NameserverEnvVar)
+	}
+	if _, _, err := net.SplitHostPort(config.Nameserver); err != nil {
+		config.Nameserver = net.JoinHostPort(config.Nameserver, "53")
+	}
+	if config.Net == "" {
+		config.Net = "tcp"
+	}
+	if config.Timeout == 0 {
+		config.Timeout = defaultTimeout
+	}
+
+	client := &dns.Client{
+		Net:     config.Net,
+		Timeout: config.Timeout,
+	}
+
+	if config.TSIGKeyName != "" {
+		if config.TSIGSecret == "" {
+			return nil, fmt.Errorf("TSIG secret is required when a TSIG key name is set, set %s", TSIGSecretEnvVar)
+		}
+		algorithm, err := tsigAlgorithm(config.TSIGAlgorithm)
+		if err != nil {
+			return nil, err
+		}
+		config.TSIGKeyName = dns.Fqdn(config.TSIGKeyName)
+		config.TSIGAlgorithm = algorithm
+		client.TsigSecret = map[string]string{config.TSIGKeyName: config.TSIGSecret}
+	}
+
+	return &Provider{
+		client: client,
+		config: config,
+		logger: log.Logger.WithName("rfc2136").WithValues("nameserver", config.Nameserver),
+	}, nil
+}
+
+func (p *Provider) Ensure(ctx context.Context, record *v1.DNSRecord, zone v1.DNSZone) error {
+	desired, err := p.rrsForEndpoints(record.Spec.Endpoints)
+	if err != nil {
+		return err
+	}
+
+	expected := make(map[string]struct{}, len(desired))
+	for _, rr := range desired {
+		expected[rrKey(rr)] = struct{}{}
+	}
+
+	// Delete any previously published records that are no longer present in record.Spec.Endpoints
+	published, err := rrsForEndpoints(endpointsFromZoneStatus(record, zone.ID), true)
+	if err != nil {
+		return err
+	}
+	var stale []dns.RR
+	for _, rr := range published {
+		if _, found := expected[rrKey(rr)]; !found {
+			stale = append(stale, rr)
+		}
+	}
+
+	if len(stale) == 0 && len(desired) == 0 {
+		return nil
+	}
+
+	m := new(dns.Msg)
+	m.SetUpdate(dns.Fqdn(zone.ID))
+	if len(stale) > 0 {
+		m.Remove(stale)
+	}
+	m.Insert(desired)
+
+	if err := p.exchange(ctx, m); err != nil {
+		return fmt.Errorf("failed to update record in zone %s: %w", zone.ID, err)
+	}
+
+	p.logger.Info("Upserted DNS record", "record", record.Spec, "zone", zone)
+	return nil
+}
+
+func (p *Provider) Delete(ctx context.Context, record *v1.DNSRecord, zone v1.DNSZone) error {
+	rrs, err := rrsForEndpoints(record.Spec.Endpoints, true)
+	if err != nil {
+		return err
+	}
+	if len(rrs) == 0 {
+		return nil
+	}
+
+	m := new(dns.Msg)
+	m.SetUpdate(dns.Fqdn(zone.ID))
+	m.Remove(rrs)
+
+	if err := p.exchange(ctx, m); err != nil {
+		return fmt.Errorf("failed to delete record in zone %s: %w", zone.ID, err)
+	}
+
+	p.logger.Info("Deleted DNS record", "record", record.Spec, "zone", zone)
+	return nil
+}
+
+// GetRecords queries the name server for the records of the zone with the
+// given name and type.
+func (p *Provider) GetRecords(ctx context.Context, zone v1.DNSZone, dnsName, recordType string) ([]*v1.Endpoint, error) {
+	qtype, ok := dns.StringToType[recordType]
+	if !ok {
+		return nil, fmt.Errorf("unsupported record type %s", recordType)
+	}
+
+	m := new(dns.Msg)
+	m.SetQuestion(dns.Fqdn(dnsName), qtype)
+	m.RecursionDesired = false
+
+	r, _, err := p.client.ExchangeContext(ctx, m, p.config.Nameserver)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query records %s in zone %s: %w", dnsName, zone.ID, err)
+	}
+	switch r.Rcode {
+	case dns.RcodeSuccess:
+	case dns.RcodeNameError:
+		return nil, nil
+	default:
+		return nil, dnserrors.Errorf(reasonForRcode(r.Rcode), "query refused by %s: %s", p.config.Nameserver, dns.RcodeToString[r.Rcode])
+	}
+
+	var rrs []dns.RR
+	for _, rr := range r.Answer {
+		if rr.Header().Rrtype == qtype && strings.EqualFold(rr.Header().Name, dns.Fqdn(dnsName)) {
+			rrs = append(rrs, rr)
+		}
+	}
+	return endpointsForRRs(rrs), nil
+}
+
+// ListRecords transfers the zone from the name server, with an AXFR request,
+// and returns all its records.
+func (p *Provider) ListRecords(ctx context.Context, zone v1.DNSZone) ([]*v1.Endpoint, error) {
+	m := new(dns.Msg)
+	m.SetAxfr(dns.Fqdn(zone.ID))
+	if p.config.TSIGKeyName != "" {
+		m.SetTsig(p.config.TSIGKeyName, p.config.TSIGAlgorithm, tsigFudge, time.Now().Unix())
+	}
+
+	transfer := &dns.Transfer{
+		DialTimeout:  p.config.Timeout,
+		ReadTimeout:  p.config.Timeout,
+		WriteTimeout: p.config.Timeout,
+		TsigSecret:   p.client.TsigSecret,
+	}
+	envelopes, err := transfer.In(m, p.config.Nameserver)
+	if err != nil {
+		return nil, fmt.Errorf("failed to transfer zone %s: %w", zone.ID, err)
+	}
+	var rrs []dns.RR
+	for envelope := range envelopes {
+		if envelope.Error != nil {
+			return nil, fmt.Errorf("failed to transfer zone %s: %w", zone.ID, envelope.Error)
+		}
+		rrs = append(rrs, envelope.RR...)
+	}
+	return endpointsForRRs(rrs), nil
+}
+
+// endpointsForRRs returns an endpoint per name and type of the resource
+// records, skipping the types that are not supported by the provider.
+func endpointsForRRs(rrs []dns.RR) []*v1.Endpoint {
+	var endpoints []*v1.Endpoint
+	byKey := map[string]*v1.Endpoint{}
+	for _, rr := range rrs {
+		var target string
+		switch rr := rr.(type) {
+		case *dns.A:
+			target = rr.A.String()
+		case *dns.AAAA:
+			target = rr.AAAA.String()
+		case *dns.CNAME:
+			target = strings.TrimSuffix(rr.Target, ".")
+		case *dns.TXT:
+			target = strconv.Quote(strings.Join(rr.Txt, ""))
+		case *dns.CAA:
+			target = v1.NewCAATarget(rr.Flag, rr.Tag, rr.Value)
+		default:
+			continue
+		}
+
+		name := strings.ToLower(strings.TrimSuffix(rr.Header().Name, "."))
+		recordType := dns.TypeToString[rr.Header().Rrtype]
+		endpoint, ok := byKey[name+"/"+recordType]
+		if !ok {
+			endpoint = &v1.Endpoint{DNSName: name, RecordType: recordType}
+			byKey[name+"/"+recordType] = endpoint
+			endpoints = append(endpoints, endpoint)
+		}
+		endpoint.RecordTTL = v1.TTL(rr.Header().Ttl)
+		endpoint.Targets = append(endpoint.Targets, target)
+	}
+	return endpoints
+}
+
+// RequiresHealthProbes returns true, as the RFC 2136 provider has no health checks
+// of its own, the endpoints are probed by the GLBC instead.
+func (p *Provider) RequiresHealthProbes() bool {
+	return true
+}
+
+// ReconcileHealthCheck is a no-op, RFC 2136 has no notion of health checks.
+func (p *Provider) ReconcileHealthCheck(ctx context.Context, _ v1.EndpointHealthCheck, endpoint *v1.Endpoint) error {
+	p.logger.V(3).Info("Health checks are not supported by the RFC 2136 provider, skipping", "endpoint", endpoint.SetID())
+	return nil
+}
+
+func (p *Provider) DeleteHealthCheck(ctx context.Context, _ *v1.Endpoint) error {
+	return nil
+}
+
+func (p *Provider) exchange(ctx context.Context, m *dns.Msg) error {
+	if p.config.TSIGKeyName != "" {
+		m.SetTsig(p.config.TSIGKeyName, p.config.TSIGAlgorithm, tsigFudge, time.Now().Unix())
+	}
+
+	r, _, err := p.client.ExchangeContext(ctx, m, p.config.Nameserver)
+	if err != nil {
+		if errors.Is(err, dns.ErrSig) || errors.Is(err, dns.ErrSecret) || errors.Is(err, dns.ErrKeyAlg) {
+			return dnserrors.New(dnserrors.ReasonPermissionDenied, err)
+		}
+		return err
+	}
+	if r.Rcode != dns.RcodeSuccess {
+		return dnserrors.Errorf(reasonForRcode(r.Rcode), "dynamic update refused by %s: %s", p.config.Nameserver, dns.RcodeToString[r.Rcode])
+	}
+	return nil
+}
+
+// reasonForRcode returns the reason of an error response of the nameserver.
+func reasonForRcode(rcode int) dnserrors.Reason {
+	switch rcode {
+	case dns.RcodeRefused, dns.RcodeNotAuth, dns.RcodeBadSig, dns.RcodeBadKey, dns.RcodeBadTime:
+		return dnserrors.ReasonPermissionDenied
+	case dns.RcodeNotZone:
+		return dnserrors.ReasonZoneNotFound
+	case dns.RcodeFormatError:
+		return dnserrors.ReasonInvalidRecord
+	case dns.RcodeNXRrset:
+		return dnserrors.ReasonNotFound
+	}
+	return dnserrors.ReasonUnknown
+}
+
+// rrsForEndpoints returns the resource records of the endpoints expected to
+// receive traffic. If none of the endpoints has a non-zero weight, all of them
+// are returned.
+func (p *Provider) rrsForEndpoints(endpoints []*v1.Endpoint) ([]dns.RR, error) {
+	rrs, err := rrsForEndpoints(endpoints, false)
+	if err != nil {
+		return nil, err
+	}
+	if len(rrs) == 0 {
+		return rrsForEndpoints(endpoints, true)
+	}
+	return rrs, nil
+}
+
+func rrsForEndpoints(endpoints []*v1.Endpoint, includeZeroWeight bool) ([]dns.RR, error) {
+	var rrs []dns.RR
+	seen := map[string]struct{}{}
+	for _, endpoint := range endpoints {
+		if err := validateEndpoint(endpoint); err != nil {
+			return nil, err
+		}
+		if !includeZeroWeight && !hasWeight(endpoint) {
+			continue
+		}
+		for _, target := range endpoint.Targets {
+			rr, err := newRR(endpoint, target)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := seen[rrKey(rr)]; ok {
+				continue
+			}
+			seen[rrKey(rr)] = struct{}{}
+			rrs = append(rrs, rr)
+		}
+	}
+	return rrs, nil
+}
+
+func newRR(endpoint *v1.Endpoint, target string) (dns.RR, error) {
+	header := dns.RR_Header{
+		Name:  dns.Fqdn(endpoint.DNSName),
+		Class: dns.ClassINET,
+		Ttl:   uint32(endpoint.RecordTTL),
+	}
+
+	switch endpoint.RecordType {
+	case string(v1.ARecordType):
+		ip := net.ParseIP(target).To4()
+		if ip == nil {
+			return nil, dnserrors.Errorf(dnserrors.ReasonInvalidRecord, "invalid A record target %s", target)
+		}
+		header.Rrtype = dns.TypeA
+		return &dns.A{Hdr: header, A: ip}, nil
+	case string(v1.AAAARecordType):
+		ip := net.ParseIP(target)
+		if ip == nil || ip.To4() != nil {
+			return nil, dnserrors.Errorf(dnserrors.ReasonInvalidRecord, "invalid AAAA record target %s", target)
+		}
+		header.Rrtype = dns.TypeAAAA
+		return &dns.AAAA{Hdr: header, AAAA: ip}, nil
+	case string(v1.CNAMERecordType):
+		header.Rrtype = dns.TypeCNAME
+		return &dns.CNAME{Hdr: header, Target: dns.Fqdn(target)}, nil
+	case string(v1.TXTRecordType):
+		header.Rrtype = dns.TypeTXT
+		if unquoted, err := strconv.Unquote(target); err == nil {
+			target = unquoted
+		}
+		return &dns.TXT{Hdr: header, Txt: []string{target}}, nil
+	case string(v1.CAARecordType):
+		flags, tag, value, err := v1.ParseCAATarget(target)
+		if err != nil {
+			return nil, dnserrors.Errorf(dnserrors.ReasonInvalidRecord, "%v", err)
+		}
+		header.Rrtype = dns.TypeCAA
+		return &dns.CAA{Hdr: header, Flag: flags, Tag: tag, Value: value}, nil
+	}
+	return nil, fmt.Errorf("unsupported record type %s", endpoint.RecordType)
+}
+
+// rrKey identifies a resource record by its name, type and data, regardless
+// of its TTL.
+func rrKey(rr dns.RR) string {
+	header := rr.Header()
+	return strings.ToLower(header.Name) + "/" + dns.TypeToString[header.Rrtype] + "/" + strings.TrimPrefix(rr.String(), header.String())
+}
+
+// hasWeight returns false if the endpoint has been given a weight of 0, and
+// is therefore not expected to receive traffic.
+func hasWeight(endpoint *v1.Endpoint) bool {
+	policy := endpoint.GetRoutingPolicy()
+	return policy == nil || policy.Weighted == nil || policy.Weighted.Weight > 0
+}
+
+func validateEndpoint(endpoint *v1.Endpoint) error {
+	switch endpoint.RecordType {
+	case string(v1.ARecordType), string(v1.AAAARecordType), string(v1.CNAMERecordType), string(v1.TXTRecordType), string(v1.CAARecordType):
+	default:
+		return dnserrors.Errorf(dnserrors.ReasonInvalidRecord, "unsupported record type %s", endpoint.RecordType)
+	}
+	if len(endpoint.DNSName) == 0 {
+		return dnserrors.Errorf(dnserrors.ReasonInvalidRecord, "domain is required")
+	}
+	if len(endpoint.Targets) == 0 {
+		return dnserrors.Errorf(dnserrors.ReasonInvalidRecord, "targets is required")
+	}
+	if endpoint.RecordType == string(v1.CAARecordType) {
+		for _, target := range endpoint.Targets {
+			if _, _, _, err := v1.ParseCAATarget(target); err != nil {
+				return dnserrors.Errorf(dnserrors.ReasonInvalidRecord, "%v", err)
+			}
+		}
+	}
+	if endpoint.RecordType == string(v1.CNAMERecordType) && len(endpoint.Targets) > 1 {
+		return dnserrors.Errorf(dnserrors.ReasonInvalidRecord, "CNAME record %s must have a single target", endpoint.DNSName)
+	}
+	return nil
+}
+
+func tsigAlgorithm(name string) (string, error) {
+	if name == "" {
+		return dns.HmacSHA256, nil
+	}
+	algorithm := dns.Fqdn(strings.ToLower(name))
+	switch algorithm {
+	case dns.HmacSHA1, dns.HmacSHA256, dns.HmacSHA512:
+		return algorithm, nil
+	}
+	return "", fmt.Errorf("unsupported TSIG algorithm %s", name)
+}
+
+func endpointsFromZoneStatus(record *v1.DNSRecord, zoneID string) []*v1.Endpoint {
+	for _, zoneStatus := range record.Status.Zones {
+		if zoneStatus.DNSZone.ID == zoneID {
+			return zoneStatus.Endpoints
+		}
+	}
+	return []*v1.Endpoint{}
+}
@@ -0,0 +1,223 @@
+package rfc2136
+
+import (
+	"context"
+	"net"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/onsi/gomega"
+
+	v1 "github.com/kuadrant/kcp-glbc/pkg/apis/kuadrant/v1"
+)
+
+const (
+	testKeyName = "glbc-key."
+	testSecret  = "c2VjcmV0LXNlY3JldC1zZWNyZXQ="
+)
+
+// fakeNameserver is an in-process name server applying the dynamic updates
+// it receives to an in-memory set of records.
+type fakeNameserver struct {
+	mu      sync.Mutex
+	records map[string]dns.RR
+	updates int
+}
+
+func (f *fakeNameserver) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	m := new(dns.Msg)
+	m.SetReply(r)
+
+	if r.IsTsig() == nil || w.TsigStatus() != nil {
+		m.Rcode = dns.RcodeRefused
+		_ = w.WriteMsg(m)
+		return
+	}
+
+	f.updates++
+	for _, rr := range r.Ns {
+		switch rr.Header().Class {
+		case dns.ClassINET:
+			f.records[rrKey(rr)] = rr
+		case dns.ClassNONE:
+			delete(f.records, rrKey(rr))
+		}
+	}
+
+	m.SetTsig(testKeyName, dns.HmacSHA256, tsigFudge, int64(r.IsTsig().TimeSigned))
+	_ = w.WriteMsg(m)
+}
+
+func (f *fakeNameserver) data() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var data []string
+	for _, rr := range f.records {
+		data = append(data, rr.String())
+	}
+	sort.Strings(data)
+	return data
+}
+
+func newTestProvider(t *testing.T, secret string) (*Provider, *fakeNameserver) {
+	fake := &fakeNameserver{records: map[string]dns.RR{}}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error listening: %v", err)
+	}
+
+	started := make(chan struct{})
+	server := &dns.Server{
+		Listener:          listener,
+		Handler:           fake,
+		TsigSecret:        map[string]string{testKeyName: testSecret},
+		NotifyStartedFunc: func() { close(started) },
+		MsgAcceptFunc: func(dh dns.Header) dns.MsgAcceptAction {
+			return dns.MsgAccept
+		},
+	}
+	go func() { _ = server.ActivateAndServe() }()
+	t.Cleanup(func() { _ = server.Shutdown() })
+	<-started
+
+	provider, err := NewProvider(Config{
+		Nameserver:  listener.Addr().String(),
+		TSIGKeyName: "glbc-key",
+		TSIGSecret:  secret,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error creating provider: %v", err)
+	}
+	return provider, fake
+}
+
+func weightedEndpoint(ip string, weight int64) *v1.Endpoint {
+	return &v1.Endpoint{
+		DNSName:       "app.example.com",
+		RecordType:    string(v1.ARecordType),
+		RecordTTL:     60,
+		SetIdentifier: ip,
+		Targets:       v1.Targets{ip},
+		RoutingPolicy: &v1.RoutingPolicy{Weighted: &v1.WeightedRoutingPolicy{Weight: weight}},
+	}
+}
+
+func TestEnsure(t *testing.T) {
+	g := gomega.NewWithT(t)
+	provider, fake := newTestProvider(t, testSecret)
+	zone := v1.DNSZone{ID: "example.com"}
+
+	record := &v1.DNSRecord{
+		Spec: v1.DNSRecordSpec{
+			Endpoints: []*v1.Endpoint{
+				weightedEndpoint("10.0.0.1", 120),
+				weightedEndpoint("10.0.0.2", 60),
+				weightedEndpoint("10.0.0.3", 0),
+			},
+		},
+	}
+
+	g.Expect(provider.Ensure(context.TODO(), record, zone)).To(gomega.Succeed())
+	g.Expect(fake.data()).To(gomega.Equal([]string{
+		"app.example.com.\t60\tIN\tA\t10.0.0.1",
+		"app.example.com.\t60\tIN\tA\t10.0.0.2",
+	}))
+
+	// Records no longer present in the spec are removed based on the zone status
+	record.Status.Zones = []v1.DNSZoneStatus{{DNSZone: zone, Endpoints: record.Spec.Endpoints}}
+	record.Spec.Endpoints = []*v1.Endpoint{
+		weightedEndpoint("10.0.0.2", 120),
+		{
+			DNSName:    "www.example.com",
+			RecordType: string(v1.CNAMERecordType),
+			RecordTTL:  60,
+			Targets:    v1.Targets{"app.example.com"},
+		},
+		{
+			DNSName:    "app.example.com",
+			RecordType: string(v1.CAARecordType),
+			RecordTTL:  300,
+			Targets:    v1.Targets{`0 issue "letsencrypt.org"`},
+		},
+	}
+
+	g.Expect(provider.Ensure(context.TODO(), record, zone)).To(gomega.Succeed())
+	g.Expect(fake.data()).To(gomega.Equal([]string{
+		"app.example.com.\t300\tIN\tCAA\t0 issue \"letsencrypt.org\"",
+		"app.example.com.\t60\tIN\tA\t10.0.0.2",
+		"www.example.com.\t60\tIN\tCNAME\tapp.example.com.",
+	}))
+}
+
+func TestDelete(t *testing.T) {
+	g := gomega.NewWithT(t)
+	provider, fake := newTestProvider(t, testSecret)
+	zone := v1.DNSZone{ID: "example.com"}
+
+	record := &v1.DNSRecord{
+		Spec: v1.DNSRecordSpec{
+			Endpoints: []*v1.Endpoint{
+				weightedEndpoint("10.0.0.1", 120),
+				weightedEndpoint("10.0.0.2", 0),
+			},
+		},
+	}
+
+	g.Expect(provider.Ensure(context.TODO(), record, zone)).To(gomega.Succeed())
+	g.Expect(provider.Delete(context.TODO(), record, zone)).To(gomega.Succeed())
+	g.Expect(fake.data()).To(gomega.BeEmpty())
+	g.Expect(fake.updates).To(gomega.Equal(2))
+}
+
+func TestEnsureRefused(t *testing.T) {
+	g := gomega.NewWithT(t)
+	provider, fake := newTestProvider(t, "d3Jvbmctc2VjcmV0")
+
+	record := &v1.DNSRecord{
+		Spec: v1.DNSRecordSpec{
+			Endpoints: []*v1.Endpoint{weightedEndpoint("10.0.0.1", 120)},
+		},
+	}
+
+	g.Expect(provider.Ensure(context.TODO(), record, v1.DNSZone{ID: "example.com"})).NotTo(gomega.Succeed())
+	g.Expect(fake.data()).To(gomega.BeEmpty())
+}
+
+func TestNewProviderInvalidConfig(t *testing.T) {
+	cases := []struct {
+		Name   string
+		Config Config
+		Error  string
+	}{
+		{
+			Name:   "missing nameserver",
+			Config: Config{},
+			Error:  "nameserver is required, set RFC2136_NAMESERVER",
+		},
+		{
+			Name:   "missing TSIG secret",
+			Config: Config{Nameserver: "127.0.0.1", TSIGKeyName: "key"},
+			Error:  "TSIG secret is required when a TSIG key name is set, set RFC2136_TSIG_SECRET",
+		},
+		{
+			Name:   "unsupported TSIG algorithm",
+			Config: Config{Nameserver: "127.0.0.1", TSIGKeyName: "key", TSIGSecret: testSecret, TSIGAlgorithm: "rsa"},
+			Error:  "unsupported TSIG algorithm rsa",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+			_, err := NewProvider(tc.Config)
+			g.Expect(err).To(gomega.MatchError(tc.Error))
+		})
+	}
+}
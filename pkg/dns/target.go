@@ -1,5 +1,13 @@
 package dns
 
+import (
+	"fmt"
+	"net"
+
+	v1 "github.com/kuadrant/kcp-glbc/pkg/apis/kuadrant/v1"
+	dnsAWS "github.com/kuadrant/kcp-glbc/pkg/dns/aws"
+)
+
 const (
 	TargetTypeHost = "HOST"
 	TargetTypeIP   = "IP"
@@ -9,4 +17,75 @@ type Target struct {
 	Cluster    string
 	TargetType string
 	Value      string
+	// Visibility is whether the target is reachable from the internet, or
+	// only from private networks. It is empty for the hosts whose visibility
+	// is not known, that of the addresses they resolve to.
+	Visibility Visibility
+}
+
+// Visibility is whether a target is reachable from the internet, or only from
+// private networks.
+type Visibility string
+
+const (
+	VisibilityPublic  Visibility = "public"
+	VisibilityPrivate Visibility = "private"
+
+	// LabelVisibility is the label of the endpoints of the private targets, so
+	// that they are only published to the private zones, if any.
+	LabelVisibility = "kuadrant.dev/visibility"
+
+	// LabelCluster is the label of the endpoints of the targets of a cluster,
+	// whose health checks are aggregated by cluster.
+	LabelCluster = "kuadrant.dev/cluster"
+)
+
+// VisibilityForIP returns the private visibility for the private addresses, as
+// defined by RFC 1918 and RFC 4193, and the public visibility otherwise.
+func VisibilityForIP(ip net.IP) Visibility {
+	if ip.IsPrivate() {
+		return VisibilityPrivate
+	}
+	return VisibilityPublic
+}
+
+// IsPrivateEndpoint returns whether the endpoint is the one of a private
+// target.
+func IsPrivateEndpoint(endpoint *v1.Endpoint) bool {
+	return endpoint.Labels[LabelVisibility] == string(VisibilityPrivate)
+}
+
+// HostTargetMode is how the targets given by a host name, e.g. the one of a
+// load balancer, are published.
+type HostTargetMode string
+
+const (
+	// HostTargetModeResolve publishes the addresses the hosts resolve to, and
+	// watches the hosts so that the records follow the changes of addresses.
+	HostTargetModeResolve HostTargetMode = "resolve"
+	// HostTargetModeCNAME publishes the hosts as weighted CNAME records.
+	HostTargetModeCNAME HostTargetMode = "cname"
+	// HostTargetModeAlias publishes the hosts of AWS load balancers as Route53
+	// alias records, and the other hosts as weighted CNAME records.
+	HostTargetModeAlias HostTargetMode = "alias"
+)
+
+// IsLoadBalancerHostname returns whether the host name is the one of an AWS
+// load balancer whose hosted zone is known, that is published as an alias
+// record in the alias host target mode. The load balancers of the other regions
+// are published as CNAME records.
+func IsLoadBalancerHostname(hostname string) bool {
+	return dnsAWS.IsLoadBalancerHostname(hostname)
+}
+
+// ParseHostTargetMode returns the host target mode of the given name, the
+// resolve mode being the default.
+func ParseHostTargetMode(name string) (HostTargetMode, error) {
+	switch mode := HostTargetMode(name); mode {
+	case "":
+		return HostTargetModeResolve, nil
+	case HostTargetModeResolve, HostTargetModeCNAME, HostTargetModeAlias:
+		return mode, nil
+	}
+	return "", fmt.Errorf("invalid host target mode %q, must be one of [%s, %s, %s]", name, HostTargetModeResolve, HostTargetModeCNAME, HostTargetModeAlias)
 }
@@ -0,0 +1,74 @@
+package dns
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// DefaultDeniedTargetCIDRs are the address ranges that are not published to
+// the public zones by default: the private ranges of RFC 1918 and RFC 4193,
+// and the loopback and link-local ranges.
+const DefaultDeniedTargetCIDRs = "10.0.0.0/8,172.16.0.0/12,192.168.0.0/16,127.0.0.0/8,169.254.0.0/16,::1/128,fe80::/10,fc00::/7"
+
+// TargetPolicy is the policy of the target addresses that can be published to
+// the public zones. An address is rejected when it belongs to one of the
+// denied ranges, unless it also belongs to one of the allowed ranges, so that
+// the allowed ranges are exceptions to the denied ones.
+type TargetPolicy struct {
+	Allow []*net.IPNet
+	Deny  []*net.IPNet
+}
+
+// ParseTargetPolicy parses the comma separated lists of allowed and denied
+// CIDRs of a target policy.
+func ParseTargetPolicy(allow, deny string) (*TargetPolicy, error) {
+	allowed, err := parseCIDRs(allow)
+	if err != nil {
+		return nil, fmt.Errorf("invalid allowed target CIDRs: %v", err)
+	}
+	denied, err := parseCIDRs(deny)
+	if err != nil {
+		return nil, fmt.Errorf("invalid denied target CIDRs: %v", err)
+	}
+	return &TargetPolicy{Allow: allowed, Deny: denied}, nil
+}
+
+func parseCIDRs(value string) ([]*net.IPNet, error) {
+	var cidrs []*net.IPNet
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		_, cidr, err := net.ParseCIDR(item)
+		if err != nil {
+			return nil, err
+		}
+		cidrs = append(cidrs, cidr)
+	}
+	return cidrs, nil
+}
+
+// Rejects returns whether the address cannot be published to the public zones.
+// The targets that are not addresses, i.e. the hosts published by name, are
+// never rejected.
+func (p *TargetPolicy) Rejects(address string) bool {
+	if p == nil {
+		return false
+	}
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return false
+	}
+	return containsIP(p.Deny, ip) && !containsIP(p.Allow, ip)
+}
+
+func containsIP(cidrs []*net.IPNet, ip net.IP) bool {
+	for _, cidr := range cidrs {
+		if cidr.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,35 @@
+package dns
+
+import (
+	"testing"
+
+	"github.com/onsi/gomega"
+)
+
+func TestTargetPolicy(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	policy, err := ParseTargetPolicy("10.1.0.0/16", DefaultDeniedTargetCIDRs)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	for _, address := range []string{"10.0.0.1", "172.18.0.2", "192.168.1.1", "127.0.0.1", "169.254.169.254", "::1", "fe80::1", "fd00::1"} {
+		g.Expect(policy.Rejects(address)).To(gomega.BeTrue(), address)
+	}
+	// The allowed ranges are exceptions to the denied ones, and the hosts are
+	// never rejected
+	for _, address := range []string{"10.1.0.1", "203.0.113.1", "2001:db8::1", "lb.example.com"} {
+		g.Expect(policy.Rejects(address)).To(gomega.BeFalse(), address)
+	}
+
+	policy, err = ParseTargetPolicy("", "")
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(policy.Rejects("10.0.0.1")).To(gomega.BeFalse())
+
+	var noPolicy *TargetPolicy
+	g.Expect(noPolicy.Rejects("10.0.0.1")).To(gomega.BeFalse())
+
+	_, err = ParseTargetPolicy("10.0.0.0", "")
+	g.Expect(err).To(gomega.MatchError(gomega.ContainSubstring("invalid allowed target CIDRs")))
+	_, err = ParseTargetPolicy("", "10.0.0.0/33")
+	g.Expect(err).To(gomega.MatchError(gomega.ContainSubstring("invalid denied target CIDRs")))
+}
@@ -0,0 +1,20 @@
+package dns
+
+import (
+	"testing"
+
+	"github.com/onsi/gomega"
+)
+
+func TestIsLoadBalancerHostname(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	g.Expect(IsLoadBalancerHostname("a1b2c3-123456789.us-east-1.elb.amazonaws.com")).To(gomega.BeTrue())
+	g.Expect(IsLoadBalancerHostname("nlb-1-0123456789abcdef.elb.eu-west-1.amazonaws.com.")).To(gomega.BeTrue())
+	g.Expect(IsLoadBalancerHostname("A1B2C3-123456789.US-EAST-1.ELB.AMAZONAWS.COM")).To(gomega.BeTrue())
+	g.Expect(IsLoadBalancerHostname("lb.example.com")).To(gomega.BeFalse())
+	g.Expect(IsLoadBalancerHostname("us-east-1.elb.amazonaws.com")).To(gomega.BeFalse())
+	g.Expect(IsLoadBalancerHostname("bucket.s3.amazonaws.com")).To(gomega.BeFalse())
+	// The load balancers of the regions whose hosted zone is unknown
+	g.Expect(IsLoadBalancerHostname("lb-1.xx-unknown-1.elb.amazonaws.com")).To(gomega.BeFalse())
+}
@@ -0,0 +1,196 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	v1 "github.com/kuadrant/kcp-glbc/pkg/apis/kuadrant/v1"
+)
+
+// Zone is a DNS zone managed by the GLBC, along with the domain of the names
+// published to it.
+type Zone struct {
+	v1.DNSZone
+	// Domain is the suffix of the names published to the zone. An empty domain
+	// matches any name.
+	Domain string
+	// Private is whether the zone is only resolved from private networks, e.g.
+	// a Route53 private hosted zone attached to VPCs. Only the endpoints of the
+	// private targets are published to the private zones, and only the others
+	// to the public zones.
+	Private bool
+}
+
+const (
+	zoneTagsPrefix    = "tags:"
+	zonePrivatePrefix = "private:"
+)
+
+// ParseZones parses a comma separated list of zones, each given as
+// <domain>=<zone id>, e.g. "dev.hcpapps.net=Z1,example.com=Z2". A zone can
+// also be given by its tags, as <domain>=tags:<key>=<value>;<key>=<value>, for
+// the providers that can find zones by tags. The private zones are prefixed
+// with private:, e.g. "dev.hcpapps.net=Z1,dev.hcpapps.net=private:Z2", a
+// domain having at most one public and one private zone.
+func ParseZones(value string) ([]Zone, error) {
+	var zones []Zone
+	domains := map[string]struct{}{}
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		kv := strings.SplitN(item, "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[1]) == "" {
+			return nil, fmt.Errorf("invalid zone %q, expected <domain>=<zone id>", item)
+		}
+		domain := normalizeDNSName(strings.TrimSpace(kv[0]))
+		zoneValue := strings.TrimSpace(kv[1])
+		private := strings.HasPrefix(zoneValue, zonePrivatePrefix)
+		zoneValue = strings.TrimPrefix(zoneValue, zonePrivatePrefix)
+		key := domain
+		if private {
+			key = zonePrivatePrefix + domain
+		}
+		if _, ok := domains[key]; ok {
+			return nil, fmt.Errorf("duplicate zone for domain %q", domain)
+		}
+		domains[key] = struct{}{}
+		zone, err := parseZone(zoneValue)
+		if err != nil {
+			return nil, fmt.Errorf("invalid zone %q: %v", item, err)
+		}
+		zones = append(zones, Zone{DNSZone: zone, Domain: domain, Private: private})
+	}
+	return zones, nil
+}
+
+// parseZone parses a zone given either by its ID, or by its tags.
+func parseZone(value string) (v1.DNSZone, error) {
+	if !strings.HasPrefix(value, zoneTagsPrefix) {
+		return v1.DNSZone{ID: value}, nil
+	}
+	tags, err := ParseZoneTags(strings.TrimPrefix(value, zoneTagsPrefix))
+	if err != nil {
+		return v1.DNSZone{}, err
+	}
+	return v1.DNSZone{Tags: tags}, nil
+}
+
+// ParseZoneTags parses a semicolon separated list of <key>=<value> tags.
+func ParseZoneTags(value string) (map[string]string, error) {
+	tags := map[string]string{}
+	for _, item := range strings.Split(value, ";") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		kv := strings.SplitN(item, "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+			return nil, fmt.Errorf("invalid tag %q, expected <key>=<value>", item)
+		}
+		tags[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+	if len(tags) == 0 {
+		return nil, fmt.Errorf("no tags")
+	}
+	return tags, nil
+}
+
+// resolveZones returns the zones with the IDs of the zones given by their tags,
+// as found by the provider.
+func resolveZones(ctx context.Context, provider Provider, zones []Zone) ([]Zone, error) {
+	resolver, ok := provider.(ZoneResolver)
+	resolved := make([]Zone, 0, len(zones))
+	for _, zone := range zones {
+		if len(zone.Tags) == 0 {
+			resolved = append(resolved, zone)
+			continue
+		}
+		if !ok {
+			return nil, fmt.Errorf("the DNS provider cannot find zones by tags")
+		}
+		dnsZone, err := resolver.ResolveZone(ctx, zone.DNSZone)
+		if err != nil {
+			return nil, err
+		}
+		resolved = append(resolved, Zone{DNSZone: dnsZone, Domain: zone.Domain, Private: zone.Private})
+	}
+	return resolved, nil
+}
+
+// zoneForName returns the index of the zone of the given visibility whose
+// domain is the longest suffix of the name, or -1 if none of the zones matches
+// the name.
+func zoneForName(zones []Zone, name string, private bool) int {
+	name = normalizeDNSName(name)
+	match := -1
+	for i, zone := range zones {
+		if zone.Private != private || !isSubdomain(name, zone.Domain) {
+			continue
+		}
+		if match < 0 || len(zone.Domain) > len(zones[match].Domain) {
+			match = i
+		}
+	}
+	return match
+}
+
+// isSubdomain returns true if the name is the domain or one of its subdomains.
+func isSubdomain(name, domain string) bool {
+	return domain == "" || name == domain || strings.HasSuffix(name, "."+domain)
+}
+
+// zoneRecords splits the record into a copy per matching zone, holding the
+// endpoints whose names belong to that zone. The endpoints of the private
+// targets belong to the private zones, and the others to the public zones,
+// unless there is no private zone, all the endpoints belonging to the public
+// zones then. The copies are returned in the order of the zones. The names
+// that do not match any zone are also returned.
+func zoneRecords(zones []Zone, record *v1.DNSRecord) ([]Zone, []*v1.DNSRecord, []string) {
+	splitHorizon := HasPrivateZone(zones)
+	endpoints := make([][]*v1.Endpoint, len(zones))
+	unmatched := map[string]struct{}{}
+	for _, endpoint := range record.Spec.Endpoints {
+		i := zoneForName(zones, endpoint.DNSName, splitHorizon && IsPrivateEndpoint(endpoint))
+		if i < 0 {
+			unmatched[normalizeDNSName(endpoint.DNSName)] = struct{}{}
+			continue
+		}
+		endpoints[i] = append(endpoints[i], endpoint)
+	}
+
+	var matching []Zone
+	var records []*v1.DNSRecord
+	for i, zone := range zones {
+		if len(endpoints[i]) == 0 {
+			continue
+		}
+		zoneRecord := record.DeepCopy()
+		zoneRecord.Spec.Endpoints = make([]*v1.Endpoint, 0, len(endpoints[i]))
+		for _, endpoint := range endpoints[i] {
+			zoneRecord.Spec.Endpoints = append(zoneRecord.Spec.Endpoints, endpoint.DeepCopy())
+		}
+		matching = append(matching, zone)
+		records = append(records, zoneRecord)
+	}
+
+	names := make([]string, 0, len(unmatched))
+	for name := range unmatched {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return matching, records, names
+}
+
+// HasPrivateZone returns whether any of the zones is private.
+func HasPrivateZone(zones []Zone) bool {
+	for _, zone := range zones {
+		if zone.Private {
+			return true
+		}
+	}
+	return false
+}
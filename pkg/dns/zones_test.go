@@ -0,0 +1,274 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/onsi/gomega"
+
+	"github.com/kuadrant/kcp-glbc/pkg/_internal/log"
+	v1 "github.com/kuadrant/kcp-glbc/pkg/apis/kuadrant/v1"
+	"github.com/kuadrant/kcp-glbc/pkg/reconciler"
+)
+
+func TestParseZones(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Value    string
+		Expected []Zone
+		Error    string
+	}{
+		{
+			Name:  "empty",
+			Value: "",
+		},
+		{
+			Name:  "multiple zones",
+			Value: "dev.hcpapps.net=Z1, Example.com.=Z2",
+			Expected: []Zone{
+				{DNSZone: v1.DNSZone{ID: "Z1"}, Domain: "dev.hcpapps.net"},
+				{DNSZone: v1.DNSZone{ID: "Z2"}, Domain: "example.com"},
+			},
+		},
+		{
+			Name:  "zone given by tags",
+			Value: "dev.hcpapps.net=tags:kuadrant.dev/zone=dev; team=glbc",
+			Expected: []Zone{
+				{DNSZone: v1.DNSZone{Tags: map[string]string{"kuadrant.dev/zone": "dev", "team": "glbc"}}, Domain: "dev.hcpapps.net"},
+			},
+		},
+		{
+			Name:  "private zones",
+			Value: "dev.hcpapps.net=Z1,dev.hcpapps.net=private:Z2,example.com=private:tags:kuadrant.dev/zone=internal",
+			Expected: []Zone{
+				{DNSZone: v1.DNSZone{ID: "Z1"}, Domain: "dev.hcpapps.net"},
+				{DNSZone: v1.DNSZone{ID: "Z2"}, Domain: "dev.hcpapps.net", Private: true},
+				{DNSZone: v1.DNSZone{Tags: map[string]string{"kuadrant.dev/zone": "internal"}}, Domain: "example.com", Private: true},
+			},
+		},
+		{
+			Name:  "duplicate private domain",
+			Value: "example.com=private:Z1,example.com=private:Z2",
+			Error: `duplicate zone for domain "example.com"`,
+		},
+		{
+			Name:  "invalid tags",
+			Value: "dev.hcpapps.net=tags:dev",
+			Error: `invalid zone "dev.hcpapps.net=tags:dev": invalid tag "dev", expected <key>=<value>`,
+		},
+		{
+			Name:  "missing tags",
+			Value: "dev.hcpapps.net=tags:",
+			Error: `invalid zone "dev.hcpapps.net=tags:": no tags`,
+		},
+		{
+			Name:  "missing zone id",
+			Value: "example.com",
+			Error: `invalid zone "example.com", expected <domain>=<zone id>`,
+		},
+		{
+			Name:  "duplicate domain",
+			Value: "example.com=Z1,example.com=Z2",
+			Error: `duplicate zone for domain "example.com"`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+			zones, err := ParseZones(tc.Value)
+			if tc.Error != "" {
+				g.Expect(err).To(gomega.MatchError(tc.Error))
+				return
+			}
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			g.Expect(zones).To(gomega.Equal(tc.Expected))
+		})
+	}
+}
+
+func TestZoneForName(t *testing.T) {
+	zones := []Zone{
+		{DNSZone: v1.DNSZone{ID: "Z1"}, Domain: "hcpapps.net"},
+		{DNSZone: v1.DNSZone{ID: "Z2"}, Domain: "dev.hcpapps.net"},
+		{DNSZone: v1.DNSZone{ID: "Z3"}, Domain: "example.com"},
+	}
+
+	cases := []struct {
+		Name     string
+		DNSName  string
+		Expected int
+	}{
+		{Name: "longest suffix", DNSName: "app.dev.hcpapps.net", Expected: 1},
+		{Name: "shorter suffix", DNSName: "app.prod.hcpapps.net", Expected: 0},
+		{Name: "zone apex", DNSName: "example.com.", Expected: 2},
+		{Name: "partial label", DNSName: "app.myexample.com", Expected: -1},
+		{Name: "no match", DNSName: "app.example.org", Expected: -1},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+			g.Expect(zoneForName(zones, tc.DNSName, false)).To(gomega.Equal(tc.Expected))
+		})
+	}
+
+	// A zone without domain matches any name
+	g := gomega.NewWithT(t)
+	g.Expect(zoneForName(append(zones, Zone{DNSZone: v1.DNSZone{ID: "Z4"}}), "app.example.org", false)).To(gomega.Equal(3))
+}
+
+func TestReconcilePublishesToMatchingZones(t *testing.T) {
+	g := gomega.NewWithT(t)
+	provider := newTestMemoryProvider(t)
+	zones := []Zone{
+		{DNSZone: v1.DNSZone{ID: "hcpapps.net"}, Domain: "hcpapps.net"},
+		{DNSZone: v1.DNSZone{ID: "dev.hcpapps.net"}, Domain: "dev.hcpapps.net"},
+		{DNSZone: v1.DNSZone{ID: "example.com"}, Domain: "example.com"},
+	}
+	c := &Controller{
+		Controller:  &reconciler.Controller{Logger: log.Logger},
+		dnsProvider: provider,
+		registry:    NewRegistry("glbc-1", provider),
+		dnsZones:    zones,
+	}
+
+	record := newTestRecord("app",
+		aEndpoint("app.dev.hcpapps.net", "10.0.0.1"),
+		aEndpoint("app.example.com", "10.0.0.1"),
+		aEndpoint("app.example.org", "10.0.0.1"),
+	)
+	g.Expect(c.reconcile(context.TODO(), record)).To(gomega.Succeed())
+
+	// The record is published to the zones of the longest suffix of its names only
+	g.Expect(record.Status.Zones).To(gomega.HaveLen(2))
+	g.Expect(record.Status.Zones[0].DNSZone).To(gomega.Equal(v1.DNSZone{ID: "dev.hcpapps.net"}))
+	g.Expect(record.Status.Zones[1].DNSZone).To(gomega.Equal(v1.DNSZone{ID: "example.com"}))
+	g.Expect(RecordIsPublished(record)).To(gomega.BeTrue())
+	g.Expect(provider.GetRecords(context.TODO(), v1.DNSZone{ID: "dev.hcpapps.net"}, "app.dev.hcpapps.net", string(v1.ARecordType))).To(gomega.HaveLen(1))
+	g.Expect(provider.GetRecords(context.TODO(), v1.DNSZone{ID: "hcpapps.net"}, "app.dev.hcpapps.net", string(v1.ARecordType))).To(gomega.BeEmpty())
+	g.Expect(provider.GetRecords(context.TODO(), v1.DNSZone{ID: "example.com"}, "app.example.com", string(v1.ARecordType))).To(gomega.HaveLen(1))
+
+	// The record is removed from the zones none of its names belong to anymore
+	record.Generation++
+	record.Spec.Endpoints = []*v1.Endpoint{aEndpoint("app.dev.hcpapps.net", "10.0.0.2")}
+	g.Expect(c.reconcile(context.TODO(), record)).To(gomega.Succeed())
+
+	g.Expect(record.Status.Zones).To(gomega.HaveLen(1))
+	g.Expect(record.Status.Zones[0].DNSZone).To(gomega.Equal(v1.DNSZone{ID: "dev.hcpapps.net"}))
+	g.Expect(provider.GetRecords(context.TODO(), v1.DNSZone{ID: "example.com"}, "app.example.com", string(v1.ARecordType))).To(gomega.BeEmpty())
+	g.Expect(provider.GetRecords(context.TODO(), v1.DNSZone{ID: "example.com"}, "_glbc-owner.app.example.com", string(v1.TXTRecordType))).To(gomega.BeEmpty())
+
+	// The record is deleted from all its zones
+	g.Expect(c.deleteRecord(context.TODO(), record)).To(gomega.Succeed())
+	g.Expect(provider.GetRecords(context.TODO(), v1.DNSZone{ID: "dev.hcpapps.net"}, "app.dev.hcpapps.net", string(v1.ARecordType))).To(gomega.BeEmpty())
+}
+
+func TestReconcilePublishesToSplitHorizonZones(t *testing.T) {
+	g := gomega.NewWithT(t)
+	provider := newTestMemoryProvider(t)
+	public := v1.DNSZone{ID: "public"}
+	private := v1.DNSZone{ID: "private"}
+	c := &Controller{
+		Controller:  &reconciler.Controller{Logger: log.Logger},
+		dnsProvider: provider,
+		registry:    NewRegistry("glbc-1", provider),
+		dnsZones: []Zone{
+			{DNSZone: public, Domain: "dev.hcpapps.net"},
+			{DNSZone: private, Domain: "dev.hcpapps.net", Private: true},
+		},
+	}
+
+	privateEndpoint := aEndpoint("app.dev.hcpapps.net", "10.0.0.1")
+	privateEndpoint.Labels = v1.Labels{LabelVisibility: string(VisibilityPrivate)}
+	record := newTestRecord("app", aEndpoint("app.dev.hcpapps.net", "203.0.113.1"), privateEndpoint)
+	g.Expect(c.reconcile(context.TODO(), record)).To(gomega.Succeed())
+
+	// The private and public targets are only published to the zones of their
+	// visibility, each with its own status
+	g.Expect(record.Status.Zones).To(gomega.HaveLen(2))
+	g.Expect(record.Status.Zones[0].DNSZone).To(gomega.Equal(public))
+	g.Expect(record.Status.Zones[1].DNSZone).To(gomega.Equal(private))
+	g.Expect(RecordIsPublished(record)).To(gomega.BeTrue())
+
+	endpoints, err := provider.GetRecords(context.TODO(), public, "app.dev.hcpapps.net", string(v1.ARecordType))
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(endpoints).To(gomega.HaveLen(1))
+	g.Expect(endpoints[0].Targets).To(gomega.Equal(v1.Targets{"203.0.113.1"}))
+
+	endpoints, err = provider.GetRecords(context.TODO(), private, "app.dev.hcpapps.net", string(v1.ARecordType))
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(endpoints).To(gomega.HaveLen(1))
+	g.Expect(endpoints[0].Targets).To(gomega.Equal(v1.Targets{"10.0.0.1"}))
+}
+
+func TestZoneRecordsWithoutPrivateZones(t *testing.T) {
+	g := gomega.NewWithT(t)
+	zones := []Zone{{DNSZone: v1.DNSZone{ID: "public"}, Domain: "dev.hcpapps.net"}}
+
+	privateEndpoint := aEndpoint("app.dev.hcpapps.net", "10.0.0.1")
+	privateEndpoint.Labels = v1.Labels{LabelVisibility: string(VisibilityPrivate)}
+	record := newTestRecord("app", aEndpoint("app.dev.hcpapps.net", "203.0.113.1"), privateEndpoint)
+
+	// All the targets are published to the public zones, when there is no
+	// private zone
+	matching, records, unmatched := zoneRecords(zones, record)
+	g.Expect(matching).To(gomega.Equal(zones))
+	g.Expect(records[0].Spec.Endpoints).To(gomega.HaveLen(2))
+	g.Expect(unmatched).To(gomega.BeEmpty())
+}
+
+// fakeZoneResolver resolves the zones given by tags to the zone ID held by the
+// value of their "zone" tag.
+type fakeZoneResolver struct {
+	Provider
+}
+
+func (r *fakeZoneResolver) ResolveZone(_ context.Context, zone v1.DNSZone) (v1.DNSZone, error) {
+	if len(zone.Tags) == 0 {
+		return zone, nil
+	}
+	id, ok := zone.Tags["zone"]
+	if !ok {
+		return zone, fmt.Errorf("no zone found with tags %v", zone.Tags)
+	}
+	resolved := *zone.DeepCopy()
+	resolved.ID = id
+	return resolved, nil
+}
+
+func TestReconcileResolvesZonesByTags(t *testing.T) {
+	g := gomega.NewWithT(t)
+	provider := &fakeZoneResolver{Provider: newTestMemoryProvider(t)}
+	tags := map[string]string{"zone": "dev.hcpapps.net"}
+	c := &Controller{
+		Controller:  &reconciler.Controller{Logger: log.Logger},
+		dnsProvider: provider,
+		registry:    NewRegistry("glbc-1", provider),
+		dnsZones:    []Zone{{DNSZone: v1.DNSZone{Tags: tags}, Domain: "hcpapps.net"}},
+	}
+
+	record := newTestRecord("app", aEndpoint("app.dev.hcpapps.net", "10.0.0.1"))
+	g.Expect(c.reconcile(context.TODO(), record)).To(gomega.Succeed())
+
+	// The resolved zone ID is exposed in the status, along with the tags
+	g.Expect(record.Status.Zones).To(gomega.HaveLen(1))
+	g.Expect(record.Status.Zones[0].DNSZone).To(gomega.Equal(v1.DNSZone{ID: "dev.hcpapps.net", Tags: tags}))
+	g.Expect(RecordIsPublished(record)).To(gomega.BeTrue())
+	g.Expect(provider.GetRecords(context.TODO(), v1.DNSZone{ID: "dev.hcpapps.net"}, "app.dev.hcpapps.net", string(v1.ARecordType))).To(gomega.HaveLen(1))
+
+	// The record follows the zone when it resolves to another ID
+	tags["zone"] = "hcpapps.net"
+	g.Expect(c.reconcile(context.TODO(), record)).To(gomega.Succeed())
+
+	g.Expect(record.Status.Zones).To(gomega.HaveLen(1))
+	g.Expect(record.Status.Zones[0].DNSZone.ID).To(gomega.Equal("hcpapps.net"))
+	g.Expect(provider.GetRecords(context.TODO(), v1.DNSZone{ID: "hcpapps.net"}, "app.dev.hcpapps.net", string(v1.ARecordType))).To(gomega.HaveLen(1))
+	g.Expect(provider.GetRecords(context.TODO(), v1.DNSZone{ID: "dev.hcpapps.net"}, "app.dev.hcpapps.net", string(v1.ARecordType))).To(gomega.BeEmpty())
+
+	// The reconciliation fails while the zone cannot be resolved
+	delete(tags, "zone")
+	tags["team"] = "glbc"
+	g.Expect(c.reconcile(context.TODO(), record)).To(gomega.MatchError(gomega.ContainSubstring("failed to resolve DNS zones")))
+}
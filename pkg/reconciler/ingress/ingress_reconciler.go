@@ -36,6 +36,12 @@ func (c *Controller) reconcile(ctx context.Context, ingress traffic.Interface) e
 			ManagedDomain:    c.domain,
 			Log:              c.Logger,
 			DNSLookup:        c.hostResolver.LookupIPAddr,
+			Nameservers:      c.nameservers,
+			GetContinent:     c.getContinent,
+			HostTargetMode:   c.hostTargetMode,
+			TargetPolicy:     c.targetPolicy,
+			PrivateZones:     c.privateZones,
+			CAAIssuers:       c.caaIssuers,
 		},
 		&traffic.HostReconciler{
 			Log:                    c.Logger,
@@ -67,6 +67,12 @@ func NewController(config *ControllerConfig) *Controller {
 		domain:                       config.Domain,
 		glbcWorkspace:                config.GLBCWorkspace,
 		hostResolver:                 hostResolver,
+		nameservers:                  config.Nameservers,
+		getContinent:                 config.GetContinent,
+		hostTargetMode:               config.HostTargetMode,
+		targetPolicy:                 config.TargetPolicy,
+		privateZones:                 config.PrivateZones,
+		caaIssuers:                   config.CAAIssuers,
 		hostsWatcher:                 dns.NewHostsWatcher(&base.Logger, hostResolver, dns.DefaultInterval),
 		certInformerFactory:          config.CertificateInformer,
 		KCPInformerFactory:           config.KCPInformer,
@@ -271,6 +277,12 @@ type ControllerConfig struct {
 	Domain                          string
 	CertProvider                    tls.Provider
 	HostResolver                    dns.HostResolver
+	Nameservers                     []string
+	GetContinent                    func(ctx context.Context, target dns.Target) (string, error)
+	HostTargetMode                  dns.HostTargetMode
+	TargetPolicy                    *dns.TargetPolicy
+	PrivateZones                    bool
+	CAAIssuers                      []string
 	GLBCWorkspace                   logicalcluster.Name
 }
 
@@ -287,6 +299,12 @@ type Controller struct {
 	certProvider                 tls.Provider
 	domain                       string
 	hostResolver                 dns.HostResolver
+	nameservers                  []string
+	getContinent                 func(ctx context.Context, target dns.Target) (string, error)
+	hostTargetMode               dns.HostTargetMode
+	targetPolicy                 *dns.TargetPolicy
+	privateZones                 bool
+	caaIssuers                   []string
 	hostsWatcher                 *dns.HostsWatcher
 	certInformerFactory          certmaninformer.SharedInformerFactory
 	glbcInformerFactory          informers.SharedInformerFactory
@@ -4,9 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net"
-	"os"
 	"sort"
-	"strconv"
 	"strings"
 
 	"github.com/go-logr/logr"
@@ -29,7 +27,6 @@ import (
 	"github.com/kuadrant/kcp-glbc/pkg/_internal/slice"
 	v1 "github.com/kuadrant/kcp-glbc/pkg/apis/kuadrant/v1"
 	"github.com/kuadrant/kcp-glbc/pkg/dns"
-	"github.com/kuadrant/kcp-glbc/pkg/dns/aws"
 )
 
 type DnsReconciler struct {
@@ -43,6 +40,31 @@ type DnsReconciler struct {
 	Log              logr.Logger
 	ManagedDomain    string
 	DNSLookup        func(ctx context.Context, host string) ([]dns.HostAddress, error)
+	// Nameservers are the addresses (host:port) of the name servers the managed
+	// host is looked up against, before being set as the DNS load balancer host.
+	// Defaults to the name servers of the managed domain.
+	Nameservers []string
+	// GetContinent returns the continent code of the DNS target. When set and
+	// continents are found for the targets, the DNS record is configured with
+	// geolocation routing. Optional.
+	GetContinent func(ctx context.Context, target dns.Target) (string, error)
+	// HostTargetMode is how the targets given by a host name are published.
+	// Defaults to dns.HostTargetModeResolve, the hosts being resolved and
+	// watched.
+	HostTargetMode dns.HostTargetMode
+	// TargetPolicy is the policy of the target addresses published to the
+	// public zones. The addresses it rejects are not published, and are
+	// recorded in the ANNOTATION_REJECTED_TARGETS annotation of the traffic
+	// object. Optional.
+	TargetPolicy *dns.TargetPolicy
+	// PrivateZones is whether private zones are configured, the private
+	// targets being published to them rather than to the public zones, so
+	// that the target policy does not apply to them.
+	PrivateZones bool
+	// CAAIssuers are the domains of the certificate authorities authorized by
+	// the CAA records published with the generated hosts. No CAA record is
+	// published when empty.
+	CAAIssuers []string
 }
 
 func (r *DnsReconciler) GetName() string {
@@ -99,15 +121,42 @@ func (r *DnsReconciler) Reconcile(ctx context.Context, accessor Interface) (Reco
 		return ReconcileStatusContinue, err
 	}
 	var activeLBHosts []string
+	continents := map[string]string{}
+	// The visibility of the published addresses, by address
+	visibilities := map[string]dns.Visibility{}
+	// The clusters of the published addresses, by address
+	clusters := map[string]string{}
+	publishedByName := r.hostsPublishedByName(accessor, targets)
 	for _, target := range targets {
 		host := target.Value
+		if r.GetContinent != nil {
+			continent, err := r.GetContinent(ctx, target)
+			if err != nil {
+				return ReconcileStatusContinue, fmt.Errorf("continent lookup failed for host %s : %s", host, err)
+			}
+			if continent != "" {
+				continents[host] = continent
+			}
+		}
 		deleteAnnotation := workload.InternalClusterDeletionTimestampAnnotationPrefix + target.Cluster
 		if metadata.HasAnnotation(accessor, deleteAnnotation) {
 			deletingTargetIPs[host] = append(deletingTargetIPs[host], host)
+			visibilities[host] = target.Visibility
+			clusters[host] = target.Cluster
 			continue
 		}
 		if target.TargetType == dns.TargetTypeIP {
 			activeDNSTargetIPs[host] = append(activeDNSTargetIPs[host], host)
+			visibilities[host] = target.Visibility
+			clusters[host] = target.Cluster
+			continue
+		}
+		// The hosts published as CNAME or alias records are neither resolved
+		// nor watched, the DNS provider following their changes of addresses
+		if publishedByName[host] {
+			activeDNSTargetIPs[host] = append(activeDNSTargetIPs[host], host)
+			visibilities[host] = target.Visibility
+			clusters[host] = target.Cluster
 			continue
 		}
 
@@ -118,6 +167,13 @@ func (r *DnsReconciler) Reconcile(ctx context.Context, accessor Interface) (Reco
 		}
 		for _, add := range addr {
 			activeDNSTargetIPs[host] = append(activeDNSTargetIPs[host], add.IP.String())
+			// The addresses of the hosts of unknown visibility are classified by their range
+			visibility := target.Visibility
+			if visibility == "" {
+				visibility = dns.VisibilityForIP(add.IP)
+			}
+			visibilities[add.IP.String()] = visibility
+			clusters[add.IP.String()] = target.Cluster
 		}
 		//add the host to host watcher to keep our DNS upto date
 		// If it is not an IP we add it to the host watcher that triggers an update when it gets IPS
@@ -133,18 +189,23 @@ func (r *DnsReconciler) Reconcile(ctx context.Context, accessor Interface) (Reco
 		}
 	}
 
+	rejected := append(r.rejectTargets(activeDNSTargetIPs, visibilities), r.rejectTargets(deletingTargetIPs, visibilities)...)
+	r.setRejectedTargets(accessor, rejected)
+	r.setEndpointHealth(accessor, existing)
+
 	// no non-deleting hosts have an IP yet, so continue using IPs of "losing" clusters
 	if len(activeDNSTargetIPs) == 0 && len(deletingTargetIPs) > 0 {
 		r.Log.V(3).Info("setting the dns Target to the deleting Target as no new dns targets set yet")
 		activeDNSTargetIPs = deletingTargetIPs
 	}
 	copyDNS := existing.DeepCopy()
-	r.setEndpointFromTargets(managedHost, activeDNSTargetIPs, copyDNS)
-	objMeta, err := meta.Accessor(accessor)
-	if err != nil {
-		return ReconcileStatusContinue, err
+	if len(continents) > 0 {
+		r.setGeoEndpointsFromTargets(managedHost, activeDNSTargetIPs, continents, visibilities, copyDNS)
+	} else {
+		r.setEndpointFromTargets(managedHost, activeDNSTargetIPs, visibilities, copyDNS)
 	}
-	copyHealthAnnotations(copyDNS, objMeta)
+	setClusters(copyDNS, clusters)
+	r.setCAAEndpoints(copyDNS)
 	if !equality.Semantic.DeepEqual(copyDNS, existing) {
 		if existing.Spec.Endpoints == nil && copyDNS.Spec.Endpoints != nil {
 			// metric to observe the accessor admission time
@@ -157,15 +218,11 @@ func (r *DnsReconciler) Reconcile(ctx context.Context, accessor Interface) (Reco
 	}
 
 	host := r.ManagedDomain
-	zoneID, _ := os.LookupEnv(aws.ZoneIDEnvVar)
-	dnsZone := &v1.DNSZone{
-		ID: zoneID,
-	}
 
 	// Once we know the DNS is created up and TMC is enabled for this ingress (IE status is stored in annotations) set the DNS load balancer in the ingress status.
 	if accessor.TMCEnabled() {
-		if !accessor.HasDNSLBHost() && len(copyDNS.Spec.Endpoints) > 0 && equality.Semantic.DeepEqual(copyDNS, existing) && dns.RecordIsAlreadyPublishedToZone(copyDNS, dnsZone) {
-			foundIPAddress := foundNameserversOfDomainAndIP(host, managedHost)
+		if !accessor.HasDNSLBHost() && len(copyDNS.Spec.Endpoints) > 0 && equality.Semantic.DeepEqual(copyDNS, existing) && dns.RecordIsPublished(copyDNS) {
+			foundIPAddress := foundNameserversOfDomainAndIP(host, managedHost, r.Nameservers)
 			if foundIPAddress {
 				fmt.Print(" Setting DNS LB host to ingress status ")
 				accessor.SetDNSLBHost(managedHost)
@@ -216,22 +273,31 @@ func newDNSRecordForObject(obj runtime.Object) (*v1.DNSRecord, error) {
 		record.Annotations[ANNOTATION_TRAFFIC_KEY] = string(objectKey(obj))
 	}
 
-	copyHealthAnnotations(record, objMeta)
 	return record, nil
 
 }
 
-func copyHealthAnnotations(dnsRecord *v1.DNSRecord, objectMeta metav1.Object) {
-	metadata.CopyAnnotationsPredicate(objectMeta, dnsRecord, metadata.KeyPredicate(func(key string) bool {
-		return strings.HasPrefix(key, ANNOTATION_HEALTH_CHECK_PREFIX)
-	}))
-}
-
 // foundNameserversOfDomainAndIP looks up for nameservers of a given domain, and performs a dig of the managed host against
-// the nameservers. It returns true if at least one A record is found.
-func foundNameserversOfDomainAndIP(host, managedHost string) bool {
-	nameservers, _ := net.LookupNS(host)
+// the nameservers. It returns true if at least one A or AAAA record is found.
+// If addresses of nameservers are given, the managed host is looked up against them instead.
+func foundNameserversOfDomainAndIP(host, managedHost string, nameserverAddrs []string) bool {
 	var dig dnsutil.Dig
+	if len(nameserverAddrs) > 0 {
+		for _, addr := range nameserverAddrs {
+			dig.RemoteAddr = addr
+			a, _ := dig.A(managedHost)
+			if len(a) > 0 {
+				return true
+			}
+			aaaa, _ := dig.AAAA(managedHost)
+			if len(aaaa) > 0 {
+				return true
+			}
+		}
+		return false
+	}
+
+	nameservers, _ := net.LookupNS(host)
 	var found bool
 	if len(nameservers) < 1 {
 		found = false
@@ -243,6 +309,11 @@ func foundNameserversOfDomainAndIP(host, managedHost string) bool {
 				found = true
 				break
 			}
+			aaaa, _ := dig.AAAA(managedHost)
+			if aaaa != nil {
+				found = true
+				break
+			}
 
 		}
 
@@ -250,7 +321,7 @@ func foundNameserversOfDomainAndIP(host, managedHost string) bool {
 	return found
 }
 
-func (r *DnsReconciler) setEndpointFromTargets(dnsName string, dnsTargets map[string][]string, dnsRecord *v1.DNSRecord) {
+func (r *DnsReconciler) setEndpointFromTargets(dnsName string, dnsTargets map[string][]string, visibilities map[string]dns.Visibility, dnsRecord *v1.DNSRecord) {
 	currentEndpoints := make(map[string]*v1.Endpoint, len(dnsRecord.Spec.Endpoints))
 	for _, endpoint := range dnsRecord.Spec.Endpoints {
 		address, ok := endpoint.GetAddress()
@@ -265,6 +336,7 @@ func (r *DnsReconciler) setEndpointFromTargets(dnsName string, dnsTargets map[st
 	)
 	ok := false
 	for _, targets := range dnsTargets {
+		counts := countTargetsByRecordType(targets)
 		for _, target := range targets {
 			// If the endpoint for this target does not exist, add a new one
 			if endpoint, ok = currentEndpoints[target]; !ok {
@@ -273,11 +345,14 @@ func (r *DnsReconciler) setEndpointFromTargets(dnsName string, dnsTargets map[st
 				}
 			}
 			// Update the endpoint fields
+			recordType := recordTypeForTarget(target)
 			endpoint.DNSName = dnsName
-			endpoint.RecordType = "A"
+			endpoint.RecordType = string(recordType)
 			endpoint.Targets = []string{target}
 			endpoint.RecordTTL = 60
-			endpoint.SetProviderSpecific(aws.ProviderSpecificWeight, awsEndpointWeight(len(targets)))
+			endpoint.SetRoutingPolicy(weightedRoutingPolicy(counts[recordType]))
+			r.setAlias(endpoint)
+			setVisibility(endpoint, visibilities[target])
 			newEndpoints = append(newEndpoints, endpoint)
 		}
 	}
@@ -289,21 +364,234 @@ func (r *DnsReconciler) setEndpointFromTargets(dnsName string, dnsTargets map[st
 	dnsRecord.Spec.Endpoints = newEndpoints
 }
 
-// awsEndpointWeight returns the weight Value for a single AWS record in a set of records where the traffic is split
+// rejectTargets removes the addresses rejected by the target policy from the targets, and returns them. The private
+// addresses are kept when they are published to the private zones.
+func (r *DnsReconciler) rejectTargets(dnsTargets map[string][]string, visibilities map[string]dns.Visibility) []string {
+	var rejected []string
+	for host, targets := range dnsTargets {
+		var allowed []string
+		for _, target := range targets {
+			if (!r.PrivateZones || visibilities[target] != dns.VisibilityPrivate) && r.TargetPolicy.Rejects(target) {
+				rejected = append(rejected, target)
+				continue
+			}
+			allowed = append(allowed, target)
+		}
+		if len(allowed) == 0 {
+			delete(dnsTargets, host)
+			continue
+		}
+		dnsTargets[host] = allowed
+	}
+	return rejected
+}
+
+// setRejectedTargets records the rejected addresses in the annotation of the traffic object, counting the ones that were
+// not rejected already.
+func (r *DnsReconciler) setRejectedTargets(accessor Interface, rejected []string) {
+	if len(rejected) == 0 {
+		metadata.RemoveAnnotation(accessor, ANNOTATION_REJECTED_TARGETS)
+		return
+	}
+	sort.Strings(rejected)
+	unique := rejected[:1]
+	for _, target := range rejected[1:] {
+		if target != unique[len(unique)-1] {
+			unique = append(unique, target)
+		}
+	}
+	rejected = unique
+	previous := strings.Split(metadata.GetAnnotation(accessor, ANNOTATION_REJECTED_TARGETS), ",")
+	for _, target := range rejected {
+		if !slice.ContainsString(previous, target) {
+			r.Log.Info("rejected DNS target by the target policy", "target", target, "object", accessor.GetNamespaceName())
+			DNSTargetRejectedTotal.Inc()
+		}
+	}
+	metadata.AddAnnotation(accessor, ANNOTATION_REJECTED_TARGETS, strings.Join(rejected, ","))
+}
+
+// setEndpointHealth summarizes the health of the endpoints of the DNS record, as reported by its HealthCheck, in the
+// annotations of the traffic object: the number of healthy endpoints out of the checked ones, and the unhealthy
+// endpoints, that are no longer published by the DNS provider.
+func (r *DnsReconciler) setEndpointHealth(accessor Interface, dnsRecord *v1.DNSRecord) {
+	if len(dnsRecord.Status.Health) == 0 {
+		metadata.RemoveAnnotation(accessor, ANNOTATION_HEALTHY_ENDPOINTS)
+		metadata.RemoveAnnotation(accessor, ANNOTATION_UNHEALTHY_ENDPOINTS)
+		return
+	}
+	healthy := 0
+	var unhealthy []string
+	for _, endpoint := range dnsRecord.Status.Health {
+		switch endpoint.Health {
+		case v1.Healthy:
+			healthy++
+		case v1.Unhealthy:
+			id := endpoint.SetIdentifier
+			if id == "" {
+				id = endpoint.DNSName
+			}
+			unhealthy = append(unhealthy, id)
+		}
+	}
+	metadata.AddAnnotation(accessor, ANNOTATION_HEALTHY_ENDPOINTS, fmt.Sprintf("%d/%d", healthy, len(dnsRecord.Status.Health)))
+	if len(unhealthy) == 0 {
+		metadata.RemoveAnnotation(accessor, ANNOTATION_UNHEALTHY_ENDPOINTS)
+		return
+	}
+	sort.Strings(unhealthy)
+	metadata.AddAnnotation(accessor, ANNOTATION_UNHEALTHY_ENDPOINTS, strings.Join(unhealthy, ","))
+}
+
+// setCAAEndpoints adds a CAA endpoint for each name of the DNS record, except the ones published as CNAME records, as
+// they cannot hold other records, the CAA records of their target applying to them.
+func (r *DnsReconciler) setCAAEndpoints(dnsRecord *v1.DNSRecord) {
+	if len(r.CAAIssuers) == 0 {
+		return
+	}
+	var names []string
+	cnames := map[string]bool{}
+	for _, endpoint := range dnsRecord.Spec.Endpoints {
+		if !slice.ContainsString(names, endpoint.DNSName) {
+			names = append(names, endpoint.DNSName)
+		}
+		if endpoint.RecordType == string(v1.CNAMERecordType) {
+			cnames[endpoint.DNSName] = true
+		}
+	}
+	for _, name := range names {
+		if !cnames[name] {
+			dnsRecord.Spec.Endpoints = append(dnsRecord.Spec.Endpoints, dns.CAAEndpoint(name, r.CAAIssuers))
+		}
+	}
+}
+
+// setVisibility labels the endpoints of the private targets, so that they are only published to the private zones, if
+// any. The targets of unknown visibility are public.
+func setVisibility(endpoint *v1.Endpoint, visibility dns.Visibility) {
+	if visibility == dns.VisibilityPrivate {
+		if endpoint.Labels == nil {
+			endpoint.Labels = v1.Labels{}
+		}
+		endpoint.Labels[dns.LabelVisibility] = string(dns.VisibilityPrivate)
+		return
+	}
+	delete(endpoint.Labels, dns.LabelVisibility)
+	if len(endpoint.Labels) == 0 {
+		endpoint.Labels = nil
+	}
+}
+
+// setClusters labels the endpoints of the DNS record with the cluster of their
+// address, so that their health checks can be aggregated by cluster.
+func setClusters(dnsRecord *v1.DNSRecord, clusters map[string]string) {
+	for _, endpoint := range dnsRecord.Spec.Endpoints {
+		address, _ := endpoint.GetAddress()
+		if cluster := clusters[address]; cluster != "" {
+			if endpoint.Labels == nil {
+				endpoint.Labels = v1.Labels{}
+			}
+			endpoint.Labels[dns.LabelCluster] = cluster
+			continue
+		}
+		delete(endpoint.Labels, dns.LabelCluster)
+		if len(endpoint.Labels) == 0 {
+			endpoint.Labels = nil
+		}
+	}
+}
+
+// recordTypeForTarget returns the AAAA record type for IPv6 addresses, the A record type for IPv4 addresses, and the
+// CNAME record type for the hosts published by name.
+func recordTypeForTarget(target string) v1.DNSRecordType {
+	ip := net.ParseIP(target)
+	switch {
+	case ip == nil:
+		return v1.CNAMERecordType
+	case ip.To4() == nil:
+		return v1.AAAARecordType
+	}
+	return v1.ARecordType
+}
+
+// hostsPublishedByName returns the hosts of the targets that are published by name, as CNAME or alias records, rather
+// than by the addresses they resolve to. As a CNAME record cannot coexist with other records of the same name, the hosts
+// that cannot be published as alias records are only published as CNAME records when none of the targets is an IP
+// address or an alias record.
+func (r *DnsReconciler) hostsPublishedByName(accessor Interface, targets []dns.Target) map[string]bool {
+	byName := map[string]bool{}
+	if r.HostTargetMode != dns.HostTargetModeCNAME && r.HostTargetMode != dns.HostTargetModeAlias {
+		return byName
+	}
+	var cnames []string
+	onlyCNAMEs := true
+	for _, target := range targets {
+		if metadata.HasAnnotation(accessor, workload.InternalClusterDeletionTimestampAnnotationPrefix+target.Cluster) {
+			continue
+		}
+		switch {
+		case target.TargetType == dns.TargetTypeIP:
+			onlyCNAMEs = false
+		case r.HostTargetMode == dns.HostTargetModeAlias && dns.IsLoadBalancerHostname(target.Value):
+			byName[target.Value] = true
+			onlyCNAMEs = false
+		default:
+			cnames = append(cnames, target.Value)
+		}
+	}
+	if onlyCNAMEs {
+		for _, host := range cnames {
+			byName[host] = true
+		}
+	} else if len(cnames) > 0 {
+		r.Log.V(3).Info("resolving the hosts that cannot be published as CNAME records alongside the other targets", "hosts", cnames)
+	}
+	return byName
+}
+
+// setAlias marks the CNAME endpoints targeting an AWS load balancer to be published as alias records, in the alias host
+// target mode. The load balancers whose hosted zone is unknown are published as CNAME records.
+func (r *DnsReconciler) setAlias(endpoint *v1.Endpoint) {
+	if r.HostTargetMode == dns.HostTargetModeAlias && endpoint.RecordType == string(v1.CNAMERecordType) && dns.IsLoadBalancerHostname(endpoint.Targets[0]) {
+		endpoint.SetProviderSpecific(v1.ProviderSpecificAlias, "true")
+		return
+	}
+	endpoint.DeleteProviderSpecific(v1.ProviderSpecificAlias)
+	if len(endpoint.ProviderSpecific) == 0 {
+		endpoint.ProviderSpecific = nil
+	}
+}
+
+// countTargetsByRecordType returns the number of targets per record type. The A and AAAA record sets are weighted
+// independently, so that the traffic of both IPv4 and IPv6 clients is split evenly between the clusters/ingresses.
+func countTargetsByRecordType(targets []string) map[v1.DNSRecordType]int {
+	counts := map[v1.DNSRecordType]int{}
+	for _, target := range targets {
+		counts[recordTypeForTarget(target)]++
+	}
+	return counts
+}
+
+// weightedRoutingPolicy returns the weighted routing policy of an endpoint, whose weight is returned by endpointWeight.
+func weightedRoutingPolicy(numIPs int) *v1.RoutingPolicy {
+	return &v1.RoutingPolicy{Weighted: &v1.WeightedRoutingPolicy{Weight: endpointWeight(numIPs)}}
+}
+
+// endpointWeight returns the weight Value for a single record in a set of records where the traffic is split
 // evenly between a number of clusters/ingresses, each splitting traffic evenly to a number of IPs (numIPs)
 //
 // Divides the number of IPs by a known weight allowance for a cluster/ingress, note that this means:
 // * Will always return 1 after a certain number of ips is reached, 60 in the current case (maxWeight / 2)
 // * Will return values that don't add up to the total maxWeight when the number of ingresses is not divisible by numIPs
 //
-// The aws weight value must be an integer between 0 and 255.
+// The weight is kept within the range all the providers accept, e.g. the aws weight value must be an integer between 0 and 255.
 // https://docs.aws.amazon.com/Route53/latest/DeveloperGuide/resource-record-sets-values-weighted.html#rrsets-values-weighted-weight
-func awsEndpointWeight(numIPs int) string {
+func endpointWeight(numIPs int) int64 {
 	maxWeight := 120
 	if numIPs > maxWeight {
 		numIPs = maxWeight
 	}
-	return strconv.Itoa(maxWeight / numIPs)
+	return int64(maxWeight / numIPs)
 }
 
 // AddHostAnnotations adds generated host annotation to a provided DNS Record CR
@@ -8,8 +8,12 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/cache"
 	"net"
+	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -263,76 +267,304 @@ func TestDNSReconciler(t *testing.T) {
 
 }
 
-func Test_awsEndpointWeight(t *testing.T) {
+func Test_setEndpointFromTargets(t *testing.T) {
+	weighted := func(recordType v1.DNSRecordType, ip string, weight int64) *v1.Endpoint {
+		return &v1.Endpoint{
+			DNSName:       "xyz.dev.hcpapps.net",
+			RecordType:    string(recordType),
+			RecordTTL:     60,
+			SetIdentifier: ip,
+			Targets:       v1.Targets{ip},
+			RoutingPolicy: &v1.RoutingPolicy{Weighted: &v1.WeightedRoutingPolicy{Weight: weight}},
+		}
+	}
+
+	alias := func(endpoint *v1.Endpoint) *v1.Endpoint {
+		endpoint.SetProviderSpecific(v1.ProviderSpecificAlias, "true")
+		return endpoint
+	}
+
+	private := func(endpoint *v1.Endpoint) *v1.Endpoint {
+		endpoint.Labels = v1.Labels{dns.LabelVisibility: string(dns.VisibilityPrivate)}
+		return endpoint
+	}
+
+	tests := []struct {
+		name         string
+		mode         dns.HostTargetMode
+		targets      map[string][]string
+		visibilities map[string]dns.Visibility
+		want         []*v1.Endpoint
+	}{
+		{
+			name:    "IPv4 targets",
+			targets: map[string][]string{"lb1": {"1.1.1.1", "2.2.2.2"}, "lb2": {"3.3.3.3"}},
+			want: []*v1.Endpoint{
+				weighted(v1.ARecordType, "1.1.1.1", 60),
+				weighted(v1.ARecordType, "2.2.2.2", 60),
+				weighted(v1.ARecordType, "3.3.3.3", 120),
+			},
+		},
+		{
+			name:    "IPv6 targets",
+			targets: map[string][]string{"lb1": {"2001:db8::1", "2001:db8::2"}},
+			want: []*v1.Endpoint{
+				weighted(v1.AAAARecordType, "2001:db8::1", 60),
+				weighted(v1.AAAARecordType, "2001:db8::2", 60),
+			},
+		},
+		{
+			name:    "dual-stack targets",
+			targets: map[string][]string{"lb1": {"1.1.1.1", "2001:db8::1", "2001:db8::2"}, "lb2": {"3.3.3.3"}},
+			want: []*v1.Endpoint{
+				weighted(v1.ARecordType, "1.1.1.1", 120),
+				weighted(v1.AAAARecordType, "2001:db8::1", 60),
+				weighted(v1.AAAARecordType, "2001:db8::2", 60),
+				weighted(v1.ARecordType, "3.3.3.3", 120),
+			},
+		},
+		{
+			name:    "host targets",
+			mode:    dns.HostTargetModeCNAME,
+			targets: map[string][]string{"lb1.example.com": {"lb1.example.com"}, "lb2.example.com": {"lb2.example.com"}},
+			want: []*v1.Endpoint{
+				weighted(v1.CNAMERecordType, "lb1.example.com", 120),
+				weighted(v1.CNAMERecordType, "lb2.example.com", 120),
+			},
+		},
+		{
+			name:    "load balancer host targets",
+			mode:    dns.HostTargetModeAlias,
+			targets: map[string][]string{"lb1": {"1.1.1.1"}, "lb-2.eu-west-1.elb.amazonaws.com": {"lb-2.eu-west-1.elb.amazonaws.com"}},
+			want: []*v1.Endpoint{
+				weighted(v1.ARecordType, "1.1.1.1", 120),
+				alias(weighted(v1.CNAMERecordType, "lb-2.eu-west-1.elb.amazonaws.com", 120)),
+			},
+		},
+		{
+			name:    "load balancer host targets of an unknown region",
+			mode:    dns.HostTargetModeAlias,
+			targets: map[string][]string{"lb-3.xx-unknown-1.elb.amazonaws.com": {"lb-3.xx-unknown-1.elb.amazonaws.com"}},
+			want: []*v1.Endpoint{
+				weighted(v1.CNAMERecordType, "lb-3.xx-unknown-1.elb.amazonaws.com", 120),
+			},
+		},
+		{
+			name:         "private targets",
+			targets:      map[string][]string{"lb1": {"10.0.0.1"}, "lb2": {"3.3.3.3"}, "lb3": {"4.4.4.4"}},
+			visibilities: map[string]dns.Visibility{"10.0.0.1": dns.VisibilityPrivate, "3.3.3.3": dns.VisibilityPublic},
+			want: []*v1.Endpoint{
+				private(weighted(v1.ARecordType, "10.0.0.1", 120)),
+				weighted(v1.ARecordType, "3.3.3.3", 120),
+				weighted(v1.ARecordType, "4.4.4.4", 120),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &DnsReconciler{HostTargetMode: tt.mode}
+			record := &v1.DNSRecord{}
+			r.setEndpointFromTargets("xyz.dev.hcpapps.net", tt.targets, tt.visibilities, record)
+			if !reflect.DeepEqual(record.Spec.Endpoints, tt.want) {
+				t.Errorf("setEndpointFromTargets() = %v, want %v", record.Spec.Endpoints, tt.want)
+			}
+		})
+	}
+}
+
+func TestDNSReconcilerHostTargetMode(t *testing.T) {
+	managedHost := "test.cb.example.com"
+	lbHost := "lb-1.eu-west-1.elb.amazonaws.com"
+	// The endpoints are labelled with the cluster of their target
+	cluster := v1.Labels{dns.LabelCluster: "somecluster"}
+	privateCluster := v1.Labels{dns.LabelVisibility: string(dns.VisibilityPrivate), dns.LabelCluster: "somecluster"}
+
+	cases := []struct {
+		Name     string
+		Mode     dns.HostTargetMode
+		Hosts    []string
+		IPs      []string
+		Expected []*v1.Endpoint
+		Watched  []string
+	}{
+		{
+			Name:    "hosts are resolved and watched",
+			Mode:    dns.HostTargetModeResolve,
+			Hosts:   []string{"lb.example.com"},
+			Watched: []string{"lb.example.com"},
+			Expected: []*v1.Endpoint{
+				{DNSName: managedHost, RecordType: "A", RecordTTL: 60, SetIdentifier: "203.0.113.2", Targets: v1.Targets{"203.0.113.2"}, RoutingPolicy: weightedRoutingPolicy(1), Labels: cluster},
+			},
+		},
+		{
+			Name:  "hosts are published as CNAME records",
+			Mode:  dns.HostTargetModeCNAME,
+			Hosts: []string{"lb.example.com", lbHost},
+			Expected: []*v1.Endpoint{
+				{DNSName: managedHost, RecordType: "CNAME", RecordTTL: 60, SetIdentifier: lbHost, Targets: v1.Targets{lbHost}, RoutingPolicy: weightedRoutingPolicy(1), Labels: cluster},
+				{DNSName: managedHost, RecordType: "CNAME", RecordTTL: 60, SetIdentifier: "lb.example.com", Targets: v1.Targets{"lb.example.com"}, RoutingPolicy: weightedRoutingPolicy(1), Labels: cluster},
+			},
+		},
+		{
+			Name:    "hosts are resolved alongside IP targets",
+			Mode:    dns.HostTargetModeCNAME,
+			Hosts:   []string{"lb.example.com"},
+			IPs:     []string{"203.0.113.3"},
+			Watched: []string{"lb.example.com"},
+			Expected: []*v1.Endpoint{
+				{DNSName: managedHost, RecordType: "A", RecordTTL: 60, SetIdentifier: "203.0.113.2", Targets: v1.Targets{"203.0.113.2"}, RoutingPolicy: weightedRoutingPolicy(1), Labels: cluster},
+				{DNSName: managedHost, RecordType: "A", RecordTTL: 60, SetIdentifier: "203.0.113.3", Targets: v1.Targets{"203.0.113.3"}, RoutingPolicy: weightedRoutingPolicy(1), Labels: cluster},
+			},
+		},
+		{
+			Name:  "load balancer hosts are published as alias records alongside IP targets",
+			Mode:  dns.HostTargetModeAlias,
+			Hosts: []string{lbHost},
+			IPs:   []string{"203.0.113.3"},
+			Expected: []*v1.Endpoint{
+				{DNSName: managedHost, RecordType: "A", RecordTTL: 60, SetIdentifier: "203.0.113.3", Targets: v1.Targets{"203.0.113.3"}, RoutingPolicy: weightedRoutingPolicy(1), Labels: cluster},
+				{DNSName: managedHost, RecordType: "CNAME", RecordTTL: 60, SetIdentifier: lbHost, Targets: v1.Targets{lbHost}, RoutingPolicy: weightedRoutingPolicy(1),
+					ProviderSpecific: v1.ProviderSpecific{{Name: v1.ProviderSpecificAlias, Value: "true"}}, Labels: cluster},
+			},
+		},
+		{
+			Name:    "private targets are labelled",
+			Mode:    dns.HostTargetModeResolve,
+			Hosts:   []string{"lb.example.com"},
+			IPs:     []string{"10.0.0.1"},
+			Watched: []string{"lb.example.com"},
+			Expected: []*v1.Endpoint{
+				{DNSName: managedHost, RecordType: "A", RecordTTL: 60, SetIdentifier: "10.0.0.1", Targets: v1.Targets{"10.0.0.1"}, RoutingPolicy: weightedRoutingPolicy(1),
+					Labels: privateCluster},
+				{DNSName: managedHost, RecordType: "A", RecordTTL: 60, SetIdentifier: "203.0.113.2", Targets: v1.Targets{"203.0.113.2"}, RoutingPolicy: weightedRoutingPolicy(1), Labels: cluster},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			ingress := &networkingv1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "ingress",
+					Annotations: map[string]string{"kcp.dev/cluster": "somecluster"},
+				},
+			}
+			for _, host := range tc.Hosts {
+				ingress.Status.LoadBalancer.Ingress = append(ingress.Status.LoadBalancer.Ingress, corev1.LoadBalancerIngress{Hostname: host})
+			}
+			for _, ip := range tc.IPs {
+				ingress.Status.LoadBalancer.Ingress = append(ingress.Status.LoadBalancer.Ingress, corev1.LoadBalancerIngress{IP: ip})
+			}
+			accessor := NewIngress(ingress)
+			accessor.SetHCGHost(managedHost)
+
+			var updated *v1.DNSRecord
+			var watched []string
+			rec := &DnsReconciler{
+				GetDNS: func(ctx context.Context, accessor Interface) (*v1.DNSRecord, error) {
+					return &v1.DNSRecord{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{ANNOTATION_HCG_HOST: managedHost}}}, nil
+				},
+				UpdateDNS: func(ctx context.Context, dns *v1.DNSRecord) (*v1.DNSRecord, error) {
+					updated = dns
+					return dns, nil
+				},
+				ListHostWatchers: func(key interface{}) []dns.RecordWatcher { return nil },
+				WatchHost: func(ctx context.Context, key interface{}, host string) bool {
+					watched = append(watched, host)
+					return true
+				},
+				DNSLookup: func(ctx context.Context, host string) ([]dns.HostAddress, error) {
+					return []dns.HostAddress{{IP: net.ParseIP("203.0.113.2")}}, nil
+				},
+				Log:            log.New(),
+				HostTargetMode: tc.Mode,
+			}
+			if _, err := rec.Reconcile(context.TODO(), accessor); err != nil {
+				t.Fatalf("unexpected error %s", err)
+			}
+			if updated == nil {
+				t.Fatalf("expected the DNSRecord to be updated")
+			}
+			if !reflect.DeepEqual(updated.Spec.Endpoints, tc.Expected) {
+				t.Errorf("expected endpoints %v, got %v", tc.Expected, updated.Spec.Endpoints)
+			}
+			if !reflect.DeepEqual(watched, tc.Watched) {
+				t.Errorf("expected watched hosts %v, got %v", tc.Watched, watched)
+			}
+		})
+	}
+}
+
+func Test_endpointWeight(t *testing.T) {
 	type args struct {
 		numIPs int
 	}
 	tests := []struct {
 		name string
 		args args
-		want string
+		want int64
 	}{
 		{
 			name: "single ip",
 			args: args{
 				numIPs: 1,
 			},
-			want: "120",
+			want: 120,
 		},
 		{
 			name: "multiple ips 2",
 			args: args{
 				numIPs: 2,
 			},
-			want: "60",
+			want: 60,
 		},
 		{
 			name: "multiple ips 3",
 			args: args{
 				numIPs: 3,
 			},
-			want: "40",
+			want: 40,
 		},
 		{
 			name: "multiple ips 4",
 			args: args{
 				numIPs: 4,
 			},
-			want: "30",
+			want: 30,
 		},
 		{
 			name: "60 ips",
 			args: args{
 				numIPs: 60,
 			},
-			want: "2",
+			want: 2,
 		},
 		{
 			name: "61 ips",
 			args: args{
 				numIPs: 61,
 			},
-			want: "1",
+			want: 1,
 		},
 		{
 			name: "ips equal to max weight (120)",
 			args: args{
 				numIPs: 120,
 			},
-			want: "1",
+			want: 1,
 		},
 		{
 			name: "more IPs than max weight (121)",
 			args: args{
 				numIPs: 121,
 			},
-			want: "1",
+			want: 1,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := awsEndpointWeight(tt.args.numIPs); got != tt.want {
-				t.Errorf("awsEndpointWeight() = %v, want %v", got, tt.want)
+			if got := endpointWeight(tt.args.numIPs); got != tt.want {
+				t.Errorf("endpointWeight() = %v, want %v", got, tt.want)
 			}
 		})
 	}
@@ -379,3 +611,198 @@ func Test_objectKey(t *testing.T) {
 		})
 	}
 }
+
+func TestDNSReconcilerTargetPolicy(t *testing.T) {
+	managedHost := "test.cb.example.com"
+	policy, err := dns.ParseTargetPolicy("", dns.DefaultDeniedTargetCIDRs)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	cluster := v1.Labels{dns.LabelCluster: "somecluster"}
+	privateCluster := v1.Labels{dns.LabelVisibility: string(dns.VisibilityPrivate), dns.LabelCluster: "somecluster"}
+
+	cases := []struct {
+		Name         string
+		IPs          []string
+		PrivateZones bool
+		Expected     []*v1.Endpoint
+		Rejected     string
+	}{
+		{
+			Name: "reserved addresses are rejected",
+			IPs:  []string{"203.0.113.1", "10.0.0.1", "127.0.0.1", "169.254.0.1"},
+			Expected: []*v1.Endpoint{
+				{DNSName: managedHost, RecordType: "A", RecordTTL: 60, SetIdentifier: "203.0.113.1", Targets: v1.Targets{"203.0.113.1"}, RoutingPolicy: weightedRoutingPolicy(1), Labels: cluster},
+			},
+			Rejected: "10.0.0.1,127.0.0.1,169.254.0.1",
+		},
+		{
+			Name:         "private addresses are published to the private zones",
+			IPs:          []string{"203.0.113.1", "10.0.0.1", "127.0.0.1"},
+			PrivateZones: true,
+			Expected: []*v1.Endpoint{
+				{DNSName: managedHost, RecordType: "A", RecordTTL: 60, SetIdentifier: "10.0.0.1", Targets: v1.Targets{"10.0.0.1"}, RoutingPolicy: weightedRoutingPolicy(1), Labels: privateCluster},
+				{DNSName: managedHost, RecordType: "A", RecordTTL: 60, SetIdentifier: "203.0.113.1", Targets: v1.Targets{"203.0.113.1"}, RoutingPolicy: weightedRoutingPolicy(1), Labels: cluster},
+			},
+			Rejected: "127.0.0.1",
+		},
+		{
+			Name: "public addresses are published",
+			IPs:  []string{"203.0.113.1"},
+			Expected: []*v1.Endpoint{
+				{DNSName: managedHost, RecordType: "A", RecordTTL: 60, SetIdentifier: "203.0.113.1", Targets: v1.Targets{"203.0.113.1"}, RoutingPolicy: weightedRoutingPolicy(1), Labels: cluster},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			ingress := &networkingv1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "ingress",
+					Annotations: map[string]string{"kcp.dev/cluster": "somecluster", ANNOTATION_REJECTED_TARGETS: "192.168.0.1"},
+				},
+			}
+			for _, ip := range tc.IPs {
+				ingress.Status.LoadBalancer.Ingress = append(ingress.Status.LoadBalancer.Ingress, corev1.LoadBalancerIngress{IP: ip})
+			}
+			accessor := NewIngress(ingress)
+			accessor.SetHCGHost(managedHost)
+
+			var updated *v1.DNSRecord
+			rec := &DnsReconciler{
+				GetDNS: func(ctx context.Context, accessor Interface) (*v1.DNSRecord, error) {
+					return &v1.DNSRecord{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{ANNOTATION_HCG_HOST: managedHost}}}, nil
+				},
+				UpdateDNS: func(ctx context.Context, dns *v1.DNSRecord) (*v1.DNSRecord, error) {
+					updated = dns
+					return dns, nil
+				},
+				ListHostWatchers: func(key interface{}) []dns.RecordWatcher { return nil },
+				Log:              log.New(),
+				TargetPolicy:     policy,
+				PrivateZones:     tc.PrivateZones,
+			}
+			rejectedTotal := testutil.ToFloat64(DNSTargetRejectedTotal)
+			if _, err := rec.Reconcile(context.TODO(), accessor); err != nil {
+				t.Fatalf("unexpected error %s", err)
+			}
+			if updated == nil {
+				t.Fatalf("expected the DNSRecord to be updated")
+			}
+			if !reflect.DeepEqual(updated.Spec.Endpoints, tc.Expected) {
+				t.Errorf("expected endpoints %v, got %v", tc.Expected, updated.Spec.Endpoints)
+			}
+			if rejected := accessor.GetAnnotations()[ANNOTATION_REJECTED_TARGETS]; rejected != tc.Rejected {
+				t.Errorf("expected rejected targets %q, got %q", tc.Rejected, rejected)
+			}
+			// The rejected targets are only counted once
+			expectedTotal := rejectedTotal + float64(len(strings.Split(tc.Rejected, ",")))
+			if tc.Rejected == "" {
+				expectedTotal = rejectedTotal
+			}
+			if _, err := rec.Reconcile(context.TODO(), accessor); err != nil {
+				t.Fatalf("unexpected error %s", err)
+			}
+			if total := testutil.ToFloat64(DNSTargetRejectedTotal); total != expectedTotal {
+				t.Errorf("expected %v rejected targets to be counted, got %v", expectedTotal-rejectedTotal, total-rejectedTotal)
+			}
+		})
+	}
+}
+
+func Test_setCAAEndpoints(t *testing.T) {
+	caa := func(name string) *v1.Endpoint {
+		return &v1.Endpoint{DNSName: name, RecordType: "CAA", RecordTTL: 300, Targets: v1.Targets{`0 issue "letsencrypt.org"`}}
+	}
+	a := &v1.Endpoint{DNSName: "test.cb.example.com", RecordType: "A", RecordTTL: 60, SetIdentifier: "203.0.113.1", Targets: v1.Targets{"203.0.113.1"}}
+	aaaa := &v1.Endpoint{DNSName: "test.cb.example.com", RecordType: "AAAA", RecordTTL: 60, SetIdentifier: "2001:db8::1", Targets: v1.Targets{"2001:db8::1"}}
+	cname := &v1.Endpoint{DNSName: "lb.cb.example.com", RecordType: "CNAME", RecordTTL: 60, Targets: v1.Targets{"lb.example.net"}}
+
+	cases := []struct {
+		Name      string
+		Issuers   []string
+		Endpoints []*v1.Endpoint
+		Expected  []*v1.Endpoint
+	}{
+		{
+			Name:      "no CAA record without issuers",
+			Endpoints: []*v1.Endpoint{a},
+			Expected:  []*v1.Endpoint{a},
+		},
+		{
+			Name:      "a single CAA record per name",
+			Issuers:   []string{"letsencrypt.org"},
+			Endpoints: []*v1.Endpoint{a, aaaa},
+			Expected:  []*v1.Endpoint{a, aaaa, caa("test.cb.example.com")},
+		},
+		{
+			Name:      "no CAA record for CNAME names",
+			Issuers:   []string{"letsencrypt.org"},
+			Endpoints: []*v1.Endpoint{cname},
+			Expected:  []*v1.Endpoint{cname},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			record := &v1.DNSRecord{Spec: v1.DNSRecordSpec{Endpoints: append([]*v1.Endpoint{}, tc.Endpoints...)}}
+			rec := &DnsReconciler{CAAIssuers: tc.Issuers}
+			rec.setCAAEndpoints(record)
+			if !reflect.DeepEqual(record.Spec.Endpoints, tc.Expected) {
+				t.Errorf("expected endpoints %v, got %v", tc.Expected, record.Spec.Endpoints)
+			}
+		})
+	}
+}
+
+func Test_setEndpointHealth(t *testing.T) {
+	cases := []struct {
+		Name      string
+		Health    []v1.EndpointHealth
+		Healthy   string
+		Unhealthy string
+	}{
+		{
+			Name: "no annotation without health checks",
+		},
+		{
+			Name: "all endpoints healthy",
+			Health: []v1.EndpointHealth{
+				{DNSName: "test.cb.example.com", SetIdentifier: "203.0.113.1", Health: v1.Healthy},
+				{DNSName: "test.cb.example.com", SetIdentifier: "203.0.113.2", Health: v1.Healthy},
+			},
+			Healthy: "2/2",
+		},
+		{
+			Name: "unhealthy endpoints are listed",
+			Health: []v1.EndpointHealth{
+				{DNSName: "test.cb.example.com", SetIdentifier: "203.0.113.2", Health: v1.Unhealthy},
+				{DNSName: "test.cb.example.com", SetIdentifier: "203.0.113.1", Health: v1.Unhealthy},
+				{DNSName: "test.cb.example.com", SetIdentifier: "203.0.113.3", Health: v1.HealthUnknown},
+			},
+			Healthy:   "0/3",
+			Unhealthy: "203.0.113.1,203.0.113.2",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			ingress := &networkingv1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "ingress",
+					Annotations: map[string]string{ANNOTATION_UNHEALTHY_ENDPOINTS: "192.168.0.1"},
+				},
+			}
+			accessor := NewIngress(ingress)
+			rec := &DnsReconciler{}
+			rec.setEndpointHealth(accessor, &v1.DNSRecord{Status: v1.DNSRecordStatus{Health: tc.Health}})
+			if healthy := accessor.GetAnnotations()[ANNOTATION_HEALTHY_ENDPOINTS]; healthy != tc.Healthy {
+				t.Errorf("expected healthy endpoints %q, got %q", tc.Healthy, healthy)
+			}
+			if unhealthy := accessor.GetAnnotations()[ANNOTATION_UNHEALTHY_ENDPOINTS]; unhealthy != tc.Unhealthy {
+				t.Errorf("expected unhealthy endpoints %q, got %q", tc.Unhealthy, unhealthy)
+			}
+		})
+	}
+}
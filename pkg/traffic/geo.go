@@ -0,0 +1,179 @@
+package traffic
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+
+	"k8s.io/apimachinery/pkg/labels"
+
+	workload "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+	workloadlisters "github.com/kcp-dev/kcp/pkg/client/listers/workload/v1alpha1"
+
+	v1 "github.com/kuadrant/kcp-glbc/pkg/apis/kuadrant/v1"
+	"github.com/kuadrant/kcp-glbc/pkg/dns"
+)
+
+// defaultGeo is the name of the group of targets whose continent is unknown.
+const defaultGeo = "default"
+
+// continentCodes are the continent codes supported by geolocation routing.
+// https://docs.aws.amazon.com/Route53/latest/DeveloperGuide/resource-record-sets-values-geo.html
+var continentCodes = map[string]struct{}{
+	"AF": {},
+	"AN": {},
+	"AS": {},
+	"EU": {},
+	"NA": {},
+	"OC": {},
+	"SA": {},
+}
+
+// ContinentFromSyncTargets returns a function that looks up the continent of a
+// DNS target from the LABEL_CONTINENT label of the SyncTarget it originates from.
+// An empty continent is returned for targets that do not originate from a
+// SyncTarget, or whose SyncTarget is not labelled.
+func ContinentFromSyncTargets(lister workloadlisters.SyncTargetLister) func(ctx context.Context, target dns.Target) (string, error) {
+	return func(ctx context.Context, target dns.Target) (string, error) {
+		syncTargets, err := lister.List(labels.Everything())
+		if err != nil {
+			return "", err
+		}
+		for _, syncTarget := range syncTargets {
+			if workload.ToSyncTargetKey(logicalcluster.From(syncTarget), syncTarget.Name) != target.Cluster {
+				continue
+			}
+			continent, ok := syncTarget.Labels[LABEL_CONTINENT]
+			if !ok {
+				return "", nil
+			}
+			continent = strings.ToUpper(continent)
+			if _, ok := continentCodes[continent]; !ok {
+				return "", fmt.Errorf("invalid continent code %s on sync target %s", continent, syncTarget.Name)
+			}
+			return continent, nil
+		}
+		return "", nil
+	}
+}
+
+// setGeoEndpointsFromTargets sets the endpoints of the DNS record so that the
+// traffic is routed to the closest continent the targets are deployed to, as
+// described in docs/proposals/geo-aware-dns.md:
+//
+//   - a weighted A or AAAA record for each IP of a continent, under <id>.<continent>.<domain>
+//   - a CNAME record for each continent, with a geolocation routing policy, pointing to <id>.<continent>.<domain>
+//   - a default CNAME record, for the other continents, pointing to the targets whose continent is unknown if any,
+//     or to the first continent otherwise
+//
+// The continents map the hosts of the targets to their continent code. The
+// endpoints of the private targets are labelled as such, as are the CNAME
+// records of the continents whose targets are all private, so that the public
+// zones do not point to names that only exist in the private zones.
+func (r *DnsReconciler) setGeoEndpointsFromTargets(dnsName string, dnsTargets map[string][]string, continents map[string]string, visibilities map[string]dns.Visibility, dnsRecord *v1.DNSRecord) {
+	currentEndpoints := make(map[string]*v1.Endpoint, len(dnsRecord.Spec.Endpoints))
+	for _, endpoint := range dnsRecord.Spec.Endpoints {
+		currentEndpoints[geoEndpointKey(endpoint.DNSName, endpoint.SetIdentifier)] = endpoint
+	}
+	endpointFor := func(name, setIdentifier string) *v1.Endpoint {
+		// If the endpoint does not exist, add a new one
+		endpoint, ok := currentEndpoints[geoEndpointKey(name, setIdentifier)]
+		if !ok {
+			endpoint = &v1.Endpoint{
+				SetIdentifier: setIdentifier,
+			}
+		}
+		endpoint.DNSName = name
+		endpoint.RecordTTL = 60
+		return endpoint
+	}
+
+	geoTargets := map[string]map[string][]string{}
+	for host, targets := range dnsTargets {
+		geo, ok := continents[host]
+		if !ok {
+			geo = defaultGeo
+		}
+		if _, ok := geoTargets[geo]; !ok {
+			geoTargets[geo] = map[string][]string{}
+		}
+		geoTargets[geo][host] = targets
+	}
+
+	geos := make([]string, 0, len(geoTargets))
+	for geo := range geoTargets {
+		geos = append(geos, geo)
+	}
+	sort.Strings(geos)
+
+	geoVisibilities := map[string]dns.Visibility{}
+	var newEndpoints []*v1.Endpoint
+	for _, geo := range geos {
+		geoHost := geoHostName(dnsName, geo)
+		geoVisibilities[geo] = dns.VisibilityPrivate
+		for _, targets := range geoTargets[geo] {
+			counts := countTargetsByRecordType(targets)
+			for _, target := range targets {
+				recordType := recordTypeForTarget(target)
+				endpoint := endpointFor(geoHost, target)
+				endpoint.RecordType = string(recordType)
+				endpoint.Targets = []string{target}
+				endpoint.SetRoutingPolicy(weightedRoutingPolicy(counts[recordType]))
+				r.setAlias(endpoint)
+				setVisibility(endpoint, visibilities[target])
+				if visibilities[target] != dns.VisibilityPrivate {
+					geoVisibilities[geo] = dns.VisibilityPublic
+				}
+				newEndpoints = append(newEndpoints, endpoint)
+			}
+		}
+		if geo == defaultGeo {
+			continue
+		}
+		endpoint := endpointFor(dnsName, geo)
+		endpoint.RecordType = string(v1.CNAMERecordType)
+		endpoint.Targets = []string{geoHost}
+		endpoint.SetRoutingPolicy(&v1.RoutingPolicy{Geo: &v1.GeoRoutingPolicy{Continent: geo}})
+		setVisibility(endpoint, geoVisibilities[geo])
+		newEndpoints = append(newEndpoints, endpoint)
+	}
+
+	if len(geos) > 0 {
+		defaultTarget := geos[0]
+		if _, ok := geoTargets[defaultGeo]; ok {
+			defaultTarget = defaultGeo
+		}
+		endpoint := endpointFor(dnsName, defaultGeo)
+		endpoint.RecordType = string(v1.CNAMERecordType)
+		endpoint.Targets = []string{geoHostName(dnsName, defaultTarget)}
+		endpoint.SetRoutingPolicy(&v1.RoutingPolicy{Geo: &v1.GeoRoutingPolicy{Default: true}})
+		setVisibility(endpoint, geoVisibilities[defaultTarget])
+		newEndpoints = append(newEndpoints, endpoint)
+	}
+
+	sort.Slice(newEndpoints, func(i, j int) bool {
+		if newEndpoints[i].DNSName != newEndpoints[j].DNSName {
+			return newEndpoints[i].DNSName < newEndpoints[j].DNSName
+		}
+		return newEndpoints[i].SetIdentifier < newEndpoints[j].SetIdentifier
+	})
+
+	dnsRecord.Spec.Endpoints = newEndpoints
+}
+
+// geoHostName inserts the lower-cased geo code after the first label of the
+// host, e.g. xyz.dev.hcpapps.net becomes xyz.na.dev.hcpapps.net for NA.
+func geoHostName(host, geo string) string {
+	parts := strings.SplitN(host, ".", 2)
+	if len(parts) < 2 {
+		return strings.ToLower(geo) + "." + host
+	}
+	return parts[0] + "." + strings.ToLower(geo) + "." + parts[1]
+}
+
+func geoEndpointKey(dnsName, setIdentifier string) string {
+	return dnsName + "/" + setIdentifier
+}
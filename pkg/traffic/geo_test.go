@@ -0,0 +1,218 @@
+package traffic
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	workload "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+	workloadlisters "github.com/kcp-dev/kcp/pkg/client/listers/workload/v1alpha1"
+
+	v1 "github.com/kuadrant/kcp-glbc/pkg/apis/kuadrant/v1"
+	"github.com/kuadrant/kcp-glbc/pkg/dns"
+)
+
+func Test_geoHostName(t *testing.T) {
+	tests := []struct {
+		name string
+		host string
+		geo  string
+		want string
+	}{
+		{
+			name: "continent",
+			host: "xyz.dev.hcpapps.net",
+			geo:  "NA",
+			want: "xyz.na.dev.hcpapps.net",
+		},
+		{
+			name: "default",
+			host: "xyz.dev.hcpapps.net",
+			geo:  defaultGeo,
+			want: "xyz.default.dev.hcpapps.net",
+		},
+		{
+			name: "single label",
+			host: "localhost",
+			geo:  "EU",
+			want: "eu.localhost",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := geoHostName(tt.host, tt.geo); got != tt.want {
+				t.Errorf("geoHostName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContinentFromSyncTargets(t *testing.T) {
+	syncTarget := func(name, continent string) *workload.SyncTarget {
+		st := &workload.SyncTarget{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Annotations: map[string]string{logicalcluster.AnnotationKey: "root:org:ws"},
+				Labels:      map[string]string{},
+			},
+		}
+		if continent != "" {
+			st.Labels[LABEL_CONTINENT] = continent
+		}
+		return st
+	}
+	targetFor := func(name string) dns.Target {
+		return dns.Target{Cluster: workload.ToSyncTargetKey(logicalcluster.New("root:org:ws"), name), TargetType: dns.TargetTypeIP, Value: "1.1.1.1"}
+	}
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, st := range []*workload.SyncTarget{
+		syncTarget("eu-cluster", "eu"),
+		syncTarget("unlabelled-cluster", ""),
+		syncTarget("invalid-cluster", "XX"),
+	} {
+		if err := indexer.Add(st); err != nil {
+			t.Fatalf("unexpected error adding sync target: %v", err)
+		}
+	}
+	getContinent := ContinentFromSyncTargets(workloadlisters.NewSyncTargetLister(indexer))
+
+	tests := []struct {
+		name    string
+		target  dns.Target
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "labelled sync target",
+			target: targetFor("eu-cluster"),
+			want:   "EU",
+		},
+		{
+			name:   "unlabelled sync target",
+			target: targetFor("unlabelled-cluster"),
+			want:   "",
+		},
+		{
+			name:   "unknown sync target",
+			target: targetFor("unknown-cluster"),
+			want:   "",
+		},
+		{
+			name:    "invalid continent",
+			target:  targetFor("invalid-cluster"),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getContinent(context.TODO(), tt.target)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ContinentFromSyncTargets() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ContinentFromSyncTargets() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_setGeoEndpointsFromTargets(t *testing.T) {
+	weighted := func(name, ip string, weight int64) *v1.Endpoint {
+		return &v1.Endpoint{
+			DNSName:       name,
+			RecordType:    string(recordTypeForTarget(ip)),
+			RecordTTL:     60,
+			SetIdentifier: ip,
+			Targets:       v1.Targets{ip},
+			RoutingPolicy: &v1.RoutingPolicy{Weighted: &v1.WeightedRoutingPolicy{Weight: weight}},
+		}
+	}
+	geolocated := func(name, setIdentifier, target string, geo v1.GeoRoutingPolicy) *v1.Endpoint {
+		return &v1.Endpoint{
+			DNSName:       name,
+			RecordType:    string(v1.CNAMERecordType),
+			RecordTTL:     60,
+			SetIdentifier: setIdentifier,
+			Targets:       v1.Targets{target},
+			RoutingPolicy: &v1.RoutingPolicy{Geo: &geo},
+		}
+	}
+
+	private := func(endpoint *v1.Endpoint) *v1.Endpoint {
+		endpoint.Labels = v1.Labels{dns.LabelVisibility: string(dns.VisibilityPrivate)}
+		return endpoint
+	}
+
+	tests := []struct {
+		name         string
+		targets      map[string][]string
+		continents   map[string]string
+		visibilities map[string]dns.Visibility
+		want         []*v1.Endpoint
+	}{
+		{
+			name:       "single continent",
+			targets:    map[string][]string{"eu.lb": {"1.1.1.1", "2.2.2.2"}},
+			continents: map[string]string{"eu.lb": "EU"},
+			want: []*v1.Endpoint{
+				geolocated("xyz.dev.hcpapps.net", "EU", "xyz.eu.dev.hcpapps.net", v1.GeoRoutingPolicy{Continent: "EU"}),
+				geolocated("xyz.dev.hcpapps.net", "default", "xyz.eu.dev.hcpapps.net", v1.GeoRoutingPolicy{Default: true}),
+				weighted("xyz.eu.dev.hcpapps.net", "1.1.1.1", 60),
+				weighted("xyz.eu.dev.hcpapps.net", "2.2.2.2", 60),
+			},
+		},
+		{
+			name:       "continent and unknown continent",
+			targets:    map[string][]string{"na.lb": {"1.1.1.1"}, "other.lb": {"3.3.3.3"}},
+			continents: map[string]string{"na.lb": "NA"},
+			want: []*v1.Endpoint{
+				weighted("xyz.default.dev.hcpapps.net", "3.3.3.3", 120),
+				geolocated("xyz.dev.hcpapps.net", "NA", "xyz.na.dev.hcpapps.net", v1.GeoRoutingPolicy{Continent: "NA"}),
+				geolocated("xyz.dev.hcpapps.net", "default", "xyz.default.dev.hcpapps.net", v1.GeoRoutingPolicy{Default: true}),
+				weighted("xyz.na.dev.hcpapps.net", "1.1.1.1", 120),
+			},
+		},
+		{
+			name:       "dual-stack continent",
+			targets:    map[string][]string{"eu.lb": {"1.1.1.1", "2.2.2.2", "2001:db8::1"}},
+			continents: map[string]string{"eu.lb": "EU"},
+			want: []*v1.Endpoint{
+				geolocated("xyz.dev.hcpapps.net", "EU", "xyz.eu.dev.hcpapps.net", v1.GeoRoutingPolicy{Continent: "EU"}),
+				geolocated("xyz.dev.hcpapps.net", "default", "xyz.eu.dev.hcpapps.net", v1.GeoRoutingPolicy{Default: true}),
+				weighted("xyz.eu.dev.hcpapps.net", "1.1.1.1", 60),
+				weighted("xyz.eu.dev.hcpapps.net", "2.2.2.2", 60),
+				weighted("xyz.eu.dev.hcpapps.net", "2001:db8::1", 120),
+			},
+		},
+		{
+			name:         "private targets",
+			targets:      map[string][]string{"eu.lb": {"10.0.0.1", "2.2.2.2"}, "na.lb": {"10.0.0.2"}},
+			continents:   map[string]string{"eu.lb": "EU", "na.lb": "NA"},
+			visibilities: map[string]dns.Visibility{"10.0.0.1": dns.VisibilityPrivate, "10.0.0.2": dns.VisibilityPrivate, "2.2.2.2": dns.VisibilityPublic},
+			want: []*v1.Endpoint{
+				geolocated("xyz.dev.hcpapps.net", "EU", "xyz.eu.dev.hcpapps.net", v1.GeoRoutingPolicy{Continent: "EU"}),
+				private(geolocated("xyz.dev.hcpapps.net", "NA", "xyz.na.dev.hcpapps.net", v1.GeoRoutingPolicy{Continent: "NA"})),
+				geolocated("xyz.dev.hcpapps.net", "default", "xyz.eu.dev.hcpapps.net", v1.GeoRoutingPolicy{Default: true}),
+				private(weighted("xyz.eu.dev.hcpapps.net", "10.0.0.1", 60)),
+				weighted("xyz.eu.dev.hcpapps.net", "2.2.2.2", 60),
+				private(weighted("xyz.na.dev.hcpapps.net", "10.0.0.2", 120)),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &DnsReconciler{}
+			record := &v1.DNSRecord{}
+			r.setGeoEndpointsFromTargets("xyz.dev.hcpapps.net", tt.targets, tt.continents, tt.visibilities, record)
+			if !reflect.DeepEqual(record.Spec.Endpoints, tt.want) {
+				t.Errorf("setGeoEndpointsFromTargets() = %v, want %v", record.Spec.Endpoints, tt.want)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net"
 	"strings"
 
 	"github.com/kcp-dev/logicalcluster/v2"
@@ -145,12 +146,17 @@ func (a *Ingress) GetDNSTargets() ([]dns.Target, error) {
 			if lb.IP != "" {
 				dnsTarget.TargetType = dns.TargetTypeIP
 				dnsTarget.Value = lb.IP
+				// Use the canonical form of IPv6 addresses, as they are used as record set identifiers
+				if ip := net.ParseIP(lb.IP); ip != nil {
+					dnsTarget.Value = ip.String()
+				}
 			}
 			if lb.Hostname != "" {
 				dnsTarget.TargetType = dns.TargetTypeHost
 				dnsTarget.Value = lb.Hostname
 
 			}
+			dnsTarget.Visibility = targetVisibility(a, dnsTarget)
 			dnsTargets = append(dnsTargets, dnsTarget)
 		}
 	}
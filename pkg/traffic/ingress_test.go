@@ -396,6 +396,7 @@ func TestGetDNSTargetsIngress(t *testing.T) {
 						Cluster:    targetCluster,
 						TargetType: dns.TargetTypeIP,
 						Value:      targetHost,
+						Visibility: dns.VisibilityPublic,
 					}
 
 					if !containsTarget(targets, expectedTarget) {
@@ -405,6 +406,90 @@ func TestGetDNSTargetsIngress(t *testing.T) {
 				return nil
 			},
 		},
+		{
+			Name: "test dual-stack cluster IPs",
+			Ingress: func() *networkingv1.Ingress {
+				ing := defaultTestIngress([]string{"guid.example.com"}, "test", []networkingv1.IngressTLS{{
+					Hosts:      []string{"guid.example.com"},
+					SecretName: "test",
+				}})
+				c1 := networkingv1.IngressStatus{
+					LoadBalancer: v1.LoadBalancerStatus{
+						Ingress: []v1.LoadBalancerIngress{
+							{
+								IP: fmt.Sprintf(lbIPFmt, 0),
+							},
+							{
+								IP: "2001:DB8:0:0:0:0:0:1",
+							},
+						},
+					},
+				}
+				ing.Annotations = map[string]string{}
+				jsonStatus, _ := json.Marshal(c1)
+				ing.Annotations[workload.InternalClusterStatusAnnotationPrefix+fmt.Sprintf(clusterFmt, 0)] = string(jsonStatus)
+				return ing
+			},
+			Validate: func(targets []dns.Target) error {
+				if len(targets) != 2 {
+					return fmt.Errorf("expected 2 dns targets but got %v", len(targets))
+				}
+				targetCluster := fmt.Sprintf(clusterFmt, 0)
+				for _, ip := range []string{fmt.Sprintf(lbIPFmt, 0), "2001:db8::1"} {
+					expectedTarget := dns.Target{
+						Cluster:    targetCluster,
+						TargetType: dns.TargetTypeIP,
+						Value:      ip,
+						Visibility: dns.VisibilityPublic,
+					}
+					if !containsTarget(targets, expectedTarget) {
+						return fmt.Errorf("dns target %v not present", expectedTarget)
+					}
+				}
+				return nil
+			},
+		},
+		{
+			Name: "test private cluster targets",
+			Ingress: func() *networkingv1.Ingress {
+				ing := defaultTestIngress([]string{"guid.example.com"}, "test", []networkingv1.IngressTLS{{
+					Hosts:      []string{"guid.example.com"},
+					SecretName: "test",
+				}})
+				c0 := networkingv1.IngressStatus{
+					LoadBalancer: v1.LoadBalancerStatus{
+						Ingress: []v1.LoadBalancerIngress{{IP: "10.0.0.1"}},
+					},
+				}
+				c1 := networkingv1.IngressStatus{
+					LoadBalancer: v1.LoadBalancerStatus{
+						Ingress: []v1.LoadBalancerIngress{{Hostname: fmt.Sprintf(lbHostFmt, 1)}},
+					},
+				}
+				ing.Annotations = map[string]string{}
+				jsonStatus, _ := json.Marshal(c0)
+				ing.Annotations[workload.InternalClusterStatusAnnotationPrefix+fmt.Sprintf(clusterFmt, 0)] = string(jsonStatus)
+				jsonStatus, _ = json.Marshal(c1)
+				ing.Annotations[workload.InternalClusterStatusAnnotationPrefix+fmt.Sprintf(clusterFmt, 1)] = string(jsonStatus)
+				ing.Annotations[traffic.ANNOTATION_VISIBILITY_PREFIX+fmt.Sprintf(clusterFmt, 1)] = string(dns.VisibilityPrivate)
+				return ing
+			},
+			Validate: func(targets []dns.Target) error {
+				if len(targets) != 2 {
+					return fmt.Errorf("expected 2 dns targets but got %v", len(targets))
+				}
+				// The IP is classified by its range, and the host by the annotation of its cluster
+				for _, expectedTarget := range []dns.Target{
+					{Cluster: fmt.Sprintf(clusterFmt, 0), TargetType: dns.TargetTypeIP, Value: "10.0.0.1", Visibility: dns.VisibilityPrivate},
+					{Cluster: fmt.Sprintf(clusterFmt, 1), TargetType: dns.TargetTypeHost, Value: fmt.Sprintf(lbHostFmt, 1), Visibility: dns.VisibilityPrivate},
+				} {
+					if !containsTarget(targets, expectedTarget) {
+						return fmt.Errorf("dns target %v not present", expectedTarget)
+					}
+				}
+				return nil
+			},
+		},
 	}
 
 	for _, tc := range cases {
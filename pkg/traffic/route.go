@@ -160,6 +160,7 @@ func (a *Route) GetDNSTargets() ([]dns.Target, error) {
 				return nil, fmt.Errorf("no usable host value on route (%v) status", a.Name)
 			}
 			target := dns.Target{Value: host, TargetType: dns.TargetTypeHost, Cluster: cluster.String()}
+			target.Visibility = targetVisibility(a, target)
 			dnsTargets = append(dnsTargets, target)
 		}
 	}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net"
 	"strings"
 
 	workload "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
@@ -30,10 +31,14 @@ const (
 	ANNOTATION_TRAFFIC_KIND             = "kuadrant.dev/traffic-kind"
 	ANNOTATION_CERTIFICATE_STATE        = "kuadrant.dev/certificate-status"
 	ANNOTATION_HCG_HOST                 = "kuadrant.dev/host.generated"
-	ANNOTATION_HEALTH_CHECK_PREFIX      = "kuadrant.experimental/health-"
 	ANNOTATION_HCG_CUSTOM_HOST_REPLACED = "kuadrant.dev/custom-hosts-status.removed"
 	ANNOTATION_PENDING_CUSTOM_HOSTS     = "kuadrant.dev/pendingCustomHosts"
+	ANNOTATION_VISIBILITY_PREFIX        = "visibility.kuadrant.dev/"
+	ANNOTATION_REJECTED_TARGETS         = "kuadrant.dev/rejected-targets"
+	ANNOTATION_HEALTHY_ENDPOINTS        = "kuadrant.dev/healthy-endpoints"
+	ANNOTATION_UNHEALTHY_ENDPOINTS      = "kuadrant.dev/unhealthy-endpoints"
 	LABEL_HAS_PENDING_HOSTS             = "kuadrant.dev/hasPendingCustomHosts"
+	LABEL_CONTINENT                     = "kuadrant.dev/continent"
 	FINALIZER_CASCADE_CLEANUP           = "kuadrant.dev/cascade-cleanup"
 )
 
@@ -73,6 +78,21 @@ func tmcEnabled(obj metav1.Object) bool {
 	return has
 }
 
+// targetVisibility returns the visibility of the target set by the visibility annotation of its cluster, if any, or
+// otherwise the visibility of its address range. The visibility of the hosts is left unknown, unless annotated.
+func targetVisibility(obj metav1.Object, target dns.Target) dns.Visibility {
+	switch visibility := dns.Visibility(metadata.GetAnnotation(obj, ANNOTATION_VISIBILITY_PREFIX+target.Cluster)); visibility {
+	case dns.VisibilityPublic, dns.VisibilityPrivate:
+		return visibility
+	}
+	if target.TargetType == dns.TargetTypeIP {
+		if ip := net.ParseIP(target.Value); ip != nil {
+			return dns.VisibilityForIP(ip)
+		}
+	}
+	return ""
+}
+
 func getSyncTargets(obj metav1.Object) []string {
 	_, labels := metadata.HasLabelsContaining(obj, workload.ClusterResourceStateLabelPrefix)
 	clusters := []string{}
@@ -87,6 +87,15 @@ var (
 		},
 	)
 
+	// DNSTargetRejectedTotal is a prometheus counter metrics which holds the total
+	// number of target addresses rejected by the target policy.
+	DNSTargetRejectedTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "glbc_dns_target_rejected_total",
+			Help: "GLBC total number of DNS target addresses rejected by the target policy",
+		},
+	)
+
 	// TlsCertificateRequestErrors is a prometheus counter metrics which holds the total
 	// number of failed TLS certificate requests.
 	TlsCertificateRequestErrors = prometheus.NewCounterVec(
@@ -109,6 +118,7 @@ func init() {
 		TlsCertificateRequestErrors,
 		TlsCertificateRequestTotal,
 		TlsCertificateIssuanceDuration,
+		DNSTargetRejectedTotal,
 	)
 }
 